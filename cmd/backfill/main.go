@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/database"
+	"github.com/DevAnuragT/context_keeper/internal/repository"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+	"github.com/DevAnuragT/context_keeper/internal/services/connectors"
+	_ "github.com/lib/pq"
+)
+
+// main replays the whole history of a platform connector into a project's
+// knowledge graph and exits. Scheduled syncs only read what changed since the
+// last one, so run it once after adding a connector, such as a local git
+// repository, whose history predates the project.
+func main() {
+	cfg := config.Load()
+	projectID := flag.String("project", "", "Project to ingest the history into (required)")
+	platform := flag.String("platform", "gitlocal", "Connector whose history is replayed")
+	batchSize := flag.Int("batch-size", 0, "Events to process per batch (default: the connector's batch size)")
+	flag.Parse()
+
+	if *projectID == "" {
+		fmt.Fprintln(os.Stderr, "contextkeeper-backfill: -project is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger := &services.SimpleLogger{}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectorManager, err := connectors.LoadConnectorManager(cfg.Connectors, logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-backfill: %v\n", err)
+		os.Exit(1)
+	}
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-backfill: failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-backfill: failed to ping database: %v\n", err)
+		os.Exit(1)
+	}
+	if err := database.Migrate(db); err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-backfill: failed to run migrations: %v\n", err)
+		os.Exit(1)
+	}
+
+	repo := repository.New(db)
+	if _, err := repo.GetProjectWorkspace(ctx, *projectID); err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-backfill: failed to get project %s: %v\n", *projectID, err)
+		os.Exit(1)
+	}
+
+	// Knowledge is extracted by LLM as well as by pattern when AI_LLM_BASE_URL is set
+	contextProcessor := services.NewContextProcessor(services.NewAIService(cfg.AIService, logger), logger)
+	knowledgeGraphSvc := services.NewKnowledgeGraphService(repo, services.NewPermissionService(repo), contextProcessor, logger)
+
+	logger.Info("Backfilling history", map[string]interface{}{
+		"project_id": *projectID,
+		"platform":   *platform,
+	})
+	processed := 0
+	err = connectors.Backfill(ctx, connectorManager, *platform, *batchSize, func(events []services.NormalizedEvent) error {
+		if _, err := knowledgeGraphSvc.ProcessAndStoreProjectEvents(ctx, *projectID, events); err != nil {
+			return err
+		}
+		processed += len(events)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-backfill: processed %d events before failing: %v\n", processed, err)
+		os.Exit(1)
+	}
+
+	logger.Info("Backfill completed", map[string]interface{}{
+		"processed": processed,
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/database"
+	"github.com/DevAnuragT/context_keeper/internal/repository"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+	_ "github.com/lib/pq"
+)
+
+// main embeds every knowledge entity that has no embedding from the configured
+// model, or whose content changed since it was embedded, and exits. Run it after
+// enabling or switching embedding models rather than waiting for the server's
+// background worker to catch up.
+func main() {
+	cfg := config.Load()
+	batchSize := flag.Int("batch-size", cfg.Embedding.BatchSize, "Entities to embed per request")
+	flag.Parse()
+
+	logger := &services.SimpleLogger{}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-embed: failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-embed: failed to ping database: %v\n", err)
+		os.Exit(1)
+	}
+	if err := database.Migrate(db); err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-embed: failed to run migrations: %v\n", err)
+		os.Exit(1)
+	}
+
+	embedder := services.NewEmbedder(cfg.Embedding, logger)
+	worker := services.NewEmbeddingWorker(repository.New(db), embedder, logger, *batchSize, 0)
+
+	logger.Info("Backfilling embeddings", map[string]interface{}{
+		"model":      embedder.Model(),
+		"batch_size": *batchSize,
+	})
+	embedded, err := worker.Backfill(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contextkeeper-embed: embedded %d entities before failing: %v\n", embedded, err)
+		os.Exit(1)
+	}
+
+	logger.Info("Backfill completed", map[string]interface{}{
+		"embedded": embedded,
+	})
+}
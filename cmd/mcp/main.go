@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/DevAnuragT/context_keeper/internal/repository"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+	"github.com/DevAnuragT/context_keeper/internal/services/connectors"
+	_ "github.com/lib/pq"
+)
+
+// main runs the MCP server over stdio so it can be launched directly by MCP clients.
+// All diagnostics go to stderr; stdout is reserved for JSON-RPC messages.
+func main() {
+	apiURL := flag.String("api-url", os.Getenv("CONTEXTKEEPER_API_URL"), "ContextKeeper API base URL; when set, requests are forwarded to the remote server instead of a local database")
+	token := flag.String("token", os.Getenv("CONTEXTKEEPER_TOKEN"), "ContextKeeper access token")
+	projectID := flag.String("project", os.Getenv("CONTEXTKEEPER_PROJECT_ID"), "Default project for tool calls; tools may override it with a project_id argument")
+	flag.Parse()
+
+	logger := &services.SimpleLogger{}
+
+	if *token == "" {
+		fmt.Fprintln(os.Stderr, "contextkeeper-mcp: an access token is required (set CONTEXTKEEPER_TOKEN or pass -token)")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var handler services.MCPMessageHandler
+	if *apiURL != "" {
+		logger.Info("Starting MCP stdio server in remote mode", map[string]interface{}{
+			"api_url": *apiURL,
+		})
+		handler = services.NewMCPRemoteClient(*apiURL, *token, *projectID)
+	} else {
+		db, user, mcpServer, err := newLocalMCPServer(*token, logger)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "contextkeeper-mcp: %v\n", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+
+		logger.Info("Starting MCP stdio server in local mode", nil)
+		handler = mcpServer
+		ctx = services.WithMCPUser(ctx, user)
+	}
+
+	if *projectID != "" {
+		ctx = services.WithMCPProject(ctx, *projectID)
+	}
+
+	if err := services.ServeMCPStdio(ctx, handler, os.Stdin, os.Stdout, logger); err != nil && err != context.Canceled {
+		logger.Error("MCP stdio server stopped", err, nil)
+		os.Exit(1)
+	}
+}
+
+// newLocalMCPServer connects to the configured database and builds the MCP server
+// with the same services the HTTP server uses
+func newLocalMCPServer(token string, logger services.Logger) (*sql.DB, *models.User, *services.MCPServer, error) {
+	cfg := config.Load()
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, nil, nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	repo := repository.New(db)
+
+	passwordSvc := services.NewPasswordService()
+	emailSvc := services.NewEmailService(cfg)
+	googleOAuthSvc := services.NewGoogleOAuthService(cfg)
+	authSvc := services.NewAuthService(cfg, repo, passwordSvc, emailSvc, googleOAuthSvc)
+
+	// Fail fast on a bad token rather than on the first tool call
+	user, err := authSvc.ValidateJWT(token)
+	if err != nil {
+		db.Close()
+		return nil, nil, nil, fmt.Errorf("invalid access token: %w", err)
+	}
+
+	logger.Info("Authenticated MCP stdio session", map[string]interface{}{
+		"user_id": user.ID,
+	})
+
+	return db, user, newMCPServer(cfg, repo, logger), nil
+}
+
+// newMCPServer builds the MCP server and the services behind it
+func newMCPServer(cfg *config.Config, repo services.RepositoryStore, logger services.Logger) *services.MCPServer {
+	permissionSvc := services.NewPermissionService(repo)
+	var contextGenerator services.ContextGenerator
+	if cfg.AIService.BaseURL != "" {
+		contextGenerator = services.NewHTTPContextGenerator(cfg.AIService.BaseURL, time.Duration(cfg.AIService.Timeout)*time.Second)
+	}
+	contextSvc := services.NewContextService(repo, permissionSvc, services.NewContextEngine(contextGenerator, logger))
+
+	contextProcessor := services.NewContextProcessor(services.NewAIService(cfg.AIService, logger), logger)
+	knowledgeGraphSvc := services.NewKnowledgeGraphService(repo, permissionSvc, contextProcessor, logger)
+	knowledgeGraphSvc.SetEmbedder(services.NewEmbedder(cfg.Embedding, logger))
+
+	mcpServer := services.NewMCPServer(knowledgeGraphSvc, contextSvc, permissionSvc, repo, logger)
+
+	// Sync integrations on request through the configured platform connectors
+	connectorManager, err := connectors.LoadConnectorManager(cfg.Connectors, logger)
+	if err != nil {
+		logger.Error("Failed to load platform connectors", err, nil)
+		connectorManager = connectors.NewConnectorManager(connectors.NewRegistry())
+	}
+	mcpServer.SetIngestionOrchestrator(connectors.NewIngestionOrchestrator(connectorManager, repo, contextProcessor, services.NewEncryptionService(cfg), logger))
+
+	return mcpServer
+}
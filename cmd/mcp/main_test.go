@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/repository"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// TestNewMCPServerListsIngestionTools tests that the stdio server is built
+// with an ingestion orchestrator, which the ingestion tools need
+func TestNewMCPServerListsIngestionTools(t *testing.T) {
+	cfg := &config.Config{Connectors: filepath.Join(t.TempDir(), "connectors.json")}
+	server := newMCPServer(cfg, repository.New(nil), &services.SimpleLogger{})
+
+	response, err := server.HandleMessage(context.Background(), []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if err != nil {
+		t.Fatalf("tools/list failed: %v", err)
+	}
+	for _, tool := range []string{`"get_ingestion_status"`, `"request_sync"`} {
+		if !strings.Contains(string(response), tool) {
+			t.Errorf("Expected tools/list to include %s", tool)
+		}
+	}
+}
@@ -62,6 +62,14 @@ func main() {
 	// Initialize server
 	srv := server.New(db, cfg)
 
+	// Start background workers
+	if err := srv.StartWorkers(context.Background()); err != nil {
+		logger.Error("Failed to start background workers", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
 	// Start HTTP server
 	httpServer := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
@@ -98,6 +106,7 @@ func main() {
 		})
 		os.Exit(1)
 	}
+	srv.StopWorkers()
 
 	logger.Info("Server exited")
 }
@@ -21,6 +21,8 @@ type Config struct {
 	SlackOAuth  SlackOAuthConfig
 	Email       EmailConfig
 	AIService   AIServiceConfig
+	Embedding   EmbeddingConfig
+	Connectors  string // connector configuration file; connectors are configured from the environment when it does not exist
 	Environment string
 	LogLevel    string
 }
@@ -57,16 +59,40 @@ type EmailConfig struct {
 	BaseURL      string // Base URL for email links (defaults to ServerURL if not set)
 }
 
-// AIServiceConfig holds AI service configuration
+// AIServiceConfig holds AI service configuration. Context queries are answered
+// in-process; BaseURL optionally points them at an external AI service first.
 type AIServiceConfig struct {
-	BaseURL string
-	Timeout int // seconds
+	BaseURL    string
+	Timeout    int // seconds, per request
+	MaxRetries int
+
+	// OpenAI-compatible chat completions API used to extract knowledge from
+	// events; extraction is pattern-based only when LLMBaseURL is empty
+	LLMBaseURL string
+	LLMAPIKey  string
+	LLMModel   string
+}
+
+// EmbeddingConfig holds the configuration for embedding knowledge entities.
+// Entities are embedded offline by feature hashing when BaseURL is empty.
+type EmbeddingConfig struct {
+	BaseURL    string // OpenAI-compatible embeddings API, defaults to AI_LLM_BASE_URL
+	APIKey     string
+	Model      string
+	BatchSize  int // 0 uses the worker's default
+	Interval   int // seconds between passes of the background worker; 0 uses the default
+	Timeout    int // seconds, per request
+	MaxRetries int
 }
 
 // Load loads configuration from environment variables
 func Load() *Config {
 	// Get server URL first as it's used for defaults
 	serverURL := getEnv("SERVER_URL", getDefaultServerURL())
+	llmBaseURL := getEnv("AI_LLM_BASE_URL", "")
+	llmAPIKey := getSecretOrEnv("AI_LLM_API_KEY_FILE", "AI_LLM_API_KEY", "")
+	aiTimeout := getEnvInt("AI_SERVICE_TIMEOUT", 30)
+	aiMaxRetries := getEnvInt("AI_SERVICE_MAX_RETRIES", 2)
 	
 	cfg := &Config{
 		Port:        getEnvInt("PORT", 8080),
@@ -100,9 +126,23 @@ func Load() *Config {
 			BaseURL:      getEnv("EMAIL_BASE_URL", serverURL),
 		},
 		AIService: AIServiceConfig{
-			BaseURL: getEnv("AI_SERVICE_URL", "http://localhost:8000"),
-			Timeout: getEnvInt("AI_SERVICE_TIMEOUT", 30),
+			BaseURL:    getEnv("AI_SERVICE_URL", ""),
+			Timeout:    aiTimeout,
+			MaxRetries: aiMaxRetries,
+			LLMBaseURL: llmBaseURL,
+			LLMAPIKey:  llmAPIKey,
+			LLMModel:   getEnv("AI_LLM_MODEL", "gpt-4o-mini"),
 		},
+		Embedding: EmbeddingConfig{
+			BaseURL:    getEnv("EMBEDDING_BASE_URL", llmBaseURL),
+			APIKey:     getSecretOrEnv("EMBEDDING_API_KEY_FILE", "EMBEDDING_API_KEY", llmAPIKey),
+			Model:      getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+			BatchSize:  getEnvInt("EMBEDDING_BATCH_SIZE", 64),
+			Interval:   getEnvInt("EMBEDDING_INTERVAL", 60),
+			Timeout:    aiTimeout,
+			MaxRetries: aiMaxRetries,
+		},
+		Connectors: getEnv("CONNECTORS_CONFIG_PATH", "connectors.json"),
 	}
 
 	// Validate configuration
@@ -159,14 +199,26 @@ func (c *Config) Validate() error {
 		}
 	}
 
-	if c.AIService.BaseURL == "" {
-		errors = append(errors, "AI_SERVICE_URL is required")
-	}
-
 	if c.AIService.Timeout <= 0 {
 		errors = append(errors, "AI_SERVICE_TIMEOUT must be positive")
 	}
 
+	if c.AIService.MaxRetries < 0 {
+		errors = append(errors, "AI_SERVICE_MAX_RETRIES must not be negative")
+	}
+
+	if c.AIService.LLMBaseURL != "" && c.AIService.LLMModel == "" {
+		errors = append(errors, "AI_LLM_MODEL is required when AI_LLM_BASE_URL is provided")
+	}
+
+	if c.Embedding.BaseURL != "" && c.Embedding.Model == "" {
+		errors = append(errors, "EMBEDDING_MODEL is required when EMBEDDING_BASE_URL is provided")
+	}
+
+	if c.Embedding.BatchSize < 0 || c.Embedding.Interval < 0 {
+		errors = append(errors, "EMBEDDING_BATCH_SIZE and EMBEDDING_INTERVAL must not be negative")
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
 	}
@@ -86,6 +86,24 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "LLM endpoint without a model",
+			config: &Config{
+				Port:        8080,
+				ServerURL:   "http://localhost:8080",
+				DatabaseURL: "postgres://localhost/test",
+				JWTSecret:   "secret",
+				GitHubOAuth: GitHubOAuthConfig{
+					ClientID:     "client-id",
+					ClientSecret: "client-secret",
+				},
+				AIService: AIServiceConfig{
+					Timeout:    30,
+					LLMBaseURL: "https://api.openai.com/v1",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -523,6 +523,52 @@ var migrations = []Migration{
 			CREATE INDEX IF NOT EXISTS idx_project_data_sources_source_id ON project_data_sources(source_id);
 		`,
 	},
+	{
+		Version: 21,
+		Name:    "create_mcp_tool_calls_table",
+		SQL: `
+			-- MCP tool calls for usage metering and quotas
+			CREATE TABLE IF NOT EXISTS mcp_tool_calls (
+				id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+				project_id UUID REFERENCES project_workspaces(id) ON DELETE CASCADE, -- NULL when no project was resolved
+				user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+				tool_name VARCHAR(100) NOT NULL,
+				status VARCHAR(20) NOT NULL, -- success, error, quota_exceeded
+				latency_ms BIGINT NOT NULL DEFAULT 0,
+				result_bytes INTEGER NOT NULL DEFAULT 0,
+				result_tokens INTEGER NOT NULL DEFAULT 0,
+				error_message TEXT,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+			);
+			
+			-- Indexes for quota checks and usage summaries
+			CREATE INDEX IF NOT EXISTS idx_mcp_tool_calls_project_created ON mcp_tool_calls(project_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_mcp_tool_calls_user_created ON mcp_tool_calls(user_id, created_at DESC);
+		`,
+	},
+	{
+		Version: 22,
+		Name:    "add_embedding_tracking_to_knowledge_entities",
+		SQL: `
+			-- Which model produced each embedding, and the content version it embeds
+			ALTER TABLE knowledge_entities ADD COLUMN IF NOT EXISTS embedding_model VARCHAR(100);
+			ALTER TABLE knowledge_entities ADD COLUMN IF NOT EXISTS embedded_at TIMESTAMP WITH TIME ZONE;
+			
+			-- Entities still waiting for an embedding
+			CREATE INDEX IF NOT EXISTS idx_knowledge_entities_unembedded ON knowledge_entities(updated_at) WHERE embedding IS NULL;
+		`,
+	},
+	{
+		Version: 23,
+		Name:    "add_adr_fields_to_decision_records",
+		SQL: `
+			-- Decisions a record replaces, and whether it is an authoritative record such as an ADR
+			ALTER TABLE decision_records ADD COLUMN IF NOT EXISTS supersedes TEXT[];
+			ALTER TABLE decision_records ADD COLUMN IF NOT EXISTS authoritative BOOLEAN NOT NULL DEFAULT FALSE;
+			
+			CREATE INDEX IF NOT EXISTS idx_decision_records_authoritative ON decision_records(authoritative) WHERE authoritative;
+		`,
+	},
 }
 
 // Migrate runs all pending migrations
@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/DevAnuragT/context_keeper/internal/middleware"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// GitLabIntegrationHandlers contains handlers for GitLab integration management
+type GitLabIntegrationHandlers struct {
+	authSvc              services.AuthService
+	gitlabIntegrationSvc services.GitLabIntegrationService
+	permissionSvc        services.PermissionService
+}
+
+// NewGitLabIntegrationHandlers creates new GitLab integration handlers
+func NewGitLabIntegrationHandlers(
+	authSvc services.AuthService,
+	gitlabIntegrationSvc services.GitLabIntegrationService,
+	permissionSvc services.PermissionService,
+) *GitLabIntegrationHandlers {
+	return &GitLabIntegrationHandlers{
+		authSvc:              authSvc,
+		gitlabIntegrationSvc: gitlabIntegrationSvc,
+		permissionSvc:        permissionSvc,
+	}
+}
+
+// HandleGitLabTokenInstallation handles GitLab access token installation
+// POST /api/projects/{project_id}/integrations/gitlab/token/install
+func (h *GitLabIntegrationHandlers) HandleGitLabTokenInstallation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID from URL path
+	projectID := extractProjectIDFromIntegrationPath(r.URL.Path, "/api/projects/", "/integrations/gitlab/token/install")
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Parse request body
+	var req services.GitLabTokenInstallationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	req.ProjectID = projectID // Ensure consistency
+
+	if req.AccessToken == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Access token required")
+		return
+	}
+
+	// Process GitLab token installation
+	integration, err := h.gitlabIntegrationSvc.ProcessTokenInstallation(r.Context(), &req, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, "integration_exists", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "validate") {
+			writeError(w, http.StatusBadRequest, "invalid_token", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "installation_error", fmt.Sprintf("Failed to process installation: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, integration)
+}
+
+// HandleGetAvailableRepositories gets available GitLab projects for selection
+// GET /api/projects/{project_id}/integrations/gitlab/{integration_id}/repositories
+func (h *GitLabIntegrationHandlers) HandleGetAvailableRepositories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndGitLabIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canRead, err := h.permissionSvc.CanReadProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canRead {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Read access required")
+		return
+	}
+
+	// Get available repositories
+	repositories, err := h.gitlabIntegrationSvc.GetAvailableRepositories(r.Context(), projectID, integrationID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "token") {
+			writeError(w, http.StatusUnauthorized, "integration_unauthorized", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "repository_error", fmt.Sprintf("Failed to get repositories: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"repositories": repositories,
+	})
+}
+
+// HandleSelectRepositories handles GitLab project selection for integration
+// POST /api/projects/{project_id}/integrations/gitlab/{integration_id}/repositories/select
+func (h *GitLabIntegrationHandlers) HandleSelectRepositories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndGitLabIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Parse request body
+	var req services.RepositorySelectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	req.ProjectID = projectID         // Ensure consistency
+	req.IntegrationID = integrationID // Ensure consistency
+
+	if len(req.RepositoryIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "At least one repository ID required")
+		return
+	}
+
+	// Select repositories
+	dataSources, err := h.gitlabIntegrationSvc.SelectRepositories(r.Context(), &req, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid repository") || strings.Contains(err.Error(), "does not belong") {
+			writeError(w, http.StatusBadRequest, "invalid_repository", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "selection_error", fmt.Sprintf("Failed to select repositories: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data_sources": dataSources,
+	})
+}
+
+// HandleUpdateGitLabConfiguration updates GitLab integration configuration
+// PUT /api/projects/{project_id}/integrations/gitlab/{integration_id}/configuration
+func (h *GitLabIntegrationHandlers) HandleUpdateGitLabConfiguration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndGitLabIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Parse request body
+	var req services.IntegrationConfigurationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	req.ProjectID = projectID         // Ensure consistency
+	req.IntegrationID = integrationID // Ensure consistency
+
+	if req.Configuration == nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Configuration required")
+		return
+	}
+
+	// Update configuration
+	integration, err := h.gitlabIntegrationSvc.UpdateConfiguration(r.Context(), &req, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid configuration") {
+			writeError(w, http.StatusBadRequest, "invalid_configuration", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "configuration_error", fmt.Sprintf("Failed to update configuration: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, integration)
+}
+
+// HandleGetGitLabIntegrationStatus gets GitLab integration status and health
+// GET /api/projects/{project_id}/integrations/gitlab/{integration_id}/status
+func (h *GitLabIntegrationHandlers) HandleGetGitLabIntegrationStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndGitLabIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canRead, err := h.permissionSvc.CanReadProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canRead {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Read access required")
+		return
+	}
+
+	// Get integration status
+	status, err := h.gitlabIntegrationSvc.GetIntegrationStatus(r.Context(), projectID, integrationID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "status_error", fmt.Sprintf("Failed to get status: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// HandleDeleteGitLabIntegration deletes a GitLab integration
+// DELETE /api/projects/{project_id}/integrations/gitlab/{integration_id}
+func (h *GitLabIntegrationHandlers) HandleDeleteGitLabIntegration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndGitLabIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Delete integration
+	err = h.gitlabIntegrationSvc.DeleteIntegration(r.Context(), projectID, integrationID, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "deletion_error", fmt.Sprintf("Failed to delete integration: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "GitLab integration deleted successfully",
+	})
+}
+
+// HandleValidateGitLabCredentials validates the GitLab access token
+// POST /api/projects/{project_id}/integrations/gitlab/{integration_id}/validate
+func (h *GitLabIntegrationHandlers) HandleValidateGitLabCredentials(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndGitLabIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canRead, err := h.permissionSvc.CanReadProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canRead {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Read access required")
+		return
+	}
+
+	// Validate credentials
+	err = h.gitlabIntegrationSvc.ValidateCredentials(r.Context(), integrationID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Credential validation failed: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"valid":   true,
+		"message": "Access token is valid",
+	})
+}
+
+// Helper functions
+
+// extractProjectAndGitLabIntegrationIDs extracts project ID and integration ID from GitLab URL path
+func extractProjectAndGitLabIntegrationIDs(path string) (projectID, integrationID string) {
+	// Expected format: /api/projects/{project_id}/integrations/gitlab/{integration_id}/...
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(parts) < 6 {
+		return "", ""
+	}
+
+	if parts[0] != "api" || parts[1] != "projects" || parts[3] != "integrations" || parts[4] != "gitlab" {
+		return "", ""
+	}
+
+	return parts[2], parts[5]
+}
@@ -0,0 +1,451 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/DevAnuragT/context_keeper/internal/middleware"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// JiraIntegrationHandlers contains handlers for Jira integration management
+type JiraIntegrationHandlers struct {
+	authSvc            services.AuthService
+	jiraIntegrationSvc services.JiraIntegrationService
+	permissionSvc      services.PermissionService
+}
+
+// NewJiraIntegrationHandlers creates new Jira integration handlers
+func NewJiraIntegrationHandlers(
+	authSvc services.AuthService,
+	jiraIntegrationSvc services.JiraIntegrationService,
+	permissionSvc services.PermissionService,
+) *JiraIntegrationHandlers {
+	return &JiraIntegrationHandlers{
+		authSvc:            authSvc,
+		jiraIntegrationSvc: jiraIntegrationSvc,
+		permissionSvc:      permissionSvc,
+	}
+}
+
+// HandleJiraTokenInstallation handles Jira API token installation
+// POST /api/projects/{project_id}/integrations/jira/token/install
+func (h *JiraIntegrationHandlers) HandleJiraTokenInstallation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID from URL path
+	projectID := extractProjectIDFromIntegrationPath(r.URL.Path, "/api/projects/", "/integrations/jira/token/install")
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Parse request body
+	var req services.JiraTokenInstallationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	req.ProjectID = projectID // Ensure consistency
+
+	if req.APIToken == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "API token required")
+		return
+	}
+	if req.BaseURL == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Base URL required")
+		return
+	}
+
+	// Process Jira token installation
+	integration, err := h.jiraIntegrationSvc.ProcessTokenInstallation(r.Context(), &req, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			writeError(w, http.StatusConflict, "integration_exists", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "validate") {
+			writeError(w, http.StatusBadRequest, "invalid_token", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "installation_error", fmt.Sprintf("Failed to process installation: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, integration)
+}
+
+// HandleGetAvailableProjects gets available Jira projects for selection
+// GET /api/projects/{project_id}/integrations/jira/{integration_id}/projects
+func (h *JiraIntegrationHandlers) HandleGetAvailableProjects(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndJiraIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canRead, err := h.permissionSvc.CanReadProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canRead {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Read access required")
+		return
+	}
+
+	// Get available projects
+	projects, err := h.jiraIntegrationSvc.GetAvailableProjects(r.Context(), projectID, integrationID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "token") {
+			writeError(w, http.StatusUnauthorized, "integration_unauthorized", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "project_error", fmt.Sprintf("Failed to get projects: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"projects": projects,
+	})
+}
+
+// HandleSelectProjects handles Jira project selection for integration
+// POST /api/projects/{project_id}/integrations/jira/{integration_id}/projects/select
+func (h *JiraIntegrationHandlers) HandleSelectProjects(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndJiraIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Parse request body
+	var req services.JiraProjectSelectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	req.ProjectID = projectID         // Ensure consistency
+	req.IntegrationID = integrationID // Ensure consistency
+
+	if len(req.ProjectKeys) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request", "At least one project key required")
+		return
+	}
+
+	// Select projects
+	dataSources, err := h.jiraIntegrationSvc.SelectProjects(r.Context(), &req, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid project") || strings.Contains(err.Error(), "does not belong") {
+			writeError(w, http.StatusBadRequest, "invalid_project", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "selection_error", fmt.Sprintf("Failed to select projects: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"data_sources": dataSources,
+	})
+}
+
+// HandleUpdateJiraConfiguration updates Jira integration configuration
+// PUT /api/projects/{project_id}/integrations/jira/{integration_id}/configuration
+func (h *JiraIntegrationHandlers) HandleUpdateJiraConfiguration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndJiraIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Parse request body
+	var req services.IntegrationConfigurationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid JSON body")
+		return
+	}
+
+	req.ProjectID = projectID         // Ensure consistency
+	req.IntegrationID = integrationID // Ensure consistency
+
+	if req.Configuration == nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Configuration required")
+		return
+	}
+
+	// Update configuration
+	integration, err := h.jiraIntegrationSvc.UpdateConfiguration(r.Context(), &req, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "invalid configuration") {
+			writeError(w, http.StatusBadRequest, "invalid_configuration", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "configuration_error", fmt.Sprintf("Failed to update configuration: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, integration)
+}
+
+// HandleGetJiraIntegrationStatus gets Jira integration status and health
+// GET /api/projects/{project_id}/integrations/jira/{integration_id}/status
+func (h *JiraIntegrationHandlers) HandleGetJiraIntegrationStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndJiraIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canRead, err := h.permissionSvc.CanReadProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canRead {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Read access required")
+		return
+	}
+
+	// Get integration status
+	status, err := h.jiraIntegrationSvc.GetIntegrationStatus(r.Context(), projectID, integrationID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "status_error", fmt.Sprintf("Failed to get status: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// HandleDeleteJiraIntegration deletes a Jira integration
+// DELETE /api/projects/{project_id}/integrations/jira/{integration_id}
+func (h *JiraIntegrationHandlers) HandleDeleteJiraIntegration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndJiraIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	// Delete integration
+	err = h.jiraIntegrationSvc.DeleteIntegration(r.Context(), projectID, integrationID, user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "deletion_error", fmt.Sprintf("Failed to delete integration: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message": "Jira integration deleted successfully",
+	})
+}
+
+// HandleValidateJiraCredentials validates the Jira API token
+// POST /api/projects/{project_id}/integrations/jira/{integration_id}/validate
+func (h *JiraIntegrationHandlers) HandleValidateJiraCredentials(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID and integration ID from URL path
+	projectID, integrationID := extractProjectAndJiraIntegrationIDs(r.URL.Path)
+	if projectID == "" || integrationID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID and Integration ID required")
+		return
+	}
+
+	// Check project permissions
+	canRead, err := h.permissionSvc.CanReadProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canRead {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Read access required")
+		return
+	}
+
+	// Validate credentials
+	err = h.jiraIntegrationSvc.ValidateCredentials(r.Context(), integrationID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			writeError(w, http.StatusNotFound, "integration_not_found", err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Credential validation failed: %v", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"valid":   true,
+		"message": "API token is valid",
+	})
+}
+
+// Helper functions
+
+// extractProjectAndJiraIntegrationIDs extracts project ID and integration ID from Jira URL path
+func extractProjectAndJiraIntegrationIDs(path string) (projectID, integrationID string) {
+	// Expected format: /api/projects/{project_id}/integrations/jira/{integration_id}/...
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(parts) < 6 {
+		return "", ""
+	}
+
+	if parts[0] != "api" || parts[1] != "projects" || parts[3] != "integrations" || parts[4] != "jira" {
+		return "", ""
+	}
+
+	return parts[2], parts[5]
+}
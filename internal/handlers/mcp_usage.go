@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/middleware"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// defaultMCPUsageWindow is how far back the usage report looks when no range is given
+const defaultMCPUsageWindow = 24 * time.Hour
+
+// MCPUsageHandlers contains handlers for MCP tool usage reporting
+type MCPUsageHandlers struct {
+	authSvc       services.AuthService
+	usageSvc      services.MCPUsageService
+	permissionSvc services.PermissionService
+}
+
+// NewMCPUsageHandlers creates new MCP usage handlers
+func NewMCPUsageHandlers(
+	authSvc services.AuthService,
+	usageSvc services.MCPUsageService,
+	permissionSvc services.PermissionService,
+) *MCPUsageHandlers {
+	return &MCPUsageHandlers{
+		authSvc:       authSvc,
+		usageSvc:      usageSvc,
+		permissionSvc: permissionSvc,
+	}
+}
+
+// HandleGetMCPUsage reports a project's MCP tool calls by tool and by user.
+// The range is set with since (RFC3339) or window (a duration such as 168h).
+// GET /api/projects/{project_id}/mcp/usage
+func (h *MCPUsageHandlers) HandleGetMCPUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Get user from context
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized", "User not found in context")
+		return
+	}
+
+	// Extract project ID from URL path
+	projectID := extractProjectIDFromIntegrationPath(r.URL.Path, "/api/projects/", "/mcp/usage")
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Project ID required")
+		return
+	}
+
+	// Check project permissions
+	canAdmin, err := h.permissionSvc.CanAdminProject(r.Context(), user.ID, projectID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "permission_error", "Failed to check permissions")
+		return
+	}
+	if !canAdmin {
+		writeError(w, http.StatusForbidden, "insufficient_permissions", "Admin access required")
+		return
+	}
+
+	since := time.Now().Add(-defaultMCPUsageWindow)
+	if value := r.URL.Query().Get("since"); value != "" {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "since must be an RFC3339 timestamp")
+			return
+		}
+	} else if value := r.URL.Query().Get("window"); value != "" {
+		window, err := time.ParseDuration(value)
+		if err != nil || window <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid_request", "window must be a positive duration such as 24h")
+			return
+		}
+		since = time.Now().Add(-window)
+	}
+
+	summary, err := h.usageSvc.GetUsageSummary(r.Context(), projectID, since)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "usage_error", "Failed to get MCP usage")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
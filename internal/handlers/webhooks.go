@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// maxWebhookBodySize bounds webhook deliveries, which are read before they are verified
+const maxWebhookBodySize = 1 << 20
+
+// WebhookParser verifies a platform's webhook deliveries and converts them to events
+type WebhookParser interface {
+	ParseWebhook(ctx context.Context, platform string, headers http.Header, body []byte) ([]services.PlatformEvent, error)
+}
+
+// WebhookHandlers contains handlers for webhooks pushed by integration platforms
+type WebhookHandlers struct {
+	store        services.RepositoryStore
+	parser       WebhookParser
+	orchestrator services.IngestionOrchestrator
+}
+
+// NewWebhookHandlers creates new webhook handlers
+func NewWebhookHandlers(
+	store services.RepositoryStore,
+	parser WebhookParser,
+	orchestrator services.IngestionOrchestrator,
+) *WebhookHandlers {
+	return &WebhookHandlers{
+		store:        store,
+		parser:       parser,
+		orchestrator: orchestrator,
+	}
+}
+
+// HandleWebhook verifies a webhook delivery for an integration and queues its
+// events for ingestion. Platforms cannot sign in, so the delivery's signature
+// is its authentication.
+// POST /api/webhooks/{platform}/{integration_id}
+func (h *WebhookHandlers) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+
+	// Extract platform and integration ID from URL path
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/webhooks/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Platform and integration ID required")
+		return
+	}
+	platform, integrationID := parts[0], parts[1]
+
+	// Unknown integrations and integrations of other platforms look the same
+	integration, err := h.store.GetProjectIntegration(r.Context(), integrationID)
+	if err != nil || integration.Platform != platform {
+		writeError(w, http.StatusNotFound, "integration_not_found", "Integration not found")
+		return
+	}
+	if integration.Status != string(models.IntegrationStatusActive) {
+		writeError(w, http.StatusConflict, "integration_inactive", "Integration is not active")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "invalid_request", "Webhook body too large")
+		return
+	}
+
+	events, err := h.parser.ParseWebhook(r.Context(), platform, r.Header, body)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "invalid_webhook", err.Error())
+		return
+	}
+
+	if err := h.orchestrator.IngestIntegrationEvents(r.Context(), integration.ID, events); err != nil {
+		writeError(w, http.StatusInternalServerError, "ingestion_error", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"queued": len(events),
+	})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// webhookStore knows active Linear integration i1 and inactive i2
+type webhookStore struct {
+	services.RepositoryStore
+}
+
+func (s *webhookStore) GetProjectIntegration(ctx context.Context, integrationID string) (*models.ProjectIntegration, error) {
+	switch integrationID {
+	case "i1":
+		return &models.ProjectIntegration{ID: "i1", Platform: "linear", Status: string(models.IntegrationStatusActive)}, nil
+	case "i2":
+		return &models.ProjectIntegration{ID: "i2", Platform: "linear", Status: "inactive"}, nil
+	}
+	return nil, errors.New("integration not found")
+}
+
+// webhookParser accepts deliveries signed "valid"
+type webhookParser struct{}
+
+func (webhookParser) ParseWebhook(ctx context.Context, platform string, headers http.Header, body []byte) ([]services.PlatformEvent, error) {
+	if headers.Get("Linear-Signature") != "valid" {
+		return nil, errors.New("Linear webhook signature does not match")
+	}
+	return []services.PlatformEvent{{ID: "issue_iss-1", Platform: platform}}, nil
+}
+
+// webhookOrchestrator records the events it is asked to ingest
+type webhookOrchestrator struct {
+	services.IngestionOrchestrator
+	integrationID string
+	events        []services.PlatformEvent
+}
+
+func (o *webhookOrchestrator) IngestIntegrationEvents(ctx context.Context, integrationID string, events []services.PlatformEvent) error {
+	o.integrationID = integrationID
+	o.events = events
+	return nil
+}
+
+func TestHandleWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		signature  string
+		wantStatus int
+		wantQueued bool
+	}{
+		{"signed delivery", http.MethodPost, "/api/webhooks/linear/i1", "valid", http.StatusAccepted, true},
+		{"bad signature", http.MethodPost, "/api/webhooks/linear/i1", "forged", http.StatusUnauthorized, false},
+		{"unknown integration", http.MethodPost, "/api/webhooks/linear/missing", "valid", http.StatusNotFound, false},
+		{"other platform", http.MethodPost, "/api/webhooks/gitlab/i1", "valid", http.StatusNotFound, false},
+		{"inactive integration", http.MethodPost, "/api/webhooks/linear/i2", "valid", http.StatusConflict, false},
+		{"missing integration", http.MethodPost, "/api/webhooks/linear", "valid", http.StatusBadRequest, false},
+		{"wrong method", http.MethodGet, "/api/webhooks/linear/i1", "valid", http.StatusMethodNotAllowed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orchestrator := &webhookOrchestrator{}
+			h := NewWebhookHandlers(&webhookStore{}, webhookParser{}, orchestrator)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{"action":"update","type":"Issue"}`))
+			req.Header.Set("Linear-Signature", tt.signature)
+			w := httptest.NewRecorder()
+			h.HandleWebhook(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if queued := len(orchestrator.events) > 0; queued != tt.wantQueued {
+				t.Errorf("Expected events queued to be %v, got %v", tt.wantQueued, queued)
+			}
+			if tt.wantQueued && orchestrator.integrationID != "i1" {
+				t.Errorf("Expected events queued for i1, got %q", orchestrator.integrationID)
+			}
+		})
+	}
+}
@@ -314,6 +314,8 @@ type DecisionRecord struct {
 	Alternatives   StringList `json:"alternatives"`
 	Consequences   StringList `json:"consequences"`
 	Status         string     `json:"status"`
+	Supersedes     StringList `json:"supersedes"`
+	Authoritative  bool       `json:"authoritative"` // written down on purpose, such as an ADR, rather than mined
 	PlatformSource string     `json:"platform_source"`
 	SourceEventIDs StringList `json:"source_event_ids"`
 	Participants   StringList `json:"participants"`
@@ -392,6 +394,17 @@ type KnowledgeGraphQuery struct {
 	Limit          int               `json:"limit,omitempty"`
 	IncludeContent bool              `json:"include_content,omitempty"`
 	Metadata       map[string]string `json:"metadata,omitempty"`
+
+	// Hybrid search weights. The lexical and semantic weights scale each
+	// ranking's share of the fused score and default to 1 when unset; the
+	// recency and relationship-degree boosts are off unless positive. The
+	// authority boost lifts decisions written down on purpose, such as ADRs,
+	// and defaults to 0.5 when unset.
+	LexicalWeight   *float64 `json:"lexical_weight,omitempty"`
+	SemanticWeight  *float64 `json:"semantic_weight,omitempty"`
+	RecencyWeight   float64  `json:"recency_weight,omitempty"`
+	DegreeWeight    float64  `json:"degree_weight,omitempty"`
+	AuthorityWeight *float64 `json:"authority_weight,omitempty"`
 }
 
 // DateRange represents a date range filter
@@ -404,8 +417,8 @@ type DateRange struct {
 type ProjectIntegration struct {
 	ID                string                 `json:"id"`
 	ProjectID         string                 `json:"project_id"`
-	Platform          string                 `json:"platform"` // github, slack, discord
-	IntegrationType   string                 `json:"integration_type"` // oauth, bot, webhook
+	Platform          string                 `json:"platform"` // github, slack, discord, gitlab, jira
+	IntegrationType   string                 `json:"integration_type"` // oauth, bot, webhook, access_token, api_token
 	Status            string                 `json:"status"` // active, inactive, error, pending
 	Configuration     map[string]interface{} `json:"configuration"`
 	Credentials       map[string]interface{} `json:"credentials"` // Encrypted storage
@@ -423,7 +436,7 @@ type ProjectDataSource struct {
 	ID              string                 `json:"id"`
 	ProjectID       string                 `json:"project_id"`
 	IntegrationID   string                 `json:"integration_id"`
-	SourceType      string                 `json:"source_type"` // repository, channel, server
+	SourceType      string                 `json:"source_type"` // repository, channel, server, jira_project
 	SourceID        string                 `json:"source_id"` // Platform-specific ID
 	SourceName      string                 `json:"source_name"`
 	Configuration   map[string]interface{} `json:"configuration"`
@@ -449,9 +462,11 @@ const (
 type IntegrationType string
 
 const (
-	IntegrationTypeOAuth   IntegrationType = "oauth"
-	IntegrationTypeBot     IntegrationType = "bot"
-	IntegrationTypeWebhook IntegrationType = "webhook"
+	IntegrationTypeOAuth       IntegrationType = "oauth"
+	IntegrationTypeBot         IntegrationType = "bot"
+	IntegrationTypeWebhook     IntegrationType = "webhook"
+	IntegrationTypeAccessToken IntegrationType = "access_token"
+	IntegrationTypeAPIToken    IntegrationType = "api_token"
 )
 
 // Platform represents supported platforms
@@ -461,17 +476,86 @@ const (
 	PlatformGitHub  Platform = "github"
 	PlatformSlack   Platform = "slack"
 	PlatformDiscord Platform = "discord"
+	PlatformGitLab  Platform = "gitlab"
+	PlatformJira    Platform = "jira"
 )
 
 // SourceType represents the type of data source
 type SourceType string
 
 const (
-	SourceTypeRepository SourceType = "repository"
-	SourceTypeChannel    SourceType = "channel"
-	SourceTypeServer     SourceType = "server"
+	SourceTypeRepository  SourceType = "repository"
+	SourceTypeChannel     SourceType = "channel"
+	SourceTypeServer      SourceType = "server"
+	SourceTypeJiraProject SourceType = "jira_project"
 )
 
+// MCPToolCallStatus is the outcome of an MCP tool call
+type MCPToolCallStatus string
+
+const (
+	MCPToolCallSuccess       MCPToolCallStatus = "success"
+	MCPToolCallError         MCPToolCallStatus = "error"
+	MCPToolCallQuotaExceeded MCPToolCallStatus = "quota_exceeded"
+)
+
+// MCPToolCall records a single MCP tool call for usage metering
+type MCPToolCall struct {
+	ID           string            `json:"id"`
+	ProjectID    *string           `json:"project_id,omitempty"`
+	UserID       string            `json:"user_id"`
+	ToolName     string            `json:"tool_name"`
+	Status       MCPToolCallStatus `json:"status"`
+	LatencyMs    int64             `json:"latency_ms"`
+	ResultBytes  int               `json:"result_bytes"`
+	ResultTokens int               `json:"result_tokens"`
+	ErrorMessage *string           `json:"error_message,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// MCPToolCallTotals counts the metered tool calls in a time window. Calls
+// rejected by a quota are not counted.
+type MCPToolCallTotals struct {
+	Calls        int `json:"calls"`
+	ResultTokens int `json:"result_tokens"`
+}
+
+// MCPToolUsage aggregates tool calls by tool, by user, or both
+type MCPToolUsage struct {
+	ToolName       string     `json:"tool_name,omitempty"`
+	UserID         string     `json:"user_id,omitempty"`
+	Calls          int        `json:"calls"`
+	Errors         int        `json:"errors"`
+	Rejected       int        `json:"rejected"`
+	TotalLatencyMs int64      `json:"total_latency_ms"`
+	AvgLatencyMs   float64    `json:"avg_latency_ms"`
+	MaxLatencyMs   int64      `json:"max_latency_ms"`
+	ResultBytes    int64      `json:"result_bytes"`
+	ResultTokens   int64      `json:"result_tokens"`
+	LastCalledAt   *time.Time `json:"last_called_at,omitempty"`
+}
+
+// MCPQuotas limits MCP tool usage. They are read from the "mcp_quotas" key of
+// the project settings; zero means unlimited.
+type MCPQuotas struct {
+	ProjectCallsPerHour int `json:"project_calls_per_hour,omitempty"`
+	ProjectCallsPerDay  int `json:"project_calls_per_day,omitempty"`
+	ProjectTokensPerDay int `json:"project_tokens_per_day,omitempty"`
+	UserCallsPerHour    int `json:"user_calls_per_hour,omitempty"`
+	UserCallsPerDay     int `json:"user_calls_per_day,omitempty"`
+	UserTokensPerDay    int `json:"user_tokens_per_day,omitempty"`
+}
+
+// MCPUsageSummary reports MCP tool usage of a project since a point in time
+type MCPUsageSummary struct {
+	ProjectID string         `json:"project_id"`
+	Since     time.Time      `json:"since"`
+	Totals    MCPToolUsage   `json:"totals"`
+	ByTool    []MCPToolUsage `json:"by_tool"`
+	ByUser    []MCPToolUsage `json:"by_user"`
+	Quotas    MCPQuotas      `json:"quotas"`
+}
+
 // JSONBMap is a custom type for handling JSONB map storage
 type JSONBMap map[string]interface{}
 
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DevAnuragT/context_keeper/internal/models"
 	"github.com/DevAnuragT/context_keeper/internal/services"
+	"github.com/lib/pq"
 )
 
 // Repository implements the RepositoryStore interface
@@ -528,6 +531,55 @@ func (r *Repository) DeleteKnowledgeEntity(ctx context.Context, id string) error
 	return err
 }
 
+// ListKnowledgeEntitiesNeedingEmbedding retrieves entities that have no embedding
+// from model, or whose content changed after they were embedded, oldest first
+func (r *Repository) ListKnowledgeEntitiesNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.KnowledgeEntity, error) {
+	query := `
+		SELECT id, entity_type, entity_id, title, content, platform_source, project_id, created_at, updated_at
+		FROM knowledge_entities
+		WHERE embedding IS NULL
+		   OR embedding_model IS DISTINCT FROM $1
+		   OR embedded_at IS NULL
+		   OR embedded_at < updated_at
+		ORDER BY updated_at
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, model, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entities []models.KnowledgeEntity
+	for rows.Next() {
+		var entity models.KnowledgeEntity
+		var projectID *string
+		if err := rows.Scan(&entity.ID, &entity.EntityType, &entity.EntityID, &entity.Title, &entity.Content,
+			&entity.PlatformSource, &projectID, &entity.CreatedAt, &entity.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if projectID != nil && *projectID != "" {
+			entity.Metadata = map[string]interface{}{"project_id": *projectID}
+		}
+		entities = append(entities, entity)
+	}
+
+	return entities, rows.Err()
+}
+
+// UpdateKnowledgeEntityEmbedding stores the embedding of an entity's content as
+// of contentUpdatedAt. It leaves the entity untouched if the content has changed
+// since, so the newer content is embedded on a later pass.
+func (r *Repository) UpdateKnowledgeEntityEmbedding(ctx context.Context, id string, embedding []float32, model string, contentUpdatedAt time.Time) error {
+	query := `
+		UPDATE knowledge_entities
+		SET embedding = $2, embedding_model = $3, embedded_at = updated_at
+		WHERE id = $1 AND updated_at = $4`
+
+	_, err := r.db.ExecContext(ctx, query, id, convertFloatSliceToString(embedding), model, contentUpdatedAt)
+	return err
+}
+
 func (r *Repository) CreateKnowledgeRelationship(ctx context.Context, relationship *models.KnowledgeRelationship) error {
 	query := `
 		INSERT INTO knowledge_relationships (id, source_entity_id, target_entity_id, relationship_type, strength, metadata, created_at)
@@ -591,8 +643,8 @@ func (r *Repository) DeleteKnowledgeRelationship(ctx context.Context, id string)
 
 func (r *Repository) CreateDecisionRecord(ctx context.Context, decision *models.DecisionRecord) error {
 	query := `
-		INSERT INTO decision_records (id, entity_id, decision_id, title, decision, rationale, alternatives, consequences, status, platform_source, source_event_ids, participants, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		INSERT INTO decision_records (id, entity_id, decision_id, title, decision, rationale, alternatives, consequences, status, supersedes, authoritative, platform_source, source_event_ids, participants, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		ON CONFLICT (decision_id) DO UPDATE SET
 			title = EXCLUDED.title,
 			decision = EXCLUDED.decision,
@@ -600,6 +652,10 @@ func (r *Repository) CreateDecisionRecord(ctx context.Context, decision *models.
 			alternatives = EXCLUDED.alternatives,
 			consequences = EXCLUDED.consequences,
 			status = EXCLUDED.status,
+			supersedes = EXCLUDED.supersedes,
+			authoritative = EXCLUDED.authoritative,
+			source_event_ids = EXCLUDED.source_event_ids,
+			participants = EXCLUDED.participants,
 			updated_at = EXCLUDED.updated_at`
 
 	now := time.Now()
@@ -616,6 +672,7 @@ func (r *Repository) CreateDecisionRecord(ctx context.Context, decision *models.
 	_, err := r.db.ExecContext(ctx, query,
 		decision.ID, decision.EntityID, decision.DecisionID, decision.Title, decision.Decision,
 		decision.Rationale, decision.Alternatives, decision.Consequences, decision.Status,
+		decision.Supersedes, decision.Authoritative,
 		decision.PlatformSource, decision.SourceEventIDs, decision.Participants,
 		decision.CreatedAt, decision.UpdatedAt)
 	return err
@@ -623,7 +680,7 @@ func (r *Repository) CreateDecisionRecord(ctx context.Context, decision *models.
 
 func (r *Repository) GetDecisionRecord(ctx context.Context, decisionID string) (*models.DecisionRecord, error) {
 	query := `
-		SELECT id, entity_id, decision_id, title, decision, rationale, alternatives, consequences, status, platform_source, source_event_ids, participants, created_at, updated_at
+		SELECT id, entity_id, decision_id, title, decision, rationale, alternatives, consequences, status, supersedes, authoritative, platform_source, source_event_ids, participants, created_at, updated_at
 		FROM decision_records
 		WHERE decision_id = $1`
 
@@ -631,6 +688,7 @@ func (r *Repository) GetDecisionRecord(ctx context.Context, decisionID string) (
 	err := r.db.QueryRowContext(ctx, query, decisionID).Scan(
 		&decision.ID, &decision.EntityID, &decision.DecisionID, &decision.Title, &decision.Decision,
 		&decision.Rationale, &decision.Alternatives, &decision.Consequences, &decision.Status,
+		&decision.Supersedes, &decision.Authoritative,
 		&decision.PlatformSource, &decision.SourceEventIDs, &decision.Participants,
 		&decision.CreatedAt, &decision.UpdatedAt)
 	if err != nil {
@@ -643,14 +701,15 @@ func (r *Repository) GetDecisionRecord(ctx context.Context, decisionID string) (
 func (r *Repository) UpdateDecisionRecord(ctx context.Context, decision *models.DecisionRecord) error {
 	query := `
 		UPDATE decision_records 
-		SET title = $2, decision = $3, rationale = $4, alternatives = $5, consequences = $6, status = $7, updated_at = $8
+		SET title = $2, decision = $3, rationale = $4, alternatives = $5, consequences = $6, status = $7, supersedes = $8, authoritative = $9, updated_at = $10
 		WHERE decision_id = $1`
 
 	decision.UpdatedAt = time.Now()
 
 	_, err := r.db.ExecContext(ctx, query,
 		decision.DecisionID, decision.Title, decision.Decision, decision.Rationale,
-		decision.Alternatives, decision.Consequences, decision.Status, decision.UpdatedAt)
+		decision.Alternatives, decision.Consequences, decision.Status,
+		decision.Supersedes, decision.Authoritative, decision.UpdatedAt)
 	return err
 }
 
@@ -1207,29 +1266,39 @@ func generateUUID() string {
 }
 
 func convertFloatSliceToString(embedding []float32) *string {
-	if embedding == nil {
+	if len(embedding) == 0 {
 		return nil
 	}
 	// Convert to PostgreSQL vector format: [1.0,2.0,3.0]
-	result := "["
+	var builder strings.Builder
+	builder.WriteString("[")
 	for i, val := range embedding {
 		if i > 0 {
-			result += ","
+			builder.WriteString(",")
 		}
-		result += fmt.Sprintf("%.6f", val)
+		builder.WriteString(strconv.FormatFloat(float64(val), 'g', -1, 32))
 	}
-	result += "]"
+	builder.WriteString("]")
+	result := builder.String()
 	return &result
 }
 
 func convertStringToFloatSlice(embeddingStr string) []float32 {
 	// Parse PostgreSQL vector format back to float slice
-	// This is a simplified implementation - in production use proper parsing
-	if embeddingStr == "" || embeddingStr == "[]" {
+	trimmed := strings.Trim(strings.TrimSpace(embeddingStr), "[]")
+	if trimmed == "" {
 		return nil
 	}
-	// For now, return empty slice - proper implementation would parse the string
-	return []float32{}
+	parts := strings.Split(trimmed, ",")
+	embedding := make([]float32, 0, len(parts))
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil
+		}
+		embedding = append(embedding, float32(value))
+	}
+	return embedding
 }
 
 // Project-scoped Knowledge Graph operations
@@ -1508,6 +1577,126 @@ func (r *Repository) SearchSimilarEntitiesByProject(ctx context.Context, project
 	return results, rows.Err()
 }
 
+// SearchKnowledgeEntitiesByEmbedding finds the project's entities closest to
+// embedding among those embedded by model, applying the query's filters
+func (r *Repository) SearchKnowledgeEntitiesByEmbedding(ctx context.Context, projectID string, embedding []float32, model string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	sqlQuery := `
+		SELECT id, entity_type, entity_id, title, content, metadata, platform_source, source_event_ids, participants, project_id, created_at, updated_at,
+		       1 - (embedding <=> $1) as similarity
+		FROM knowledge_entities
+		WHERE project_id = $2 AND embedding IS NOT NULL AND embedding_model = $3`
+
+	args := []interface{}{convertFloatSliceToString(embedding), projectID, model}
+	argIndex := 4
+
+	if len(query.EntityTypes) > 0 {
+		sqlQuery += fmt.Sprintf(" AND entity_type = ANY($%d)", argIndex)
+		args = append(args, pq.Array(query.EntityTypes))
+		argIndex++
+	}
+
+	if len(query.Platforms) > 0 {
+		sqlQuery += fmt.Sprintf(" AND platform_source = ANY($%d)", argIndex)
+		args = append(args, pq.Array(query.Platforms))
+		argIndex++
+	}
+
+	if query.DateRange != nil {
+		if query.DateRange.Start != nil {
+			sqlQuery += fmt.Sprintf(" AND created_at >= $%d", argIndex)
+			args = append(args, *query.DateRange.Start)
+			argIndex++
+		}
+		if query.DateRange.End != nil {
+			sqlQuery += fmt.Sprintf(" AND created_at <= $%d", argIndex)
+			args = append(args, *query.DateRange.End)
+			argIndex++
+		}
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	sqlQuery += fmt.Sprintf(" ORDER BY embedding <=> $1 LIMIT $%d", argIndex)
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var entity models.KnowledgeEntity
+		var metadata models.JSONBMap
+		var entityProjectID *string
+		var similarity float64
+
+		if err := rows.Scan(&entity.ID, &entity.EntityType, &entity.EntityID, &entity.Title,
+			&entity.Content, &metadata, &entity.PlatformSource, &entity.SourceEventIDs,
+			&entity.Participants, &entityProjectID, &entity.CreatedAt, &entity.UpdatedAt, &similarity); err != nil {
+			return nil, err
+		}
+
+		entity.Metadata = map[string]interface{}(metadata)
+		if entityProjectID != nil && *entityProjectID != "" {
+			if entity.Metadata == nil {
+				entity.Metadata = make(map[string]interface{})
+			}
+			entity.Metadata["project_id"] = *entityProjectID
+		}
+
+		if !query.IncludeContent {
+			entity.Content = ""
+		}
+
+		results = append(results, models.SearchResult{
+			Entity:     entity,
+			Similarity: similarity,
+			Rank:       len(results) + 1,
+		})
+	}
+
+	return results, rows.Err()
+}
+
+// CountKnowledgeRelationships returns how many relationships each of the
+// entities takes part in; entities without relationships are left out
+func (r *Repository) CountKnowledgeRelationships(ctx context.Context, entityIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(entityIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT entity_id, COUNT(*)
+		FROM (
+			SELECT source_entity_id AS entity_id FROM knowledge_relationships WHERE source_entity_id::text = ANY($1)
+			UNION ALL
+			SELECT target_entity_id AS entity_id FROM knowledge_relationships WHERE target_entity_id::text = ANY($1)
+		) AS endpoints
+		GROUP BY entity_id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(entityIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entityID string
+		var count int
+		if err := rows.Scan(&entityID, &count); err != nil {
+			return nil, err
+		}
+		counts[entityID] = count
+	}
+
+	return counts, rows.Err()
+}
+
 // TraverseKnowledgeGraphByProject performs graph traversal within a specific project
 func (r *Repository) TraverseKnowledgeGraphByProject(ctx context.Context, projectID, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error) {
 	query := `
@@ -1661,6 +1850,76 @@ func (r *Repository) GetRelatedEntitiesByProject(ctx context.Context, projectID,
 	return entities, rows.Err()
 }
 
+// ListFilePathsByProject returns distinct indexed file paths in a project that contain the given text
+func (r *Repository) ListFilePathsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	query := `
+		SELECT f.file_path
+		FROM file_context_history f
+		JOIN knowledge_entities e ON e.id = f.entity_id
+		WHERE e.project_id = $1 AND f.file_path ILIKE $2 ESCAPE '\'
+		GROUP BY f.file_path
+		ORDER BY f.file_path ILIKE $3 ESCAPE '\' DESC, MAX(f.created_at) DESC, f.file_path
+		LIMIT $4`
+
+	return r.listProjectValues(ctx, query, projectID, contains, limit)
+}
+
+// ListFeatureNamesByProject returns distinct feature names in a project that contain the given text
+func (r *Repository) ListFeatureNamesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	query := `
+		SELECT f.feature_name
+		FROM feature_contexts f
+		JOIN knowledge_entities e ON e.id = f.entity_id
+		WHERE e.project_id = $1 AND f.feature_name ILIKE $2 ESCAPE '\'
+		GROUP BY f.feature_name
+		ORDER BY f.feature_name ILIKE $3 ESCAPE '\' DESC, MAX(f.updated_at) DESC, f.feature_name
+		LIMIT $4`
+
+	return r.listProjectValues(ctx, query, projectID, contains, limit)
+}
+
+// ListDecisionTitlesByProject returns distinct decision titles in a project that contain the given text
+func (r *Repository) ListDecisionTitlesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	query := `
+		SELECT d.title
+		FROM decision_records d
+		JOIN knowledge_entities e ON e.id = d.entity_id
+		WHERE e.project_id = $1 AND d.title ILIKE $2 ESCAPE '\'
+		GROUP BY d.title
+		ORDER BY d.title ILIKE $3 ESCAPE '\' DESC, MAX(d.updated_at) DESC, d.title
+		LIMIT $4`
+
+	return r.listProjectValues(ctx, query, projectID, contains, limit)
+}
+
+// listProjectValues runs a single-column value query, ranking values that start
+// with the given text ahead of those that only contain it
+func (r *Repository) listProjectValues(ctx context.Context, query, projectID, contains string, limit int) ([]string, error) {
+	pattern := escapeLikePattern(contains)
+
+	rows, err := r.db.QueryContext(ctx, query, projectID, "%"+pattern+"%", pattern+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// escapeLikePattern escapes the LIKE wildcards in user-supplied text
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
 // User operations
 
 func (r *Repository) CreateUser(ctx context.Context, user *models.User) error {
@@ -2399,4 +2658,83 @@ func (r *Repository) DeleteProjectDataSource(ctx context.Context, dataSourceID s
 	query := `DELETE FROM project_data_sources WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, query, dataSourceID)
 	return err
-}
\ No newline at end of file
+}
+// MCP usage operations
+
+func (r *Repository) CreateMCPToolCall(ctx context.Context, call *models.MCPToolCall) error {
+	query := `
+		INSERT INTO mcp_tool_calls (id, project_id, user_id, tool_name, status, latency_ms, result_bytes, result_tokens, error_message, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	if call.ID == "" {
+		call.ID = generateUUID()
+	}
+	if call.CreatedAt.IsZero() {
+		call.CreatedAt = time.Now()
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		call.ID, call.ProjectID, call.UserID, call.ToolName, call.Status, call.LatencyMs,
+		call.ResultBytes, call.ResultTokens, call.ErrorMessage, call.CreatedAt)
+	return err
+}
+
+// GetMCPToolCallTotals counts a project's tool calls since the given time, for
+// all users when userID is empty. Calls rejected by a quota are not counted.
+func (r *Repository) GetMCPToolCallTotals(ctx context.Context, projectID, userID string, since time.Time) (*models.MCPToolCallTotals, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(result_tokens), 0)
+		FROM mcp_tool_calls
+		WHERE project_id = $1 AND created_at >= $2 AND status != $3`
+
+	args := []interface{}{projectID, since, models.MCPToolCallQuotaExceeded}
+	if userID != "" {
+		query += " AND user_id = $4"
+		args = append(args, userID)
+	}
+
+	var totals models.MCPToolCallTotals
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&totals.Calls, &totals.ResultTokens); err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+// GetMCPToolUsageByProject aggregates a project's tool calls since the given time per user and tool
+func (r *Repository) GetMCPToolUsageByProject(ctx context.Context, projectID string, since time.Time) ([]models.MCPToolUsage, error) {
+	query := `
+		SELECT tool_name, user_id,
+		       COUNT(*) FILTER (WHERE status != $3),
+		       COUNT(*) FILTER (WHERE status = $4),
+		       COUNT(*) FILTER (WHERE status = $3),
+		       COALESCE(SUM(latency_ms) FILTER (WHERE status != $3), 0),
+		       COALESCE(MAX(latency_ms) FILTER (WHERE status != $3), 0),
+		       COALESCE(SUM(result_bytes) FILTER (WHERE status != $3), 0),
+		       COALESCE(SUM(result_tokens) FILTER (WHERE status != $3), 0),
+		       MAX(created_at)
+		FROM mcp_tool_calls
+		WHERE project_id = $1 AND created_at >= $2
+		GROUP BY tool_name, user_id
+		ORDER BY COUNT(*) DESC, tool_name, user_id`
+
+	rows, err := r.db.QueryContext(ctx, query, projectID, since, models.MCPToolCallQuotaExceeded, models.MCPToolCallError)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []models.MCPToolUsage
+	for rows.Next() {
+		var u models.MCPToolUsage
+		var lastCalledAt time.Time
+		err := rows.Scan(&u.ToolName, &u.UserID, &u.Calls, &u.Errors, &u.Rejected,
+			&u.TotalLatencyMs, &u.MaxLatencyMs, &u.ResultBytes, &u.ResultTokens, &lastCalledAt)
+		if err != nil {
+			return nil, err
+		}
+		u.LastCalledAt = &lastCalledAt
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
@@ -245,3 +245,30 @@ func cleanupTestDB(db *sql.DB) {
 		db.Exec("DELETE FROM " + table)
 	}
 }
+
+func TestEmbeddingSerialization(t *testing.T) {
+	embedding := []float32{0.25, -1, 3.5e-7}
+
+	serialized := convertFloatSliceToString(embedding)
+	if serialized == nil || *serialized != "[0.25,-1,3.5e-07]" {
+		t.Fatalf("Unexpected vector literal: %v", serialized)
+	}
+
+	parsed := convertStringToFloatSlice(*serialized)
+	if len(parsed) != len(embedding) {
+		t.Fatalf("Expected %d values, got %d", len(embedding), len(parsed))
+	}
+	for i := range embedding {
+		if parsed[i] != embedding[i] {
+			t.Errorf("Value %d: expected %v, got %v", i, embedding[i], parsed[i])
+		}
+	}
+
+	// Empty embeddings are stored as NULL rather than an invalid vector
+	if convertFloatSliceToString([]float32{}) != nil {
+		t.Error("Expected an empty embedding to serialize to NULL")
+	}
+	if convertStringToFloatSlice("[]") != nil {
+		t.Error("Expected an empty vector to parse to nil")
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
@@ -13,13 +14,15 @@ import (
 	"github.com/DevAnuragT/context_keeper/internal/middleware"
 	"github.com/DevAnuragT/context_keeper/internal/repository"
 	"github.com/DevAnuragT/context_keeper/internal/services"
+	"github.com/DevAnuragT/context_keeper/internal/services/connectors"
 )
 
 // Server represents the HTTP server
 type Server struct {
-	mux       *http.ServeMux
-	config    *config.Config
-	startTime time.Time
+	mux             *http.ServeMux
+	config          *config.Config
+	startTime       time.Time
+	embeddingWorker *services.EmbeddingWorker
 }
 
 // New creates a new server instance
@@ -45,14 +48,25 @@ func New(db *sql.DB, cfg *config.Config) *Server {
 	permissionSvc := services.NewPermissionService(repo)
 	
 	jobSvc := services.NewJobService(repo, githubSvc)
-	contextSvc := services.NewContextService(repo, permissionSvc, cfg.AIService.BaseURL)
 	
-	// Initialize context processor and knowledge graph services
+	// Context queries are answered in-process unless an AI service is configured
 	logger := &services.SimpleLogger{}
-	mockAI := &services.ProductionMockAIService{} // Use production mock AI service
-	contextProcessor := services.NewContextProcessor(mockAI, logger)
+	var contextGenerator services.ContextGenerator
+	if cfg.AIService.BaseURL != "" {
+		contextGenerator = services.NewHTTPContextGenerator(cfg.AIService.BaseURL, time.Duration(cfg.AIService.Timeout)*time.Second)
+	}
+	contextSvc := services.NewContextService(repo, permissionSvc, services.NewContextEngine(contextGenerator, logger))
+	
+	// Initialize context processor and knowledge graph services
+	// Knowledge is extracted by LLM as well as by pattern when AI_LLM_BASE_URL is set
+	contextProcessor := services.NewContextProcessor(services.NewAIService(cfg.AIService, logger), logger)
 	knowledgeGraphSvc := services.NewKnowledgeGraphService(repo, permissionSvc, contextProcessor, logger)
 	
+	// Embed knowledge entities for semantic search, offline unless an embeddings API is configured
+	embedder := services.NewEmbedder(cfg.Embedding, logger)
+	server.embeddingWorker = services.NewEmbeddingWorker(repo, embedder, logger, cfg.Embedding.BatchSize, time.Duration(cfg.Embedding.Interval)*time.Second)
+	knowledgeGraphSvc.SetEmbedder(embedder)
+	
 	// Initialize encryption service
 	encryptSvc := services.NewEncryptionService(cfg)
 	
@@ -65,14 +79,33 @@ func New(db *sql.DB, cfg *config.Config) *Server {
 	// Initialize Discord integration service
 	discordIntegrationSvc := services.NewDiscordIntegrationService(cfg, repo, encryptSvc, logger)
 	
+	// Initialize GitLab integration service
+	gitlabIntegrationSvc := services.NewGitLabIntegrationService(cfg, repo, encryptSvc, logger)
+	
+	// Initialize Jira integration service
+	jiraIntegrationSvc := services.NewJiraIntegrationService(cfg, repo, encryptSvc, logger)
+	
 	// Initialize MCP server
-	mcpSvc := services.NewMCPServer(knowledgeGraphSvc, contextSvc, logger)
+	mcpSvc := services.NewMCPServer(knowledgeGraphSvc, contextSvc, permissionSvc, repo, logger)
+	
+	// Sync integrations on request through the configured platform connectors
+	connectorManager, err := connectors.LoadConnectorManager(cfg.Connectors, logger)
+	if err != nil {
+		logger.Error("Failed to load platform connectors", err, nil)
+		connectorManager = connectors.NewConnectorManager(connectors.NewRegistry())
+	}
+	ingestionOrchestrator := connectors.NewIngestionOrchestrator(connectorManager, repo, contextProcessor, encryptSvc, logger)
+	mcpSvc.SetIngestionOrchestrator(ingestionOrchestrator)
 
 	// Initialize handlers
 	h := handlers.New(authSvc, jobSvc, contextSvc, repo, permissionSvc)
 	githubIntegrationHandlers := handlers.NewGitHubIntegrationHandlers(authSvc, githubIntegrationSvc, permissionSvc)
 	slackIntegrationHandlers := handlers.NewSlackIntegrationHandlers(authSvc, slackIntegrationSvc, permissionSvc)
 	discordIntegrationHandlers := handlers.NewDiscordIntegrationHandlers(authSvc, discordIntegrationSvc, permissionSvc)
+	gitlabIntegrationHandlers := handlers.NewGitLabIntegrationHandlers(authSvc, gitlabIntegrationSvc, permissionSvc)
+	jiraIntegrationHandlers := handlers.NewJiraIntegrationHandlers(authSvc, jiraIntegrationSvc, permissionSvc)
+	mcpUsageHandlers := handlers.NewMCPUsageHandlers(authSvc, services.NewMCPUsageService(repo), permissionSvc)
+	webhookHandlers := handlers.NewWebhookHandlers(repo, connectors.NewWebhookParser(connectorManager), ingestionOrchestrator)
 
 	// Create router
 	mux := http.NewServeMux()
@@ -236,11 +269,108 @@ func New(db *sql.DB, cfg *config.Config) *Server {
 			return
 		}
 		
+		// GitLab token installation: POST /api/projects/{project_id}/integrations/gitlab/token/install
+		if strings.Contains(path, "/integrations/gitlab/token/install") && r.Method == http.MethodPost {
+			middleware.AuthRequired(authSvc, gitlabIntegrationHandlers.HandleGitLabTokenInstallation)(w, r)
+			return
+		}
+		
+		// Get available repositories: GET /api/projects/{project_id}/integrations/gitlab/{integration_id}/repositories
+		if strings.Contains(path, "/integrations/gitlab/") && strings.HasSuffix(path, "/repositories") && r.Method == http.MethodGet {
+			middleware.AuthRequired(authSvc, gitlabIntegrationHandlers.HandleGetAvailableRepositories)(w, r)
+			return
+		}
+		
+		// Select repositories: POST /api/projects/{project_id}/integrations/gitlab/{integration_id}/repositories/select
+		if strings.Contains(path, "/integrations/gitlab/") && strings.HasSuffix(path, "/repositories/select") && r.Method == http.MethodPost {
+			middleware.AuthRequired(authSvc, gitlabIntegrationHandlers.HandleSelectRepositories)(w, r)
+			return
+		}
+		
+		// Update configuration: PUT /api/projects/{project_id}/integrations/gitlab/{integration_id}/configuration
+		if strings.Contains(path, "/integrations/gitlab/") && strings.HasSuffix(path, "/configuration") && r.Method == http.MethodPut {
+			middleware.AuthRequired(authSvc, gitlabIntegrationHandlers.HandleUpdateGitLabConfiguration)(w, r)
+			return
+		}
+		
+		// Get integration status: GET /api/projects/{project_id}/integrations/gitlab/{integration_id}/status
+		if strings.Contains(path, "/integrations/gitlab/") && strings.HasSuffix(path, "/status") && r.Method == http.MethodGet {
+			middleware.AuthRequired(authSvc, gitlabIntegrationHandlers.HandleGetGitLabIntegrationStatus)(w, r)
+			return
+		}
+		
+		// Validate access token: POST /api/projects/{project_id}/integrations/gitlab/{integration_id}/validate
+		if strings.Contains(path, "/integrations/gitlab/") && strings.HasSuffix(path, "/validate") && r.Method == http.MethodPost {
+			middleware.AuthRequired(authSvc, gitlabIntegrationHandlers.HandleValidateGitLabCredentials)(w, r)
+			return
+		}
+		
+		// Delete integration: DELETE /api/projects/{project_id}/integrations/gitlab/{integration_id}
+		if strings.Contains(path, "/integrations/gitlab/") && !strings.Contains(path, "/repositories") && !strings.Contains(path, "/configuration") && !strings.Contains(path, "/status") && !strings.Contains(path, "/validate") && r.Method == http.MethodDelete {
+			middleware.AuthRequired(authSvc, gitlabIntegrationHandlers.HandleDeleteGitLabIntegration)(w, r)
+			return
+		}
+		
+		// Jira token installation: POST /api/projects/{project_id}/integrations/jira/token/install
+		if strings.Contains(path, "/integrations/jira/token/install") && r.Method == http.MethodPost {
+			middleware.AuthRequired(authSvc, jiraIntegrationHandlers.HandleJiraTokenInstallation)(w, r)
+			return
+		}
+		
+		// Get available projects: GET /api/projects/{project_id}/integrations/jira/{integration_id}/projects
+		if strings.Contains(path, "/integrations/jira/") && strings.HasSuffix(path, "/projects") && r.Method == http.MethodGet {
+			middleware.AuthRequired(authSvc, jiraIntegrationHandlers.HandleGetAvailableProjects)(w, r)
+			return
+		}
+		
+		// Select projects: POST /api/projects/{project_id}/integrations/jira/{integration_id}/projects/select
+		if strings.Contains(path, "/integrations/jira/") && strings.HasSuffix(path, "/projects/select") && r.Method == http.MethodPost {
+			middleware.AuthRequired(authSvc, jiraIntegrationHandlers.HandleSelectProjects)(w, r)
+			return
+		}
+		
+		// Update configuration: PUT /api/projects/{project_id}/integrations/jira/{integration_id}/configuration
+		if strings.Contains(path, "/integrations/jira/") && strings.HasSuffix(path, "/configuration") && r.Method == http.MethodPut {
+			middleware.AuthRequired(authSvc, jiraIntegrationHandlers.HandleUpdateJiraConfiguration)(w, r)
+			return
+		}
+		
+		// Get integration status: GET /api/projects/{project_id}/integrations/jira/{integration_id}/status
+		if strings.Contains(path, "/integrations/jira/") && strings.HasSuffix(path, "/status") && r.Method == http.MethodGet {
+			middleware.AuthRequired(authSvc, jiraIntegrationHandlers.HandleGetJiraIntegrationStatus)(w, r)
+			return
+		}
+		
+		// Validate API token: POST /api/projects/{project_id}/integrations/jira/{integration_id}/validate
+		if strings.Contains(path, "/integrations/jira/") && strings.HasSuffix(path, "/validate") && r.Method == http.MethodPost {
+			middleware.AuthRequired(authSvc, jiraIntegrationHandlers.HandleValidateJiraCredentials)(w, r)
+			return
+		}
+		
+		// Delete integration: DELETE /api/projects/{project_id}/integrations/jira/{integration_id}
+		// (every path starts with /api/projects, so the projects sub-resource is matched after the integration ID)
+		if strings.Contains(path, "/integrations/jira/") && !strings.HasSuffix(path, "/projects") && !strings.Contains(path, "/projects/select") && !strings.Contains(path, "/configuration") && !strings.Contains(path, "/status") && !strings.Contains(path, "/validate") && r.Method == http.MethodDelete {
+			middleware.AuthRequired(authSvc, jiraIntegrationHandlers.HandleDeleteJiraIntegration)(w, r)
+			return
+		}
+		
+		// MCP tool usage: GET /api/projects/{project_id}/mcp/usage
+		if strings.HasSuffix(path, "/mcp/usage") && r.Method == http.MethodGet {
+			middleware.AuthRequired(authSvc, mcpUsageHandlers.HandleGetMCPUsage)(w, r)
+			return
+		}
+		
 		http.NotFound(w, r)
 	})
 	
-	// MCP JSON-RPC endpoint
-	mux.Handle("/mcp", mcpSvc)
+	// MCP JSON-RPC endpoint (authenticated; tools are scoped to a project the user can read)
+	mux.HandleFunc("/mcp", middleware.AuthRequired(authSvc, func(w http.ResponseWriter, r *http.Request) {
+		user, _ := middleware.GetUserFromContext(r.Context())
+		mcpSvc.ServeHTTP(w, r.WithContext(services.WithMCPUser(r.Context(), user)))
+	}))
+
+	// Platform webhooks (unauthenticated; deliveries are verified by their signature)
+	mux.HandleFunc("/api/webhooks/", webhookHandlers.HandleWebhook)
 
 	// Handle repo status endpoint with pattern matching
 	mux.HandleFunc("/api/repos/", func(w http.ResponseWriter, r *http.Request) {
@@ -255,6 +385,17 @@ func New(db *sql.DB, cfg *config.Config) *Server {
 	return server
 }
 
+// StartWorkers starts the server's background workers; they run until ctx is
+// cancelled or StopWorkers is called
+func (s *Server) StartWorkers(ctx context.Context) error {
+	return s.embeddingWorker.Start(ctx)
+}
+
+// StopWorkers stops the background workers and waits for them to finish
+func (s *Server) StopWorkers() {
+	s.embeddingWorker.Stop()
+}
+
 // handleHealth handles basic health checks
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -1,11 +1,17 @@
 package server
 
 import (
+	"database/sql"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/DevAnuragT/context_keeper/internal/services"
 )
 
 func TestHealthEndpoint(t *testing.T) {
@@ -58,3 +64,77 @@ func TestHealthEndpoint(t *testing.T) {
 		t.Error("Missing X-Frame-Options header")
 	}
 }
+
+// TestMCPListsIngestionTools tests that the server's MCP endpoint is wired to an
+// ingestion orchestrator, which the ingestion tools need
+func TestMCPListsIngestionTools(t *testing.T) {
+	// Listing tools never reaches the database, so it is opened but not connected
+	db, err := sql.Open("postgres", "postgres://localhost/contextkeeper_test?sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := &config.Config{
+		JWTSecret:  "test-secret-key-for-wiring-tests",
+		Connectors: filepath.Join(t.TempDir(), "connectors.json"),
+	}
+	server := New(db, cfg)
+
+	token, err := services.NewAuthService(cfg, nil, nil, nil, nil).GenerateJWT(&models.User{ID: "u1", Login: "alice"})
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	post := func(sessionID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(services.MCPSessionHeader, sessionID)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected initialize to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = post(w.Header().Get(services.MCPSessionHeader), `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected tools/list to succeed, got %d: %s", w.Code, body)
+	}
+	for _, tool := range []string{`"get_ingestion_status"`, `"request_sync"`} {
+		if !strings.Contains(string(body), tool) {
+			t.Errorf("Expected tools/list to include %s", tool)
+		}
+	}
+}
+
+// TestWebhookRouteIsUnauthenticated tests that platform webhooks reach their
+// handler without a token; the delivery's signature authenticates it instead
+func TestWebhookRouteIsUnauthenticated(t *testing.T) {
+	// The database is not running, so no integration is found
+	db, err := sql.Open("postgres", "postgres://127.0.0.1:1/contextkeeper_test?sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	server := New(db, &config.Config{
+		JWTSecret:  "test-secret-key-for-wiring-tests",
+		Connectors: filepath.Join(t.TempDir(), "connectors.json"),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks/linear/i1", strings.NewReader(`{"action":"update","type":"Issue"}`))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown integration without asking for a token, got %d: %s", w.Code, w.Body.String())
+	}
+}
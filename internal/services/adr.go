@@ -0,0 +1,344 @@
+package services
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ADR is an architecture decision record parsed from markdown written with
+// the MADR or Nygard template
+type ADR struct {
+	ID           string // ADR-0005 when numbered, otherwise the file name without extension
+	Number       int
+	Title        string
+	Status       string // lowercase: proposed, accepted, rejected, deprecated, superseded
+	Date         string
+	Deciders     []string
+	Tags         []string
+	Context      string
+	Decision     string
+	Consequences []string
+	Options      []string
+	Supersedes   []string // IDs of the ADRs this one replaces
+	SupersededBy []string // IDs of the ADRs that replace this one
+	Mentions     []string // paths mentioned in code spans, links and text, as written
+}
+
+var (
+	// adrFileNumberPattern reads the number of files named like 0005-use-postgres.md
+	adrFileNumberPattern = regexp.MustCompile(`^(\d+)[-_]`)
+	// adrTitlePattern reads numbered titles such as "5. Use Postgres" or "ADR-0005: Use Postgres"
+	adrTitlePattern = regexp.MustCompile(`(?i)^(?:adr[-\s]?)?(\d+)[.:]?\s+(.+)$`)
+	// adrFieldPattern reads "Status: accepted" lines, optionally bulleted
+	adrFieldPattern = regexp.MustCompile(`(?i)^[-*+]?\s*(status|date|deciders|tags)\s*:\s*(.+)$`)
+	// adrHeadingPattern matches the section headings that structure a record
+	adrHeadingPattern = regexp.MustCompile(`^#{2,3}\s+(.+?)\s*#*$`)
+	// adrSupersedePattern finds supersede statements and what follows them
+	adrSupersedePattern = regexp.MustCompile(`(?i)\b(superseded by|supersedes|replaced by|replaces)\b(.*)`)
+	// adrLinkPattern matches markdown links
+	adrLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)[^)]*\)`)
+	// adrRefPattern matches ADR numbers in text, such as ADR-0005 or ADR 5
+	adrRefPattern = regexp.MustCompile(`(?i)\badr[-\s]?(\d+)\b`)
+	// adrLinkTextNumberPattern matches Nygard link texts such as "5. Use Postgres"
+	adrLinkTextNumberPattern = regexp.MustCompile(`^(\d+)\.\s`)
+	// adrCodeSpanPattern matches inline code
+	adrCodeSpanPattern = regexp.MustCompile("`([^`\n]+)`")
+	// adrPathPattern matches bare relative paths with an extension, such as internal/auth/jwt.go
+	adrPathPattern = regexp.MustCompile(`(?:^|[\s(])((?:\.{1,2}/)?[\w.-]+(?:/[\w.-]+)+\.\w+)\b`)
+)
+
+// adrStatuses are the statuses the templates use
+var adrStatuses = map[string]bool{
+	"proposed": true, "accepted": true, "rejected": true, "deprecated": true, "superseded": true,
+}
+
+// ParseADR parses an architecture decision record. filePath names the record
+// when its title has no number. A file without a title is not a record.
+func ParseADR(filePath, content string) (*ADR, error) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	adr := &ADR{}
+	fields := make(map[string]string)
+
+	// MADR keeps its metadata in YAML front matter
+	lines := strings.Split(content, "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				lines = lines[i+1:]
+				break
+			}
+			if key, value, ok := strings.Cut(lines[i], ":"); ok {
+				fields[strings.ToLower(strings.TrimSpace(key))] = trimADRValue(value)
+			}
+		}
+	}
+
+	name := strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+	if match := adrFileNumberPattern.FindStringSubmatch(name); match != nil {
+		adr.Number, _ = strconv.Atoi(match[1])
+	}
+
+	var (
+		section      string
+		sectionLines = make(map[string][]string)
+	)
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if adr.Title == "" && strings.HasPrefix(trimmed, "# ") {
+			adr.Title = strings.TrimSpace(strings.TrimPrefix(trimmed, "# "))
+			if match := adrTitlePattern.FindStringSubmatch(adr.Title); match != nil {
+				if adr.Number == 0 {
+					adr.Number, _ = strconv.Atoi(match[1])
+				}
+				adr.Title = match[2]
+			}
+			continue
+		}
+		if match := adrHeadingPattern.FindStringSubmatch(trimmed); match != nil {
+			section = adrSection(match[1])
+			continue
+		}
+		// Nygard and early MADR records list their metadata under the title
+		if section == "" {
+			if match := adrFieldPattern.FindStringSubmatch(trimmed); match != nil {
+				key := strings.ToLower(match[1])
+				if _, ok := fields[key]; !ok {
+					fields[key] = trimADRValue(match[2])
+				}
+			}
+			continue
+		}
+		sectionLines[section] = append(sectionLines[section], line)
+	}
+
+	if adr.Title == "" {
+		return nil, fmt.Errorf("%s has no title, so it is not an architecture decision record", filePath)
+	}
+
+	adr.ID = name
+	if adr.Number > 0 {
+		adr.ID = fmt.Sprintf("ADR-%04d", adr.Number)
+	}
+
+	statusText := fields["status"]
+	if statusText == "" {
+		statusText = firstADRParagraph(sectionLines["status"])
+	}
+	adr.Status = adrStatus(statusText)
+	adr.Date = fields["date"]
+	adr.Deciders = splitADRList(fields["deciders"])
+	adr.Tags = splitADRList(fields["tags"])
+	adr.Context = strings.TrimSpace(strings.Join(sectionLines["context"], "\n"))
+	adr.Decision = strings.TrimSpace(strings.Join(sectionLines["decision"], "\n"))
+	adr.Consequences = adrListItems(sectionLines["consequences"])
+	adr.Options = adrListItems(sectionLines["options"])
+
+	// Supersede links appear in the status, in a links section or in the text
+	for _, line := range append([]string{statusText}, lines...) {
+		match := adrSupersedePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		// Only the sentence holding the statement names what is superseded
+		refs := adrRefs(firstADRSentence(match[2]))
+		if strings.HasSuffix(strings.ToLower(match[1]), "by") {
+			adr.SupersededBy = appendUniqueStrings(adr.SupersededBy, refs...)
+		} else {
+			adr.Supersedes = appendUniqueStrings(adr.Supersedes, refs...)
+		}
+	}
+	if len(adr.SupersededBy) > 0 && adr.Status == "accepted" {
+		adr.Status = "superseded"
+	}
+
+	adr.Mentions = adrMentions(strings.Join(lines, "\n"))
+	return adr, nil
+}
+
+// adrSection maps a heading to the part of the record it holds, or "other"
+func adrSection(heading string) string {
+	heading = strings.ToLower(strings.Trim(heading, "*_ "))
+	switch {
+	case strings.HasPrefix(heading, "status"):
+		return "status"
+	case strings.HasPrefix(heading, "context"):
+		return "context"
+	case heading == "decision" || strings.HasPrefix(heading, "decision outcome"):
+		return "decision"
+	case strings.Contains(heading, "consequences"):
+		return "consequences"
+	case strings.HasPrefix(heading, "considered options"):
+		return "options"
+	}
+	return "other"
+}
+
+// adrStatus reduces a status line such as "Superseded by ADR-0005" to its
+// status word
+func adrStatus(text string) string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !('a' <= r && r <= 'z')
+	}) {
+		if adrStatuses[word] {
+			return word
+		}
+	}
+	if text == "" {
+		return "proposed"
+	}
+	return strings.Fields(text)[0]
+}
+
+// adrRefs returns the IDs of the ADRs referenced in text, by link or number
+func adrRefs(text string) []string {
+	var refs []string
+	for _, link := range adrLinkPattern.FindAllStringSubmatch(text, -1) {
+		target := strings.SplitN(link[2], "#", 2)[0]
+		name := strings.TrimSuffix(path.Base(target), path.Ext(target))
+		switch {
+		case adrFileNumberPattern.MatchString(name):
+			number, _ := strconv.Atoi(adrFileNumberPattern.FindStringSubmatch(name)[1])
+			refs = append(refs, fmt.Sprintf("ADR-%04d", number))
+		case adrLinkTextNumberPattern.MatchString(link[1]):
+			number, _ := strconv.Atoi(adrLinkTextNumberPattern.FindStringSubmatch(link[1])[1])
+			refs = append(refs, fmt.Sprintf("ADR-%04d", number))
+		case adrRefPattern.MatchString(link[1]):
+			number, _ := strconv.Atoi(adrRefPattern.FindStringSubmatch(link[1])[1])
+			refs = append(refs, fmt.Sprintf("ADR-%04d", number))
+		case path.Ext(target) == ".md":
+			refs = append(refs, name)
+		}
+	}
+	for _, match := range adrRefPattern.FindAllStringSubmatch(adrLinkPattern.ReplaceAllString(text, ""), -1) {
+		number, _ := strconv.Atoi(match[1])
+		refs = append(refs, fmt.Sprintf("ADR-%04d", number))
+	}
+	return appendUniqueStrings(nil, refs...)
+}
+
+// adrMentions returns the paths a record mentions, in code spans, as link
+// targets or as bare paths, in order of appearance
+func adrMentions(text string) []string {
+	var mentions []string
+	for _, match := range adrCodeSpanPattern.FindAllStringSubmatch(text, -1) {
+		if span := strings.TrimSpace(match[1]); !strings.ContainsAny(span, " \t") && strings.ContainsAny(span, "./") {
+			mentions = append(mentions, span)
+		}
+	}
+	for _, match := range adrLinkPattern.FindAllStringSubmatch(text, -1) {
+		target := strings.SplitN(match[2], "#", 2)[0]
+		if target != "" && !strings.Contains(target, "://") && !strings.HasPrefix(target, "mailto:") {
+			mentions = append(mentions, target)
+		}
+	}
+	for _, match := range adrPathPattern.FindAllStringSubmatch(adrCodeSpanPattern.ReplaceAllString(adrLinkPattern.ReplaceAllString(text, ""), ""), -1) {
+		mentions = append(mentions, match[1])
+	}
+	return appendUniqueStrings(nil, mentions...)
+}
+
+// adrListItems returns the items of markdown lists, or the paragraphs of
+// prose when there is no list
+func adrListItems(lines []string) []string {
+	var items []string
+	bulleted := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if item, ok := adrListItem(trimmed); ok {
+			items = append(items, item)
+			bulleted = true
+		} else if bulleted && trimmed != "" && len(items) > 0 && line != trimmed {
+			// Indented lines continue the item above
+			items[len(items)-1] += " " + trimmed
+		}
+	}
+	if bulleted {
+		return items
+	}
+
+	for _, paragraph := range strings.Split(strings.Join(lines, "\n"), "\n\n") {
+		if paragraph = strings.Join(strings.Fields(paragraph), " "); paragraph != "" {
+			items = append(items, paragraph)
+		}
+	}
+	return items
+}
+
+// adrListItem returns the text of a bulleted or numbered list item
+func adrListItem(line string) (string, bool) {
+	for _, bullet := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(line, bullet) {
+			return strings.TrimSpace(line[len(bullet):]), true
+		}
+	}
+	if i := strings.Index(line, ". "); i > 0 && i <= 3 {
+		if _, err := strconv.Atoi(line[:i]); err == nil {
+			return strings.TrimSpace(line[i+2:]), true
+		}
+	}
+	return "", false
+}
+
+// firstADRSentence returns text up to the first full stop outside a link
+func firstADRSentence(text string) string {
+	depth := 0
+	for i, r := range text {
+		switch r {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case '.':
+			if depth <= 0 && (i+1 == len(text) || text[i+1] == ' ') {
+				return text[:i]
+			}
+		}
+	}
+	return text
+}
+
+// firstADRParagraph returns the first paragraph of a section
+func firstADRParagraph(lines []string) string {
+	var paragraph []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+	return strings.Join(paragraph, " ")
+}
+
+// trimADRValue strips the quotes and brackets YAML values may carry
+func trimADRValue(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'[]`)
+}
+
+// splitADRList splits a comma-separated metadata value
+func splitADRList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = trimADRValue(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// appendUniqueStrings appends the values not already in list
+func appendUniqueStrings(list []string, values ...string) []string {
+	for _, value := range values {
+		if value != "" && !containsString(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
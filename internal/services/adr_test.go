@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseADR(t *testing.T) {
+	t.Run("MADR", func(t *testing.T) {
+		adr, err := ParseADR("docs/adr/0007-use-postgres-for-events.md", `---
+status: accepted
+date: 2024-05-01
+deciders: Alice, Bob
+---
+# Use Postgres for events
+
+## Context and Problem Statement
+
+Events are stored in `+"`internal/events/store.go`"+` and we need durable storage.
+
+## Considered Options
+
+* Postgres
+* Kafka
+
+## Decision Outcome
+
+Chosen option: "Postgres", because it is already deployed.
+
+### Consequences
+
+* Good, because backups already exist
+* Bad, because throughput is limited
+  by a single writer
+
+## More Information
+
+Supersedes [ADR-0003](0003-store-events-in-redis.md). See [the schema](../../internal/database/migrations.go).
+`)
+		require.NoError(t, err)
+		assert.Equal(t, "ADR-0007", adr.ID)
+		assert.Equal(t, "Use Postgres for events", adr.Title)
+		assert.Equal(t, "accepted", adr.Status)
+		assert.Equal(t, "2024-05-01", adr.Date)
+		assert.Equal(t, []string{"Alice", "Bob"}, adr.Deciders)
+		assert.Contains(t, adr.Context, "durable storage")
+		assert.Equal(t, `Chosen option: "Postgres", because it is already deployed.`, adr.Decision)
+		assert.Equal(t, []string{"Postgres", "Kafka"}, adr.Options)
+		assert.Equal(t, []string{"Good, because backups already exist", "Bad, because throughput is limited by a single writer"}, adr.Consequences)
+		assert.Equal(t, []string{"ADR-0003"}, adr.Supersedes)
+		assert.Equal(t, []string{"internal/events/store.go", "0003-store-events-in-redis.md", "../../internal/database/migrations.go"}, adr.Mentions)
+	})
+
+	t.Run("Nygard", func(t *testing.T) {
+		adr, err := ParseADR("doc/adr/retries.md", `# 2. Retry webhooks with backoff
+
+Date: 2023-11-02
+
+## Status
+
+Superseded by [5. Queue webhooks](0005-queue-webhooks.md)
+
+## Context
+
+Deliveries fail when receivers restart.
+
+## Decision
+
+We will retry deliveries from internal/webhooks/retry.go.
+
+## Consequences
+
+Receivers see duplicate deliveries.
+
+Retries delay the queue.
+`)
+		require.NoError(t, err)
+		assert.Equal(t, "ADR-0002", adr.ID)
+		assert.Equal(t, "Retry webhooks with backoff", adr.Title)
+		assert.Equal(t, "superseded", adr.Status)
+		assert.Equal(t, "2023-11-02", adr.Date)
+		assert.Equal(t, []string{"ADR-0005"}, adr.SupersededBy)
+		assert.Empty(t, adr.Supersedes)
+		assert.Equal(t, "We will retry deliveries from internal/webhooks/retry.go.", adr.Decision)
+		assert.Equal(t, []string{"Receivers see duplicate deliveries.", "Retries delay the queue."}, adr.Consequences)
+		assert.Contains(t, adr.Mentions, "internal/webhooks/retry.go")
+	})
+
+	t.Run("unnumbered", func(t *testing.T) {
+		adr, err := ParseADR("docs/adr/logging.md", "# Log in JSON\n\n* Status: proposed\n")
+		require.NoError(t, err)
+		assert.Equal(t, "logging", adr.ID)
+		assert.Equal(t, "proposed", adr.Status)
+	})
+
+	_, err := ParseADR("docs/adr/README.md", "This directory holds our decisions.\n")
+	assert.Error(t, err)
+}
@@ -3,10 +3,13 @@ package connectors
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/services"
 )
 
 // ConfigManager handles loading and managing connector configurations
@@ -76,6 +79,26 @@ func (cm *ConfigManager) loadFromEnvironment() error {
 		cm.configs["discord"] = *discordConfig
 	}
 
+	// Load GitLab configuration from environment
+	if gitlabConfig := cm.loadGitLabFromEnv(); gitlabConfig != nil {
+		cm.configs["gitlab"] = *gitlabConfig
+	}
+
+	// Load Jira configuration from environment
+	if jiraConfig := cm.loadJiraFromEnv(); jiraConfig != nil {
+		cm.configs["jira"] = *jiraConfig
+	}
+
+	// Load Linear configuration from environment
+	if linearConfig := cm.loadLinearFromEnv(); linearConfig != nil {
+		cm.configs["linear"] = *linearConfig
+	}
+
+	// Load local git configuration from environment
+	if gitLocalConfig := cm.loadGitLocalFromEnv(); gitLocalConfig != nil {
+		cm.configs["gitlocal"] = *gitLocalConfig
+	}
+
 	return nil
 }
 
@@ -202,6 +225,183 @@ func (cm *ConfigManager) loadDiscordFromEnv() *ConnectorConfig {
 	return config
 }
 
+// loadGitLabFromEnv loads GitLab configuration from environment variables
+func (cm *ConfigManager) loadGitLabFromEnv() *ConnectorConfig {
+	token := os.Getenv("GITLAB_TOKEN")
+
+	if token == "" {
+		return nil
+	}
+
+	enabled := strings.ToLower(os.Getenv("GITLAB_CONNECTOR_ENABLED")) != "false"
+
+	baseURL := os.Getenv("GITLAB_BASE_URL")
+	if baseURL == "" {
+		baseURL = services.DefaultGitLabBaseURL
+	}
+
+	config := &ConnectorConfig{
+		Platform: "gitlab",
+		Enabled:  enabled,
+		AuthConfig: AuthConfig{
+			Scopes: []string{"read_api"},
+			Metadata: map[string]string{
+				"access_token": token, // personal, project or group access token
+			},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   7200,
+			RequestsPerMinute: 120,
+			BurstLimit:        10,
+			BackoffMultiplier: 2.0,
+			MaxRetries:        3,
+		},
+		SyncConfig: SyncConfig{
+			BatchSize:       100,
+			SyncInterval:    5 * time.Minute,
+			MaxLookback:     30 * 24 * time.Hour, // 30 days
+			IncrementalSync: true,
+		},
+		Metadata: map[string]interface{}{
+			"base_url": baseURL,
+			"projects": os.Getenv("GITLAB_PROJECTS"), // IDs or full paths, comma-separated
+		},
+	}
+
+	return config
+}
+
+// loadJiraFromEnv loads Jira configuration from environment variables
+func (cm *ConfigManager) loadJiraFromEnv() *ConnectorConfig {
+	baseURL := os.Getenv("JIRA_BASE_URL")
+	token := os.Getenv("JIRA_API_TOKEN")
+
+	if baseURL == "" || token == "" {
+		return nil
+	}
+
+	enabled := strings.ToLower(os.Getenv("JIRA_CONNECTOR_ENABLED")) != "false"
+
+	config := &ConnectorConfig{
+		Platform: "jira",
+		Enabled:  enabled,
+		AuthConfig: AuthConfig{
+			Scopes: []string{"read:jira-work", "read:jira-user"},
+			Metadata: map[string]string{
+				"email":     os.Getenv("JIRA_EMAIL"), // Jira Cloud only; Data Center tokens are used alone
+				"api_token": token,
+			},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   3600,
+			RequestsPerMinute: 60,
+			BurstLimit:        10,
+			BackoffMultiplier: 2.0,
+			MaxRetries:        3,
+		},
+		SyncConfig: SyncConfig{
+			BatchSize:       100,
+			SyncInterval:    10 * time.Minute,
+			MaxLookback:     30 * 24 * time.Hour, // 30 days
+			IncrementalSync: true,
+		},
+		Metadata: map[string]interface{}{
+			"base_url": baseURL,
+			"projects": os.Getenv("JIRA_PROJECTS"), // project keys, comma-separated
+		},
+	}
+
+	return config
+}
+
+// loadLinearFromEnv loads Linear configuration from environment variables
+func (cm *ConfigManager) loadLinearFromEnv() *ConnectorConfig {
+	apiKey := os.Getenv("LINEAR_API_KEY")
+
+	if apiKey == "" {
+		return nil
+	}
+
+	enabled := strings.ToLower(os.Getenv("LINEAR_CONNECTOR_ENABLED")) != "false"
+
+	config := &ConnectorConfig{
+		Platform: "linear",
+		Enabled:  enabled,
+		AuthConfig: AuthConfig{
+			Scopes: []string{"read"},
+			Metadata: map[string]string{
+				"api_key":        apiKey,
+				"webhook_secret": os.Getenv("LINEAR_WEBHOOK_SECRET"), // signing secret of the workspace webhook
+			},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   1500,
+			RequestsPerMinute: 25,
+			BurstLimit:        10,
+			BackoffMultiplier: 2.0,
+			MaxRetries:        3,
+		},
+		SyncConfig: SyncConfig{
+			BatchSize:       100,
+			MaxLookback:     30 * 24 * time.Hour, // 30 days
+			IncrementalSync: true,
+		},
+		Metadata: map[string]interface{}{
+			"teams": os.Getenv("LINEAR_TEAMS"), // team keys, comma-separated; all teams when empty
+		},
+	}
+
+	return config
+}
+
+// loadGitLocalFromEnv loads local git configuration from environment variables
+func (cm *ConfigManager) loadGitLocalFromEnv() *ConnectorConfig {
+	repoPath := os.Getenv("GITLOCAL_REPO_PATH")
+
+	if repoPath == "" {
+		return nil
+	}
+
+	enabled := strings.ToLower(os.Getenv("GITLOCAL_CONNECTOR_ENABLED")) != "false"
+
+	// Set but empty turns decision records off
+	adrDir, ok := os.LookupEnv("GITLOCAL_ADR_DIR")
+	if !ok {
+		adrDir = "docs/adr"
+	}
+
+	config := &ConnectorConfig{
+		Platform: "gitlocal",
+		Enabled:  enabled,
+		AuthConfig: AuthConfig{
+			Scopes:   []string{},
+			Metadata: map[string]string{},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   600,
+			RequestsPerMinute: 10,
+			BurstLimit:        5,
+			BackoffMultiplier: 2.0,
+			MaxRetries:        3,
+		},
+		SyncConfig: SyncConfig{
+			BatchSize:       500,
+			SyncInterval:    5 * time.Minute,
+			MaxLookback:     365 * 24 * time.Hour, // 1 year; older history is read by a backfill
+			IncrementalSync: true,
+		},
+		Metadata: map[string]interface{}{
+			"repo_path":  repoPath,
+			"remote_url": os.Getenv("GITLOCAL_REMOTE_URL"), // kept as a mirror at repo_path when set
+			"ref":        os.Getenv("GITLOCAL_REF"),        // HEAD when empty
+			"repository": os.Getenv("GITLOCAL_REPOSITORY"), // display name; the directory name when empty
+			"adr_dir":    adrDir,                           // architecture decision records, read as decisions
+		},
+	}
+
+	return config
+}
+
 // GetConfig returns the configuration for a specific platform
 func (cm *ConfigManager) GetConfig(platform string) (ConnectorConfig, bool) {
 	config, exists := cm.configs[platform]
@@ -303,6 +503,14 @@ func (cm *ConfigManager) ValidateConfig(config ConnectorConfig) error {
 		return cm.validateSlackConfig(config)
 	case "discord":
 		return cm.validateDiscordConfig(config)
+	case "gitlab":
+		return cm.validateGitLabConfig(config)
+	case "jira":
+		return cm.validateJiraConfig(config)
+	case "linear":
+		return cm.validateLinearConfig(config)
+	case "gitlocal":
+		return cm.validateGitLocalConfig(config)
 	}
 
 	return nil
@@ -359,6 +567,67 @@ func (cm *ConfigManager) validateDiscordConfig(config ConnectorConfig) error {
 	return nil
 }
 
+// validateGitLabConfig validates GitLab-specific configuration
+func (cm *ConfigManager) validateGitLabConfig(config ConnectorConfig) error {
+	token, ok := config.AuthConfig.Metadata["access_token"]
+	if !ok || token == "" {
+		return fmt.Errorf("GitLab access token is required")
+	}
+
+	if baseURL, ok := config.Metadata["base_url"].(string); ok && baseURL != "" {
+		parsed, err := url.Parse(baseURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("GitLab base URL must be an http or https URL")
+		}
+	}
+
+	if len(metadataStrings(config.Metadata["projects"])) == 0 {
+		return fmt.Errorf("at least one GitLab project is required")
+	}
+
+	return nil
+}
+
+// validateJiraConfig validates Jira-specific configuration
+func (cm *ConfigManager) validateJiraConfig(config ConnectorConfig) error {
+	token, ok := config.AuthConfig.Metadata["api_token"]
+	if !ok || token == "" {
+		return fmt.Errorf("Jira API token is required")
+	}
+
+	baseURL, _ := config.Metadata["base_url"].(string)
+	parsed, err := url.Parse(baseURL)
+	if baseURL == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("Jira base URL must be an http or https URL")
+	}
+
+	if len(metadataStrings(config.Metadata["projects"])) == 0 {
+		return fmt.Errorf("at least one Jira project is required")
+	}
+
+	return nil
+}
+
+// validateLinearConfig validates Linear-specific configuration
+func (cm *ConfigManager) validateLinearConfig(config ConnectorConfig) error {
+	apiKey, ok := config.AuthConfig.Metadata["api_key"]
+	if !ok || apiKey == "" {
+		return fmt.Errorf("Linear API key is required")
+	}
+
+	return nil
+}
+
+// validateGitLocalConfig validates local git configuration
+func (cm *ConfigManager) validateGitLocalConfig(config ConnectorConfig) error {
+	repoPath, _ := config.Metadata["repo_path"].(string)
+	if repoPath == "" {
+		return fmt.Errorf("local git repository path is required")
+	}
+
+	return nil
+}
+
 // SecurityBoundary enforces security boundaries between connectors
 type SecurityBoundary struct {
 	allowedPlatforms map[string]bool
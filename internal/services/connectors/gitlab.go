@@ -0,0 +1,438 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// GitLabConnector implements PlatformConnector for GitLab integration, on
+// gitlab.com or a self-hosted instance
+type GitLabConnector struct {
+	*BaseConnector
+	gitlabService services.GitLabService
+	normalizer    *EventNormalizer
+}
+
+// NewGitLabConnector creates a new GitLab connector for the instance named by
+// the base_url metadata, gitlab.com by default
+func NewGitLabConnector(config ConnectorConfig) (PlatformConnector, error) {
+	baseURL, _ := config.Metadata["base_url"].(string)
+
+	return &GitLabConnector{
+		BaseConnector: NewBaseConnector(config),
+		gitlabService: services.NewGitLabService(baseURL),
+		normalizer:    NewEventNormalizer("gitlab"),
+	}, nil
+}
+
+// Authenticate validates a personal, project or group access token
+func (gc *GitLabConnector) Authenticate(ctx context.Context, config AuthConfig) (*AuthResult, error) {
+	token, ok := config.Metadata["access_token"]
+	if !ok || token == "" {
+		return nil, &ConnectorError{
+			Platform:  "gitlab",
+			Code:      "missing_token",
+			Message:   "GitLab access token not provided in config metadata",
+			Retryable: false,
+		}
+	}
+
+	user, err := gc.gitlabService.GetCurrentUser(ctx, token)
+	if err != nil {
+		return nil, gc.handleGitLabError(err)
+	}
+
+	result := &AuthResult{
+		AccessToken: token,
+		UserID:      fmt.Sprintf("%d", user.ID),
+		UserLogin:   user.Username,
+		ExpiresAt:   time.Now().Add(24 * time.Hour), // re-check daily; tokens are revoked or expire by date
+		Scopes:      config.Scopes,
+	}
+
+	// Older instances cannot describe the token, so its scopes stay as configured
+	if info, err := gc.gitlabService.GetTokenInfo(ctx, token); err == nil {
+		result.Scopes = info.Scopes
+		if info.ExpiresAt != nil {
+			if expiresAt, err := time.Parse("2006-01-02", *info.ExpiresAt); err == nil {
+				result.ExpiresAt = expiresAt
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// FetchEvents retrieves GitLab merge requests with their discussion notes,
+// issues and commits changed since the last sync, from every configured project
+func (gc *GitLabConnector) FetchEvents(ctx context.Context, since time.Time, limit int) ([]PlatformEvent, error) {
+	config := gc.GetConfig()
+	token, ok := config.AuthConfig.Metadata["access_token"]
+	if !ok || token == "" {
+		return nil, &ConnectorError{
+			Platform:  "gitlab",
+			Code:      "missing_token",
+			Message:   "GitLab access token not configured",
+			Retryable: false,
+		}
+	}
+
+	projects := metadataStrings(config.Metadata["projects"])
+	if len(projects) == 0 {
+		return nil, &ConnectorError{
+			Platform:  "gitlab",
+			Code:      "missing_projects",
+			Message:   "GitLab projects not configured",
+			Retryable: false,
+		}
+	}
+
+	// Split the limit between projects and, like GitHub, between merge
+	// requests, issues and commits
+	perKind := limit / (3 * len(projects))
+	if perKind < 1 {
+		perKind = 1
+	}
+
+	var events []PlatformEvent
+	for _, project := range projects {
+		projectEvents, err := gc.fetchProjectEvents(ctx, token, project, since, perKind)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, projectEvents...)
+	}
+
+	return events, nil
+}
+
+// fetchProjectEvents retrieves the events of one project
+func (gc *GitLabConnector) fetchProjectEvents(ctx context.Context, token, project string, since time.Time, perKind int) ([]PlatformEvent, error) {
+	var events []PlatformEvent
+
+	if err := gc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	mergeRequests, err := gc.gitlabService.ListMergeRequests(ctx, token, project, since, perKind)
+	if err != nil {
+		return nil, gc.handleGitLabError(err)
+	}
+
+	for _, mr := range mergeRequests {
+		if err := gc.WaitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		// Instances older than GitLab 15.7 have no diffs endpoint; the merge
+		// request is still worth ingesting without its files
+		diffs, err := gc.gitlabService.GetMergeRequestDiffs(ctx, token, project, mr.IID)
+		if err != nil && !services.IsGitLabStatus(err, http.StatusNotFound) {
+			return nil, gc.handleGitLabError(err)
+		}
+		event := gc.convertMergeRequestToEvent(project, mr, diffs)
+		events = append(events, event)
+
+		if err := gc.WaitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		discussions, err := gc.gitlabService.ListMergeRequestDiscussions(ctx, token, project, mr.IID)
+		if err != nil {
+			return nil, gc.handleGitLabError(err)
+		}
+		events = append(events, gc.convertDiscussionsToEvents(project, event.ID, mr, discussions, since)...)
+	}
+
+	if err := gc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	issues, err := gc.gitlabService.ListIssues(ctx, token, project, since, perKind)
+	if err != nil {
+		return nil, gc.handleGitLabError(err)
+	}
+	for _, issue := range issues {
+		events = append(events, gc.convertIssueToEvent(project, issue))
+	}
+
+	if err := gc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	commits, err := gc.gitlabService.ListCommits(ctx, token, project, since, perKind)
+	if err != nil {
+		return nil, gc.handleGitLabError(err)
+	}
+	for _, commit := range commits {
+		if err := gc.WaitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		diffs, err := gc.gitlabService.GetCommitDiff(ctx, token, project, commit.ID)
+		if err != nil {
+			return nil, gc.handleGitLabError(err)
+		}
+		events = append(events, gc.convertCommitToEvent(project, commit, diffs))
+	}
+
+	return events, nil
+}
+
+// NormalizeData converts GitLab platform events to normalized format
+func (gc *GitLabConnector) NormalizeData(ctx context.Context, events []PlatformEvent) ([]NormalizedEvent, error) {
+	normalized := make([]NormalizedEvent, len(events))
+	for i, event := range events {
+		normalized[i] = gc.normalizer.NormalizeEvent(event)
+	}
+	return normalized, nil
+}
+
+// ScheduleSync determines the next sync interval for GitLab
+func (gc *GitLabConnector) ScheduleSync(ctx context.Context, lastSync time.Time) (time.Duration, error) {
+	config := gc.GetConfig()
+	if config.SyncConfig.SyncInterval > 0 {
+		return config.SyncConfig.SyncInterval, nil
+	}
+	return 5 * time.Minute, nil
+}
+
+// GetPlatformInfo returns GitLab connector metadata
+func (gc *GitLabConnector) GetPlatformInfo() PlatformInfo {
+	return PlatformInfo{
+		Name:        "gitlab",
+		DisplayName: "GitLab",
+		Version:     "1.0.0",
+		Description: "GitLab project connector for merge requests, discussions, issues and commits",
+		SupportedEvents: []EventType{
+			EventTypePullRequest,
+			EventTypeDiscussion,
+			EventTypeIssue,
+			EventTypeCommit,
+		},
+		RateLimits: RateLimitInfo{
+			RequestsPerHour:   7200, // well under gitlab.com's authenticated limit; self-hosted limits vary
+			RequestsPerMinute: 120,
+			BurstLimit:        10,
+			BackoffStrategy:   "exponential",
+			RetryAfterHeader:  "Retry-After",
+		},
+		AuthType:       "token",
+		RequiredScopes: []string{"read_api"},
+	}
+}
+
+// convertMergeRequestToEvent converts a GitLab merge request to a platform event
+func (gc *GitLabConnector) convertMergeRequestToEvent(project string, mr services.GitLabMergeRequest, diffs []services.GitLabDiff) PlatformEvent {
+	files, renames := diffFiles(diffs)
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("mr-%d", mr.ID),
+		Type:      EventTypePullRequest,
+		Timestamp: mr.CreatedAt,
+		Author:    mr.Author.Username,
+		Content:   mr.Description,
+		Title:     mr.Title,
+		Platform:  "gitlab",
+		Metadata: map[string]interface{}{
+			"project":       project,
+			"iid":           mr.IID,
+			"state":         mr.State,
+			"merged_at":     mr.MergedAt,
+			"updated_at":    mr.UpdatedAt,
+			"labels":        nonNilStrings(mr.Labels),
+			"source_branch": mr.SourceBranch,
+			"target_branch": mr.TargetBranch,
+			"web_url":       mr.WebURL,
+			"files_changed": files,
+			"renamed_files": renames,
+		},
+	}
+}
+
+// convertDiscussionsToEvents converts the notes of a merge request's
+// discussions to platform events. Each discussion is a thread whose parent is
+// the merge request; system notes such as "added 1 commit" are skipped.
+func (gc *GitLabConnector) convertDiscussionsToEvents(project, mergeRequestEventID string, mr services.GitLabMergeRequest, discussions []services.GitLabDiscussion, since time.Time) []PlatformEvent {
+	var events []PlatformEvent
+	for _, discussion := range discussions {
+		for _, note := range discussion.Notes {
+			if note.System || !note.UpdatedAt.After(since) {
+				continue
+			}
+			events = append(events, PlatformEvent{
+				ID:        fmt.Sprintf("note-%d", note.ID),
+				Type:      EventTypeDiscussion,
+				Timestamp: note.CreatedAt,
+				Author:    note.Author.Username,
+				Content:   note.Body,
+				Title:     mr.Title,
+				Platform:  "gitlab",
+				Metadata: map[string]interface{}{
+					"project":           project,
+					"merge_request_iid": mr.IID,
+					"thread_id":         discussion.ID,
+					"parent_id":         mergeRequestEventID,
+					"resolved":          note.Resolved,
+					"web_url":           fmt.Sprintf("%s#note_%d", mr.WebURL, note.ID),
+				},
+			})
+		}
+	}
+	return events
+}
+
+// convertIssueToEvent converts a GitLab issue to a platform event
+func (gc *GitLabConnector) convertIssueToEvent(project string, issue services.GitLabIssue) PlatformEvent {
+	return PlatformEvent{
+		ID:        fmt.Sprintf("issue-%d", issue.ID),
+		Type:      EventTypeIssue,
+		Timestamp: issue.CreatedAt,
+		Author:    issue.Author.Username,
+		Content:   issue.Description,
+		Title:     issue.Title,
+		Platform:  "gitlab",
+		Metadata: map[string]interface{}{
+			"project":    project,
+			"iid":        issue.IID,
+			"state":      issue.State,
+			"closed_at":  issue.ClosedAt,
+			"updated_at": issue.UpdatedAt,
+			"labels":     nonNilStrings(issue.Labels),
+			"web_url":    issue.WebURL,
+		},
+	}
+}
+
+// convertCommitToEvent converts a GitLab commit to a platform event
+func (gc *GitLabConnector) convertCommitToEvent(project string, commit services.GitLabCommit, diffs []services.GitLabDiff) PlatformEvent {
+	files, renames := diffFiles(diffs)
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("commit-%s", commit.ID),
+		Type:      EventTypeCommit,
+		Timestamp: commit.AuthoredDate,
+		Author:    commit.AuthorName,
+		Content:   commit.Message,
+		Title:     commit.Title,
+		Platform:  "gitlab",
+		Metadata: map[string]interface{}{
+			"project":       project,
+			"sha":           commit.ID,
+			"parent_shas":   nonNilStrings(commit.ParentIDs),
+			"web_url":       commit.WebURL,
+			"files_changed": files,
+			"renamed_files": renames,
+		},
+	}
+}
+
+// diffFiles lists the current paths of changed files, and maps the new path
+// of each renamed file to its old path
+func diffFiles(diffs []services.GitLabDiff) ([]string, map[string]string) {
+	files := make([]string, 0, len(diffs))
+	renames := make(map[string]string)
+	for _, diff := range diffs {
+		files = append(files, diff.NewPath)
+		if diff.RenamedFile {
+			renames[diff.NewPath] = diff.OldPath
+		}
+	}
+	return files, renames
+}
+
+// nonNilStrings keeps empty lists from being stored as null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// metadataStrings reads a list of strings from connector metadata, which
+// holds []string when set in code, []interface{} when loaded from JSON and a
+// comma-separated string when loaded from the environment
+func metadataStrings(value interface{}) []string {
+	var raw []string
+	switch v := value.(type) {
+	case []string:
+		raw = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	case string:
+		raw = strings.Split(v, ",")
+	}
+
+	var values []string
+	for _, s := range raw {
+		if s = strings.TrimSpace(s); s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// handleGitLabError converts GitLab service errors to connector errors
+func (gc *GitLabConnector) handleGitLabError(err error) error {
+	var apiErr *services.GitLabAPIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			retryAfter := apiErr.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = time.Minute
+			}
+			return &ConnectorError{
+				Platform:   "gitlab",
+				Code:       "rate_limit",
+				Message:    "GitLab API rate limit exceeded",
+				Retryable:  true,
+				RetryAfter: &retryAfter,
+			}
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return &ConnectorError{
+				Platform:  "gitlab",
+				Code:      "auth_error",
+				Message:   fmt.Sprintf("GitLab authentication failed: %v", err),
+				Retryable: false,
+			}
+		case apiErr.StatusCode == http.StatusNotFound:
+			return &ConnectorError{
+				Platform:  "gitlab",
+				Code:      "not_found",
+				Message:   fmt.Sprintf("GitLab project not found or not accessible with this token: %v", err),
+				Retryable: false,
+			}
+		case apiErr.StatusCode < 500:
+			return &ConnectorError{
+				Platform:  "gitlab",
+				Code:      "api_error",
+				Message:   fmt.Sprintf("GitLab API error: %v", err),
+				Retryable: false,
+			}
+		}
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &ConnectorError{
+			Platform:  "gitlab",
+			Code:      "network_error",
+			Message:   fmt.Sprintf("GitLab API network error: %v", err),
+			Retryable: true,
+		}
+	}
+
+	// Server errors and anything unexpected are worth retrying
+	return &ConnectorError{
+		Platform:  "gitlab",
+		Code:      "api_error",
+		Message:   fmt.Sprintf("GitLab API error: %v", err),
+		Retryable: true,
+	}
+}
@@ -0,0 +1,293 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestGitLabServer serves a small GitLab API for the group/app project,
+// with the merge request list split over two keyset pages
+func newTestGitLabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch path := r.URL.EscapedPath(); path {
+		case "/api/v4/user":
+			fmt.Fprint(w, `{"id":7,"username":"alice","name":"Alice"}`)
+		case "/api/v4/personal_access_tokens/self":
+			fmt.Fprint(w, `{"id":1,"name":"context-keeper","scopes":["read_api"],"expires_at":"2030-01-31"}`)
+		case "/api/v4/projects/group%2Fapp/merge_requests":
+			if r.URL.Query().Get("cursor") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s%s?cursor=next&per_page=%s>; rel="next"`, server.URL, path, r.URL.Query().Get("per_page")))
+				fmt.Fprint(w, `[{"id":101,"iid":1,"title":"Retry webhooks","description":"Retries Stripe webhooks","state":"merged","author":{"username":"alice"},"labels":["payments"],"created_at":"2024-05-01T10:00:00Z","updated_at":"2024-05-02T10:00:00Z","web_url":"https://gitlab.example.com/group/app/-/merge_requests/1"}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id":102,"iid":2,"title":"Draft refunds","state":"opened","author":{"username":"bob"},"created_at":"2024-05-03T10:00:00Z","updated_at":"2024-05-03T10:00:00Z"}]`)
+		case "/api/v4/projects/group%2Fapp/merge_requests/1/diffs":
+			fmt.Fprint(w, `[{"old_path":"webhooks.go","new_path":"internal/webhooks/retry.go","renamed_file":true},{"old_path":"go.mod","new_path":"go.mod"}]`)
+		case "/api/v4/projects/group%2Fapp/merge_requests/2/diffs":
+			// Instances older than GitLab 15.7 have no diffs endpoint
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Not found"}`)
+		case "/api/v4/projects/group%2Fapp/merge_requests/1/discussions":
+			fmt.Fprint(w, `[{"id":"d1","notes":[{"id":501,"body":"Why five retries?","author":{"username":"bob"},"system":false,"created_at":"2024-05-01T11:00:00Z","updated_at":"2024-05-01T11:00:00Z"},{"id":502,"body":"added 1 commit","author":{"username":"alice"},"system":true,"created_at":"2024-05-01T12:00:00Z","updated_at":"2024-05-01T12:00:00Z"}]}]`)
+		case "/api/v4/projects/group%2Fapp/merge_requests/2/discussions":
+			fmt.Fprint(w, `[]`)
+		case "/api/v4/projects/group%2Fapp/issues":
+			fmt.Fprint(w, `[{"id":301,"iid":5,"title":"Webhooks time out","description":"Stripe retries pile up","state":"opened","author":{"username":"carol"},"labels":[],"created_at":"2024-04-30T10:00:00Z","updated_at":"2024-05-01T10:00:00Z"}]`)
+		case "/api/v4/projects/group%2Fapp/repository/commits":
+			fmt.Fprint(w, `[{"id":"abc123","title":"Move webhook retries","message":"Move webhook retries\n\nRefs: #5","author_name":"Alice","authored_date":"2024-05-01T09:00:00Z","parent_ids":["fff000"]}]`)
+		case "/api/v4/projects/group%2Fapp/repository/commits/abc123/diff":
+			fmt.Fprint(w, `[{"old_path":"webhooks.go","new_path":"internal/webhooks/retry.go","renamed_file":true}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Project Not Found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestGitLabConnector(t *testing.T, baseURL, token string, projects interface{}) PlatformConnector {
+	t.Helper()
+
+	connector, err := NewGitLabConnector(ConnectorConfig{
+		Platform: "gitlab",
+		Enabled:  true,
+		AuthConfig: AuthConfig{
+			Metadata: map[string]string{"access_token": token},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   100000,
+			RequestsPerMinute: 6000,
+			BurstLimit:        100,
+		},
+		Metadata: map[string]interface{}{
+			"base_url": baseURL,
+			"projects": projects,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create GitLab connector: %v", err)
+	}
+	return connector
+}
+
+// TestGitLabConnectorIntegration tests the GitLab connector against a fake GitLab API
+func TestGitLabConnectorIntegration(t *testing.T) {
+	server := newTestGitLabServer(t)
+	connector := newTestGitLabConnector(t, server.URL, "glpat-test", "group/app")
+	ctx := context.Background()
+
+	info := connector.GetPlatformInfo()
+	if info.Name != "gitlab" || info.AuthType != "token" {
+		t.Errorf("Unexpected platform info: %+v", info)
+	}
+
+	auth, err := connector.Authenticate(ctx, AuthConfig{Metadata: map[string]string{"access_token": "glpat-test"}})
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if auth.UserLogin != "alice" || auth.UserID != "7" {
+		t.Errorf("Expected user alice (7), got %s (%s)", auth.UserLogin, auth.UserID)
+	}
+	if len(auth.Scopes) != 1 || auth.Scopes[0] != "read_api" {
+		t.Errorf("Expected token scopes [read_api], got %v", auth.Scopes)
+	}
+	if auth.ExpiresAt.Format("2006-01-02") != "2030-01-31" {
+		t.Errorf("Expected token expiry 2030-01-31, got %v", auth.ExpiresAt)
+	}
+
+	events, err := connector.FetchEvents(ctx, time.Time{}, 1000)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+
+	byID := make(map[string]PlatformEvent)
+	for _, event := range events {
+		byID[event.ID] = event
+	}
+	wantIDs := []string{"mr-101", "mr-102", "note-501", "issue-301", "commit-abc123"}
+	if len(events) != len(wantIDs) {
+		t.Errorf("Expected %d events, got %d", len(wantIDs), len(events))
+	}
+	for _, id := range wantIDs {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("Expected event %s", id)
+		}
+	}
+	if _, ok := byID["note-502"]; ok {
+		t.Error("System notes should be skipped")
+	}
+
+	mr := byID["mr-101"]
+	if mr.Type != EventTypePullRequest {
+		t.Errorf("Expected merge request to be a pull request event, got %s", mr.Type)
+	}
+	files, _ := mr.Metadata["files_changed"].([]string)
+	if strings.Join(files, ",") != "internal/webhooks/retry.go,go.mod" {
+		t.Errorf("Unexpected files changed: %v", files)
+	}
+	renames, _ := mr.Metadata["renamed_files"].(map[string]string)
+	if renames["internal/webhooks/retry.go"] != "webhooks.go" {
+		t.Errorf("Expected rename to be recorded, got %v", renames)
+	}
+	if files, _ := byID["mr-102"].Metadata["files_changed"].([]string); len(files) != 0 {
+		t.Errorf("Expected no files for a merge request without diffs, got %v", files)
+	}
+
+	note := byID["note-501"]
+	if note.Type != EventTypeDiscussion || note.Metadata["thread_id"] != "d1" || note.Metadata["parent_id"] != "mr-101" {
+		t.Errorf("Unexpected discussion note: %+v", note)
+	}
+
+	normalized, err := connector.NormalizeData(ctx, []PlatformEvent{note, byID["commit-abc123"]})
+	if err != nil {
+		t.Fatalf("NormalizeData failed: %v", err)
+	}
+	if normalized[0].ThreadID == nil || *normalized[0].ThreadID != "d1" {
+		t.Errorf("Expected note to be threaded under d1, got %v", normalized[0].ThreadID)
+	}
+	if normalized[0].ParentID == nil || *normalized[0].ParentID != "mr-101" {
+		t.Errorf("Expected note parent mr-101, got %v", normalized[0].ParentID)
+	}
+	if len(normalized[1].FileRefs) != 1 || normalized[1].FileRefs[0] != "internal/webhooks/retry.go" {
+		t.Errorf("Expected commit files to be normalized, got %v", normalized[1].FileRefs)
+	}
+}
+
+// TestGitLabConnectorErrors tests that GitLab API failures become connector errors
+func TestGitLabConnectorErrors(t *testing.T) {
+	server := newTestGitLabServer(t)
+	ctx := context.Background()
+
+	_, err := newTestGitLabConnector(t, server.URL, "wrong", []string{"group/app"}).FetchEvents(ctx, time.Time{}, 100)
+	var connErr *ConnectorError
+	if !errors.As(err, &connErr) || connErr.Code != "auth_error" || connErr.Retryable {
+		t.Errorf("Expected non-retryable auth_error, got %v", err)
+	}
+
+	_, err = newTestGitLabConnector(t, server.URL, "glpat-test", []interface{}{"group/missing"}).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "not_found" {
+		t.Errorf("Expected not_found, got %v", err)
+	}
+
+	_, err = newTestGitLabConnector(t, server.URL, "glpat-test", nil).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "missing_projects" {
+		t.Errorf("Expected missing_projects, got %v", err)
+	}
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+
+	_, err = newTestGitLabConnector(t, limited.URL, "glpat-test", "group/app").FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "rate_limit" || !connErr.Retryable {
+		t.Fatalf("Expected retryable rate_limit, got %v", err)
+	}
+	if connErr.RetryAfter == nil || *connErr.RetryAfter != 30*time.Second {
+		t.Errorf("Expected retry after 30s, got %v", connErr.RetryAfter)
+	}
+}
+
+// TestGitLabConnectorPagination tests that commit windows are read oldest
+// first across pages, that a first sync reads only the newest commits and
+// that next-page links to other hosts are not followed
+func TestGitLabConnectorPagination(t *testing.T) {
+	var foreignRequests, commitPageRequests int
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignRequests++
+		fmt.Fprint(w, `[]`)
+	}))
+	defer foreign.Close()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch path := r.URL.EscapedPath(); {
+		case path == "/api/v4/projects/group%2Fapp/merge_requests":
+			fmt.Fprint(w, `[]`)
+		case path == "/api/v4/projects/group%2Fapp/issues":
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, foreign.URL, path))
+			fmt.Fprint(w, `[{"id":301,"iid":5,"title":"Webhooks time out","state":"opened","author":{"username":"carol"},"created_at":"2024-04-30T10:00:00Z","updated_at":"2024-05-01T10:00:00Z"}]`)
+		case path == "/api/v4/projects/group%2Fapp/repository/commits":
+			// Commits come newest first
+			commitPageRequests++
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next"`, server.URL, path))
+				fmt.Fprint(w, `[{"id":"c3","title":"Third","authored_date":"2024-05-03T09:00:00Z"},{"id":"c2","title":"Second","authored_date":"2024-05-02T09:00:00Z"}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id":"c1","title":"First","authored_date":"2024-05-01T09:00:00Z"}]`)
+		case strings.HasSuffix(path, "/diff"):
+			fmt.Fprint(w, `[]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Not found"}`)
+		}
+	}))
+	defer server.Close()
+
+	connector := newTestGitLabConnector(t, server.URL, "glpat-test", "group/app")
+	ctx := context.Background()
+
+	events, err := connector.FetchEvents(ctx, time.Time{}, 30)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if strings.Join(ids, ",") != "issue-301,commit-c1,commit-c2,commit-c3" {
+		t.Errorf("Expected the issue and all commits oldest first, got %v", ids)
+	}
+	if foreignRequests != 0 {
+		t.Errorf("Expected no requests to another host, got %d", foreignRequests)
+	}
+
+	since := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	events, err = connector.FetchEvents(ctx, since, 3)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+	ids = ids[:0]
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if strings.Join(ids, ",") != "issue-301,commit-c1" {
+		t.Errorf("Expected a capped sync to keep the oldest commit, got %v", ids)
+	}
+
+	commitPageRequests = 0
+	events, err = connector.FetchEvents(ctx, time.Time{}, 3)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+	ids = ids[:0]
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	if strings.Join(ids, ",") != "issue-301,commit-c3" {
+		t.Errorf("Expected a capped first sync to take the newest commit, got %v", ids)
+	}
+	if commitPageRequests != 1 {
+		t.Errorf("Expected a capped first sync to read one page of commits, got %d", commitPageRequests)
+	}
+}
@@ -0,0 +1,532 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// GitLocalConnector implements PlatformConnector and BackfillConnector for git
+// repositories on the local disk. It reads history with git itself, so it
+// needs no hosting platform API and works in air-gapped deployments. The
+// architecture decision records kept in the repository are read too, each as
+// one event carrying its current text and the commits that edited it.
+type GitLocalConnector struct {
+	*BaseConnector
+	gitService services.GitLocalService
+	normalizer *EventNormalizer
+}
+
+// NewGitLocalConnector creates a new local git connector
+func NewGitLocalConnector(config ConnectorConfig) (PlatformConnector, error) {
+	gitPath, _ := config.Metadata["git_path"].(string)
+
+	return &GitLocalConnector{
+		BaseConnector: NewBaseConnector(config),
+		gitService:    services.NewGitLocalService(gitPath),
+		normalizer:    NewEventNormalizer("gitlocal"),
+	}, nil
+}
+
+// Authenticate checks that the repository can be read. A mirror that has not
+// been cloned yet is cloned first.
+func (gc *GitLocalConnector) Authenticate(ctx context.Context, config AuthConfig) (*AuthResult, error) {
+	repoPath, err := gitLocalRepoPath(gc.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	if remoteURL, _ := gc.GetConfig().Metadata["remote_url"].(string); remoteURL != "" {
+		if _, err := os.Stat(repoPath); errors.Is(err, os.ErrNotExist) {
+			if err := gc.refreshMirror(ctx, remoteURL, repoPath); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ref, _ := gc.GetConfig().Metadata["ref"].(string)
+	head, err := gc.gitService.ResolveRef(ctx, repoPath, ref)
+	if err != nil {
+		return nil, gc.handleGitLocalError(err)
+	}
+
+	return &AuthResult{
+		UserID:    head,
+		UserLogin: gitLocalRepoName(gc.GetConfig(), repoPath),
+		ExpiresAt: time.Now().Add(24 * time.Hour), // local repositories have no credentials; re-check daily
+		Scopes:    config.Scopes,
+	}, nil
+}
+
+// FetchEvents reads the commits made since the last sync, newest first. When
+// the repository mirrors a remote, the mirror is refreshed first.
+func (gc *GitLocalConnector) FetchEvents(ctx context.Context, since time.Time, limit int) ([]PlatformEvent, error) {
+	config := gc.GetConfig()
+	repoPath, err := gitLocalRepoPath(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if remoteURL, _ := config.Metadata["remote_url"].(string); remoteURL != "" {
+		if err := gc.refreshMirror(ctx, remoteURL, repoPath); err != nil {
+			return nil, err
+		}
+	}
+
+	ref, _ := config.Metadata["ref"].(string)
+	repository := gitLocalRepoName(config, repoPath)
+
+	var events []PlatformEvent
+	err = gc.gitService.WalkLog(ctx, repoPath, services.GitLogOptions{Ref: ref, Since: since, MaxCount: limit}, func(commit services.GitLocalCommit) error {
+		events = append(events, gc.convertCommitToEvent(commit, repository))
+		return nil
+	})
+	if err != nil {
+		return nil, gc.handleGitLocalError(err)
+	}
+
+	// Records edited by the new commits are read again as they are now
+	touched := make(map[string]bool)
+	adrDir := gitLocalADRDir(config)
+	for _, event := range events {
+		files, _ := event.Metadata["files_changed"].([]string)
+		for _, file := range files {
+			if isADRPath(adrDir, file) {
+				touched[file] = true
+			}
+		}
+	}
+	if len(touched) > 0 {
+		adrEvents, err := gc.fetchADREvents(ctx, repoPath, ref, repository, touched)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, adrEvents...)
+	}
+
+	return events, nil
+}
+
+// Backfill reads the whole history of the repository as it is on disk,
+// oldest first. The mirror is not refreshed, so a backfill works offline.
+func (gc *GitLocalConnector) Backfill(ctx context.Context, batchSize int, handle func([]PlatformEvent) error) error {
+	config := gc.GetConfig()
+	repoPath, err := gitLocalRepoPath(config)
+	if err != nil {
+		return err
+	}
+	if batchSize <= 0 {
+		batchSize = config.SyncConfig.BatchSize
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	ref, _ := config.Metadata["ref"].(string)
+	repository := gitLocalRepoName(config, repoPath)
+
+	// Errors of handle stop the walk and are returned as they are
+	var handleErr error
+	batch := make([]PlatformEvent, 0, batchSize)
+	err = gc.gitService.WalkLog(ctx, repoPath, services.GitLogOptions{Ref: ref, Reverse: true}, func(commit services.GitLocalCommit) error {
+		batch = append(batch, gc.convertCommitToEvent(commit, repository))
+		if len(batch) < batchSize {
+			return nil
+		}
+		if handleErr = handle(batch); handleErr != nil {
+			return handleErr
+		}
+		batch = make([]PlatformEvent, 0, batchSize)
+		return nil
+	})
+	if handleErr != nil {
+		return handleErr
+	}
+	if err != nil {
+		return gc.handleGitLocalError(err)
+	}
+
+	// Records follow the commits that edited them
+	if gitLocalADRDir(config) != "" {
+		adrEvents, err := gc.fetchADREvents(ctx, repoPath, ref, repository, nil)
+		if err != nil {
+			return err
+		}
+		for _, event := range adrEvents {
+			batch = append(batch, event)
+			if len(batch) < batchSize {
+				continue
+			}
+			if err := handle(batch); err != nil {
+				return err
+			}
+			batch = make([]PlatformEvent, 0, batchSize)
+		}
+	}
+
+	if len(batch) > 0 {
+		return handle(batch)
+	}
+	return nil
+}
+
+// NormalizeData converts local git events to normalized format
+func (gc *GitLocalConnector) NormalizeData(ctx context.Context, events []PlatformEvent) ([]NormalizedEvent, error) {
+	normalized := make([]NormalizedEvent, len(events))
+	for i, event := range events {
+		normalized[i] = gc.normalizer.NormalizeEvent(event)
+	}
+	return normalized, nil
+}
+
+// ScheduleSync determines the next sync interval for a local repository
+func (gc *GitLocalConnector) ScheduleSync(ctx context.Context, lastSync time.Time) (time.Duration, error) {
+	// Reading history is cheap, but refreshing a mirror fetches from its remote
+	config := gc.GetConfig()
+	if config.SyncConfig.SyncInterval > 0 {
+		return config.SyncConfig.SyncInterval, nil
+	}
+	return 5 * time.Minute, nil
+}
+
+// GetPlatformInfo returns local git connector metadata
+func (gc *GitLocalConnector) GetPlatformInfo() PlatformInfo {
+	return PlatformInfo{
+		Name:        "gitlocal",
+		DisplayName: "Local Git",
+		Version:     "1.0.0",
+		Description: "Local git repository connector for commits, renames, trailers, tags and architecture decision records",
+		SupportedEvents: []EventType{
+			EventTypeCommit,
+			EventTypeDecisionRecord,
+		},
+		RateLimits: RateLimitInfo{
+			RequestsPerHour:   600, // mirror refreshes only; reading history is not limited
+			RequestsPerMinute: 10,
+			BurstLimit:        5,
+			BackoffStrategy:   "exponential",
+		},
+		AuthType:       "none",
+		RequiredScopes: []string{},
+	}
+}
+
+// refreshMirror clones or updates the mirror of remoteURL at repoPath
+func (gc *GitLocalConnector) refreshMirror(ctx context.Context, remoteURL, repoPath string) error {
+	if err := gc.WaitForRateLimit(ctx); err != nil {
+		return err
+	}
+	if err := gc.gitService.Mirror(ctx, remoteURL, repoPath); err != nil {
+		var cmdErr *services.GitCommandError
+		if errors.As(err, &cmdErr) {
+			if strings.Contains(cmdErr.Stderr, "Authentication failed") || strings.Contains(cmdErr.Stderr, "Permission denied") {
+				return &ConnectorError{
+					Platform:  "gitlocal",
+					Code:      "auth_error",
+					Message:   fmt.Sprintf("git remote authentication failed: %v", err),
+					Retryable: false,
+				}
+			}
+			return &ConnectorError{
+				Platform:  "gitlocal",
+				Code:      "network_error",
+				Message:   fmt.Sprintf("git mirror refresh failed: %v", err),
+				Retryable: true,
+			}
+		}
+		return gc.handleGitLocalError(err)
+	}
+	return nil
+}
+
+// convertCommitToEvent converts a local commit to a platform event. Renamed
+// files are listed under their new path, with the rename kept alongside.
+func (gc *GitLocalConnector) convertCommitToEvent(commit services.GitLocalCommit, repository string) PlatformEvent {
+	files := make([]string, len(commit.Files))
+	renames := []map[string]string{}
+	additions, deletions := 0, 0
+	for i, file := range commit.Files {
+		files[i] = file.Path
+		if file.OldPath != "" {
+			renames = append(renames, map[string]string{"from": file.OldPath, "to": file.Path})
+		}
+		additions += file.Additions
+		deletions += file.Deletions
+	}
+
+	trailers := make(map[string][]string)
+	for _, trailer := range commit.Trailers {
+		key := strings.ToLower(trailer.Key)
+		trailers[key] = append(trailers[key], trailer.Value)
+	}
+
+	// Refs trailers list tickets separated by commas or spaces
+	var refs []string
+	for _, value := range commit.TrailerValues("Refs") {
+		refs = append(refs, strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})...)
+	}
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("commit-%s", commit.SHA),
+		Type:      EventTypeCommit,
+		Timestamp: commit.AuthoredAt,
+		Author:    commit.AuthorName,
+		Content:   commit.Message,
+		Platform:  "gitlocal",
+		Metadata: map[string]interface{}{
+			"sha":           commit.SHA,
+			"parents":       nonNilStrings(commit.Parents),
+			"repository":    repository,
+			"author_email":  commit.AuthorEmail,
+			"committer":     commit.CommitterName,
+			"committed_at":  commit.CommittedAt.Format(time.RFC3339),
+			"files_changed": files,
+			"renames":       renames,
+			"additions":     additions,
+			"deletions":     deletions,
+			"co_authors":    nonNilStrings(commit.TrailerValues("Co-authored-by")),
+			"refs":          nonNilStrings(refs),
+			"trailers":      trailers,
+			"tags":          nonNilStrings(commit.Tags),
+		},
+		References: files,
+	}
+}
+
+// fetchADREvents reads the architecture decision records at ref, or only those
+// in paths when it is not nil. Markdown files that are not records, such as
+// an index, are skipped.
+func (gc *GitLocalConnector) fetchADREvents(ctx context.Context, repoPath, ref, repository string, paths map[string]bool) ([]PlatformEvent, error) {
+	adrDir := gitLocalADRDir(gc.GetConfig())
+	if adrDir == "" {
+		return nil, nil
+	}
+
+	adrFiles, err := gc.gitService.ListFiles(ctx, repoPath, ref, adrDir)
+	if err != nil {
+		return nil, gc.handleGitLocalError(err)
+	}
+	source := gitLocalRepoSource(gc.GetConfig(), repoPath)
+	var tree map[string]bool
+
+	var events []PlatformEvent
+	for _, adrPath := range adrFiles {
+		// Deleted records are no longer in the tree and are left as they were
+		if !isADRPath(adrDir, adrPath) || (paths != nil && !paths[adrPath]) {
+			continue
+		}
+		content, err := gc.gitService.ReadFile(ctx, repoPath, ref, adrPath)
+		if err != nil {
+			return nil, gc.handleGitLocalError(err)
+		}
+		adr, err := services.ParseADR(adrPath, string(content))
+		if err != nil {
+			continue
+		}
+
+		var revisions []services.GitLocalCommit
+		err = gc.gitService.WalkLog(ctx, repoPath, services.GitLogOptions{Ref: ref, Reverse: true, Paths: []string{adrPath}}, func(commit services.GitLocalCommit) error {
+			revisions = append(revisions, commit)
+			return nil
+		})
+		if err != nil {
+			return nil, gc.handleGitLocalError(err)
+		}
+
+		// Mentions are resolved against the whole tree, listed once
+		if tree == nil {
+			files, err := gc.gitService.ListFiles(ctx, repoPath, ref, "")
+			if err != nil {
+				return nil, gc.handleGitLocalError(err)
+			}
+			tree = make(map[string]bool, len(files))
+			for _, file := range files {
+				tree[file] = true
+			}
+		}
+
+		event := gc.convertADRToEvent(adrPath, string(content), adr, revisions, gitLocalADRMentions(adrDir, adrPath, adr.Mentions, tree), repository)
+		event.Metadata["repository_source"] = source
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// convertADRToEvent converts an architecture decision record to a platform
+// event, dated and authored by its last edit
+func (gc *GitLocalConnector) convertADRToEvent(adrPath, content string, adr *services.ADR, revisions []services.GitLocalCommit, mentions []string, repository string) PlatformEvent {
+	event := PlatformEvent{
+		ID:        fmt.Sprintf("adr-%s", adrPath),
+		Type:      EventTypeDecisionRecord,
+		Timestamp: time.Now(),
+		Title:     adr.Title,
+		Content:   content,
+		Platform:  "gitlocal",
+		Metadata: map[string]interface{}{
+			"adr_path":      adrPath,
+			"adr_id":        adr.ID,
+			"state":         adr.Status,
+			"repository":    repository,
+			"features":      nonNilStrings(adr.Tags),
+			"supersedes":    nonNilStrings(adr.Supersedes),
+			"superseded_by": nonNilStrings(adr.SupersededBy),
+		},
+		References: nonNilStrings(mentions),
+	}
+
+	revisionIDs := make([]string, len(revisions))
+	var contributors []string
+	for i, commit := range revisions {
+		revisionIDs[i] = fmt.Sprintf("commit-%s", commit.SHA)
+		if !containsString(contributors, commit.AuthorName) {
+			contributors = append(contributors, commit.AuthorName)
+		}
+	}
+	event.Metadata["revisions"] = revisionIDs
+	event.Metadata["contributors"] = nonNilStrings(contributors)
+	if len(revisions) > 0 {
+		first, last := revisions[0], revisions[len(revisions)-1]
+		event.Timestamp = last.AuthoredAt
+		event.Author = last.AuthorName
+		event.Metadata["first_committed_at"] = first.CommittedAt.Format(time.RFC3339)
+		event.Metadata["last_commit"] = last.SHA
+	}
+	return event
+}
+
+// gitLocalADRMentions resolves the paths a record mentions to files in the
+// tree, trying them relative to the record and to the repository root. Other
+// records are linked through supersedes instead.
+func gitLocalADRMentions(adrDir, adrPath string, mentions []string, tree map[string]bool) []string {
+	var files []string
+	for _, mention := range mentions {
+		candidates := []string{path.Join(path.Dir(adrPath), mention), path.Clean(strings.TrimPrefix(mention, "/"))}
+		for _, candidate := range candidates {
+			if !tree[candidate] || isADRPath(adrDir, candidate) {
+				continue
+			}
+			if !containsString(files, candidate) {
+				files = append(files, candidate)
+			}
+			break
+		}
+	}
+	return files
+}
+
+// gitLocalADRDir returns the directory holding architecture decision records,
+// docs/adr unless configured. An empty directory turns records off.
+func gitLocalADRDir(config ConnectorConfig) string {
+	adrDir, ok := config.Metadata["adr_dir"].(string)
+	if !ok {
+		adrDir = "docs/adr"
+	}
+	return strings.Trim(path.Clean("/"+adrDir), "/")
+}
+
+// isADRPath reports whether a file is a markdown file under adrDir, other
+// than the index and template such directories often hold
+func isADRPath(adrDir, file string) bool {
+	if adrDir == "" || !strings.HasPrefix(file, adrDir+"/") || !strings.EqualFold(path.Ext(file), ".md") {
+		return false
+	}
+	name := strings.ToLower(strings.TrimSuffix(path.Base(file), path.Ext(file)))
+	return name != "readme" && name != "index" && !strings.Contains(name, "template")
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// gitLocalRepoPath returns the configured repository path
+func gitLocalRepoPath(config ConnectorConfig) (string, error) {
+	repoPath, _ := config.Metadata["repo_path"].(string)
+	if repoPath == "" {
+		return "", &ConnectorError{
+			Platform:  "gitlocal",
+			Code:      "missing_repo_path",
+			Message:   "local git repository path not configured",
+			Retryable: false,
+		}
+	}
+	return repoPath, nil
+}
+
+// gitLocalRepoName names the repository by its configured name, or by its
+// directory without the .git suffix of bare clones
+func gitLocalRepoName(config ConnectorConfig, repoPath string) string {
+	if name, _ := config.Metadata["repository"].(string); name != "" {
+		return name
+	}
+	return strings.TrimSuffix(filepath.Base(filepath.Clean(repoPath)), ".git")
+}
+
+// gitLocalRepoSource identifies the repository beyond its name: by the remote
+// it mirrors, or by its absolute path on disk
+func gitLocalRepoSource(config ConnectorConfig, repoPath string) string {
+	if remoteURL, _ := config.Metadata["remote_url"].(string); remoteURL != "" {
+		return remoteURL
+	}
+	if absPath, err := filepath.Abs(repoPath); err == nil {
+		return absPath
+	}
+	return repoPath
+}
+
+// handleGitLocalError converts git failures to connector errors
+func (gc *GitLocalConnector) handleGitLocalError(err error) error {
+	// A missing binary fails at lookup on the PATH, or at exec for a set path
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+		return &ConnectorError{
+			Platform:  "gitlocal",
+			Code:      "git_not_found",
+			Message:   "git is not installed or not on the PATH",
+			Retryable: false,
+		}
+	}
+
+	var cmdErr *services.GitCommandError
+	if errors.As(err, &cmdErr) {
+		switch {
+		case strings.Contains(cmdErr.Stderr, "not a git repository") || strings.Contains(cmdErr.Stderr, "cannot change to"):
+			return &ConnectorError{
+				Platform:  "gitlocal",
+				Code:      "repository_not_found",
+				Message:   fmt.Sprintf("local git repository not found: %v", err),
+				Retryable: false,
+			}
+		case strings.Contains(cmdErr.Stderr, "unknown revision") || strings.Contains(cmdErr.Stderr, "bad revision") ||
+			strings.Contains(cmdErr.Stderr, "Needed a single revision") || strings.Contains(cmdErr.Stderr, "does not have any commits"):
+			return &ConnectorError{
+				Platform:  "gitlocal",
+				Code:      "invalid_ref",
+				Message:   fmt.Sprintf("git revision not found: %v", err),
+				Retryable: false,
+			}
+		}
+	}
+
+	// A repository being written to by a concurrent fetch may fail to read
+	return &ConnectorError{
+		Platform:  "gitlocal",
+		Code:      "git_error",
+		Message:   fmt.Sprintf("git error: %v", err),
+		Retryable: true,
+	}
+}
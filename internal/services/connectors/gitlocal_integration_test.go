@@ -0,0 +1,347 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testGitRepo is a scratch repository whose commits have fixed dates
+type testGitRepo struct {
+	t    *testing.T
+	dir  string
+	date time.Time
+}
+
+func newTestGitRepo(t *testing.T) *testGitRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := &testGitRepo{t: t, dir: t.TempDir(), date: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)}
+	repo.git("init", "--quiet", "--initial-branch=main")
+	return repo
+}
+
+func (r *testGitRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	date := r.date.Format(time.RFC3339)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Alice", "GIT_AUTHOR_EMAIL=alice@example.com", "GIT_AUTHOR_DATE="+date,
+		"GIT_COMMITTER_NAME=Alice", "GIT_COMMITTER_EMAIL=alice@example.com", "GIT_COMMITTER_DATE="+date,
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *testGitRepo) write(path, content string) {
+	r.t.Helper()
+	full := filepath.Join(r.dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// commit commits all changes an hour after the previous commit
+func (r *testGitRepo) commit(message string) string {
+	r.t.Helper()
+	r.date = r.date.Add(time.Hour)
+	r.git("add", "--all")
+	r.git("commit", "--quiet", "--allow-empty", "-m", message)
+	return r.git("rev-parse", "HEAD")
+}
+
+func newTestGitLocalConnector(t *testing.T, metadata map[string]interface{}) PlatformConnector {
+	t.Helper()
+
+	connector, err := NewGitLocalConnector(ConnectorConfig{
+		Platform: "gitlocal",
+		Enabled:  true,
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   100000,
+			RequestsPerMinute: 6000,
+			BurstLimit:        100,
+		},
+		Metadata: metadata,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create local git connector: %v", err)
+	}
+	return connector
+}
+
+// TestGitLocalConnectorIntegration tests reading commits from a local repository
+func TestGitLocalConnectorIntegration(t *testing.T) {
+	repo := newTestGitRepo(t)
+	repo.write("internal/webhooks/retry.go", "package webhooks\n\nfunc retry() {}\n\nfunc backoff() {}\n")
+	repo.write("logo.png", "\x89PNG\x00\x01\x02")
+	first := repo.commit("Add webhook retries")
+
+	repo.git("mv", "internal/webhooks/retry.go", "internal/webhooks/retries.go")
+	repo.write("README.md", "Webhooks are retried.\n")
+	second := repo.commit("Move webhook retries\n\nKeeps retries next to delivery.\n\nRefs: PAY-1, PAY-2\nCo-authored-by: Bob <bob@example.com>")
+	repo.git("tag", "-a", "v1.0.0", "-m", "Release 1.0.0")
+
+	connector := newTestGitLocalConnector(t, map[string]interface{}{"repo_path": repo.dir, "repository": "payments"})
+	ctx := context.Background()
+
+	auth, err := connector.Authenticate(ctx, AuthConfig{})
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if auth.UserID != second || auth.UserLogin != "payments" {
+		t.Errorf("Expected payments at %s, got %s at %s", second, auth.UserLogin, auth.UserID)
+	}
+
+	events, err := connector.FetchEvents(ctx, time.Time{}, 100)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != "commit-"+second || events[1].ID != "commit-"+first {
+		t.Fatalf("Expected both commits newest first, got %+v", events)
+	}
+
+	moved := events[0]
+	if moved.Type != EventTypeCommit || moved.Author != "Alice" || moved.Platform != "gitlocal" {
+		t.Errorf("Unexpected commit event: %+v", moved)
+	}
+	if files, _ := moved.Metadata["files_changed"].([]string); strings.Join(files, ",") != "README.md,internal/webhooks/retries.go" {
+		t.Errorf("Unexpected files changed: %v", moved.Metadata["files_changed"])
+	}
+	renames, _ := moved.Metadata["renames"].([]map[string]string)
+	if len(renames) != 1 || renames[0]["from"] != "internal/webhooks/retry.go" || renames[0]["to"] != "internal/webhooks/retries.go" {
+		t.Errorf("Expected the rename to be detected, got %v", moved.Metadata["renames"])
+	}
+	if refs, _ := moved.Metadata["refs"].([]string); strings.Join(refs, ",") != "PAY-1,PAY-2" {
+		t.Errorf("Unexpected refs: %v", moved.Metadata["refs"])
+	}
+	if coAuthors, _ := moved.Metadata["co_authors"].([]string); len(coAuthors) != 1 || coAuthors[0] != "Bob <bob@example.com>" {
+		t.Errorf("Unexpected co-authors: %v", moved.Metadata["co_authors"])
+	}
+	if tags, _ := moved.Metadata["tags"].([]string); len(tags) != 1 || tags[0] != "v1.0.0" {
+		t.Errorf("Unexpected tags: %v", moved.Metadata["tags"])
+	}
+	if moved.Metadata["additions"] != 1 || moved.Metadata["deletions"] != 0 {
+		t.Errorf("Expected one added line, got +%v -%v", moved.Metadata["additions"], moved.Metadata["deletions"])
+	}
+
+	added := events[1]
+	if added.Metadata["additions"] != 5 {
+		t.Errorf("Expected five added lines, binary files uncounted, got %v", added.Metadata["additions"])
+	}
+	if files, _ := added.Metadata["files_changed"].([]string); len(files) != 2 {
+		t.Errorf("Expected the binary file to be listed, got %v", files)
+	}
+
+	// Only commits made after the last sync are read
+	events, err = connector.FetchEvents(ctx, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), 100)
+	if err != nil || len(events) != 1 || events[0].ID != "commit-"+second {
+		t.Errorf("Expected only the second commit, got %+v (%v)", events, err)
+	}
+
+	normalized, err := connector.NormalizeData(ctx, []PlatformEvent{moved})
+	if err != nil {
+		t.Fatalf("NormalizeData failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(normalized[0].FileRefs, ","), "internal/webhooks/retries.go") {
+		t.Errorf("Expected file references, got %v", normalized[0].FileRefs)
+	}
+}
+
+// TestGitLocalConnectorMirrorAndBackfill tests refreshing a mirror and
+// replaying its history
+func TestGitLocalConnectorMirrorAndBackfill(t *testing.T) {
+	origin := newTestGitRepo(t)
+	var shas []string
+	for _, message := range []string{"First", "Second", "Third"} {
+		origin.write(strings.ToLower(message)+".txt", message+"\n")
+		shas = append(shas, origin.commit(message))
+	}
+
+	mirror := filepath.Join(t.TempDir(), "origin.git")
+	connector := newTestGitLocalConnector(t, map[string]interface{}{"repo_path": mirror, "remote_url": origin.dir})
+	ctx := context.Background()
+
+	// The first sync clones the mirror
+	events, err := connector.FetchEvents(ctx, time.Time{}, 100)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+	if len(events) != 3 || events[0].Metadata["repository"] != "origin" {
+		t.Fatalf("Expected three commits of origin, got %+v", events)
+	}
+
+	origin.write("fourth.txt", "Fourth\n")
+	shas = append(shas, origin.commit("Fourth"))
+
+	events, err = connector.FetchEvents(ctx, events[0].Timestamp, 100)
+	if err != nil || len(events) != 1 || events[0].ID != "commit-"+shas[3] {
+		t.Fatalf("Expected the refreshed mirror to have the new commit, got %+v (%v)", events, err)
+	}
+
+	// Backfills read the mirror as it is, even with the remote gone
+	if err := os.RemoveAll(origin.dir); err != nil {
+		t.Fatal(err)
+	}
+	var batches [][]string
+	err = connector.(BackfillConnector).Backfill(ctx, 3, func(batch []PlatformEvent) error {
+		var ids []string
+		for _, event := range batch {
+			ids = append(ids, strings.TrimPrefix(event.ID, "commit-"))
+		}
+		batches = append(batches, ids)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 3 || len(batches[1]) != 1 {
+		t.Fatalf("Expected batches of three and one commits, got %v", batches)
+	}
+	if batches[0][0] != shas[0] || batches[1][0] != shas[3] {
+		t.Errorf("Expected the oldest commit first, got %v", batches)
+	}
+
+	stop := errors.New("stop")
+	err = connector.(BackfillConnector).Backfill(ctx, 1, func(batch []PlatformEvent) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+
+	var connErr *ConnectorError
+	_, err = connector.FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "network_error" || !connErr.Retryable {
+		t.Errorf("Expected a retryable network_error once the remote is gone, got %v", err)
+	}
+}
+
+// TestGitLocalConnectorADRs tests reading architecture decision records and
+// the commits that edited them
+func TestGitLocalConnectorADRs(t *testing.T) {
+	repo := newTestGitRepo(t)
+	repo.write("internal/webhooks/retry.go", "package webhooks\n")
+	repo.write("docs/adr/README.md", "# Decisions\n\nOne file per decision.\n")
+	repo.write("docs/adr/0001-retry-webhooks.md", "# 1. Retry webhooks\n\n## Status\n\nAccepted\n\n"+
+		"## Context\n\nReceivers restart.\n\n## Decision\n\nRetry from `internal/webhooks/retry.go` and `missing.go`.\n")
+	first := repo.commit("Record the retry decision")
+
+	repo.write("docs/adr/0001-retry-webhooks.md", "# 1. Retry webhooks\n\n## Status\n\nSuperseded by [2. Queue webhooks](0002-queue-webhooks.md)\n\n"+
+		"## Context\n\nReceivers restart.\n\n## Decision\n\nRetry from `internal/webhooks/retry.go` and `missing.go`.\n")
+	repo.write("docs/adr/0002-queue-webhooks.md", "---\nstatus: accepted\ntags: webhooks\n---\n# Queue webhooks\n\n"+
+		"## Decision Outcome\n\nQueue deliveries, see [the queue](../../internal/webhooks/retry.go).\n\n"+
+		"Supersedes [ADR-0001](0001-retry-webhooks.md).\n")
+	second := repo.commit("Queue webhooks")
+
+	connector := newTestGitLocalConnector(t, map[string]interface{}{"repo_path": repo.dir, "repository": "payments"})
+	ctx := context.Background()
+
+	events, err := connector.FetchEvents(ctx, time.Time{}, 100)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+	records := make(map[string]PlatformEvent)
+	for _, event := range events {
+		if event.Type == EventTypeDecisionRecord {
+			records[event.ID] = event
+		}
+	}
+	if len(events) != 4 || len(records) != 2 {
+		t.Fatalf("Expected two commits and two records without the index, got %+v", events)
+	}
+
+	retry := records["adr-docs/adr/0001-retry-webhooks.md"]
+	if revisions, _ := retry.Metadata["revisions"].([]string); strings.Join(revisions, ",") != "commit-"+first+",commit-"+second {
+		t.Errorf("Expected both edits oldest first, got %v", retry.Metadata["revisions"])
+	}
+	if retry.Metadata["state"] != "superseded" || !strings.Contains(retry.Content, "Superseded by") {
+		t.Errorf("Expected the record as it is now, got %+v", retry)
+	}
+	if retry.Metadata["first_committed_at"] != "2024-05-01T10:00:00Z" || !retry.Timestamp.Equal(time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the record dated by its edits, got %v and %v", retry.Metadata["first_committed_at"], retry.Timestamp)
+	}
+	if strings.Join(retry.References, ",") != "internal/webhooks/retry.go" {
+		t.Errorf("Expected only mentioned files that exist, got %v", retry.References)
+	}
+	if retry.Metadata["repository_source"] != repo.dir {
+		t.Errorf("Expected the record to name the repository's path, got %v", retry.Metadata["repository_source"])
+	}
+
+	queue := records["adr-docs/adr/0002-queue-webhooks.md"]
+	if supersedes, _ := queue.Metadata["supersedes"].([]string); len(supersedes) != 1 || supersedes[0] != "ADR-0001" {
+		t.Errorf("Expected the record to supersede ADR-0001, got %v", queue.Metadata["supersedes"])
+	}
+	if strings.Join(queue.References, ",") != "internal/webhooks/retry.go" {
+		t.Errorf("Expected links resolved from the record's directory, got %v", queue.References)
+	}
+	normalized, err := connector.NormalizeData(ctx, []PlatformEvent{queue})
+	if err != nil || len(normalized[0].FeatureRefs) != 1 || normalized[0].FeatureRefs[0] != "webhooks" {
+		t.Errorf("Expected tags as feature references, got %+v (%v)", normalized, err)
+	}
+
+	// Commits that leave the records alone do not read them again
+	repo.write("internal/webhooks/queue.go", "package webhooks\n")
+	repo.commit("Add the queue")
+	events, err = connector.FetchEvents(ctx, time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC), 100)
+	if err != nil || len(events) != 1 || events[0].Type != EventTypeCommit {
+		t.Errorf("Expected only the new commit, got %+v (%v)", events, err)
+	}
+
+	var backfilled []string
+	err = connector.(BackfillConnector).Backfill(ctx, 10, func(batch []PlatformEvent) error {
+		for _, event := range batch {
+			backfilled = append(backfilled, event.ID)
+		}
+		return nil
+	})
+	if err != nil || len(backfilled) != 5 || !strings.HasPrefix(backfilled[3], "adr-") || !strings.HasPrefix(backfilled[4], "adr-") {
+		t.Errorf("Expected the records after the commits, got %v (%v)", backfilled, err)
+	}
+
+	// An empty directory turns records off
+	events, err = newTestGitLocalConnector(t, map[string]interface{}{"repo_path": repo.dir, "adr_dir": ""}).FetchEvents(ctx, time.Time{}, 100)
+	if err != nil || len(events) != 3 {
+		t.Errorf("Expected commits only, got %d events (%v)", len(events), err)
+	}
+}
+
+// TestGitLocalConnectorErrors tests that git failures become connector errors
+func TestGitLocalConnectorErrors(t *testing.T) {
+	repo := newTestGitRepo(t)
+	repo.commit("Initial commit")
+	ctx := context.Background()
+
+	var connErr *ConnectorError
+	_, err := newTestGitLocalConnector(t, map[string]interface{}{}).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "missing_repo_path" {
+		t.Errorf("Expected missing_repo_path, got %v", err)
+	}
+
+	_, err = newTestGitLocalConnector(t, map[string]interface{}{"repo_path": filepath.Join(repo.dir, "missing")}).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "repository_not_found" || connErr.Retryable {
+		t.Errorf("Expected non-retryable repository_not_found, got %v", err)
+	}
+
+	_, err = newTestGitLocalConnector(t, map[string]interface{}{"repo_path": repo.dir, "ref": "release"}).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "invalid_ref" {
+		t.Errorf("Expected invalid_ref, got %v", err)
+	}
+
+	_, err = newTestGitLocalConnector(t, map[string]interface{}{"repo_path": repo.dir, "git_path": "/nonexistent/git"}).Authenticate(ctx, AuthConfig{})
+	if !errors.As(err, &connErr) || connErr.Code != "git_not_found" {
+		t.Errorf("Expected git_not_found, got %v", err)
+	}
+}
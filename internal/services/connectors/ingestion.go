@@ -0,0 +1,215 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// The ingestion orchestrator lives in the services package, which cannot
+// import connectors, so it sees connectors through its own narrower
+// interfaces. The adapters below convert between the two sets of event types.
+
+// LoadConnectorManager loads connector configurations from configPath, or from
+// the environment when that file does not exist, and creates every enabled
+// connector. A connector that cannot be created is logged and left out so that
+// one bad configuration does not stop the others from syncing.
+func LoadConnectorManager(configPath string, logger services.Logger) (*ConnectorManager, error) {
+	configManager := NewConfigManager(configPath)
+	if err := configManager.LoadConfigurations(); err != nil {
+		return nil, fmt.Errorf("failed to load connector configurations: %w", err)
+	}
+
+	registry := NewRegistry()
+	if err := registry.RegisterDefaultConnectors(); err != nil {
+		return nil, err
+	}
+	for platform, config := range configManager.GetAllConfigs() {
+		registry.SetConfig(platform, config)
+	}
+
+	manager := NewConnectorManager(registry)
+	for _, platform := range registry.ListEnabledPlatforms() {
+		connector, err := registry.CreateConnector(platform)
+		if err != nil {
+			logger.Error("Failed to create connector", err, map[string]interface{}{
+				"platform": platform,
+			})
+			continue
+		}
+		manager.mu.Lock()
+		manager.connectors[platform] = connector
+		manager.mu.Unlock()
+	}
+
+	return manager, nil
+}
+
+// NewIngestionOrchestrator builds an ingestion orchestrator that syncs through
+// the connectors of manager
+func NewIngestionOrchestrator(manager *ConnectorManager, store services.RepositoryStore, processor services.ContextProcessorService, encryptSvc services.EncryptionService, logger services.Logger) services.IngestionOrchestrator {
+	return services.NewIngestionOrchestrator(store, &ingestionConnectorManager{manager: manager}, processor, encryptSvc, logger)
+}
+
+// Backfill replays the whole history of the connector of platform, oldest
+// first, and passes it to handle normalized, in batches of up to batchSize
+// events. Only connectors that implement BackfillConnector can be replayed.
+func Backfill(ctx context.Context, manager *ConnectorManager, platform string, batchSize int, handle func([]services.NormalizedEvent) error) error {
+	connector, err := manager.GetConnector(platform)
+	if err != nil {
+		return err
+	}
+	backfillConnector, ok := connector.(BackfillConnector)
+	if !ok {
+		return fmt.Errorf("connector for platform %s cannot backfill", platform)
+	}
+
+	return backfillConnector.Backfill(ctx, batchSize, func(events []PlatformEvent) error {
+		normalized, err := connector.NormalizeData(ctx, events)
+		if err != nil {
+			return fmt.Errorf("failed to normalize events: %w", err)
+		}
+
+		converted := make([]services.NormalizedEvent, len(normalized))
+		for i, event := range normalized {
+			converted[i] = services.NormalizedEvent{
+				PlatformID:  event.PlatformID,
+				EventType:   services.EventType(event.EventType),
+				Timestamp:   event.Timestamp,
+				Author:      event.Author,
+				Content:     event.Content,
+				Title:       event.Title,
+				ThreadID:    event.ThreadID,
+				ParentID:    event.ParentID,
+				FileRefs:    event.FileRefs,
+				FeatureRefs: event.FeatureRefs,
+				Labels:      event.Labels,
+				State:       event.State,
+				Metadata:    event.Metadata,
+				Platform:    event.Platform,
+			}
+		}
+		return handle(converted)
+	})
+}
+
+// WebhookParser verifies webhook deliveries with the connectors of a manager
+// and converts them to the orchestrator's events
+type WebhookParser struct {
+	manager *ConnectorManager
+}
+
+// NewWebhookParser creates a webhook parser for the connectors of manager
+func NewWebhookParser(manager *ConnectorManager) *WebhookParser {
+	return &WebhookParser{manager: manager}
+}
+
+// ParseWebhook verifies a delivery with the signing secret configured for the
+// platform's connector and converts it to events
+func (p *WebhookParser) ParseWebhook(ctx context.Context, platform string, headers http.Header, body []byte) ([]services.PlatformEvent, error) {
+	connector, err := p.manager.GetConnector(platform)
+	if err != nil {
+		return nil, err
+	}
+	webhookConnector, ok := connector.(WebhookConnector)
+	if !ok {
+		return nil, fmt.Errorf("connector for platform %s does not accept webhooks", platform)
+	}
+
+	events, err := webhookConnector.ParseWebhook(ctx, headers, body)
+	if err != nil {
+		return nil, err
+	}
+	return toServicePlatformEvents(events), nil
+}
+
+// ingestionConnectorManager hands the orchestrator its connectors
+type ingestionConnectorManager struct {
+	manager *ConnectorManager
+}
+
+func (m *ingestionConnectorManager) GetConnector(platform string) (services.PlatformConnector, error) {
+	connector, err := m.manager.GetConnector(platform)
+	if err != nil {
+		return nil, err
+	}
+	return &ingestionConnector{connector: connector}, nil
+}
+
+// ingestionConnector converts a connector's events to the orchestrator's types
+type ingestionConnector struct {
+	connector PlatformConnector
+}
+
+func (c *ingestionConnector) FetchEvents(ctx context.Context, since time.Time, limit int) ([]services.PlatformEvent, error) {
+	events, err := c.connector.FetchEvents(ctx, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	return toServicePlatformEvents(events), nil
+}
+
+func (c *ingestionConnector) NormalizeData(ctx context.Context, events []services.PlatformEvent) ([]services.PlatformNormalizedEvent, error) {
+	converted := make([]PlatformEvent, len(events))
+	for i, event := range events {
+		converted[i] = PlatformEvent{
+			ID:         event.ID,
+			Type:       EventType(event.Type),
+			Timestamp:  event.Timestamp,
+			Author:     event.Author,
+			Content:    event.Content,
+			Title:      event.Title,
+			Metadata:   event.Metadata,
+			References: event.References,
+			Platform:   event.Platform,
+		}
+	}
+
+	normalized, err := c.connector.NormalizeData(ctx, converted)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]services.PlatformNormalizedEvent, len(normalized))
+	for i, event := range normalized {
+		result[i] = services.PlatformNormalizedEvent{
+			PlatformID:  event.PlatformID,
+			EventType:   string(event.EventType),
+			Timestamp:   event.Timestamp,
+			Author:      event.Author,
+			Content:     event.Content,
+			Title:       event.Title,
+			ThreadID:    event.ThreadID,
+			ParentID:    event.ParentID,
+			FileRefs:    event.FileRefs,
+			FeatureRefs: event.FeatureRefs,
+			Labels:      event.Labels,
+			State:       event.State,
+			Metadata:    event.Metadata,
+			Platform:    event.Platform,
+		}
+	}
+	return result, nil
+}
+
+// toServicePlatformEvents converts connector events to the orchestrator's type
+func toServicePlatformEvents(events []PlatformEvent) []services.PlatformEvent {
+	converted := make([]services.PlatformEvent, len(events))
+	for i, event := range events {
+		converted[i] = services.PlatformEvent{
+			ID:         event.ID,
+			Type:       string(event.Type),
+			Timestamp:  event.Timestamp,
+			Author:     event.Author,
+			Content:    event.Content,
+			Title:      event.Title,
+			Metadata:   event.Metadata,
+			References: event.References,
+			Platform:   event.Platform,
+		}
+	}
+	return converted
+}
@@ -0,0 +1,68 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// TestWebhookParser tests that deliveries are verified by the platform's connector
+func TestWebhookParser(t *testing.T) {
+	manager := NewConnectorManager(NewRegistry())
+	manager.connectors["linear"] = newTestLinearConnector(t, "http://127.0.0.1:0", "lin_api_test", "ENG")
+	parser := NewWebhookParser(manager)
+	ctx := context.Background()
+
+	body := []byte(fmt.Sprintf(`{"action":"create","type":"Issue","webhookTimestamp":%d,"data":{"id":"iss-1","identifier":"ENG-42","title":"Back off webhook retries","createdAt":"2024-05-01T10:00:00Z","updatedAt":"2024-05-01T10:00:00Z","team":{"key":"ENG"}}}`, time.Now().UnixMilli()))
+	events, err := parser.ParseWebhook(ctx, "linear", signLinearWebhook(body, "whsec-test"), body)
+	if err != nil {
+		t.Fatalf("ParseWebhook failed: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "issue-iss-1" || events[0].Platform != "linear" {
+		t.Errorf("Unexpected webhook events: %+v", events)
+	}
+
+	if _, err := parser.ParseWebhook(ctx, "linear", signLinearWebhook(body, "wrong-secret"), body); err == nil {
+		t.Error("Expected a forged delivery to be rejected")
+	}
+	if _, err := parser.ParseWebhook(ctx, "jira", signLinearWebhook(body, "whsec-test"), body); err == nil {
+		t.Error("Expected a delivery for a platform without a connector to be rejected")
+	}
+}
+
+// TestBackfill tests that a connector's history is replayed normalized
+func TestBackfill(t *testing.T) {
+	repo := newTestGitRepo(t)
+	var shas []string
+	for _, message := range []string{"First", "Second", "Third"} {
+		repo.write("notes.txt", message+"\n")
+		shas = append(shas, repo.commit(message))
+	}
+
+	manager := NewConnectorManager(NewRegistry())
+	manager.connectors["gitlocal"] = newTestGitLocalConnector(t, map[string]interface{}{"repo_path": repo.dir})
+	manager.connectors["linear"] = newTestLinearConnector(t, "http://127.0.0.1:0", "lin_api_test", "ENG")
+	ctx := context.Background()
+
+	var batches [][]services.NormalizedEvent
+	err := Backfill(ctx, manager, "gitlocal", 2, func(events []services.NormalizedEvent) error {
+		batches = append(batches, events)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("Expected batches of two and one commits, got %+v", batches)
+	}
+	if first := batches[0][0]; first.PlatformID != "commit-"+shas[0] || first.EventType != services.EventTypeCommit || first.Platform != "gitlocal" {
+		t.Errorf("Expected the oldest commit first, normalized, got %+v", first)
+	}
+
+	if err := Backfill(ctx, manager, "linear", 2, func([]services.NormalizedEvent) error { return nil }); err == nil {
+		t.Error("Expected a connector that cannot backfill to be rejected")
+	}
+}
@@ -2,6 +2,7 @@ package connectors
 
 import (
 	"context"
+	"net/http"
 	"time"
 )
 
@@ -23,6 +24,20 @@ type PlatformConnector interface {
 	GetPlatformInfo() PlatformInfo
 }
 
+// WebhookConnector is implemented by connectors that also accept events pushed
+// by their platform, between scheduled syncs
+type WebhookConnector interface {
+	// ParseWebhook verifies a webhook delivery and converts it to events
+	ParseWebhook(ctx context.Context, headers http.Header, body []byte) ([]PlatformEvent, error)
+}
+
+// BackfillConnector is implemented by connectors that can replay their whole
+// history, oldest first, without the limits of incremental syncs
+type BackfillConnector interface {
+	// Backfill passes the history to handle in batches of up to batchSize events
+	Backfill(ctx context.Context, batchSize int, handle func([]PlatformEvent) error) error
+}
+
 // AuthConfig contains platform-specific authentication configuration
 type AuthConfig struct {
 	ClientID     string            `json:"client_id"`
@@ -54,6 +69,7 @@ const (
 	EventTypeReaction       EventType = "reaction"
 	EventTypeFileChange     EventType = "file_change"
 	EventTypeDiscussion     EventType = "discussion"
+	EventTypeDecisionRecord EventType = "decision_record" // a written decision, such as an ADR
 )
 
 // PlatformEvent represents a raw event from a platform
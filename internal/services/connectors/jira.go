@@ -0,0 +1,392 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// jiraQueryWindow widens the JQL update window. JQL compares dates in the
+// Jira user's time zone to the minute, so the query reaches back a day and
+// results are filtered by their exact timestamps.
+const jiraQueryWindow = 24 * time.Hour
+
+// JiraConnector implements PlatformConnector for Jira Cloud and Jira Data Center
+type JiraConnector struct {
+	*BaseConnector
+	jiraService services.JiraService
+	baseURL     string
+	normalizer  *EventNormalizer
+}
+
+// NewJiraConnector creates a new Jira connector for the site named by the
+// base_url metadata
+func NewJiraConnector(config ConnectorConfig) (PlatformConnector, error) {
+	baseURL, _ := config.Metadata["base_url"].(string)
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	return &JiraConnector{
+		BaseConnector: NewBaseConnector(config),
+		jiraService:   services.NewJiraService(baseURL),
+		baseURL:       baseURL,
+		normalizer:    NewEventNormalizer("jira"),
+	}, nil
+}
+
+// Authenticate validates a Jira Cloud API token, with the account email, or
+// a Jira Data Center personal access token
+func (jc *JiraConnector) Authenticate(ctx context.Context, config AuthConfig) (*AuthResult, error) {
+	creds, err := jiraCredentials(config)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := jc.jiraService.GetCurrentUser(ctx, creds)
+	if err != nil {
+		return nil, jc.handleJiraError(err)
+	}
+
+	userID := user.AccountID
+	if userID == "" {
+		userID = user.Name
+	}
+
+	return &AuthResult{
+		AccessToken: creds.APIToken,
+		UserID:      userID,
+		UserLogin:   user.DisplayName,
+		ExpiresAt:   time.Now().Add(24 * time.Hour), // re-check daily; tokens are revoked or expire by date
+		Scopes:      config.Scopes,
+	}, nil
+}
+
+// FetchEvents retrieves the issues of the configured projects updated since
+// the last sync, with their new comments and status transitions
+func (jc *JiraConnector) FetchEvents(ctx context.Context, since time.Time, limit int) ([]PlatformEvent, error) {
+	config := jc.GetConfig()
+	creds, err := jiraCredentials(config.AuthConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := metadataStrings(config.Metadata["projects"])
+	if len(projects) == 0 {
+		return nil, &ConnectorError{
+			Platform:  "jira",
+			Code:      "missing_projects",
+			Message:   "Jira projects not configured",
+			Retryable: false,
+		}
+	}
+
+	if err := jc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	issues, err := jc.jiraService.SearchIssues(ctx, creds, jiraUpdatedSinceJQL(projects, since), limit)
+	if err != nil {
+		return nil, jc.handleJiraError(err)
+	}
+
+	var events []PlatformEvent
+	for _, issue := range issues {
+		if !issue.Fields.Updated.After(since) {
+			continue
+		}
+
+		issueEvent := jc.convertIssueToEvent(issue)
+		events = append(events, issueEvent)
+		events = append(events, jc.convertTransitionsToEvents(issue, issueEvent.ID, since)...)
+
+		if err := jc.WaitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		comments, err := jc.jiraService.ListIssueComments(ctx, creds, issue.Key)
+		if err != nil {
+			return nil, jc.handleJiraError(err)
+		}
+		events = append(events, jc.convertCommentsToEvents(issue, issueEvent.ID, comments, since)...)
+	}
+
+	return events, nil
+}
+
+// NormalizeData converts Jira platform events to normalized format
+func (jc *JiraConnector) NormalizeData(ctx context.Context, events []PlatformEvent) ([]NormalizedEvent, error) {
+	normalized := make([]NormalizedEvent, len(events))
+	for i, event := range events {
+		normalized[i] = jc.normalizer.NormalizeEvent(event)
+	}
+	return normalized, nil
+}
+
+// ScheduleSync determines the next sync interval for Jira
+func (jc *JiraConnector) ScheduleSync(ctx context.Context, lastSync time.Time) (time.Duration, error) {
+	config := jc.GetConfig()
+	if config.SyncConfig.SyncInterval > 0 {
+		return config.SyncConfig.SyncInterval, nil
+	}
+	return 10 * time.Minute, nil
+}
+
+// GetPlatformInfo returns Jira connector metadata
+func (jc *JiraConnector) GetPlatformInfo() PlatformInfo {
+	return PlatformInfo{
+		Name:        "jira",
+		DisplayName: "Jira",
+		Version:     "1.0.0",
+		Description: "Jira project connector for issues, comments, status transitions and issue links",
+		SupportedEvents: []EventType{
+			EventTypeIssue,
+			EventTypeDiscussion,
+		},
+		RateLimits: RateLimitInfo{
+			RequestsPerHour:   3600,
+			RequestsPerMinute: 60,
+			BurstLimit:        10,
+			BackoffStrategy:   "exponential",
+			RetryAfterHeader:  "Retry-After",
+		},
+		AuthType:       "api_token",
+		RequiredScopes: []string{"read:jira-work", "read:jira-user"},
+	}
+}
+
+// convertIssueToEvent converts a Jira issue to a platform event. Comments and
+// transitions are threaded under the issue key, so an issue and its history
+// are summarized together; epics name the feature their issues belong to.
+func (jc *JiraConnector) convertIssueToEvent(issue services.JiraIssue) PlatformEvent {
+	fields := issue.Fields
+
+	metadata := map[string]interface{}{
+		"project":         fields.Project.Key,
+		"ticket_key":      issue.Key,
+		"thread_id":       issue.Key,
+		"issue_type":      fields.IssueType.Name,
+		"state":           fields.Status.Name,
+		"status_category": fields.Status.StatusCategory.Key,
+		"labels":          nonNilStrings(fields.Labels),
+		"issue_links":     jiraIssueLinks(issue),
+		"web_url":         jc.browseURL(issue.Key),
+		"updated_at":      fields.Updated.Time,
+	}
+	if fields.Assignee != nil {
+		metadata["assignee"] = fields.Assignee.DisplayName
+	}
+	if !fields.ResolutionDate.IsZero() {
+		metadata["resolved_at"] = fields.ResolutionDate.Time
+	}
+	if fields.Parent != nil {
+		metadata["parent_key"] = fields.Parent.Key
+	}
+	if epic := jiraEpic(issue); epic != "" {
+		metadata["features"] = []string{epic}
+	}
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("issue-%s", issue.ID),
+		Type:      EventTypeIssue,
+		Timestamp: fields.Created.Time,
+		Author:    jiraUserName(fields.Reporter),
+		Content:   fields.Description,
+		Title:     fmt.Sprintf("%s: %s", issue.Key, fields.Summary),
+		Platform:  "jira",
+		Metadata:  metadata,
+	}
+}
+
+// convertTransitionsToEvents converts the status changes in an issue's
+// changelog since the last sync to platform events
+func (jc *JiraConnector) convertTransitionsToEvents(issue services.JiraIssue, issueEventID string, since time.Time) []PlatformEvent {
+	var events []PlatformEvent
+	for _, history := range issue.Changelog.Histories {
+		if !history.Created.After(since) {
+			continue
+		}
+		for _, item := range history.Items {
+			if item.Field != "status" {
+				continue
+			}
+			events = append(events, PlatformEvent{
+				ID:        fmt.Sprintf("transition-%s", history.ID),
+				Type:      EventTypeIssue,
+				Timestamp: history.Created.Time,
+				Author:    history.Author.DisplayName,
+				Content:   fmt.Sprintf("%s moved from %s to %s", issue.Key, item.FromString, item.ToString),
+				Title:     fmt.Sprintf("%s: %s", issue.Key, issue.Fields.Summary),
+				Platform:  "jira",
+				Metadata: map[string]interface{}{
+					"project":     issue.Fields.Project.Key,
+					"ticket_key":  issue.Key,
+					"thread_id":   issue.Key,
+					"parent_id":   issueEventID,
+					"state":       item.ToString,
+					"from_status": item.FromString,
+					"to_status":   item.ToString,
+					"web_url":     jc.browseURL(issue.Key),
+				},
+			})
+		}
+	}
+	return events
+}
+
+// convertCommentsToEvents converts the comments on an issue added or edited
+// since the last sync to platform events
+func (jc *JiraConnector) convertCommentsToEvents(issue services.JiraIssue, issueEventID string, comments []services.JiraComment, since time.Time) []PlatformEvent {
+	var events []PlatformEvent
+	for _, comment := range comments {
+		updated := comment.Updated.Time
+		if updated.IsZero() {
+			updated = comment.Created.Time
+		}
+		if !updated.After(since) {
+			continue
+		}
+		events = append(events, PlatformEvent{
+			ID:        fmt.Sprintf("comment-%s", comment.ID),
+			Type:      EventTypeDiscussion,
+			Timestamp: comment.Created.Time,
+			Author:    comment.Author.DisplayName,
+			Content:   comment.Body,
+			Title:     fmt.Sprintf("%s: %s", issue.Key, issue.Fields.Summary),
+			Platform:  "jira",
+			Metadata: map[string]interface{}{
+				"project":    issue.Fields.Project.Key,
+				"ticket_key": issue.Key,
+				"thread_id":  issue.Key,
+				"parent_id":  issueEventID,
+				"web_url":    fmt.Sprintf("%s?focusedCommentId=%s", jc.browseURL(issue.Key), comment.ID),
+			},
+		})
+	}
+	return events
+}
+
+// browseURL is the web page of an issue
+func (jc *JiraConnector) browseURL(issueKey string) string {
+	return jc.baseURL + "/browse/" + issueKey
+}
+
+// jiraUpdatedSinceJQL selects the issues of the projects updated since the
+// last sync, least recently updated first
+func jiraUpdatedSinceJQL(projects []string, since time.Time) string {
+	quoted := make([]string, len(projects))
+	for i, project := range projects {
+		quoted[i] = services.JiraJQLString(project)
+	}
+
+	jql := fmt.Sprintf("project in (%s)", strings.Join(quoted, ", "))
+	if !since.IsZero() {
+		jql += fmt.Sprintf(` AND updated >= "%s"`, since.Add(-jiraQueryWindow).UTC().Format("2006/01/02 15:04"))
+	}
+	return jql + " ORDER BY updated ASC"
+}
+
+// jiraEpic returns the name of the epic an issue belongs to, or is
+func jiraEpic(issue services.JiraIssue) string {
+	if strings.EqualFold(issue.Fields.IssueType.Name, "Epic") {
+		return issue.Fields.Summary
+	}
+	if parent := issue.Fields.Parent; parent != nil && strings.EqualFold(parent.Fields.IssueType.Name, "Epic") {
+		return parent.Fields.Summary
+	}
+	return ""
+}
+
+// jiraIssueLinks lists an issue's links in their outward direction, so that
+// both ends of a link describe it the same way
+func jiraIssueLinks(issue services.JiraIssue) []map[string]string {
+	links := []map[string]string{}
+	for _, link := range issue.Fields.IssueLinks {
+		switch {
+		case link.OutwardIssue != nil:
+			links = append(links, map[string]string{"type": link.Type.Outward, "source": issue.Key, "target": link.OutwardIssue.Key})
+		case link.InwardIssue != nil:
+			links = append(links, map[string]string{"type": link.Type.Outward, "source": link.InwardIssue.Key, "target": issue.Key})
+		}
+	}
+	return links
+}
+
+// jiraUserName is the display name of an optional user
+func jiraUserName(user *services.JiraUser) string {
+	if user == nil {
+		return ""
+	}
+	return user.DisplayName
+}
+
+// jiraCredentials reads the API token, and the account email Jira Cloud
+// needs with it, from auth metadata
+func jiraCredentials(config AuthConfig) (services.JiraCredentials, error) {
+	token, ok := config.Metadata["api_token"]
+	if !ok || token == "" {
+		return services.JiraCredentials{}, &ConnectorError{
+			Platform:  "jira",
+			Code:      "missing_token",
+			Message:   "Jira API token not configured",
+			Retryable: false,
+		}
+	}
+	return services.JiraCredentials{Email: config.Metadata["email"], APIToken: token}, nil
+}
+
+// handleJiraError converts Jira service errors to connector errors
+func (jc *JiraConnector) handleJiraError(err error) error {
+	var apiErr *services.JiraAPIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			retryAfter := apiErr.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = time.Minute
+			}
+			return &ConnectorError{
+				Platform:   "jira",
+				Code:       "rate_limit",
+				Message:    "Jira API rate limit exceeded",
+				Retryable:  true,
+				RetryAfter: &retryAfter,
+			}
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return &ConnectorError{
+				Platform:  "jira",
+				Code:      "auth_error",
+				Message:   fmt.Sprintf("Jira authentication failed: %v", err),
+				Retryable: false,
+			}
+		case apiErr.StatusCode < 500:
+			// Jira answers 400 for JQL naming a project that does not exist
+			return &ConnectorError{
+				Platform:  "jira",
+				Code:      "api_error",
+				Message:   fmt.Sprintf("Jira API error: %v", err),
+				Retryable: false,
+			}
+		}
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &ConnectorError{
+			Platform:  "jira",
+			Code:      "network_error",
+			Message:   fmt.Sprintf("Jira API network error: %v", err),
+			Retryable: true,
+		}
+	}
+
+	// Server errors and anything unexpected are worth retrying
+	return &ConnectorError{
+		Platform:  "jira",
+		Code:      "api_error",
+		Message:   fmt.Sprintf("Jira API error: %v", err),
+		Retryable: true,
+	}
+}
@@ -0,0 +1,206 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestJiraServer serves a small Jira Cloud API for the PAY project, with
+// the issue search split over two token pages. The last JQL query is kept.
+func newTestJiraServer(t *testing.T, lastJQL *string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if email, token, ok := r.BasicAuth(); !ok || email != "alice@example.com" || token != "jira-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errorMessages":["You are not authenticated"]}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/2/myself":
+			fmt.Fprint(w, `{"accountId":"acc-1","displayName":"Alice","emailAddress":"alice@example.com"}`)
+		case "/rest/api/2/search/jql":
+			jql := r.URL.Query().Get("jql")
+			if lastJQL != nil {
+				*lastJQL = jql
+			}
+			if strings.Contains(jql, "NOPE") {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errorMessages":["The value 'NOPE' does not exist for the field 'project'."]}`)
+				return
+			}
+			if r.URL.Query().Get("nextPageToken") == "" {
+				fmt.Fprint(w, `{"issues":[{"id":"10001","key":"PAY-1","fields":{"summary":"Stripe webhooks","description":"Retry failed webhook deliveries","issuetype":{"name":"Epic"},"status":{"name":"In Progress","statusCategory":{"key":"indeterminate"}},"reporter":{"displayName":"Alice"},"labels":["payments"],"created":"2024-05-01T10:00:00.000+0000","updated":"2024-05-03T10:00:00.000+0000","project":{"key":"PAY"}},"changelog":{"histories":[{"id":"899","author":{"displayName":"Alice"},"created":"2024-04-01T10:00:00.000+0000","items":[{"field":"status","fromString":"Backlog","toString":"To Do"}]},{"id":"900","author":{"displayName":"Bob"},"created":"2024-05-02T10:00:00.000+0000","items":[{"field":"assignee","fromString":"","toString":"Bob"},{"field":"status","fromString":"To Do","toString":"In Progress"}]}]}}],"nextPageToken":"page-2"}`)
+				return
+			}
+			fmt.Fprint(w, `{"issues":[{"id":"10002","key":"PAY-2","fields":{"summary":"Back off webhook retries","issuetype":{"name":"Story"},"status":{"name":"To Do","statusCategory":{"key":"new"}},"reporter":{"displayName":"Bob"},"created":"2024-05-02T09:00:00.000+0000","updated":"2024-05-02T09:30:00.000+0200","parent":{"id":"10001","key":"PAY-1","fields":{"summary":"Stripe webhooks","issuetype":{"name":"Epic"}}},"issuelinks":[{"id":"1","type":{"name":"Blocks","inward":"is blocked by","outward":"blocks"},"inwardIssue":{"id":"10003","key":"PAY-3"}}],"project":{"key":"PAY"}}},{"id":"10004","key":"PAY-4","fields":{"summary":"Old issue","issuetype":{"name":"Bug"},"status":{"name":"Done"},"created":"2024-04-01T09:00:00.000+0000","updated":"2024-04-29T20:00:00.000+0000","project":{"key":"PAY"}}}],"isLast":true}`)
+		case "/rest/api/2/issue/PAY-1/comment":
+			fmt.Fprint(w, `{"comments":[{"id":"500","author":{"displayName":"Bob"},"body":"Old note","created":"2024-04-01T10:00:00.000+0000","updated":"2024-04-01T10:00:00.000+0000"},{"id":"501","author":{"displayName":"Bob"},"body":"Should we cap retries at five?","created":"2024-05-02T11:00:00.000+0000","updated":"2024-05-02T11:00:00.000+0000"}],"total":2}`)
+		case "/rest/api/2/issue/PAY-2/comment":
+			fmt.Fprint(w, `{"comments":[],"total":0}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorMessages":["Not found"]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestJiraConnector(t *testing.T, baseURL, token string, projects interface{}) PlatformConnector {
+	t.Helper()
+
+	connector, err := NewJiraConnector(ConnectorConfig{
+		Platform: "jira",
+		Enabled:  true,
+		AuthConfig: AuthConfig{
+			Metadata: map[string]string{"email": "alice@example.com", "api_token": token},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   100000,
+			RequestsPerMinute: 6000,
+			BurstLimit:        100,
+		},
+		Metadata: map[string]interface{}{
+			"base_url": baseURL,
+			"projects": projects,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Jira connector: %v", err)
+	}
+	return connector
+}
+
+// TestJiraConnectorIntegration tests the Jira connector against a fake Jira API
+func TestJiraConnectorIntegration(t *testing.T) {
+	var jql string
+	server := newTestJiraServer(t, &jql)
+	connector := newTestJiraConnector(t, server.URL, "jira-token", "PAY")
+	ctx := context.Background()
+
+	info := connector.GetPlatformInfo()
+	if info.Name != "jira" || info.AuthType != "api_token" {
+		t.Errorf("Unexpected platform info: %+v", info)
+	}
+
+	auth, err := connector.Authenticate(ctx, AuthConfig{Metadata: map[string]string{"email": "alice@example.com", "api_token": "jira-token"}})
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if auth.UserLogin != "Alice" || auth.UserID != "acc-1" {
+		t.Errorf("Expected user Alice (acc-1), got %s (%s)", auth.UserLogin, auth.UserID)
+	}
+
+	since := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	events, err := connector.FetchEvents(ctx, since, 1000)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+
+	wantJQL := `project in ("PAY") AND updated >= "2024/04/29 00:00" ORDER BY updated ASC`
+	if jql != wantJQL {
+		t.Errorf("Expected JQL %q, got %q", wantJQL, jql)
+	}
+
+	byID := make(map[string]PlatformEvent)
+	for _, event := range events {
+		byID[event.ID] = event
+	}
+	wantIDs := []string{"issue-10001", "transition-900", "comment-501", "issue-10002"}
+	if len(events) != len(wantIDs) {
+		t.Errorf("Expected %d events, got %d", len(wantIDs), len(events))
+	}
+	for _, id := range wantIDs {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("Expected event %s", id)
+		}
+	}
+	for _, id := range []string{"issue-10004", "transition-899", "comment-500"} {
+		if _, ok := byID[id]; ok {
+			t.Errorf("Event %s predates the last sync and should be skipped", id)
+		}
+	}
+
+	epic := byID["issue-10001"]
+	if epic.Type != EventTypeIssue || epic.Title != "PAY-1: Stripe webhooks" || epic.Metadata["ticket_key"] != "PAY-1" {
+		t.Errorf("Unexpected epic event: %+v", epic)
+	}
+	if features, _ := epic.Metadata["features"].([]string); len(features) != 1 || features[0] != "Stripe webhooks" {
+		t.Errorf("Expected the epic to name its feature, got %v", epic.Metadata["features"])
+	}
+	if epic.Metadata["web_url"] != server.URL+"/browse/PAY-1" {
+		t.Errorf("Unexpected web URL %v", epic.Metadata["web_url"])
+	}
+
+	transition := byID["transition-900"]
+	if transition.Content != "PAY-1 moved from To Do to In Progress" || transition.Metadata["parent_id"] != "issue-10001" || transition.Metadata["thread_id"] != "PAY-1" {
+		t.Errorf("Unexpected transition event: %+v", transition)
+	}
+
+	comment := byID["comment-501"]
+	if comment.Type != EventTypeDiscussion || comment.Metadata["thread_id"] != "PAY-1" || comment.Metadata["parent_id"] != "issue-10001" {
+		t.Errorf("Unexpected comment event: %+v", comment)
+	}
+
+	story := byID["issue-10002"]
+	links, _ := story.Metadata["issue_links"].([]map[string]string)
+	if len(links) != 1 || links[0]["type"] != "blocks" || links[0]["source"] != "PAY-3" || links[0]["target"] != "PAY-2" {
+		t.Errorf("Expected inward link to be recorded outward, got %v", links)
+	}
+
+	normalized, err := connector.NormalizeData(ctx, []PlatformEvent{story, comment})
+	if err != nil {
+		t.Fatalf("NormalizeData failed: %v", err)
+	}
+	if len(normalized[0].FeatureRefs) != 1 || normalized[0].FeatureRefs[0] != "Stripe webhooks" {
+		t.Errorf("Expected a story to belong to its epic's feature, got %v", normalized[0].FeatureRefs)
+	}
+	if normalized[1].ThreadID == nil || *normalized[1].ThreadID != "PAY-1" {
+		t.Errorf("Expected comment to be threaded under PAY-1, got %v", normalized[1].ThreadID)
+	}
+}
+
+// TestJiraConnectorErrors tests that Jira API failures become connector errors
+func TestJiraConnectorErrors(t *testing.T) {
+	server := newTestJiraServer(t, nil)
+	ctx := context.Background()
+
+	_, err := newTestJiraConnector(t, server.URL, "wrong", "PAY").FetchEvents(ctx, time.Time{}, 100)
+	var connErr *ConnectorError
+	if !errors.As(err, &connErr) || connErr.Code != "auth_error" || connErr.Retryable {
+		t.Errorf("Expected non-retryable auth_error, got %v", err)
+	}
+
+	_, err = newTestJiraConnector(t, server.URL, "jira-token", []string{"NOPE"}).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "api_error" || connErr.Retryable {
+		t.Errorf("Expected non-retryable api_error, got %v", err)
+	}
+
+	_, err = newTestJiraConnector(t, server.URL, "jira-token", nil).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "missing_projects" {
+		t.Errorf("Expected missing_projects, got %v", err)
+	}
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+
+	_, err = newTestJiraConnector(t, limited.URL, "jira-token", "PAY").FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "rate_limit" || !connErr.Retryable {
+		t.Fatalf("Expected retryable rate_limit, got %v", err)
+	}
+	if connErr.RetryAfter == nil || *connErr.RetryAfter != 30*time.Second {
+		t.Errorf("Expected retry after 30s, got %v", connErr.RetryAfter)
+	}
+}
@@ -0,0 +1,533 @@
+package connectors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/services"
+)
+
+// linearWebhookTolerance is how far a webhook's timestamp may be from now
+// before the delivery is rejected as a replay
+const linearWebhookTolerance = time.Minute
+
+// LinearConnector implements PlatformConnector and WebhookConnector for Linear
+type LinearConnector struct {
+	*BaseConnector
+	linearService services.LinearService
+	normalizer    *EventNormalizer
+}
+
+// NewLinearConnector creates a new Linear connector
+func NewLinearConnector(config ConnectorConfig) (PlatformConnector, error) {
+	apiURL, _ := config.Metadata["api_url"].(string)
+
+	return &LinearConnector{
+		BaseConnector: NewBaseConnector(config),
+		linearService: services.NewLinearService(apiURL),
+		normalizer:    NewEventNormalizer("linear"),
+	}, nil
+}
+
+// Authenticate validates a Linear personal API key or OAuth access token
+func (lc *LinearConnector) Authenticate(ctx context.Context, config AuthConfig) (*AuthResult, error) {
+	apiKey, err := linearAPIKey(config)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := lc.linearService.GetViewer(ctx, apiKey)
+	if err != nil {
+		return nil, lc.handleLinearError(err)
+	}
+
+	return &AuthResult{
+		AccessToken: apiKey,
+		UserID:      viewer.ID,
+		UserLogin:   viewer.DisplayName,
+		ExpiresAt:   time.Now().Add(24 * time.Hour), // API keys do not expire; re-check daily for revocation
+		Scopes:      config.Scopes,
+	}, nil
+}
+
+// FetchEvents retrieves the projects, cycles, issues and comments of the
+// configured teams, or of the whole workspace, updated since the last sync
+func (lc *LinearConnector) FetchEvents(ctx context.Context, since time.Time, limit int) ([]PlatformEvent, error) {
+	config := lc.GetConfig()
+	apiKey, err := linearAPIKey(config.AuthConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := services.LinearFilter{
+		TeamKeys:     metadataStrings(config.Metadata["teams"]),
+		UpdatedAfter: since,
+	}
+
+	var events []PlatformEvent
+
+	// Projects come first so the features they describe are built from them
+	if err := lc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	projects, err := lc.linearService.ListProjects(ctx, apiKey, filter, limit)
+	if err != nil {
+		return nil, lc.handleLinearError(err)
+	}
+	for _, project := range projects {
+		events = append(events, lc.convertProjectToEvent(project))
+	}
+
+	if err := lc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	cycles, err := lc.linearService.ListCycles(ctx, apiKey, filter, limit)
+	if err != nil {
+		return nil, lc.handleLinearError(err)
+	}
+	for _, cycle := range cycles {
+		events = append(events, lc.convertCycleToEvent(cycle))
+	}
+
+	if err := lc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	issues, err := lc.linearService.ListIssues(ctx, apiKey, filter, limit)
+	if err != nil {
+		return nil, lc.handleLinearError(err)
+	}
+	for _, issue := range issues {
+		events = append(events, lc.convertIssueToEvent(issue))
+	}
+
+	if err := lc.WaitForRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	comments, err := lc.linearService.ListComments(ctx, apiKey, filter, limit)
+	if err != nil {
+		return nil, lc.handleLinearError(err)
+	}
+	for _, comment := range comments {
+		events = append(events, lc.convertCommentToEvent(comment))
+	}
+
+	return events, nil
+}
+
+// ParseWebhook verifies a Linear webhook delivery by its Linear-Signature
+// header and converts the created or updated object to events. Removals and
+// object types that are not ingested yield no events.
+func (lc *LinearConnector) ParseWebhook(ctx context.Context, headers http.Header, body []byte) ([]PlatformEvent, error) {
+	config := lc.GetConfig()
+	secret := config.AuthConfig.Metadata["webhook_secret"]
+	if secret == "" {
+		return nil, &ConnectorError{
+			Platform:  "linear",
+			Code:      "missing_webhook_secret",
+			Message:   "Linear webhook signing secret not configured",
+			Retryable: false,
+		}
+	}
+
+	signature, err := hex.DecodeString(headers.Get("Linear-Signature"))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, &ConnectorError{
+			Platform:  "linear",
+			Code:      "invalid_signature",
+			Message:   "Linear webhook signature does not match",
+			Retryable: false,
+		}
+	}
+
+	var payload struct {
+		Action           string          `json:"action"` // create, update or remove
+		Type             string          `json:"type"`
+		Data             json.RawMessage `json:"data"`
+		URL              string          `json:"url"`
+		WebhookTimestamp int64           `json:"webhookTimestamp"` // Unix milliseconds
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, &ConnectorError{
+			Platform:  "linear",
+			Code:      "invalid_payload",
+			Message:   fmt.Sprintf("Linear webhook payload is not valid JSON: %v", err),
+			Retryable: false,
+		}
+	}
+
+	age := time.Since(time.UnixMilli(payload.WebhookTimestamp))
+	if age > linearWebhookTolerance || age < -linearWebhookTolerance {
+		return nil, &ConnectorError{
+			Platform:  "linear",
+			Code:      "stale_webhook",
+			Message:   "Linear webhook timestamp is outside the accepted window",
+			Retryable: false,
+		}
+	}
+
+	if payload.Action == "remove" {
+		return nil, nil
+	}
+
+	teams := make(map[string]bool)
+	for _, key := range metadataStrings(config.Metadata["teams"]) {
+		teams[key] = true
+	}
+	inTeams := func(keys ...string) bool {
+		if len(teams) == 0 || len(keys) == 0 {
+			return true
+		}
+		for _, key := range keys {
+			if teams[key] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var event PlatformEvent
+	var keys []string
+	var decodeErr error
+	switch payload.Type {
+	case "Issue":
+		var issue services.LinearIssue
+		if decodeErr = json.Unmarshal(payload.Data, &issue); decodeErr == nil {
+			if issue.URL == "" {
+				issue.URL = payload.URL
+			}
+			if issue.Team.Key != "" {
+				keys = append(keys, issue.Team.Key)
+			}
+			event = lc.convertIssueToEvent(issue)
+		}
+	case "Comment":
+		var comment services.LinearComment
+		if decodeErr = json.Unmarshal(payload.Data, &comment); decodeErr == nil {
+			if comment.URL == "" {
+				comment.URL = payload.URL
+			}
+			event = lc.convertCommentToEvent(comment)
+		}
+	case "Project":
+		var project services.LinearProject
+		if decodeErr = json.Unmarshal(payload.Data, &project); decodeErr == nil {
+			if project.URL == "" {
+				project.URL = payload.URL
+			}
+			for _, team := range project.Teams {
+				keys = append(keys, team.Key)
+			}
+			event = lc.convertProjectToEvent(project)
+		}
+	case "Cycle":
+		var cycle services.LinearCycle
+		if decodeErr = json.Unmarshal(payload.Data, &cycle); decodeErr == nil {
+			if cycle.Team.Key != "" {
+				keys = append(keys, cycle.Team.Key)
+			}
+			event = lc.convertCycleToEvent(cycle)
+		}
+	default:
+		return nil, nil
+	}
+
+	if decodeErr != nil {
+		return nil, &ConnectorError{
+			Platform:  "linear",
+			Code:      "invalid_payload",
+			Message:   fmt.Sprintf("Linear webhook %s data is not valid: %v", payload.Type, decodeErr),
+			Retryable: false,
+		}
+	}
+	if !inTeams(keys...) {
+		return nil, nil
+	}
+
+	return []PlatformEvent{event}, nil
+}
+
+// NormalizeData converts Linear platform events to normalized format
+func (lc *LinearConnector) NormalizeData(ctx context.Context, events []PlatformEvent) ([]NormalizedEvent, error) {
+	normalized := make([]NormalizedEvent, len(events))
+	for i, event := range events {
+		normalized[i] = lc.normalizer.NormalizeEvent(event)
+	}
+	return normalized, nil
+}
+
+// ScheduleSync determines the next sync interval for Linear. Webhooks deliver
+// changes as they happen, so scheduled syncs only catch up on missed ones.
+func (lc *LinearConnector) ScheduleSync(ctx context.Context, lastSync time.Time) (time.Duration, error) {
+	config := lc.GetConfig()
+	if config.SyncConfig.SyncInterval > 0 {
+		return config.SyncConfig.SyncInterval, nil
+	}
+	if config.AuthConfig.Metadata["webhook_secret"] != "" {
+		return time.Hour, nil
+	}
+	return 10 * time.Minute, nil
+}
+
+// GetPlatformInfo returns Linear connector metadata
+func (lc *LinearConnector) GetPlatformInfo() PlatformInfo {
+	return PlatformInfo{
+		Name:        "linear",
+		DisplayName: "Linear",
+		Version:     "1.0.0",
+		Description: "Linear connector for issues, comments, projects and cycles",
+		SupportedEvents: []EventType{
+			EventTypeIssue,
+			EventTypeDiscussion,
+		},
+		RateLimits: RateLimitInfo{
+			RequestsPerHour:   1500,
+			RequestsPerMinute: 25,
+			BurstLimit:        10,
+			BackoffStrategy:   "exponential",
+			RetryAfterHeader:  "Retry-After",
+		},
+		AuthType:       "api_key",
+		RequiredScopes: []string{"read"},
+	}
+}
+
+// convertIssueToEvent converts a Linear issue to a platform event. Comments
+// are threaded under the issue identifier, and an issue in a project is part
+// of the project's feature.
+func (lc *LinearConnector) convertIssueToEvent(issue services.LinearIssue) PlatformEvent {
+	labels := make([]string, len(issue.Labels))
+	for i, label := range issue.Labels {
+		labels[i] = label.Name
+	}
+
+	metadata := map[string]interface{}{
+		"team":       issue.Team.Key,
+		"ticket_key": issue.Identifier,
+		"thread_id":  issue.Identifier,
+		"state":      issue.State.Name,
+		"state_type": issue.State.Type,
+		"labels":     labels,
+		"priority":   issue.PriorityLabel,
+		"web_url":    issue.URL,
+		"updated_at": issue.UpdatedAt,
+	}
+	if issue.Assignee != nil {
+		metadata["assignee"] = issue.Assignee.Name
+	}
+	if issue.CompletedAt != nil {
+		metadata["completed_at"] = *issue.CompletedAt
+	}
+	if issue.Parent != nil {
+		metadata["parent_key"] = issue.Parent.Identifier
+	}
+	if issue.Cycle != nil {
+		metadata["cycle"] = linearCycleName(issue.Cycle.Number, issue.Cycle.Name)
+	}
+	if issue.Project != nil && issue.Project.Name != "" {
+		metadata["project"] = issue.Project.Name
+		metadata["features"] = []string{issue.Project.Name}
+	}
+
+	author := ""
+	if issue.Creator != nil {
+		author = issue.Creator.Name
+	}
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("issue-%s", issue.ID),
+		Type:      EventTypeIssue,
+		Timestamp: issue.CreatedAt,
+		Author:    author,
+		Content:   issue.Description,
+		Title:     fmt.Sprintf("%s: %s", issue.Identifier, issue.Title),
+		Platform:  "linear",
+		Metadata:  metadata,
+	}
+}
+
+// convertCommentToEvent converts a comment on a Linear issue to a platform event
+func (lc *LinearConnector) convertCommentToEvent(comment services.LinearComment) PlatformEvent {
+	issue := services.LinearIssueRef{ID: comment.IssueID}
+	if comment.Issue != nil {
+		issue = *comment.Issue
+	}
+	threadID := issue.Identifier
+	if threadID == "" {
+		threadID = issue.ID
+	}
+
+	author := ""
+	if comment.User != nil {
+		author = comment.User.Name
+	}
+
+	title := issue.Title
+	if issue.Identifier != "" {
+		title = fmt.Sprintf("%s: %s", issue.Identifier, issue.Title)
+	}
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("comment-%s", comment.ID),
+		Type:      EventTypeDiscussion,
+		Timestamp: comment.CreatedAt,
+		Author:    author,
+		Content:   comment.Body,
+		Title:     title,
+		Platform:  "linear",
+		Metadata: map[string]interface{}{
+			"ticket_key": issue.Identifier,
+			"thread_id":  threadID,
+			"parent_id":  fmt.Sprintf("issue-%s", issue.ID),
+			"web_url":    comment.URL,
+			"updated_at": comment.UpdatedAt,
+		},
+	}
+}
+
+// convertProjectToEvent converts a Linear project to a platform event naming
+// the feature the project delivers
+func (lc *LinearConnector) convertProjectToEvent(project services.LinearProject) PlatformEvent {
+	teams := make([]string, len(project.Teams))
+	for i, team := range project.Teams {
+		teams[i] = team.Key
+	}
+
+	metadata := map[string]interface{}{
+		"features":    []string{project.Name},
+		"state":       project.State,
+		"teams":       teams,
+		"start_date":  project.StartDate,
+		"target_date": project.TargetDate,
+		"web_url":     project.URL,
+		"updated_at":  project.UpdatedAt,
+	}
+
+	author := ""
+	if project.Lead != nil {
+		author = project.Lead.Name
+		metadata["lead"] = project.Lead.Name
+	}
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("project-%s", project.ID),
+		Type:      EventTypeDiscussion,
+		Timestamp: project.CreatedAt,
+		Author:    author,
+		Content:   project.Description,
+		Title:     project.Name,
+		Platform:  "linear",
+		Metadata:  metadata,
+	}
+}
+
+// convertCycleToEvent converts a Linear cycle to a platform event
+func (lc *LinearConnector) convertCycleToEvent(cycle services.LinearCycle) PlatformEvent {
+	metadata := map[string]interface{}{
+		"team":         cycle.Team.Key,
+		"cycle":        linearCycleName(cycle.Number, cycle.Name),
+		"cycle_number": cycle.Number,
+		"starts_at":    cycle.StartsAt,
+		"ends_at":      cycle.EndsAt,
+		"progress":     cycle.Progress,
+		"updated_at":   cycle.UpdatedAt,
+	}
+	if cycle.CompletedAt != nil {
+		metadata["completed_at"] = *cycle.CompletedAt
+	}
+
+	return PlatformEvent{
+		ID:        fmt.Sprintf("cycle-%s", cycle.ID),
+		Type:      EventTypeDiscussion,
+		Timestamp: cycle.StartsAt,
+		Content:   cycle.Description,
+		Title:     fmt.Sprintf("%s %s", cycle.Team.Key, linearCycleName(cycle.Number, cycle.Name)),
+		Platform:  "linear",
+		Metadata:  metadata,
+	}
+}
+
+// linearCycleName names a cycle as Linear shows it: by name, or by number
+// for unnamed cycles
+func linearCycleName(number int, name string) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("Cycle %d", number)
+}
+
+// linearAPIKey reads the API key from auth metadata
+func linearAPIKey(config AuthConfig) (string, error) {
+	apiKey, ok := config.Metadata["api_key"]
+	if !ok || apiKey == "" {
+		return "", &ConnectorError{
+			Platform:  "linear",
+			Code:      "missing_token",
+			Message:   "Linear API key not configured",
+			Retryable: false,
+		}
+	}
+	return apiKey, nil
+}
+
+// handleLinearError converts Linear service errors to connector errors
+func (lc *LinearConnector) handleLinearError(err error) error {
+	var apiErr *services.LinearAPIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			retryAfter := apiErr.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = time.Minute
+			}
+			return &ConnectorError{
+				Platform:   "linear",
+				Code:       "rate_limit",
+				Message:    "Linear API rate limit exceeded",
+				Retryable:  true,
+				RetryAfter: &retryAfter,
+			}
+		case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+			return &ConnectorError{
+				Platform:  "linear",
+				Code:      "auth_error",
+				Message:   fmt.Sprintf("Linear authentication failed: %v", err),
+				Retryable: false,
+			}
+		case apiErr.StatusCode < 500:
+			return &ConnectorError{
+				Platform:  "linear",
+				Code:      "api_error",
+				Message:   fmt.Sprintf("Linear API error: %v", err),
+				Retryable: false,
+			}
+		}
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &ConnectorError{
+			Platform:  "linear",
+			Code:      "network_error",
+			Message:   fmt.Sprintf("Linear API network error: %v", err),
+			Retryable: true,
+		}
+	}
+
+	// Server errors and anything unexpected are worth retrying
+	return &ConnectorError{
+		Platform:  "linear",
+		Code:      "api_error",
+		Message:   fmt.Sprintf("Linear API error: %v", err),
+		Retryable: true,
+	}
+}
@@ -0,0 +1,272 @@
+package connectors
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestLinearServer serves a small Linear GraphQL API for the ENG team, with
+// the issue list split over two cursor pages. The filter of the last issue
+// query is kept.
+func newTestLinearServer(t *testing.T, issueFilter *map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "lin_api_test" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":[{"message":"Authentication required, not authenticated","extensions":{"code":"AUTHENTICATION_ERROR"}}]}`)
+			return
+		}
+
+		var request struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case strings.Contains(request.Query, "viewer"):
+			fmt.Fprint(w, `{"data":{"viewer":{"id":"user-1","name":"alice","displayName":"Alice"}}}`)
+		case strings.Contains(request.Query, "projects("):
+			fmt.Fprint(w, `{"data":{"projects":{"nodes":[{"id":"proj-1","name":"Webhook retries","description":"Retry failed deliveries","state":"started","url":"https://linear.app/acme/project/webhook-retries","createdAt":"2024-05-01T09:00:00Z","updatedAt":"2024-05-02T09:00:00Z","lead":{"name":"alice"},"teams":{"nodes":[{"key":"ENG"}]}},{"id":"proj-2","name":"Brand refresh","createdAt":"2024-05-01T09:00:00Z","updatedAt":"2024-05-02T09:00:00Z","teams":{"nodes":[{"key":"DES"}]}}],"pageInfo":{"hasNextPage":false}}}}`)
+		case strings.Contains(request.Query, "cycles("):
+			fmt.Fprint(w, `{"data":{"cycles":{"nodes":[{"id":"cyc-1","number":12,"name":"","startsAt":"2024-04-29T00:00:00Z","endsAt":"2024-05-13T00:00:00Z","progress":0.4,"updatedAt":"2024-05-02T09:00:00Z","team":{"key":"ENG"}}],"pageInfo":{"hasNextPage":false}}}}`)
+		case strings.Contains(request.Query, "issues("):
+			if issueFilter != nil {
+				*issueFilter, _ = request.Variables["filter"].(map[string]interface{})
+			}
+			if request.Variables["after"] == nil {
+				fmt.Fprint(w, `{"data":{"issues":{"nodes":[{"id":"iss-1","identifier":"ENG-42","title":"Back off webhook retries","description":"Retries hammer the endpoint","url":"https://linear.app/acme/issue/ENG-42","priorityLabel":"High","createdAt":"2024-05-01T10:00:00Z","updatedAt":"2024-05-02T10:00:00Z","state":{"name":"In Progress","type":"started"},"team":{"key":"ENG"},"creator":{"name":"alice"},"assignee":{"name":"bob"},"labels":{"nodes":[{"name":"payments"}]},"project":{"id":"proj-1","name":"Webhook retries"},"cycle":{"id":"cyc-1","number":12,"name":""}}],"pageInfo":{"hasNextPage":true,"endCursor":"cursor-1"}}}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"issues":{"nodes":[{"id":"iss-2","identifier":"ENG-43","title":"Document retry policy","createdAt":"2024-05-02T10:00:00Z","updatedAt":"2024-05-02T11:00:00Z","state":{"name":"Todo","type":"unstarted"},"team":{"key":"ENG"},"labels":{"nodes":[]},"parent":{"id":"iss-1","identifier":"ENG-42"}}],"pageInfo":{"hasNextPage":false,"endCursor":"cursor-2"}}}}`)
+		case strings.Contains(request.Query, "comments("):
+			fmt.Fprint(w, `{"data":{"comments":{"nodes":[{"id":"com-1","body":"Should we cap retries at five?","url":"https://linear.app/acme/issue/ENG-42#comment-1","createdAt":"2024-05-02T12:00:00Z","updatedAt":"2024-05-02T12:00:00Z","user":{"name":"bob"},"issue":{"id":"iss-1","identifier":"ENG-42","title":"Back off webhook retries"}}],"pageInfo":{"hasNextPage":false}}}}`)
+		default:
+			fmt.Fprint(w, `{"errors":[{"message":"Unknown query","extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}}]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestLinearConnector(t *testing.T, apiURL, apiKey string, teams interface{}) PlatformConnector {
+	t.Helper()
+
+	connector, err := NewLinearConnector(ConnectorConfig{
+		Platform: "linear",
+		Enabled:  true,
+		AuthConfig: AuthConfig{
+			Metadata: map[string]string{"api_key": apiKey, "webhook_secret": "whsec-test"},
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerHour:   100000,
+			RequestsPerMinute: 6000,
+			BurstLimit:        100,
+		},
+		Metadata: map[string]interface{}{
+			"api_url": apiURL,
+			"teams":   teams,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Linear connector: %v", err)
+	}
+	return connector
+}
+
+// TestLinearConnectorIntegration tests the Linear connector against a fake Linear API
+func TestLinearConnectorIntegration(t *testing.T) {
+	var issueFilter map[string]interface{}
+	server := newTestLinearServer(t, &issueFilter)
+	connector := newTestLinearConnector(t, server.URL, "lin_api_test", "ENG")
+	ctx := context.Background()
+
+	info := connector.GetPlatformInfo()
+	if info.Name != "linear" || info.AuthType != "api_key" {
+		t.Errorf("Unexpected platform info: %+v", info)
+	}
+
+	auth, err := connector.Authenticate(ctx, AuthConfig{Metadata: map[string]string{"api_key": "lin_api_test"}})
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if auth.UserLogin != "Alice" || auth.UserID != "user-1" {
+		t.Errorf("Expected user Alice (user-1), got %s (%s)", auth.UserLogin, auth.UserID)
+	}
+
+	since := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	events, err := connector.FetchEvents(ctx, since, 1000)
+	if err != nil {
+		t.Fatalf("FetchEvents failed: %v", err)
+	}
+
+	filterJSON, _ := json.Marshal(issueFilter)
+	if string(filterJSON) != `{"team":{"key":{"in":["ENG"]}},"updatedAt":{"gt":"2024-04-30T00:00:00Z"}}` {
+		t.Errorf("Unexpected issue filter %s", filterJSON)
+	}
+
+	byID := make(map[string]PlatformEvent)
+	for _, event := range events {
+		byID[event.ID] = event
+	}
+	wantIDs := []string{"project-proj-1", "cycle-cyc-1", "issue-iss-1", "issue-iss-2", "comment-com-1"}
+	if len(events) != len(wantIDs) {
+		t.Errorf("Expected %d events, got %d", len(wantIDs), len(events))
+	}
+	for _, id := range wantIDs {
+		if _, ok := byID[id]; !ok {
+			t.Errorf("Expected event %s", id)
+		}
+	}
+	if _, ok := byID["project-proj-2"]; ok {
+		t.Error("Projects of other teams should be skipped")
+	}
+
+	project := byID["project-proj-1"]
+	if features, _ := project.Metadata["features"].([]string); project.Type != EventTypeDiscussion || len(features) != 1 || features[0] != "Webhook retries" {
+		t.Errorf("Expected the project to name its feature, got %+v", project)
+	}
+
+	issue := byID["issue-iss-1"]
+	if issue.Type != EventTypeIssue || issue.Title != "ENG-42: Back off webhook retries" || issue.Metadata["ticket_key"] != "ENG-42" {
+		t.Errorf("Unexpected issue event: %+v", issue)
+	}
+	if issue.Metadata["cycle"] != "Cycle 12" || issue.Metadata["state"] != "In Progress" {
+		t.Errorf("Unexpected issue metadata: %v", issue.Metadata)
+	}
+	if byID["cycle-cyc-1"].Title != "ENG Cycle 12" {
+		t.Errorf("Unexpected cycle title %q", byID["cycle-cyc-1"].Title)
+	}
+
+	comment := byID["comment-com-1"]
+	if comment.Type != EventTypeDiscussion || comment.Metadata["thread_id"] != "ENG-42" || comment.Metadata["parent_id"] != "issue-iss-1" {
+		t.Errorf("Unexpected comment event: %+v", comment)
+	}
+
+	normalized, err := connector.NormalizeData(ctx, []PlatformEvent{issue, comment})
+	if err != nil {
+		t.Fatalf("NormalizeData failed: %v", err)
+	}
+	if len(normalized[0].FeatureRefs) != 1 || normalized[0].FeatureRefs[0] != "Webhook retries" {
+		t.Errorf("Expected an issue to belong to its project's feature, got %v", normalized[0].FeatureRefs)
+	}
+	if len(normalized[0].Labels) != 1 || normalized[0].Labels[0] != "payments" {
+		t.Errorf("Expected issue labels to be normalized, got %v", normalized[0].Labels)
+	}
+	if normalized[1].ThreadID == nil || *normalized[1].ThreadID != "ENG-42" {
+		t.Errorf("Expected comment to be threaded under ENG-42, got %v", normalized[1].ThreadID)
+	}
+}
+
+// signLinearWebhook signs a webhook body as Linear does
+func signLinearWebhook(body []byte, secret string) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	headers := http.Header{}
+	headers.Set("Linear-Signature", hex.EncodeToString(mac.Sum(nil)))
+	return headers
+}
+
+// TestLinearConnectorWebhook tests that signed webhook deliveries become events
+func TestLinearConnectorWebhook(t *testing.T) {
+	linear := newTestLinearConnector(t, "http://127.0.0.1:0", "lin_api_test", "ENG")
+	connector := linear.(WebhookConnector)
+	ctx := context.Background()
+	now := time.Now().UnixMilli()
+
+	// Webhooks deliver changes as they happen, so syncs only catch up hourly
+	if interval, _ := linear.ScheduleSync(ctx, time.Now()); interval != time.Hour {
+		t.Errorf("Expected hourly polling with a signing secret, got %v", interval)
+	}
+
+	// Webhook payloads list labels as plain arrays
+	body := []byte(fmt.Sprintf(`{"action":"update","type":"Issue","url":"https://linear.app/acme/issue/ENG-42","webhookTimestamp":%d,"data":{"id":"iss-1","identifier":"ENG-42","title":"Back off webhook retries","createdAt":"2024-05-01T10:00:00Z","updatedAt":"2024-05-02T10:00:00Z","state":{"name":"Done","type":"completed"},"team":{"key":"ENG"},"labels":[{"name":"payments"}],"project":{"id":"proj-1","name":"Webhook retries"}}}`, now))
+	events, err := connector.ParseWebhook(ctx, signLinearWebhook(body, "whsec-test"), body)
+	if err != nil {
+		t.Fatalf("ParseWebhook failed: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "issue-iss-1" || events[0].Metadata["state"] != "Done" {
+		t.Fatalf("Unexpected webhook events: %+v", events)
+	}
+	if labels, _ := events[0].Metadata["labels"].([]string); len(labels) != 1 || labels[0] != "payments" {
+		t.Errorf("Expected webhook labels, got %v", events[0].Metadata["labels"])
+	}
+	if events[0].Metadata["web_url"] != "https://linear.app/acme/issue/ENG-42" {
+		t.Errorf("Expected the delivery URL, got %v", events[0].Metadata["web_url"])
+	}
+
+	comment := []byte(fmt.Sprintf(`{"action":"create","type":"Comment","webhookTimestamp":%d,"data":{"id":"com-2","body":"Shipped","createdAt":"2024-05-03T10:00:00Z","issueId":"iss-1","issue":{"id":"iss-1","identifier":"ENG-42","title":"Back off webhook retries"},"user":{"name":"bob"}}}`, now))
+	events, err = connector.ParseWebhook(ctx, signLinearWebhook(comment, "whsec-test"), comment)
+	if err != nil || len(events) != 1 || events[0].Metadata["thread_id"] != "ENG-42" {
+		t.Errorf("Expected a threaded comment event, got %+v (%v)", events, err)
+	}
+
+	for name, skipped := range map[string]string{
+		"removal":    fmt.Sprintf(`{"action":"remove","type":"Issue","webhookTimestamp":%d,"data":{"id":"iss-1"}}`, now),
+		"other team": fmt.Sprintf(`{"action":"create","type":"Issue","webhookTimestamp":%d,"data":{"id":"iss-9","identifier":"DES-1","team":{"key":"DES"}}}`, now),
+		"other type": fmt.Sprintf(`{"action":"create","type":"Reaction","webhookTimestamp":%d,"data":{"id":"r-1"}}`, now),
+	} {
+		events, err := connector.ParseWebhook(ctx, signLinearWebhook([]byte(skipped), "whsec-test"), []byte(skipped))
+		if err != nil || len(events) != 0 {
+			t.Errorf("Expected %s to yield no events, got %+v (%v)", name, events, err)
+		}
+	}
+
+	var connErr *ConnectorError
+	_, err = connector.ParseWebhook(ctx, signLinearWebhook(body, "wrong-secret"), body)
+	if !errors.As(err, &connErr) || connErr.Code != "invalid_signature" {
+		t.Errorf("Expected invalid_signature, got %v", err)
+	}
+
+	stale := []byte(fmt.Sprintf(`{"action":"update","type":"Issue","webhookTimestamp":%d,"data":{"id":"iss-1"}}`, time.Now().Add(-10*time.Minute).UnixMilli()))
+	_, err = connector.ParseWebhook(ctx, signLinearWebhook(stale, "whsec-test"), stale)
+	if !errors.As(err, &connErr) || connErr.Code != "stale_webhook" {
+		t.Errorf("Expected stale_webhook, got %v", err)
+	}
+}
+
+// TestLinearConnectorErrors tests that Linear API failures become connector errors
+func TestLinearConnectorErrors(t *testing.T) {
+	server := newTestLinearServer(t, nil)
+	ctx := context.Background()
+
+	_, err := newTestLinearConnector(t, server.URL, "lin_api_wrong", "ENG").FetchEvents(ctx, time.Time{}, 100)
+	var connErr *ConnectorError
+	if !errors.As(err, &connErr) || connErr.Code != "auth_error" || connErr.Retryable {
+		t.Errorf("Expected non-retryable auth_error, got %v", err)
+	}
+
+	_, err = newTestLinearConnector(t, server.URL, "", "ENG").FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "missing_token" {
+		t.Errorf("Expected missing_token, got %v", err)
+	}
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"errors":[{"message":"Rate limit exceeded","extensions":{"code":"RATELIMITED"}}]}`)
+	}))
+	defer limited.Close()
+
+	_, err = newTestLinearConnector(t, limited.URL, "lin_api_test", nil).FetchEvents(ctx, time.Time{}, 100)
+	if !errors.As(err, &connErr) || connErr.Code != "rate_limit" || !connErr.Retryable {
+		t.Fatalf("Expected retryable rate_limit, got %v", err)
+	}
+	if connErr.RetryAfter == nil || *connErr.RetryAfter != time.Minute {
+		t.Errorf("Expected retry after a minute, got %v", connErr.RetryAfter)
+	}
+}
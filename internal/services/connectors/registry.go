@@ -82,6 +82,26 @@ func (r *Registry) RegisterDefaultConnectors() error {
 		return fmt.Errorf("failed to register Discord connector: %w", err)
 	}
 
+	// Register GitLab connector
+	if err := r.Register("gitlab", NewGitLabConnector); err != nil {
+		return fmt.Errorf("failed to register GitLab connector: %w", err)
+	}
+
+	// Register Jira connector
+	if err := r.Register("jira", NewJiraConnector); err != nil {
+		return fmt.Errorf("failed to register Jira connector: %w", err)
+	}
+
+	// Register Linear connector
+	if err := r.Register("linear", NewLinearConnector); err != nil {
+		return fmt.Errorf("failed to register Linear connector: %w", err)
+	}
+
+	// Register local git connector
+	if err := r.Register("gitlocal", NewGitLocalConnector); err != nil {
+		return fmt.Errorf("failed to register local git connector: %w", err)
+	}
+
 	return nil
 }
 
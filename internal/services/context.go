@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/DevAnuragT/context_keeper/internal/models"
@@ -16,25 +17,26 @@ import (
 type ContextServiceImpl struct {
 	repo           RepositoryStore
 	permissionSvc  PermissionService
-	aiBaseURL      string
-	client         *http.Client
+	engine         *ContextEngine
 }
 
-// NewContextService creates a new context service instance
-func NewContextService(repo RepositoryStore, permissionSvc PermissionService, aiBaseURL string) ContextService {
+// NewContextService creates a new context service instance that answers
+// queries with the given engine
+func NewContextService(repo RepositoryStore, permissionSvc PermissionService, engine *ContextEngine) ContextService {
+	if engine == nil {
+		engine = NewContextEngine(nil, &SimpleLogger{})
+	}
 	return &ContextServiceImpl{
 		repo:          repo,
 		permissionSvc: permissionSvc,
-		aiBaseURL:     aiBaseURL,
-		client: &http.Client{
-			Timeout: 30 * time.Second, // 30-second timeout as per requirements
-		},
+		engine:        engine,
 	}
 }
 
-// ProcessQuery processes a context query by filtering repository data and sending it to the AI service
+// ProcessQuery processes a context query from the repository's recent activity and, when the
+// repository belongs to a project, the project's knowledge graph
 func (c *ContextServiceImpl) ProcessQuery(ctx context.Context, repoID int64, query, mode string) (*models.ContextResponse, error) {
-	// Filter repository data for AI service
+	// Filter repository data for the context engine
 	repoData, err := c.FilterRepoData(ctx, repoID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to filter repository data: %w", err)
@@ -46,23 +48,24 @@ func (c *ContextServiceImpl) ProcessQuery(ctx context.Context, repoID int64, que
 		return nil, fmt.Errorf("failed to get repository: %w", err)
 	}
 
-	// Prepare request payload for AI service
-	aiRequest := models.FilteredRepoData{
-		Repo:    repo.FullName,
-		Query:   query,
-		Context: *repoData,
-	}
-
-	// Send request to AI service
-	response, err := c.callAIService(ctx, aiRequest, mode)
-	if err != nil {
-		return nil, fmt.Errorf("AI service call failed: %w", err)
+	var knowledge []models.SearchResult
+	if repo.ProjectID != nil {
+		knowledge, err = c.searchKnowledge(ctx, *repo.ProjectID, query)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return response, nil
+	return c.engine.Synthesize(ctx, &ContextRequest{
+		Repo:      repo.FullName,
+		Query:     query,
+		Mode:      mode,
+		Context:   *repoData,
+		Knowledge: knowledge,
+	})
 }
 
-// FilterRepoData filters repository data to the most recent items for AI service
+// FilterRepoData filters repository data to the most recent items for the context engine
 func (c *ContextServiceImpl) FilterRepoData(ctx context.Context, repoID int64) (*models.RepoContext, error) {
 	// Get the most recent 10 pull requests
 	prs, err := c.repo.GetRecentPRs(ctx, repoID, 10)
@@ -89,16 +92,104 @@ func (c *ContextServiceImpl) FilterRepoData(ctx context.Context, repoID int64) (
 	}, nil
 }
 
-// callAIService makes an HTTP request to the AI service with timeout handling
-func (c *ContextServiceImpl) callAIService(ctx context.Context, request models.FilteredRepoData, mode string) (*models.ContextResponse, error) {
+// ProcessQueryByProject processes a context query for all repositories in a project and its knowledge graph
+func (c *ContextServiceImpl) ProcessQueryByProject(ctx context.Context, projectID string, query, mode string) (*models.ContextResponse, error) {
+	// Filter project data for the context engine
+	projectData, err := c.FilterProjectData(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter project data: %w", err)
+	}
+
+	// Get project information for context
+	project, err := c.repo.GetProjectWorkspace(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project workspace: %w", err)
+	}
+
+	knowledge, err := c.searchKnowledge(ctx, projectID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.engine.Synthesize(ctx, &ContextRequest{
+		Repo:      fmt.Sprintf("Project: %s", project.Name),
+		Query:     query,
+		Mode:      mode,
+		Context:   *projectData,
+		Knowledge: knowledge,
+	})
+}
+
+// searchKnowledge finds the project's knowledge entities that match the query
+func (c *ContextServiceImpl) searchKnowledge(ctx context.Context, projectID, query string) ([]models.SearchResult, error) {
+	results, err := c.repo.SearchKnowledgeEntitiesByProject(ctx, projectID, &models.KnowledgeGraphQuery{
+		Query: query,
+		Limit: maxContextKnowledge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search project knowledge: %w", err)
+	}
+	return results, nil
+}
+
+// FilterProjectData filters project data to the most recent items for the context engine
+func (c *ContextServiceImpl) FilterProjectData(ctx context.Context, projectID string) (*models.RepoContext, error) {
+	// Get the most recent 10 pull requests across all project repositories
+	prs, err := c.repo.GetRecentPRsByProject(ctx, projectID, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent pull requests: %w", err)
+	}
+
+	// Get the most recent 10 issues across all project repositories
+	issues, err := c.repo.GetRecentIssuesByProject(ctx, projectID, 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent issues: %w", err)
+	}
+
+	// Get the most recent 20 commits across all project repositories
+	commits, err := c.repo.GetRecentCommitsByProject(ctx, projectID, 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent commits: %w", err)
+	}
+
+	return &models.RepoContext{
+		PullRequests: prs,
+		Issues:       issues,
+		Commits:      commits,
+	}, nil
+}
+
+// HTTPContextGenerator is a ContextGenerator backed by an external AI service
+// that accepts FilteredRepoData and replies with a ContextResponse
+type HTTPContextGenerator struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPContextGenerator creates a generator for the AI service at baseURL
+func NewHTTPContextGenerator(baseURL string, timeout time.Duration) *HTTPContextGenerator {
+	return &HTTPContextGenerator{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// GenerateContext makes an HTTP request to the AI service with timeout handling
+func (g *HTTPContextGenerator) GenerateContext(ctx context.Context, request *ContextRequest) (*models.ContextResponse, error) {
 	// Prepare the request body
-	requestBody, err := json.Marshal(request)
+	requestBody, err := json.Marshal(models.FilteredRepoData{
+		Repo:    request.Repo,
+		Query:   request.Query,
+		Context: request.Context,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Determine the AI service endpoint based on mode
-	endpoint := c.getAIEndpoint(mode)
+	endpoint := g.getAIEndpoint(request.Mode)
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(requestBody))
@@ -111,11 +202,11 @@ func (c *ContextServiceImpl) callAIService(ctx context.Context, request models.F
 	req.Header.Set("Accept", "application/json")
 
 	// Make the request with timeout
-	resp, err := c.client.Do(req)
+	resp, err := g.client.Do(req)
 	if err != nil {
 		// Check if this is a timeout error
 		if ctx.Err() == context.DeadlineExceeded {
-			return nil, fmt.Errorf("AI service timeout: request exceeded 30 seconds")
+			return nil, fmt.Errorf("AI service timeout: request exceeded %s", g.client.Timeout)
 		}
 		return nil, fmt.Errorf("AI service request failed: %w", err)
 	}
@@ -142,72 +233,15 @@ func (c *ContextServiceImpl) callAIService(ctx context.Context, request models.F
 	return &aiResponse, nil
 }
 
-// ProcessQueryByProject processes a context query for all repositories in a project
-func (c *ContextServiceImpl) ProcessQueryByProject(ctx context.Context, projectID string, query, mode string) (*models.ContextResponse, error) {
-	// Filter project data for AI service
-	projectData, err := c.FilterProjectData(ctx, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to filter project data: %w", err)
-	}
-
-	// Get project information for context
-	project, err := c.repo.GetProjectWorkspace(ctx, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get project workspace: %w", err)
-	}
-
-	// Prepare request payload for AI service
-	aiRequest := models.FilteredRepoData{
-		Repo:    fmt.Sprintf("Project: %s", project.Name),
-		Query:   query,
-		Context: *projectData,
-	}
-
-	// Send request to AI service
-	response, err := c.callAIService(ctx, aiRequest, mode)
-	if err != nil {
-		return nil, fmt.Errorf("AI service call failed: %w", err)
-	}
-
-	return response, nil
-}
-
-// FilterProjectData filters project data to the most recent items for AI service
-func (c *ContextServiceImpl) FilterProjectData(ctx context.Context, projectID string) (*models.RepoContext, error) {
-	// Get the most recent 10 pull requests across all project repositories
-	prs, err := c.repo.GetRecentPRsByProject(ctx, projectID, 10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get recent pull requests: %w", err)
-	}
-
-	// Get the most recent 10 issues across all project repositories
-	issues, err := c.repo.GetRecentIssuesByProject(ctx, projectID, 10)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get recent issues: %w", err)
-	}
-
-	// Get the most recent 20 commits across all project repositories
-	commits, err := c.repo.GetRecentCommitsByProject(ctx, projectID, 20)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get recent commits: %w", err)
-	}
-
-	return &models.RepoContext{
-		PullRequests: prs,
-		Issues:       issues,
-		Commits:      commits,
-	}, nil
-}
-
 // getAIEndpoint returns the appropriate AI service endpoint based on mode
-func (c *ContextServiceImpl) getAIEndpoint(mode string) string {
+func (g *HTTPContextGenerator) getAIEndpoint(mode string) string {
 	switch mode {
 	case "restore":
-		return c.aiBaseURL + "/context/restore"
+		return g.baseURL + "/context/restore"
 	case "clarify":
-		return c.aiBaseURL + "/context/clarify"
+		return g.baseURL + "/context/clarify"
 	default:
-		return c.aiBaseURL + "/context/query"
+		return g.baseURL + "/context/query"
 	}
 }
 
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// ContextGenerator answers context queries with a language model. Generators
+// are optional: the ContextEngine falls back to its templates when none is
+// configured or the generator fails.
+type ContextGenerator interface {
+	GenerateContext(ctx context.Context, request *ContextRequest) (*models.ContextResponse, error)
+}
+
+// ContextRequest holds everything known when answering a context query
+type ContextRequest struct {
+	Repo      string                // repository or project the query is about
+	Query     string                // the user's query
+	Mode      string                // restore, clarify or query
+	Context   models.RepoContext    // recent pull requests, issues and commits
+	Knowledge []models.SearchResult // knowledge graph entities matching the query
+}
+
+// Limits on how much related activity a templated response refers to
+const (
+	maxContextPullRequests = 5
+	maxContextIssues       = 5
+	maxContextCommits      = 5
+	maxContextKnowledge    = 5
+	maxContextFiles        = 5
+
+	// staleContextPROpen is how long a related pull request can stay open
+	// before restore asks whether it is still needed
+	staleContextPROpen = 14 * 24 * time.Hour
+)
+
+// ContextEngine builds restore and clarify responses in-process from recent
+// repository activity and the knowledge graph, optionally handing the work
+// to a ContextGenerator first
+type ContextEngine struct {
+	generator ContextGenerator
+	logger    Logger
+	now       func() time.Time
+}
+
+// NewContextEngine creates a context engine; generator may be nil
+func NewContextEngine(generator ContextGenerator, logger Logger) *ContextEngine {
+	return &ContextEngine{
+		generator: generator,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Synthesize answers a context query. Restore summarises where related work
+// stands and what is left to do; clarify turns the query into a goal, tasks,
+// open questions and a PR scaffold. Other modes are answered like restore.
+func (e *ContextEngine) Synthesize(ctx context.Context, request *ContextRequest) (*models.ContextResponse, error) {
+	if e.generator != nil {
+		response, err := e.generator.GenerateContext(ctx, request)
+		if err == nil {
+			return response, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		e.logger.Error("Context generator failed, answering from templates", err, map[string]interface{}{
+			"repo": request.Repo,
+			"mode": request.Mode,
+		})
+	}
+
+	related := selectRelatedContext(request)
+	if request.Mode == "clarify" {
+		return e.clarify(request, related), nil
+	}
+	return e.restore(request, related), nil
+}
+
+// relatedContext is the part of a request's activity that bears on its query
+type relatedContext struct {
+	terms        []string
+	matched      bool // false when nothing matched and recent activity stands in
+	pullRequests []models.PullRequest
+	issues       []models.Issue
+	commits      []models.Commit
+	knowledge    []models.SearchResult
+	decisions    []models.SearchResult
+	files        []string
+}
+
+// selectRelatedContext keeps the activity that mentions the query's terms,
+// most mentions first, or the most recent activity when none does
+func selectRelatedContext(request *ContextRequest) *relatedContext {
+	related := &relatedContext{terms: contextQueryTerms(request.Query)}
+
+	related.pullRequests = rankByTerms(request.Context.PullRequests, related.terms, maxContextPullRequests, func(pr models.PullRequest) string {
+		return pr.Title + " " + pr.Body + " " + strings.Join(pr.Labels, " ") + " " + strings.Join(pr.FilesChanged, " ")
+	})
+	related.issues = rankByTerms(request.Context.Issues, related.terms, maxContextIssues, func(issue models.Issue) string {
+		return issue.Title + " " + issue.Body + " " + strings.Join(issue.Labels, " ")
+	})
+	related.commits = rankByTerms(request.Context.Commits, related.terms, maxContextCommits, func(commit models.Commit) string {
+		return commit.Message + " " + strings.Join(commit.FilesChanged, " ")
+	})
+	related.matched = len(related.pullRequests)+len(related.issues)+len(related.commits) > 0
+
+	if !related.matched {
+		related.pullRequests = firstN(request.Context.PullRequests, 3)
+		related.issues = firstN(request.Context.Issues, 3)
+		related.commits = firstN(request.Context.Commits, maxContextCommits)
+	}
+
+	// Knowledge results were already searched for the query
+	related.knowledge = firstN(request.Knowledge, maxContextKnowledge)
+	for _, result := range related.knowledge {
+		if result.Entity.EntityType == "decision" {
+			related.decisions = append(related.decisions, result)
+		}
+	}
+
+	// The files the related changes touch most often
+	counts := make(map[string]int)
+	for _, pr := range related.pullRequests {
+		for _, file := range pr.FilesChanged {
+			counts[file]++
+		}
+	}
+	for _, commit := range related.commits {
+		for _, file := range commit.FilesChanged {
+			counts[file]++
+		}
+	}
+	for file := range counts {
+		related.files = append(related.files, file)
+	}
+	sort.Slice(related.files, func(i, j int) bool {
+		if counts[related.files[i]] != counts[related.files[j]] {
+			return counts[related.files[i]] > counts[related.files[j]]
+		}
+		return related.files[i] < related.files[j]
+	})
+	related.files = firstN(related.files, maxContextFiles)
+
+	return related
+}
+
+// restore builds a response describing where work related to the query stands
+func (e *ContextEngine) restore(request *ContextRequest, related *relatedContext) *models.ContextResponse {
+	response := &models.ContextResponse{
+		ClarifiedGoal: fmt.Sprintf("Resume work on %s in %s", lowerFirst(contextGoal(request.Query)), request.Repo),
+		Tasks:         []models.Task{},
+		Questions:     []string{},
+	}
+
+	var openPR *models.PullRequest
+	for i, pr := range related.pullRequests {
+		if pr.State != "open" {
+			continue
+		}
+		if openPR == nil {
+			openPR = &related.pullRequests[i]
+		}
+		response.Tasks = append(response.Tasks, models.Task{
+			Title:      fmt.Sprintf("Finish PR #%d: %s", pr.Number, pr.Title),
+			Acceptance: fmt.Sprintf("PR #%d is reviewed and merged", pr.Number),
+		})
+		if e.now().Sub(pr.CreatedAt) > staleContextPROpen {
+			response.Questions = append(response.Questions, fmt.Sprintf("PR #%d has been open since %s; is it still needed?", pr.Number, pr.CreatedAt.Format("2006-01-02")))
+		}
+	}
+	for _, issue := range related.issues {
+		if issue.State == "open" {
+			response.Tasks = append(response.Tasks, models.Task{
+				Title:      "Resolve issue: " + issue.Title,
+				Acceptance: "The issue is closed by a change that addresses it",
+			})
+		}
+	}
+	for _, decision := range related.decisions {
+		response.Tasks = append(response.Tasks, models.Task{
+			Title:      "Follow decision: " + decision.Entity.Title,
+			Acceptance: "The change is consistent with the recorded decision",
+		})
+	}
+	if len(response.Tasks) == 0 {
+		target := request.Repo
+		if len(related.files) > 0 {
+			target = strings.Join(firstN(related.files, 3), ", ")
+		}
+		response.Tasks = append(response.Tasks, models.Task{
+			Title:      "Review the latest changes to " + target,
+			Acceptance: "The next step for the work is identified",
+		})
+	}
+
+	if !related.matched {
+		response.Questions = append(response.Questions, fmt.Sprintf("No recent pull requests, issues or commits mention %q; is the work tracked under another name?", request.Query))
+	}
+
+	// Work already under way continues on its pull request
+	if openPR == nil {
+		response.PRScaffold = contextPRScaffold(request, related, response.Tasks)
+	}
+
+	response.Context = contextSummary(request, related)
+	return response
+}
+
+// clarify builds a response that turns the query into concrete, reviewable work
+func (e *ContextEngine) clarify(request *ContextRequest, related *relatedContext) *models.ContextResponse {
+	goal := contextGoal(request.Query)
+	response := &models.ContextResponse{
+		ClarifiedGoal: goal,
+		Tasks:         []models.Task{},
+		Questions:     []string{},
+	}
+	if len(related.files) > 0 && related.matched {
+		response.ClarifiedGoal = fmt.Sprintf("%s, most likely in %s", goal, strings.Join(firstN(related.files, 3), ", "))
+	}
+
+	if related.matched && len(related.files) > 0 {
+		for _, file := range firstN(related.files, 3) {
+			response.Tasks = append(response.Tasks, models.Task{
+				Title:      "Update " + file,
+				Acceptance: fmt.Sprintf("%s supports: %s", file, lowerFirst(goal)),
+			})
+		}
+	} else {
+		response.Tasks = append(response.Tasks, models.Task{
+			Title:      "Identify the code that has to change to " + lowerFirst(goal),
+			Acceptance: "The files and components to change are listed",
+		})
+	}
+
+	var openIssue bool
+	for _, issue := range related.issues {
+		if related.matched && issue.State == "open" {
+			openIssue = true
+			response.Tasks = append(response.Tasks, models.Task{
+				Title:      "Address issue: " + issue.Title,
+				Acceptance: "The issue can be closed by this change",
+			})
+		}
+	}
+	for _, decision := range related.decisions {
+		response.Tasks = append(response.Tasks, models.Task{
+			Title:      "Check the change against decision: " + decision.Entity.Title,
+			Acceptance: "The change follows the decision or the decision is updated",
+		})
+	}
+	response.Tasks = append(response.Tasks, models.Task{
+		Title:      "Add tests for the new behaviour",
+		Acceptance: "Tests cover " + lowerFirst(goal) + " and pass in CI",
+	})
+
+	response.Questions = append(response.Questions, "What should a reviewer check to confirm the goal is met?")
+	if !openIssue {
+		response.Questions = append(response.Questions, "Is there an issue tracking this work that the change should link to?")
+	}
+	if !related.matched {
+		response.Questions = append(response.Questions, fmt.Sprintf("Which part of %s does this apply to?", request.Repo))
+	}
+	if len(related.decisions) > 0 {
+		response.Questions = append(response.Questions, fmt.Sprintf("Does this change affect the decision %q?", related.decisions[0].Entity.Title))
+	}
+	if related.matched {
+		for _, pr := range related.pullRequests {
+			if pr.State == "open" {
+				response.Questions = append(response.Questions, fmt.Sprintf("Should this build on PR #%d (%s) or be a separate change?", pr.Number, pr.Title))
+				break
+			}
+		}
+	}
+
+	response.PRScaffold = contextPRScaffold(request, related, response.Tasks)
+	response.Context = contextSummary(request, related)
+	return response
+}
+
+// contextPRScaffold drafts the branch, title and description of a PR for the query
+func contextPRScaffold(request *ContextRequest, related *relatedContext, tasks []models.Task) *models.PRScaffold {
+	goal := contextGoal(request.Query)
+
+	prefix := "feature/"
+	for _, term := range related.terms {
+		switch term {
+		case "fix", "bug", "broken", "crash", "error", "regression":
+			prefix = "fix/"
+		case "docs", "document", "documentation", "readme":
+			if prefix == "feature/" {
+				prefix = "docs/"
+			}
+		}
+	}
+	slug := strings.Join(firstN(related.terms, 5), "-")
+	if slug == "" {
+		slug = "update"
+	}
+
+	var body strings.Builder
+	body.WriteString("## Summary\n\n" + goal + "\n\n## Tasks\n\n")
+	for _, task := range tasks {
+		body.WriteString(fmt.Sprintf("- [ ] %s\n", task.Title))
+	}
+	if related.matched && (len(related.pullRequests) > 0 || len(related.issues) > 0) {
+		body.WriteString("\n## Related\n\n")
+		for _, pr := range related.pullRequests {
+			body.WriteString(fmt.Sprintf("- #%d %s\n", pr.Number, pr.Title))
+		}
+		for _, issue := range related.issues {
+			body.WriteString(fmt.Sprintf("- %s\n", issue.Title))
+		}
+	}
+	if len(related.decisions) > 0 {
+		body.WriteString("\n## Decisions\n\n")
+		for _, decision := range related.decisions {
+			body.WriteString(fmt.Sprintf("- %s\n", decision.Entity.Title))
+		}
+	}
+
+	return &models.PRScaffold{
+		Branch: prefix + slug,
+		Title:  goal,
+		Body:   body.String(),
+	}
+}
+
+// contextSummary lists what a templated response was built from
+func contextSummary(request *ContextRequest, related *relatedContext) map[string]interface{} {
+	pullRequests := make([]map[string]interface{}, 0, len(related.pullRequests))
+	for _, pr := range related.pullRequests {
+		pullRequests = append(pullRequests, map[string]interface{}{"number": pr.Number, "title": pr.Title, "state": pr.State})
+	}
+	issues := make([]map[string]interface{}, 0, len(related.issues))
+	for _, issue := range related.issues {
+		issues = append(issues, map[string]interface{}{"title": issue.Title, "state": issue.State})
+	}
+	commits := make([]map[string]interface{}, 0, len(related.commits))
+	for _, commit := range related.commits {
+		message, _, _ := strings.Cut(commit.Message, "\n")
+		commits = append(commits, map[string]interface{}{"sha": commit.SHA, "message": message})
+	}
+	knowledge := make([]map[string]interface{}, 0, len(related.knowledge))
+	for _, result := range related.knowledge {
+		knowledge = append(knowledge, map[string]interface{}{"id": result.Entity.ID, "type": result.Entity.EntityType, "title": result.Entity.Title})
+	}
+
+	return map[string]interface{}{
+		"repo":          request.Repo,
+		"mode":          request.Mode,
+		"generated_by":  "templates",
+		"matched_query": related.matched,
+		"pull_requests": pullRequests,
+		"issues":        issues,
+		"commits":       commits,
+		"knowledge":     knowledge,
+		"files":         append([]string{}, related.files...),
+	}
+}
+
+// contextStopWords are left out of query terms
+var contextStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "are": true, "was": true,
+	"were": true, "been": true, "have": true, "has": true, "had": true, "does": true,
+	"did": true, "will": true, "would": true, "could": true, "should": true, "this": true,
+	"that": true, "what": true, "where": true, "when": true, "how": true, "why": true,
+	"from": true, "into": true, "need": true, "want": true, "can": true, "our": true,
+	"add": true, "make": true, "get": true,
+}
+
+// contextQueryTerms returns the distinct words of a query worth matching on
+func contextQueryTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if len(word) > 2 && !contextStopWords[word] && !seen[word] {
+			seen[word] = true
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// rankByTerms keeps the items whose text mentions the most query terms, up
+// to limit; ties keep their original, most recent first, order
+func rankByTerms[T any](items []T, terms []string, limit int, text func(T) string) []T {
+	type scoredItem struct {
+		item  T
+		score int
+	}
+	var scored []scoredItem
+	for _, item := range items {
+		haystack := strings.ToLower(text(item))
+		score := 0
+		for _, term := range terms {
+			if strings.Contains(haystack, term) {
+				score++
+			}
+		}
+		if score > 0 {
+			scored = append(scored, scoredItem{item: item, score: score})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	var ranked []T
+	for _, s := range firstN(scored, limit) {
+		ranked = append(ranked, s.item)
+	}
+	return ranked
+}
+
+// contextGoal restates a query as a goal sentence
+func contextGoal(query string) string {
+	goal := strings.TrimRight(strings.TrimSpace(query), ".?! ")
+	if goal == "" {
+		return "Continue the current work"
+	}
+	runes := []rune(goal)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func lowerFirst(text string) string {
+	runes := []rune(text)
+	if len(runes) > 1 && unicode.IsUpper(runes[1]) {
+		return text // an acronym such as JWT
+	}
+	if len(runes) > 0 {
+		runes[0] = unicode.ToLower(runes[0])
+	}
+	return string(runes)
+}
+
+func firstN[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
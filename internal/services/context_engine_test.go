@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contextRepositoryStub serves one project's recent activity and knowledge
+type contextRepositoryStub struct {
+	RepositoryStore
+	context   models.RepoContext
+	knowledge []models.SearchResult
+	queries   []string
+}
+
+func (r *contextRepositoryStub) GetRecentPRsByProject(ctx context.Context, projectID string, limit int) ([]models.PullRequest, error) {
+	return r.context.PullRequests, nil
+}
+
+func (r *contextRepositoryStub) GetRecentIssuesByProject(ctx context.Context, projectID string, limit int) ([]models.Issue, error) {
+	return r.context.Issues, nil
+}
+
+func (r *contextRepositoryStub) GetRecentCommitsByProject(ctx context.Context, projectID string, limit int) ([]models.Commit, error) {
+	return r.context.Commits, nil
+}
+
+func (r *contextRepositoryStub) GetProjectWorkspace(ctx context.Context, projectID string) (*models.ProjectWorkspace, error) {
+	return &models.ProjectWorkspace{ID: projectID, Name: "Billing"}, nil
+}
+
+func (r *contextRepositoryStub) SearchKnowledgeEntitiesByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	r.queries = append(r.queries, query.Query)
+	return r.knowledge, nil
+}
+
+// failingContextGenerator stands in for an unreachable AI service
+type failingContextGenerator struct {
+	calls int
+}
+
+func (g *failingContextGenerator) GenerateContext(ctx context.Context, request *ContextRequest) (*models.ContextResponse, error) {
+	g.calls++
+	return nil, errors.New("AI service request failed: connection refused")
+}
+
+func billingActivity(now time.Time) models.RepoContext {
+	return models.RepoContext{
+		PullRequests: []models.PullRequest{
+			{Number: 12, Title: "Retry failed invoice webhooks", State: "open", CreatedAt: now.AddDate(0, 0, -20), FilesChanged: models.StringList{"billing/webhooks.go", "billing/retry.go"}},
+			{Number: 11, Title: "Update README", State: "merged", CreatedAt: now.AddDate(0, 0, -2), FilesChanged: models.StringList{"README.md"}},
+		},
+		Issues: []models.Issue{
+			{Title: "Invoice webhooks are dropped when Stripe times out", State: "open"},
+			{Title: "Dark mode", State: "open"},
+		},
+		Commits: []models.Commit{
+			{SHA: "abc123", Message: "Log webhook delivery failures\n\nDetails", FilesChanged: models.StringList{"billing/webhooks.go"}},
+			{SHA: "def456", Message: "Bump dependencies", FilesChanged: models.StringList{"go.mod"}},
+		},
+	}
+}
+
+func TestContextEngine_Restore(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	engine := NewContextEngine(nil, &SimpleLogger{})
+	engine.now = func() time.Time { return now }
+
+	response, err := engine.Synthesize(context.Background(), &ContextRequest{
+		Repo:    "acme/billing",
+		Query:   "Invoice webhooks",
+		Mode:    "restore",
+		Context: billingActivity(now),
+		Knowledge: []models.SearchResult{
+			{Entity: models.KnowledgeEntity{ID: "k1", EntityType: "decision", Title: "Deliver webhooks at least once"}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Resume work on invoice webhooks in acme/billing", response.ClarifiedGoal)
+	assert.Equal(t, []models.Task{
+		{Title: "Finish PR #12: Retry failed invoice webhooks", Acceptance: "PR #12 is reviewed and merged"},
+		{Title: "Resolve issue: Invoice webhooks are dropped when Stripe times out", Acceptance: "The issue is closed by a change that addresses it"},
+		{Title: "Follow decision: Deliver webhooks at least once", Acceptance: "The change is consistent with the recorded decision"},
+	}, response.Tasks)
+	assert.Equal(t, []string{"PR #12 has been open since 2026-02-10; is it still needed?"}, response.Questions)
+	assert.Nil(t, response.PRScaffold, "work continues on the open pull request")
+
+	assert.Equal(t, "templates", response.Context["generated_by"])
+	assert.Equal(t, true, response.Context["matched_query"])
+	assert.Equal(t, []string{"billing/webhooks.go", "billing/retry.go"}, response.Context["files"])
+	assert.Len(t, response.Context["commits"], 1)
+}
+
+func TestContextEngine_Clarify(t *testing.T) {
+	now := time.Now()
+	engine := NewContextEngine(nil, &SimpleLogger{})
+
+	response, err := engine.Synthesize(context.Background(), &ContextRequest{
+		Repo:    "acme/billing",
+		Query:   "fix dropped invoice webhooks?",
+		Mode:    "clarify",
+		Context: billingActivity(now),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Fix dropped invoice webhooks, most likely in billing/webhooks.go, billing/retry.go", response.ClarifiedGoal)
+	require.NotEmpty(t, response.Tasks)
+	assert.Equal(t, "Update billing/webhooks.go", response.Tasks[0].Title)
+	assert.Contains(t, response.Tasks, models.Task{Title: "Address issue: Invoice webhooks are dropped when Stripe times out", Acceptance: "The issue can be closed by this change"})
+	assert.Contains(t, response.Questions, "Should this build on PR #12 (Retry failed invoice webhooks) or be a separate change?")
+	assert.NotContains(t, response.Questions, "Is there an issue tracking this work that the change should link to?")
+
+	require.NotNil(t, response.PRScaffold)
+	assert.Equal(t, "fix/fix-dropped-invoice-webhooks", response.PRScaffold.Branch)
+	assert.Equal(t, "Fix dropped invoice webhooks", response.PRScaffold.Title)
+	assert.Contains(t, response.PRScaffold.Body, "- [ ] Update billing/retry.go")
+	assert.Contains(t, response.PRScaffold.Body, "- #12 Retry failed invoice webhooks")
+	assert.NotContains(t, response.PRScaffold.Body, "Dark mode")
+}
+
+func TestContextEngine_UnmatchedQueryFallsBackToRecentActivity(t *testing.T) {
+	engine := NewContextEngine(nil, &SimpleLogger{})
+
+	response, err := engine.Synthesize(context.Background(), &ContextRequest{
+		Repo:    "acme/billing",
+		Query:   "tax exports",
+		Mode:    "clarify",
+		Context: billingActivity(time.Now()),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Tax exports", response.ClarifiedGoal)
+	assert.Equal(t, "Identify the code that has to change to tax exports", response.Tasks[0].Title)
+	assert.Contains(t, response.Questions, "Which part of acme/billing does this apply to?")
+	assert.Contains(t, response.Questions, "Is there an issue tracking this work that the change should link to?")
+	assert.Equal(t, "feature/tax-exports", response.PRScaffold.Branch)
+	assert.Equal(t, false, response.Context["matched_query"])
+}
+
+func TestContextEngine_FallsBackWhenGeneratorFails(t *testing.T) {
+	generator := &failingContextGenerator{}
+	engine := NewContextEngine(generator, &SimpleLogger{})
+
+	response, err := engine.Synthesize(context.Background(), &ContextRequest{Repo: "acme/billing", Query: "invoice webhooks", Mode: "query", Context: billingActivity(time.Now())})
+	require.NoError(t, err)
+	assert.Equal(t, 1, generator.calls)
+	assert.Equal(t, "Resume work on invoice webhooks in acme/billing", response.ClarifiedGoal)
+
+	// A cancelled request is not answered
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = engine.Synthesize(ctx, &ContextRequest{Repo: "acme/billing", Query: "invoice webhooks"})
+	assert.Error(t, err)
+}
+
+func TestContextService_ProcessQueryByProjectWithoutAIService(t *testing.T) {
+	repo := &contextRepositoryStub{
+		context: billingActivity(time.Now()),
+		knowledge: []models.SearchResult{
+			{Entity: models.KnowledgeEntity{ID: "k1", EntityType: "decision", Title: "Deliver webhooks at least once"}},
+		},
+	}
+	service := NewContextService(repo, nil, nil)
+
+	response, err := service.ProcessQueryByProject(context.Background(), "p1", "invoice webhooks", "clarify")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"invoice webhooks"}, repo.queries)
+	assert.Contains(t, response.Tasks, models.Task{Title: "Check the change against decision: Deliver webhooks at least once", Acceptance: "The change follows the decision or the decision is updated"})
+	assert.Contains(t, response.Questions, `Does this change affect the decision "Deliver webhooks at least once"?`)
+	assert.Equal(t, "Project: Billing", response.Context["repo"])
+}
@@ -59,10 +59,13 @@ type DecisionRecord struct {
 	Alternatives   []string  `json:"alternatives"`
 	Consequences   []string  `json:"consequences"`
 	Status         string    `json:"status"` // active, superseded, deprecated
+	Supersedes     []string  `json:"supersedes,omitempty"` // titles or IDs of the decisions it replaces
+	Authoritative  bool      `json:"authoritative,omitempty"` // written down on purpose, such as an ADR
 	PlatformSource string    `json:"platform_source"`
 	SourceEventIDs []string  `json:"source_event_ids"`
 	Participants   []string  `json:"participants"`
 	ProjectID      string    `json:"project_id"` // Project scoping
+	RecordedBy     string    `json:"recorded_by,omitempty"` // User who recorded it by hand
 	CreatedAt      time.Time `json:"created_at"`
 }
 
@@ -106,6 +109,7 @@ type FileContextHistory struct {
 	Contributors      []string               `json:"contributors"`
 	PlatformSources   map[string]interface{} `json:"platform_sources"`
 	ProjectID         string                 `json:"project_id"` // Project scoping
+	RecordedBy        string                 `json:"recorded_by,omitempty"` // User who recorded it by hand
 	CreatedAt         time.Time              `json:"created_at"`
 }
 
@@ -214,6 +218,8 @@ func (cp *ContextProcessor) processBatches(ctx context.Context, events []Normali
 
 	// Extract relationships from all processed entities
 	relationships := cp.extractRelationships(totalResult)
+	relationships = append(relationships, cp.extractTicketRelationships(events, totalResult)...)
+	relationships = append(relationships, cp.extractADRRelationships(events, totalResult)...)
 	totalResult.Relationships = relationships
 
 	cp.logger.Info("Context processing completed", map[string]interface{}{
@@ -280,6 +286,9 @@ func (cp *ContextProcessor) processSingleBatch(ctx context.Context, events []Nor
 		Errors:              []ProcessingError{},
 	}
 
+	// Decision records are already structured and need no extraction
+	events = cp.processDecisionRecordEvents(events, result)
+
 	// Group related events (threads, file references, etc.)
 	eventGroups := cp.groupRelatedEvents(events)
 
@@ -310,7 +319,7 @@ func (cp *ContextProcessor) processEventGroupWithErrorHandling(ctx context.Conte
 	}
 
 	// Generate discussion summaries with error handling
-	if len(events) > 1 {
+	if len(events) > 1 || isTicketThread(events) {
 		if summary, err := cp.generateSummaryWithErrorHandling(ctx, events); err != nil {
 			processingErrors = append(processingErrors, fmt.Errorf("summary generation failed: %w", err))
 			cp.addProcessingError(result, events, "summary_generation", err)
@@ -353,7 +362,11 @@ func (cp *ContextProcessor) extractDecisionsWithErrorHandling(ctx context.Contex
 		}
 	}()
 
-	return cp.extractDecisions(ctx, events)
+	decisions, err := cp.extractDecisions(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	return cp.mergeAIDecisions(ctx, events, decisions), nil
 }
 
 func (cp *ContextProcessor) generateSummaryWithErrorHandling(ctx context.Context, events []NormalizedEvent) (*DiscussionSummary, error) {
@@ -365,7 +378,11 @@ func (cp *ContextProcessor) generateSummaryWithErrorHandling(ctx context.Context
 		}
 	}()
 
-	return cp.generateSummary(ctx, events)
+	summary, err := cp.generateSummary(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	return cp.mergeAISummary(ctx, events, summary), nil
 }
 
 func (cp *ContextProcessor) buildFeatureContextsWithErrorHandling(ctx context.Context, events []NormalizedEvent) ([]FeatureContext, error) {
@@ -377,7 +394,11 @@ func (cp *ContextProcessor) buildFeatureContextsWithErrorHandling(ctx context.Co
 		}
 	}()
 
-	return cp.buildFeatureContexts(ctx, events)
+	features, err := cp.buildFeatureContexts(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	return cp.mergeAIFeatures(ctx, events, features), nil
 }
 
 func (cp *ContextProcessor) buildFileContextsWithErrorHandling(ctx context.Context, events []NormalizedEvent) ([]FileContextHistory, error) {
@@ -389,7 +410,11 @@ func (cp *ContextProcessor) buildFileContextsWithErrorHandling(ctx context.Conte
 		}
 	}()
 
-	return cp.buildFileContexts(ctx, events)
+	fileContexts, err := cp.buildFileContexts(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	return cp.mergeAIFileContexts(ctx, events, fileContexts), nil
 }
 
 // mergeBatchResults merges results from a batch into the total result
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Architecture decision records are decisions written down on purpose, so
+// they skip extraction: each record becomes one authoritative decision whose
+// status, context and consequences come straight from its markdown. Records
+// keep one ID across edits, listing the commits that touched them as sources.
+// A record is linked to the files and features it mentions, to the file that
+// holds it and to the records it supersedes, which are named by ADR ID.
+
+// processDecisionRecordEvents turns decision record events into decisions and
+// returns the other events, which still need extraction
+func (cp *ContextProcessor) processDecisionRecordEvents(events []NormalizedEvent, result *ProcessingResult) []NormalizedEvent {
+	remaining := make([]NormalizedEvent, 0, len(events))
+	for _, event := range events {
+		if event.EventType != EventTypeDecisionRecord {
+			remaining = append(remaining, event)
+			continue
+		}
+
+		decision, err := decisionFromADREvent(event)
+		if err != nil {
+			cp.addProcessingError(result, []NormalizedEvent{event}, "adr_parsing_failed", err)
+			continue
+		}
+		result.DecisionRecords = append(result.DecisionRecords, decision)
+	}
+	return remaining
+}
+
+// decisionFromADREvent parses the record an event carries into a decision
+func decisionFromADREvent(event NormalizedEvent) (DecisionRecord, error) {
+	adrPath := adrEventPath(event)
+	adr, err := ParseADR(adrPath, event.Content)
+	if err != nil {
+		return DecisionRecord{}, err
+	}
+
+	status := adr.Status
+	if status == "accepted" {
+		status = "active"
+	}
+
+	sources := metadataStringList(event.Metadata["revisions"])
+	if len(sources) == 0 {
+		sources = []string{event.PlatformID}
+	}
+
+	createdAt := event.Timestamp
+	if first, ok := event.Metadata["first_committed_at"].(string); ok {
+		if parsed, err := time.Parse(time.RFC3339, first); err == nil {
+			createdAt = parsed
+		}
+	}
+
+	return DecisionRecord{
+		ID:             adrDecisionID(event, adrPath),
+		Title:          adrDecisionTitle(adr),
+		Decision:       adr.Decision,
+		Rationale:      adr.Context,
+		Alternatives:   adr.Options,
+		Consequences:   adr.Consequences,
+		Status:         status,
+		Supersedes:     adr.Supersedes,
+		Authoritative:  true,
+		PlatformSource: event.Platform,
+		SourceEventIDs: sources,
+		Participants:   appendUniqueStrings(appendUniqueStrings(adr.Deciders, metadataStringList(event.Metadata["contributors"])...), event.Author),
+		CreatedAt:      createdAt,
+	}, nil
+}
+
+// extractADRRelationships links decision records to the files and features
+// they mention, to the files holding them and to the records they supersede
+func (cp *ContextProcessor) extractADRRelationships(events []NormalizedEvent, result *ProcessingResult) []Relationship {
+	var relationships []Relationship
+	seen := make(map[string]bool)
+	add := func(relationship Relationship) {
+		if !seen[relationship.ID] {
+			seen[relationship.ID] = true
+			relationships = append(relationships, relationship)
+		}
+	}
+
+	for _, event := range events {
+		if event.EventType != EventTypeDecisionRecord {
+			continue
+		}
+		adrPath := adrEventPath(event)
+		adr, err := ParseADR(adrPath, event.Content)
+		if err != nil {
+			continue
+		}
+		title := adrDecisionTitle(adr)
+		relationship := func(targetType, targetID, relationshipType string, strength float64) Relationship {
+			return Relationship{
+				ID:         fmt.Sprintf("adr-%s-%s-%s", adr.ID, relationshipType, targetID),
+				SourceType: "decision",
+				SourceID:   title,
+				TargetType: targetType,
+				TargetID:   targetID,
+				Type:       relationshipType,
+				Strength:   strength,
+				Metadata: map[string]interface{}{
+					"adr_id":   adr.ID,
+					"adr_path": adrPath,
+					"event_id": event.PlatformID,
+					"platform": event.Platform,
+				},
+				CreatedAt: time.Now(),
+			}
+		}
+
+		add(relationship("file_context", adrPath, "documented_in", 1.0))
+		for _, filePath := range cp.deduplicateStrings(event.FileRefs) {
+			if filePath != adrPath {
+				add(relationship("file_context", filePath, "relates_to", 0.9))
+			}
+		}
+
+		features := append([]string{}, event.FeatureRefs...)
+		text := strings.ToLower(adr.Title + "\n" + event.Content)
+		for _, feature := range result.FeatureContexts {
+			if feature.FeatureName != "" && strings.Contains(text, strings.ToLower(feature.FeatureName)) {
+				features = append(features, feature.FeatureName)
+			}
+		}
+		for _, feature := range cp.deduplicateStrings(features) {
+			add(relationship("feature", feature, "relates_to", 0.8))
+		}
+
+		for _, superseded := range adr.Supersedes {
+			add(relationship("decision", superseded, "supersedes", 1.0))
+		}
+		for _, successor := range adr.SupersededBy {
+			successorRelationship := relationship("decision", adr.ID, "supersedes", 1.0)
+			successorRelationship.ID = fmt.Sprintf("adr-%s-supersedes-%s", successor, adr.ID)
+			successorRelationship.SourceID = successor
+			add(successorRelationship)
+		}
+	}
+
+	return relationships
+}
+
+// adrEventPath returns the repository path of the record an event carries
+func adrEventPath(event NormalizedEvent) string {
+	if adrPath, ok := event.Metadata["adr_path"].(string); ok && adrPath != "" {
+		return adrPath
+	}
+	return strings.TrimPrefix(event.PlatformID, "adr-")
+}
+
+// adrDecisionID names the decision a record becomes. Repository names are
+// only display names, so the repository's source, such as its remote URL, is
+// hashed into the ID to keep records of same-named repositories apart.
+func adrDecisionID(event NormalizedEvent, adrPath string) string {
+	repository, _ := event.Metadata["repository"].(string)
+	if source, _ := event.Metadata["repository_source"].(string); source != "" {
+		sum := sha256.Sum256([]byte(source))
+		repository = fmt.Sprintf("%s-%s", repository, hex.EncodeToString(sum[:6]))
+	}
+	return fmt.Sprintf("decision-adr-%s-%s", repository, strings.ReplaceAll(adrPath, "/", "-"))
+}
+
+// adrDecisionTitle titles numbered records by ID so that supersede links,
+// which name records by ID, find them
+func adrDecisionTitle(adr *ADR) string {
+	if adr.Number > 0 {
+		return fmt.Sprintf("%s: %s", adr.ID, adr.Title)
+	}
+	return adr.Title
+}
+
+// metadataStringList reads a string list from event metadata, whether built
+// in process or decoded from JSON
+func metadataStringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
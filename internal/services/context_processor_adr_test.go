@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextProcessor_TurnsADRsIntoAuthoritativeDecisions(t *testing.T) {
+	processor := NewContextProcessor(nil, &SimpleLogger{})
+	now := time.Now()
+
+	events := []NormalizedEvent{
+		{
+			PlatformID: "adr-docs/adr/0005-queue-webhooks.md",
+			EventType:  EventTypeDecisionRecord,
+			Timestamp:  now,
+			Author:     "Bob",
+			Title:      "Queue webhooks",
+			Content: "# 5. Queue webhooks\n\n## Status\n\nAccepted\n\nSupersedes [2. Retry webhooks](0002-retry-webhooks.md)\n\n" +
+				"## Context\n\nRetries block webhook delivery.\n\n## Decision\n\nWe will queue deliveries.\n\n## Consequences\n\n- Deliveries are ordered\n",
+			FileRefs:    []string{"internal/webhooks/queue.go"},
+			FeatureRefs: []string{"webhook delivery"},
+			Metadata: map[string]interface{}{
+				"adr_path":           "docs/adr/0005-queue-webhooks.md",
+				"repository":         "payments",
+				"revisions":          []interface{}{"commit-aaa", "commit-bbb"},
+				"contributors":       []string{"Alice", "Bob"},
+				"first_committed_at": "2024-05-01T10:00:00Z",
+			},
+			Platform: "gitlocal",
+		},
+		{
+			PlatformID: "adr-docs/adr/README.md",
+			EventType:  EventTypeDecisionRecord,
+			Timestamp:  now,
+			Content:    "This directory holds our decisions.",
+			Platform:   "gitlocal",
+		},
+	}
+
+	result, err := processor.ProcessEvents(context.Background(), events)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ProcessedEvents)
+
+	require.Len(t, result.DecisionRecords, 1)
+	decision := result.DecisionRecords[0]
+	assert.Equal(t, "decision-adr-payments-docs-adr-0005-queue-webhooks.md", decision.ID)
+	assert.Equal(t, "ADR-0005: Queue webhooks", decision.Title)
+	assert.Equal(t, "We will queue deliveries.", decision.Decision)
+	assert.Equal(t, "Retries block webhook delivery.", decision.Rationale)
+	assert.Equal(t, []string{"Deliveries are ordered"}, decision.Consequences)
+	assert.Equal(t, "active", decision.Status)
+	assert.Equal(t, []string{"ADR-0002"}, decision.Supersedes)
+	assert.True(t, decision.Authoritative)
+	assert.Equal(t, []string{"commit-aaa", "commit-bbb"}, decision.SourceEventIDs)
+	assert.Equal(t, []string{"Alice", "Bob"}, decision.Participants)
+	assert.True(t, decision.CreatedAt.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)))
+
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "adr-docs/adr/README.md", result.Errors[0].EventID)
+
+	byID := make(map[string]Relationship)
+	for _, relationship := range result.Relationships {
+		byID[relationship.ID] = relationship
+	}
+
+	supersedes, ok := byID["adr-ADR-0005-supersedes-ADR-0002"]
+	require.True(t, ok, "the record should supersede ADR-0002")
+	assert.Equal(t, "ADR-0005: Queue webhooks", supersedes.SourceID)
+	assert.Equal(t, "decision", supersedes.TargetType)
+
+	assert.Equal(t, "file_context", byID["adr-ADR-0005-relates_to-internal/webhooks/queue.go"].TargetType)
+	assert.Equal(t, "feature", byID["adr-ADR-0005-relates_to-webhook delivery"].TargetType)
+	assert.Equal(t, "docs/adr/0005-queue-webhooks.md", byID["adr-ADR-0005-documented_in-docs/adr/0005-queue-webhooks.md"].TargetID)
+}
+
+func TestADRDecisionID_KeepsSameNamedRepositoriesApart(t *testing.T) {
+	event := func(source string) NormalizedEvent {
+		return NormalizedEvent{Metadata: map[string]interface{}{"repository": "docs", "repository_source": source}}
+	}
+
+	first := adrDecisionID(event("git@gitlab.example.com:payments/docs.git"), "adr/0001-queue.md")
+	second := adrDecisionID(event("git@gitlab.example.com:billing/docs.git"), "adr/0001-queue.md")
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, first, adrDecisionID(event("git@gitlab.example.com:payments/docs.git"), "adr/0001-queue.md"))
+	assert.Regexp(t, `^decision-adr-docs-[0-9a-f]{12}-adr-0001-queue\.md$`, first)
+}
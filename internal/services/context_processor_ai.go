@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"strings"
+)
+
+// The processor always runs its pattern-based extractors, then merges in what
+// the AI service finds. AI failures are logged and the pattern-based results
+// are kept, so an unreachable model never costs a group its knowledge.
+
+// mergeAIDecisions adds the AI service's decisions to the extracted ones.
+// Decisions citing the same source event are merged, preferring the AI's text.
+func (cp *ContextProcessor) mergeAIDecisions(ctx context.Context, events []NormalizedEvent, decisions []DecisionRecord) []DecisionRecord {
+	if cp.aiService == nil {
+		return decisions
+	}
+	extracted, err := cp.aiService.ExtractDecisions(ctx, events)
+	if err != nil {
+		cp.logAIFailure("decision extraction", events, err)
+		return decisions
+	}
+
+	for _, ai := range extracted {
+		index := -1
+		for i := range decisions {
+			if sharesString(decisions[i].SourceEventIDs, ai.SourceEventIDs) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			decisions = append(decisions, ai)
+			continue
+		}
+
+		decision := &decisions[index]
+		decision.Title = preferNonEmpty(ai.Title, decision.Title)
+		decision.Decision = preferNonEmpty(ai.Decision, decision.Decision)
+		decision.Rationale = preferNonEmpty(ai.Rationale, decision.Rationale)
+		decision.Alternatives = cp.deduplicateStrings(append(decision.Alternatives, ai.Alternatives...))
+		decision.Consequences = cp.deduplicateStrings(append(decision.Consequences, ai.Consequences...))
+		decision.SourceEventIDs = cp.deduplicateStrings(append(decision.SourceEventIDs, ai.SourceEventIDs...))
+		decision.Participants = cp.deduplicateStrings(append(decision.Participants, ai.Participants...))
+	}
+	return decisions
+}
+
+// mergeAISummary adds the AI service's summary of the events to the generated one
+func (cp *ContextProcessor) mergeAISummary(ctx context.Context, events []NormalizedEvent, summary *DiscussionSummary) *DiscussionSummary {
+	if cp.aiService == nil || summary == nil {
+		return summary
+	}
+	ai, err := cp.aiService.SummarizeDiscussion(ctx, events)
+	if err != nil {
+		cp.logAIFailure("summary generation", events, err)
+		return summary
+	}
+	if ai == nil {
+		return summary
+	}
+
+	summary.Summary = preferNonEmpty(ai.Summary, summary.Summary)
+	summary.KeyPoints = cp.deduplicateStrings(append(ai.KeyPoints, summary.KeyPoints...))
+	summary.ActionItems = cp.deduplicateStrings(append(ai.ActionItems, summary.ActionItems...))
+	return summary
+}
+
+// mergeAIFeatures adds the AI service's features to the extracted ones,
+// merging features with the same name
+func (cp *ContextProcessor) mergeAIFeatures(ctx context.Context, events []NormalizedEvent, features []FeatureContext) []FeatureContext {
+	if cp.aiService == nil {
+		return features
+	}
+	identified, err := cp.aiService.IdentifyFeatures(ctx, events)
+	if err != nil {
+		cp.logAIFailure("feature extraction", events, err)
+		return features
+	}
+
+	for _, ai := range identified {
+		index := -1
+		for i := range features {
+			if strings.EqualFold(features[i].FeatureName, ai.FeatureName) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			features = append(features, ai)
+			continue
+		}
+
+		feature := &features[index]
+		feature.Description = preferNonEmpty(ai.Description, feature.Description)
+		feature.Status = preferNonEmpty(ai.Status, feature.Status)
+		feature.Contributors = cp.deduplicateStrings(append(feature.Contributors, ai.Contributors...))
+		feature.RelatedFiles = cp.deduplicateStrings(append(feature.RelatedFiles, ai.RelatedFiles...))
+		feature.Discussions = cp.deduplicateStrings(append(feature.Discussions, ai.Discussions...))
+		if ai.UpdatedAt.After(feature.UpdatedAt) {
+			feature.UpdatedAt = ai.UpdatedAt
+		}
+	}
+	return features
+}
+
+// mergeAIFileContexts adds the AI service's change reasons to the file contexts
+func (cp *ContextProcessor) mergeAIFileContexts(ctx context.Context, events []NormalizedEvent, fileContexts []FileContextHistory) []FileContextHistory {
+	if cp.aiService == nil {
+		return fileContexts
+	}
+	analyzed, err := cp.aiService.AnalyzeFileContext(ctx, events)
+	if err != nil {
+		cp.logAIFailure("file context extraction", events, err)
+		return fileContexts
+	}
+
+	for _, ai := range analyzed {
+		index := -1
+		for i := range fileContexts {
+			if fileContexts[i].FilePath == ai.FilePath {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			fileContexts = append(fileContexts, ai)
+			continue
+		}
+
+		fileContext := &fileContexts[index]
+		fileContext.ChangeReason = preferNonEmpty(ai.ChangeReason, fileContext.ChangeReason)
+		fileContext.Contributors = cp.deduplicateStrings(append(fileContext.Contributors, ai.Contributors...))
+	}
+	return fileContexts
+}
+
+func (cp *ContextProcessor) logAIFailure(step string, events []NormalizedEvent, err error) {
+	cp.logger.Error("AI "+step+" failed, keeping pattern-based results", err, map[string]interface{}{
+		"event_count": len(events),
+	})
+}
+
+func preferNonEmpty(preferred, fallback string) string {
+	if strings.TrimSpace(preferred) != "" {
+		return preferred
+	}
+	return fallback
+}
+
+func sharesString(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Issue trackers thread an issue's comments and transitions under its ticket
+// key, so each ticket is summarized into a discussion titled by its key.
+// Commits and pull requests that mention a key are linked to that discussion,
+// and issue links between tickets become relationships between discussions.
+// Relationship ends are looked up by the text of the stored entity, so ticket
+// ends are named by key and file ends by path.
+
+// ticketKeyPattern matches issue tracker keys such as PAY-123
+var ticketKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9]+-[1-9][0-9]*\b`)
+
+// nonTicketPrefixes are standards and encodings written like ticket keys
+var nonTicketPrefixes = map[string]bool{
+	"AES": true, "CVE": true, "HTTP": true, "ISO": true, "RFC": true,
+	"SHA": true, "TLS": true, "UTF": true, "X509": true,
+}
+
+// extractTicketKeys returns the distinct ticket keys mentioned in text
+func extractTicketKeys(text string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range ticketKeyPattern.FindAllString(text, -1) {
+		prefix := key[:strings.LastIndex(key, "-")]
+		if nonTicketPrefixes[prefix] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// isTicketThread reports whether a group of events is an issue tracker ticket,
+// which is worth a summary even when only the ticket itself changed
+func isTicketThread(events []NormalizedEvent) bool {
+	if len(events) == 0 {
+		return false
+	}
+	key, _ := events[0].Metadata["ticket_key"].(string)
+	return key != ""
+}
+
+// extractTicketRelationships links the files and decisions of commits and pull
+// requests to the tickets they mention, and tickets to the tickets they link
+func (cp *ContextProcessor) extractTicketRelationships(events []NormalizedEvent, result *ProcessingResult) []Relationship {
+	var relationships []Relationship
+	seen := make(map[string]bool)
+	add := func(relationship Relationship) {
+		if !seen[relationship.ID] {
+			seen[relationship.ID] = true
+			relationships = append(relationships, relationship)
+		}
+	}
+
+	for _, event := range events {
+		var mentions string
+		switch event.EventType {
+		case EventTypeCommit:
+			mentions = event.Title + "\n" + event.Content
+		case EventTypePullRequest:
+			mentions = event.Title
+		default:
+			continue
+		}
+
+		for _, key := range extractTicketKeys(mentions) {
+			for _, filePath := range cp.deduplicateStrings(event.FileRefs) {
+				add(Relationship{
+					ID:         fmt.Sprintf("ticket-%s-%s", key, filePath),
+					SourceType: "file_context",
+					SourceID:   filePath,
+					TargetType: "discussion",
+					TargetID:   key,
+					Type:       "references",
+					Strength:   0.9,
+					Metadata: map[string]interface{}{
+						"ticket_key": key,
+						"file_path":  filePath,
+						"event_id":   event.PlatformID,
+						"platform":   event.Platform,
+					},
+					CreatedAt: time.Now(),
+				})
+			}
+
+			for _, decision := range result.DecisionRecords {
+				if !containsString(decision.SourceEventIDs, event.PlatformID) {
+					continue
+				}
+				add(Relationship{
+					ID:         fmt.Sprintf("ticket-%s-%s", key, decision.ID),
+					SourceType: "decision",
+					SourceID:   decision.Title,
+					TargetType: "discussion",
+					TargetID:   key,
+					Type:       "references",
+					Strength:   0.9,
+					Metadata: map[string]interface{}{
+						"ticket_key":     key,
+						"decision_title": decision.Title,
+						"event_id":       event.PlatformID,
+						"platform":       event.Platform,
+					},
+					CreatedAt: time.Now(),
+				})
+			}
+		}
+	}
+
+	for _, event := range events {
+		for _, link := range ticketLinks(event.Metadata["issue_links"]) {
+			linkType := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(link["type"])), " ", "_")
+			if linkType == "" || link["source"] == "" || link["target"] == "" {
+				continue
+			}
+			add(Relationship{
+				ID:         fmt.Sprintf("ticket-%s-%s-%s", link["source"], linkType, link["target"]),
+				SourceType: "discussion",
+				SourceID:   link["source"],
+				TargetType: "discussion",
+				TargetID:   link["target"],
+				Type:       linkType,
+				Strength:   0.8,
+				Metadata: map[string]interface{}{
+					"source_key": link["source"],
+					"target_key": link["target"],
+					"platform":   event.Platform,
+				},
+				CreatedAt: time.Now(),
+			})
+		}
+	}
+
+	return relationships
+}
+
+// ticketLinks reads issue links from event metadata, whether built in process
+// or decoded from JSON
+func ticketLinks(value interface{}) []map[string]string {
+	switch v := value.(type) {
+	case []map[string]string:
+		return v
+	case []interface{}:
+		links := make([]map[string]string, 0, len(v))
+		for _, item := range v {
+			fields, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			link := make(map[string]string, len(fields))
+			for name, field := range fields {
+				if s, ok := field.(string); ok {
+					link[name] = s
+				}
+			}
+			links = append(links, link)
+		}
+		return links
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractTicketKeys(t *testing.T) {
+	assert.Equal(t, []string{"PAY-123", "OPS2-7"}, extractTicketKeys("PAY-123: retry webhooks (see OPS2-7, PAY-123)"))
+	assert.Empty(t, extractTicketKeys("Decode UTF-8 and hash with SHA-256 per RFC-7231"))
+	assert.Empty(t, extractTicketKeys("pay-123 and PAY-0 are not keys"))
+}
+
+func TestContextProcessor_LinksTicketsToCommitsAndPullRequests(t *testing.T) {
+	processor := NewContextProcessor(nil, &SimpleLogger{})
+	now := time.Now()
+	thread := func(id string) *string { return &id }
+
+	events := []NormalizedEvent{
+		{
+			PlatformID: "issue-10001",
+			EventType:  EventTypeIssue,
+			Timestamp:  now,
+			Author:     "Alice",
+			Title:      "PAY-1: Stripe webhooks",
+			Content:    "Retry failed webhook deliveries",
+			ThreadID:   thread("PAY-1"),
+			Metadata:   map[string]interface{}{"ticket_key": "PAY-1"},
+			Platform:   "jira",
+		},
+		{
+			PlatformID: "issue-10002",
+			EventType:  EventTypeIssue,
+			Timestamp:  now,
+			Author:     "Bob",
+			Title:      "PAY-2: Back off webhook retries",
+			ThreadID:   thread("PAY-2"),
+			Metadata: map[string]interface{}{
+				"ticket_key": "PAY-2",
+				// Links decoded from JSON are generic maps
+				"issue_links": []interface{}{map[string]interface{}{"type": "relates to", "source": "PAY-1", "target": "PAY-2"}},
+			},
+			Platform: "jira",
+		},
+		{
+			PlatformID: "commit-abc123",
+			EventType:  EventTypeCommit,
+			Timestamp:  now,
+			Author:     "Alice",
+			Content:    "PAY-1 move webhook retries, decode UTF-8 bodies",
+			FileRefs:   []string{"internal/webhooks/retry.go", "internal/webhooks/retry.go"},
+			Platform:   "github",
+		},
+		{
+			PlatformID: "pr-7",
+			EventType:  EventTypePullRequest,
+			Timestamp:  now,
+			Author:     "Bob",
+			Title:      "Back off retries",
+			Content:    "Also touches PAY-9, but only the title counts",
+			FileRefs:   []string{"internal/webhooks/backoff.go"},
+			Platform:   "github",
+		},
+	}
+
+	result, err := processor.ProcessEvents(context.Background(), events)
+	require.NoError(t, err)
+
+	// A ticket is summarized even when only the ticket itself changed
+	var threads []string
+	for _, summary := range result.DiscussionSummaries {
+		threads = append(threads, summary.ThreadID)
+	}
+	assert.Contains(t, threads, "PAY-1")
+	assert.Contains(t, threads, "PAY-2")
+
+	byID := make(map[string]Relationship)
+	for _, relationship := range result.Relationships {
+		byID[relationship.ID] = relationship
+	}
+
+	reference, ok := byID["ticket-PAY-1-internal/webhooks/retry.go"]
+	require.True(t, ok, "the commit's file should reference the ticket")
+	assert.Equal(t, "file_context", reference.SourceType)
+	assert.Equal(t, "internal/webhooks/retry.go", reference.SourceID)
+	assert.Equal(t, "discussion", reference.TargetType)
+	assert.Equal(t, "PAY-1", reference.TargetID)
+	assert.Equal(t, "references", reference.Type)
+
+	link, ok := byID["ticket-PAY-1-relates_to-PAY-2"]
+	require.True(t, ok, "issue links should relate the tickets")
+	assert.Equal(t, "relates_to", link.Type)
+	assert.Equal(t, "discussion", link.SourceType)
+
+	for id := range byID {
+		assert.NotContains(t, id, "PAY-9", "keys in pull request bodies are not links")
+		assert.NotContains(t, id, "UTF-8")
+	}
+}
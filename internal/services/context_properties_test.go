@@ -93,7 +93,7 @@ func validateContextFilteringProperty(t *testing.T, scenario ContextFilteringSce
 
 	mockRepo := NewMockRepositoryStore()
 	mockPermission := NewMockPermissionService()
-	contextService := NewContextService(mockRepo, mockPermission, mockAIServer.URL)
+	contextService := NewContextService(mockRepo, mockPermission, NewContextEngine(NewHTTPContextGenerator(mockAIServer.URL, 30*time.Second), &SimpleLogger{}))
 
 	// Set up test repository
 	repoID := int64(1)
@@ -223,31 +223,12 @@ func validateTimeoutProperty(t *testing.T, scenario TimeoutScenario) bool {
 	}))
 	defer mockAIServer.Close()
 
-	mockRepo := NewMockRepositoryStore()
-	mockPermission := NewMockPermissionService()
-	// Create context service with shorter timeout for testing
-	contextService := &ContextServiceImpl{
-		repo:          mockRepo,
-		permissionSvc: mockPermission,
-		aiBaseURL:     mockAIServer.URL,
-		client: &http.Client{
-			Timeout: 3 * time.Second, // 3-second timeout for testing
-		},
-	}
-
-	// Set up test repository
-	repoID := int64(1)
-	testRepo := &models.Repository{
-		ID:       repoID,
-		Name:     "test-repo",
-		FullName: "testuser/test-repo",
-		Owner:    "testuser",
-	}
-	mockRepo.repos[repoID] = testRepo
+	// Create the AI service generator with a shorter timeout for testing
+	generator := NewHTTPContextGenerator(mockAIServer.URL, 3*time.Second)
 
 	ctx := context.Background()
 	startTime := time.Now()
-	_, err := contextService.ProcessQuery(ctx, repoID, scenario.Query, "query")
+	_, err := generator.GenerateContext(ctx, &ContextRequest{Repo: "testuser/test-repo", Query: scenario.Query, Mode: "query"})
 	duration := time.Since(startTime)
 
 	if scenario.ShouldTimeout {
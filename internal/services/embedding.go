@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+const (
+	// EmbeddingDimensions is the size of knowledge_entities.embedding
+	EmbeddingDimensions = 1536
+
+	// maxEmbeddingTextLength bounds the text embedded per entity, well under
+	// the input limit of common embedding models
+	maxEmbeddingTextLength = 8000
+
+	// HashingEmbeddingModel names the offline embedder's vector space
+	HashingEmbeddingModel = "hashing-v1"
+)
+
+// Embedder turns text into vectors for semantic search
+type Embedder interface {
+	// Embed returns one EmbeddingDimensions-long vector per text
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+
+	// Model names the vector space; vectors from different models are not comparable
+	Model() string
+}
+
+// NewEmbedder returns an embedder for the embeddings API configured in cfg,
+// or the offline hashing embedder when none is configured
+func NewEmbedder(cfg config.EmbeddingConfig, logger Logger) Embedder {
+	if cfg.BaseURL == "" {
+		return NewHashingEmbedder()
+	}
+	return NewOpenAIEmbedder(cfg, logger)
+}
+
+// embeddingText is the text of an entity that is embedded
+func embeddingText(entity *models.KnowledgeEntity) string {
+	text := entity.Title + "\n\n" + entity.Content
+	if len(text) > maxEmbeddingTextLength {
+		text = text[:maxEmbeddingTextLength]
+	}
+	return text
+}
+
+// OpenAIEmbedder embeds text with an OpenAI-compatible embeddings API
+type OpenAIEmbedder struct {
+	api   *openAIClient
+	model string
+}
+
+// NewOpenAIEmbedder creates an embedder for the embeddings API configured in cfg
+func NewOpenAIEmbedder(cfg config.EmbeddingConfig, logger Logger) *OpenAIEmbedder {
+	return &OpenAIEmbedder{
+		api:   newOpenAIClient(cfg.BaseURL, cfg.APIKey, time.Duration(cfg.Timeout)*time.Second, cfg.MaxRetries, logger),
+		model: cfg.Model,
+	}
+}
+
+// Model returns the configured embedding model
+func (e *OpenAIEmbedder) Model() string {
+	return e.model
+}
+
+// Embed embeds texts in a single request
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	request := map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	}
+	// Only the text-embedding-3 models can be shortened, and older models
+	// reject the parameter
+	if strings.HasPrefix(e.model, "text-embedding-3") {
+		request["dimensions"] = EmbeddingDimensions
+	}
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := e.api.post(ctx, "/embeddings", request, &response); err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+
+	if len(response.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding API returned %d embeddings for %d texts", len(response.Data), len(texts))
+	}
+	sort.Slice(response.Data, func(i, j int) bool { return response.Data[i].Index < response.Data[j].Index })
+
+	embeddings := make([][]float32, len(texts))
+	for i, data := range response.Data {
+		if len(data.Embedding) != EmbeddingDimensions {
+			return nil, fmt.Errorf("embedding API returned %d dimensions, expected %d", len(data.Embedding), EmbeddingDimensions)
+		}
+		embeddings[i] = data.Embedding
+	}
+	return embeddings, nil
+}
+
+// HashingEmbedder embeds text offline by feature hashing. Words, word pairs
+// and character trigrams are hashed into the vector with sublinear term
+// frequency weights, so texts sharing vocabulary, including word variants
+// such as "webhook" and "webhooks", end up close together. It needs no
+// network access or model, at the cost of knowing nothing about synonyms.
+type HashingEmbedder struct{}
+
+// NewHashingEmbedder creates the offline embedder
+func NewHashingEmbedder() *HashingEmbedder {
+	return &HashingEmbedder{}
+}
+
+// Model returns the hashing embedder's model name
+func (e *HashingEmbedder) Model() string {
+	return HashingEmbeddingModel
+}
+
+// Embed embeds each text independently
+func (e *HashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = hashEmbedding(text)
+	}
+	return embeddings, nil
+}
+
+func hashEmbedding(text string) []float32 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	var terms []string
+	for _, word := range words {
+		if !contextStopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+
+	weights := make(map[string]float64)
+	for i, term := range terms {
+		weights["w:"+term]++
+		if i > 0 {
+			weights["b:"+terms[i-1]+" "+term] += 0.5
+		}
+		padded := "^" + term + "$"
+		for j := 0; j+3 <= len(padded); j++ {
+			weights["c:"+padded[j:j+3]] += 0.25
+		}
+	}
+
+	vector := make([]float64, EmbeddingDimensions)
+	for feature, count := range weights {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		weight := 1 + math.Log(count) // sublinear term frequency
+		if count < 1 {
+			weight = count
+		}
+		if sum&(1<<63) != 0 {
+			weight = -weight
+		}
+		vector[sum%EmbeddingDimensions] += weight
+	}
+
+	var norm float64
+	for _, value := range vector {
+		norm += value * value
+	}
+	embedding := make([]float32, EmbeddingDimensions)
+	if norm == 0 {
+		// Cosine distance is undefined for zero vectors; texts without terms
+		// all share one direction instead
+		embedding[0] = 1
+		return embedding
+	}
+	norm = math.Sqrt(norm)
+	for i, value := range vector {
+		embedding[i] = float32(value / norm)
+	}
+	return embedding
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func TestHashingEmbedder_SimilarTextsAreClose(t *testing.T) {
+	embeddings, err := NewHashingEmbedder().Embed(context.Background(), []string{
+		"Retry failed invoice webhooks when Stripe times out",
+		"Invoice webhook retries after Stripe timeouts",
+		"Dark mode for the settings page",
+		"",
+	})
+	require.NoError(t, err)
+	require.Len(t, embeddings, 4)
+
+	for _, embedding := range embeddings {
+		require.Len(t, embedding, EmbeddingDimensions)
+		assert.InDelta(t, 1.0, cosine(embedding, embedding), 1e-5, "embeddings are unit vectors")
+	}
+	assert.Greater(t, cosine(embeddings[0], embeddings[1]), cosine(embeddings[0], embeddings[2])+0.2)
+
+	again, err := NewHashingEmbedder().Embed(context.Background(), []string{"Retry failed invoice webhooks when Stripe times out"})
+	require.NoError(t, err)
+	assert.Equal(t, embeddings[0], again[0], "embeddings are deterministic")
+}
+
+func TestOpenAIEmbedder_Embed(t *testing.T) {
+	var request map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		first := make([]float32, EmbeddingDimensions)
+		first[0] = 1
+		second := make([]float32, EmbeddingDimensions)
+		second[1] = 1
+		// Results may arrive out of order
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"index": 1, "embedding": second},
+				{"index": 0, "embedding": first},
+			},
+		})
+	}))
+	defer server.Close()
+
+	embedder := NewOpenAIEmbedder(config.EmbeddingConfig{BaseURL: server.URL + "/v1", Model: "text-embedding-3-small", Timeout: 5}, &SimpleLogger{})
+	embeddings, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "text-embedding-3-small", request["model"])
+	assert.Equal(t, []interface{}{"first", "second"}, request["input"])
+	assert.Equal(t, float64(EmbeddingDimensions), request["dimensions"])
+	assert.Equal(t, float32(1), embeddings[0][0])
+	assert.Equal(t, float32(1), embeddings[1][1])
+
+	// Vectors that do not fit the column are rejected
+	request = nil
+	legacy := NewOpenAIEmbedder(config.EmbeddingConfig{BaseURL: server.URL + "/v1", Model: "text-embedding-ada-002", Timeout: 5}, &SimpleLogger{})
+	_, err = legacy.Embed(context.Background(), []string{"only one"})
+	assert.Error(t, err)
+	assert.NotContains(t, request, "dimensions")
+}
+
+// embeddingRepositoryStub stores embeddings for a set of entities, and can
+// simulate entities edited while they are being embedded
+type embeddingRepositoryStub struct {
+	RepositoryStore
+	entities   []models.KnowledgeEntity
+	embedded   map[string]string
+	editedOnce map[string]bool
+	lists      int
+}
+
+func (r *embeddingRepositoryStub) ListKnowledgeEntitiesNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.KnowledgeEntity, error) {
+	r.lists++
+	var pending []models.KnowledgeEntity
+	for _, entity := range r.entities {
+		if r.embedded[entity.ID] != model && len(pending) < limit {
+			pending = append(pending, entity)
+		}
+	}
+	return pending, nil
+}
+
+func (r *embeddingRepositoryStub) UpdateKnowledgeEntityEmbedding(ctx context.Context, id string, embedding []float32, model string, contentUpdatedAt time.Time) error {
+	if r.editedOnce[id] {
+		return nil // the content changed, so the embedding is not stored
+	}
+	r.embedded[id] = model
+	return nil
+}
+
+func TestEmbeddingWorker_Backfill(t *testing.T) {
+	repo := &embeddingRepositoryStub{embedded: map[string]string{"e2": "old-model"}, editedOnce: map[string]bool{"e4": true}}
+	for _, id := range []string{"e1", "e2", "e3", "e4", "e5"} {
+		repo.entities = append(repo.entities, models.KnowledgeEntity{ID: id, Title: "Decision " + id, Content: "We decided to use Redis"})
+	}
+	worker := NewEmbeddingWorker(repo, NewHashingEmbedder(), &SimpleLogger{}, 2, 0)
+
+	embedded, err := worker.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, embedded)
+	for _, id := range []string{"e1", "e2", "e3", "e5"} {
+		assert.Equal(t, HashingEmbeddingModel, repo.embedded[id], id)
+	}
+
+	// e4 keeps coming back, but the pass still ends
+	assert.LessOrEqual(t, repo.lists, 4)
+	embedded, err = worker.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, embedded)
+}
+
+func TestEmbeddingWorker_StartAndStop(t *testing.T) {
+	repo := &embeddingRepositoryStub{embedded: map[string]string{}, entities: []models.KnowledgeEntity{{ID: "e1", Title: "Feature"}}}
+	worker := NewEmbeddingWorker(repo, NewHashingEmbedder(), &SimpleLogger{}, 0, time.Hour)
+
+	require.NoError(t, worker.Start(context.Background()))
+	assert.Error(t, worker.Start(context.Background()))
+	worker.Stop()
+
+	assert.Equal(t, HashingEmbeddingModel, repo.embedded["e1"], "the first pass runs immediately")
+}
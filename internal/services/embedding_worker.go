@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultEmbeddingBatchSize = 64
+	defaultEmbeddingInterval  = time.Minute
+)
+
+// EmbeddingWorker embeds new and updated knowledge entities in the background,
+// so semantic search covers everything that has been ingested
+type EmbeddingWorker struct {
+	repo      RepositoryStore
+	embedder  Embedder
+	logger    Logger
+	batchSize int
+	interval  time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewEmbeddingWorker creates a worker that embeds up to batchSize entities per
+// request and looks for new entities every interval. Zero values use the defaults.
+func NewEmbeddingWorker(repo RepositoryStore, embedder Embedder, logger Logger, batchSize int, interval time.Duration) *EmbeddingWorker {
+	if batchSize <= 0 {
+		batchSize = defaultEmbeddingBatchSize
+	}
+	if interval <= 0 {
+		interval = defaultEmbeddingInterval
+	}
+	return &EmbeddingWorker{
+		repo:      repo,
+		embedder:  embedder,
+		logger:    logger,
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
+
+// Start runs the worker until ctx is cancelled or Stop is called
+func (w *EmbeddingWorker) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return fmt.Errorf("embedding worker already running")
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.wg.Add(1)
+	go w.run(ctx)
+
+	w.logger.Info("Started embedding worker", map[string]interface{}{
+		"model":      w.embedder.Model(),
+		"batch_size": w.batchSize,
+		"interval":   w.interval.String(),
+	})
+	return nil
+}
+
+// Stop stops the worker and waits for the batch in progress
+func (w *EmbeddingWorker) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+	}
+	w.mu.Unlock()
+
+	w.wg.Wait()
+}
+
+func (w *EmbeddingWorker) run(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Backfill(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Error("Embedding pass failed", err, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Backfill embeds every entity that needs it, in batches, and returns how
+// many were embedded
+func (w *EmbeddingWorker) Backfill(ctx context.Context) (int, error) {
+	model := w.embedder.Model()
+	attempted := make(map[string]bool)
+	total := 0
+
+	for {
+		entities, err := w.repo.ListKnowledgeEntitiesNeedingEmbedding(ctx, model, w.batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to list entities to embed: %w", err)
+		}
+
+		// Entities edited while being embedded come back; stop once a batch
+		// has nothing new so a busy entity cannot keep the pass going
+		fresh := entities[:0]
+		for _, entity := range entities {
+			if !attempted[entity.ID] {
+				attempted[entity.ID] = true
+				fresh = append(fresh, entity)
+			}
+		}
+		if len(fresh) == 0 {
+			return total, nil
+		}
+
+		texts := make([]string, len(fresh))
+		for i := range fresh {
+			texts[i] = embeddingText(&fresh[i])
+		}
+		embeddings, err := w.embedder.Embed(ctx, texts)
+		if err != nil {
+			return total, fmt.Errorf("failed to embed %d entities: %w", len(fresh), err)
+		}
+
+		for i, entity := range fresh {
+			if err := w.repo.UpdateKnowledgeEntityEmbedding(ctx, entity.ID, embeddings[i], model, entity.UpdatedAt); err != nil {
+				return total, fmt.Errorf("failed to store embedding for entity %s: %w", entity.ID, err)
+			}
+			total++
+		}
+
+		w.logger.Debug("Embedded knowledge entities", map[string]interface{}{
+			"model": model,
+			"count": len(fresh),
+			"total": total,
+		})
+
+		if len(entities) < w.batchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGitLabBaseURL is used when no self-hosted instance is configured
+	DefaultGitLabBaseURL = "https://gitlab.com"
+
+	// gitLabPageSize is the largest page the GitLab API returns
+	gitLabPageSize = 100
+
+	// maxGitLabErrorBody bounds how much of an error response is kept
+	maxGitLabErrorBody = 512
+)
+
+// GitLabServiceImpl implements GitLabService against the REST API (v4) of
+// gitlab.com or a self-hosted instance
+type GitLabServiceImpl struct {
+	httpClient *http.Client
+	apiURL     string
+}
+
+// NewGitLabService creates a GitLab API client for the instance at baseURL,
+// which may be given with or without the /api/v4 suffix
+func NewGitLabService(baseURL string) GitLabService {
+	return &GitLabServiceImpl{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		apiURL: GitLabAPIURL(baseURL),
+	}
+}
+
+// GitLabAPIURL returns the REST API root of the GitLab instance at baseURL
+func GitLabAPIURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = DefaultGitLabBaseURL
+	}
+	if strings.HasSuffix(baseURL, "/api/v4") {
+		return baseURL
+	}
+	return baseURL + "/api/v4"
+}
+
+// GitLabAPIError is returned for GitLab API responses with an error status
+type GitLabAPIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *GitLabAPIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("GitLab API request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("GitLab API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// GitLabUser represents a GitLab user from API
+type GitLabUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Bot      bool   `json:"bot"`
+}
+
+// GitLabTokenInfo describes the access token making the request. Personal,
+// project and group access tokens all report themselves the same way.
+type GitLabTokenInfo struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	Active    bool     `json:"active"`
+	ExpiresAt *string  `json:"expires_at"`
+}
+
+// GitLabProject represents a GitLab project from API
+type GitLabProject struct {
+	ID                int64     `json:"id"`
+	Name              string    `json:"name"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Description       string    `json:"description"`
+	Visibility        string    `json:"visibility"`
+	DefaultBranch     string    `json:"default_branch"`
+	WebURL            string    `json:"web_url"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+}
+
+// GitLabMergeRequest represents a GitLab merge request from API
+type GitLabMergeRequest struct {
+	ID           int64      `json:"id"`
+	IID          int64      `json:"iid"`
+	ProjectID    int64      `json:"project_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	State        string     `json:"state"`
+	Author       GitLabUser `json:"author"`
+	Labels       []string   `json:"labels"`
+	SourceBranch string     `json:"source_branch"`
+	TargetBranch string     `json:"target_branch"`
+	WebURL       string     `json:"web_url"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	MergedAt     *time.Time `json:"merged_at"`
+}
+
+// GitLabIssue represents a GitLab issue from API
+type GitLabIssue struct {
+	ID          int64      `json:"id"`
+	IID         int64      `json:"iid"`
+	ProjectID   int64      `json:"project_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	State       string     `json:"state"`
+	Author      GitLabUser `json:"author"`
+	Labels      []string   `json:"labels"`
+	WebURL      string     `json:"web_url"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+}
+
+// GitLabCommit represents a GitLab commit from API
+type GitLabCommit struct {
+	ID            string    `json:"id"`
+	ShortID       string    `json:"short_id"`
+	Title         string    `json:"title"`
+	Message       string    `json:"message"`
+	AuthorName    string    `json:"author_name"`
+	AuthorEmail   string    `json:"author_email"`
+	AuthoredDate  time.Time `json:"authored_date"`
+	CommittedDate time.Time `json:"committed_date"`
+	ParentIDs     []string  `json:"parent_ids"`
+	WebURL        string    `json:"web_url"`
+}
+
+// GitLabDiff is one file changed by a commit or merge request
+type GitLabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// GitLabDiscussion is a thread of notes on a merge request
+type GitLabDiscussion struct {
+	ID             string       `json:"id"`
+	IndividualNote bool         `json:"individual_note"`
+	Notes          []GitLabNote `json:"notes"`
+}
+
+// GitLabNote is a comment in a discussion
+type GitLabNote struct {
+	ID         int64      `json:"id"`
+	Body       string     `json:"body"`
+	Author     GitLabUser `json:"author"`
+	System     bool       `json:"system"`
+	Resolvable bool       `json:"resolvable"`
+	Resolved   bool       `json:"resolved"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// GetCurrentUser returns the user the token acts as; project and group
+// access tokens act as a bot user
+func (g *GitLabServiceImpl) GetCurrentUser(ctx context.Context, token string) (*GitLabUser, error) {
+	var user GitLabUser
+	if _, err := g.get(ctx, token, g.apiURL+"/user", &user); err != nil {
+		return nil, fmt.Errorf("failed to get GitLab user: %w", err)
+	}
+	return &user, nil
+}
+
+// GetTokenInfo returns the token's scopes and expiry. Instances older than
+// GitLab 15.5 do not support this and return 404.
+func (g *GitLabServiceImpl) GetTokenInfo(ctx context.Context, token string) (*GitLabTokenInfo, error) {
+	var info GitLabTokenInfo
+	if _, err := g.get(ctx, token, g.apiURL+"/personal_access_tokens/self", &info); err != nil {
+		return nil, fmt.Errorf("failed to get GitLab token info: %w", err)
+	}
+	return &info, nil
+}
+
+// ListProjects returns up to limit projects the token is a member of
+func (g *GitLabServiceImpl) ListProjects(ctx context.Context, token string, limit int) ([]GitLabProject, error) {
+	query := url.Values{
+		"membership":       {"true"},
+		"min_access_level": {"20"}, // reporter, enough to read merge requests
+		"simple":           {"true"},
+		"pagination":       {"keyset"},
+		"order_by":         {"id"},
+		"sort":             {"asc"},
+	}
+	projects, err := gitLabList[GitLabProject](ctx, g, token, "/projects", query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GitLab projects: %w", err)
+	}
+	return projects, nil
+}
+
+// GetProject returns a project by numeric ID or full path
+func (g *GitLabServiceImpl) GetProject(ctx context.Context, token, projectID string) (*GitLabProject, error) {
+	var project GitLabProject
+	if _, err := g.get(ctx, token, g.projectURL(projectID, ""), &project); err != nil {
+		return nil, fmt.Errorf("failed to get GitLab project %s: %w", projectID, err)
+	}
+	return &project, nil
+}
+
+// ListMergeRequests returns up to limit merge requests updated after since,
+// oldest update first
+func (g *GitLabServiceImpl) ListMergeRequests(ctx context.Context, token, projectID string, since time.Time, limit int) ([]GitLabMergeRequest, error) {
+	mergeRequests, err := gitLabList[GitLabMergeRequest](ctx, g, token, g.projectPath(projectID, "/merge_requests"), updatedAfter(since), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests for %s: %w", projectID, err)
+	}
+	return mergeRequests, nil
+}
+
+// GetMergeRequestDiffs returns the files a merge request changes
+func (g *GitLabServiceImpl) GetMergeRequestDiffs(ctx context.Context, token, projectID string, iid int64) ([]GitLabDiff, error) {
+	diffs, err := gitLabList[GitLabDiff](ctx, g, token, g.projectPath(projectID, fmt.Sprintf("/merge_requests/%d/diffs", iid)), url.Values{}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diffs of merge request !%d: %w", iid, err)
+	}
+	return diffs, nil
+}
+
+// ListMergeRequestDiscussions returns every discussion thread on a merge request
+func (g *GitLabServiceImpl) ListMergeRequestDiscussions(ctx context.Context, token, projectID string, iid int64) ([]GitLabDiscussion, error) {
+	discussions, err := gitLabList[GitLabDiscussion](ctx, g, token, g.projectPath(projectID, fmt.Sprintf("/merge_requests/%d/discussions", iid)), url.Values{}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list discussions of merge request !%d: %w", iid, err)
+	}
+	return discussions, nil
+}
+
+// ListIssues returns up to limit issues updated after since, oldest update first
+func (g *GitLabServiceImpl) ListIssues(ctx context.Context, token, projectID string, since time.Time, limit int) ([]GitLabIssue, error) {
+	issues, err := gitLabList[GitLabIssue](ctx, g, token, g.projectPath(projectID, "/issues"), updatedAfter(since), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues for %s: %w", projectID, err)
+	}
+	return issues, nil
+}
+
+// ListCommits returns up to limit commits on the default branch made after
+// since, oldest first. GitLab lists commits newest first, so the whole window
+// is read and its oldest commits kept; the rest are left for the next sync
+// rather than skipped. Without since there is no window to bound the read, so
+// the first sync takes only the newest limit commits and leaves older history
+// unsynced.
+func (g *GitLabServiceImpl) ListCommits(ctx context.Context, token, projectID string, since time.Time, limit int) ([]GitLabCommit, error) {
+	query := url.Values{}
+	readLimit := limit
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+		readLimit = 0
+	}
+	commits, err := gitLabList[GitLabCommit](ctx, g, token, g.projectPath(projectID, "/repository/commits"), query, readLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits for %s: %w", projectID, err)
+	}
+
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	if limit > 0 && len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, nil
+}
+
+// GetCommitDiff returns the files a commit changes, with renames detected
+func (g *GitLabServiceImpl) GetCommitDiff(ctx context.Context, token, projectID, sha string) ([]GitLabDiff, error) {
+	diffs, err := gitLabList[GitLabDiff](ctx, g, token, g.projectPath(projectID, "/repository/commits/"+url.PathEscape(sha)+"/diff"), url.Values{}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff of commit %s: %w", sha, err)
+	}
+	return diffs, nil
+}
+
+// updatedAfter is the query for lists of merge requests and issues changed since
+func updatedAfter(since time.Time) url.Values {
+	query := url.Values{
+		"scope":    {"all"},
+		"order_by": {"updated_at"},
+		"sort":     {"asc"},
+	}
+	if !since.IsZero() {
+		query.Set("updated_after", since.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
+// projectPath is the API path of a project resource. Projects can be named by
+// path, whose slashes must be encoded.
+func (g *GitLabServiceImpl) projectPath(projectID, resource string) string {
+	return "/projects/" + url.PathEscape(projectID) + resource
+}
+
+func (g *GitLabServiceImpl) projectURL(projectID, resource string) string {
+	return g.apiURL + g.projectPath(projectID, resource)
+}
+
+// gitLabList fetches up to limit items (all when limit is 0) from a list
+// endpoint. Pages are followed through the Link header, which GitLab sends
+// for keyset pagination and, on most endpoints, offset pagination too; the
+// X-Next-Page header covers the rest. Links are only followed to the API's
+// own scheme and host, since the token is sent along.
+func gitLabList[T any](ctx context.Context, g *GitLabServiceImpl, token, path string, query url.Values, limit int) ([]T, error) {
+	perPage := gitLabPageSize
+	if limit > 0 && limit < perPage {
+		perPage = limit
+	}
+	query.Set("per_page", strconv.Itoa(perPage))
+	next := g.apiURL + path + "?" + query.Encode()
+
+	var items []T
+	for next != "" {
+		var page []T
+		header, err := g.get(ctx, token, next, &page)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if limit > 0 && len(items) >= limit {
+			return items[:limit], nil
+		}
+		if len(page) == 0 {
+			break
+		}
+
+		next = nextGitLabPage(header, g.apiURL)
+		if next == "" {
+			if page := header.Get("X-Next-Page"); page != "" {
+				query.Set("page", page)
+				next = g.apiURL + path + "?" + query.Encode()
+			}
+		}
+	}
+	return items, nil
+}
+
+var gitLabNextLinkPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// nextGitLabPage returns the next page's URL from the Link header, unless it
+// points away from the scheme and host of apiURL
+func nextGitLabPage(header http.Header, apiURL string) string {
+	api, err := url.Parse(apiURL)
+	if err != nil {
+		return ""
+	}
+	for _, link := range header.Values("Link") {
+		match := gitLabNextLinkPattern.FindStringSubmatch(link)
+		if match == nil {
+			continue
+		}
+		next, err := url.Parse(match[1])
+		if err != nil || next.Scheme != api.Scheme || next.Host != api.Host {
+			return ""
+		}
+		return match[1]
+	}
+	return ""
+}
+
+// get makes an authenticated GET request and decodes the JSON response
+func (g *GitLabServiceImpl) get(ctx context.Context, token, requestURL string, result any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// PRIVATE-TOKEN accepts personal, project and group access tokens alike
+	req.Header.Set("PRIVATE-TOKEN", token)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "ContextKeeper/1.0")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxGitLabErrorBody))
+		apiErr := &GitLabAPIError{StatusCode: resp.StatusCode, Message: gitLabErrorMessage(body)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return nil, apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("failed to decode GitLab response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// gitLabErrorMessage extracts the message from a GitLab error body, which is
+// {"message": ...} or {"error": ...}
+func gitLabErrorMessage(body []byte) string {
+	var payload struct {
+		Message interface{} `json:"message"`
+		Error   string      `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if payload.Message != nil {
+			if message, ok := payload.Message.(string); ok {
+				return message
+			}
+			encoded, _ := json.Marshal(payload.Message)
+			return string(encoded)
+		}
+		if payload.Error != "" {
+			return payload.Error
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// IsGitLabStatus reports whether err is a GitLab API error with the status
+func IsGitLabStatus(err error, status int) bool {
+	var apiErr *GitLabAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
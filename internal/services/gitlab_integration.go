@@ -0,0 +1,505 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// maxGitLabRepositories bounds the projects listed for selection
+const maxGitLabRepositories = 1000
+
+// GitLabIntegrationService handles GitLab integration operations
+type GitLabIntegrationService interface {
+	// Access token installation flow
+	ProcessTokenInstallation(ctx context.Context, req *GitLabTokenInstallationRequest, userID string) (*models.ProjectIntegration, error)
+
+	// Repository management
+	GetAvailableRepositories(ctx context.Context, projectID, integrationID string) ([]GitLabRepositoryInfo, error)
+	SelectRepositories(ctx context.Context, req *RepositorySelectionRequest, userID string) ([]models.ProjectDataSource, error)
+
+	// Configuration management
+	UpdateConfiguration(ctx context.Context, req *IntegrationConfigurationRequest, userID string) (*models.ProjectIntegration, error)
+
+	// Status and health
+	GetIntegrationStatus(ctx context.Context, projectID, integrationID string) (*GitLabIntegrationStatus, error)
+
+	// Integration lifecycle
+	DeleteIntegration(ctx context.Context, projectID, integrationID, userID string) error
+
+	// Credential management
+	ValidateCredentials(ctx context.Context, integrationID string) error
+}
+
+// GitLabIntegrationServiceImpl implements GitLabIntegrationService
+type GitLabIntegrationServiceImpl struct {
+	config     *config.Config
+	store      RepositoryStore
+	encryptSvc EncryptionService
+	logger     Logger
+}
+
+// NewGitLabIntegrationService creates a new GitLab integration service
+func NewGitLabIntegrationService(
+	cfg *config.Config,
+	store RepositoryStore,
+	encryptSvc EncryptionService,
+	logger Logger,
+) GitLabIntegrationService {
+	return &GitLabIntegrationServiceImpl{
+		config:     cfg,
+		store:      store,
+		encryptSvc: encryptSvc,
+		logger:     logger,
+	}
+}
+
+// Request/Response types
+type GitLabTokenInstallationRequest struct {
+	ProjectID   string `json:"project_id"`
+	BaseURL     string `json:"base_url"` // defaults to https://gitlab.com
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"` // personal, project or group; defaults to personal
+}
+
+type GitLabRepositoryInfo struct {
+	ID                int64     `json:"id"`
+	Name              string    `json:"name"`
+	PathWithNamespace string    `json:"path_with_namespace"`
+	Description       string    `json:"description"`
+	Visibility        string    `json:"visibility"`
+	DefaultBranch     string    `json:"default_branch"`
+	WebURL            string    `json:"web_url"`
+	LastActivityAt    time.Time `json:"last_activity_at"`
+	Selected          bool      `json:"selected"`
+}
+
+type GitLabIntegrationStatus struct {
+	IntegrationID    string                 `json:"integration_id"`
+	Status           string                 `json:"status"`
+	LastSyncAt       *time.Time             `json:"last_sync_at"`
+	LastSyncStatus   *string                `json:"last_sync_status"`
+	ErrorMessage     *string                `json:"error_message"`
+	CredentialsValid bool                   `json:"credentials_valid"`
+	TokenScopes      []string               `json:"token_scopes"`
+	TokenExpiresAt   *string                `json:"token_expires_at"`
+	RepositoryCount  int                    `json:"repository_count"`
+	Configuration    map[string]interface{} `json:"configuration"`
+}
+
+// ProcessTokenInstallation connects a GitLab instance with a personal, project
+// or group access token
+func (g *GitLabIntegrationServiceImpl) ProcessTokenInstallation(ctx context.Context, req *GitLabTokenInstallationRequest, userID string) (*models.ProjectIntegration, error) {
+	if req.AccessToken == "" {
+		return nil, fmt.Errorf("access token required")
+	}
+
+	tokenType := req.TokenType
+	if tokenType == "" {
+		tokenType = "personal"
+	}
+	if tokenType != "personal" && tokenType != "project" && tokenType != "group" {
+		return nil, fmt.Errorf("invalid token type %q: expected personal, project or group", req.TokenType)
+	}
+
+	baseURL := req.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultGitLabBaseURL
+	}
+	if parsed, err := url.Parse(baseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: expected an http or https URL", req.BaseURL)
+	}
+
+	// Check if integration already exists for this project
+	existing, err := g.store.GetProjectIntegrationByPlatform(ctx, req.ProjectID, string(models.PlatformGitLab))
+	if err == nil && existing != nil {
+		return nil, fmt.Errorf("GitLab integration already exists for project")
+	}
+
+	// Validate the token against the instance
+	gitlab := NewGitLabService(baseURL)
+	user, err := gitlab.GetCurrentUser(ctx, req.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate GitLab access token: %w", err)
+	}
+
+	configuration := map[string]interface{}{
+		"base_url":   baseURL,
+		"user_id":    user.ID,
+		"username":   user.Username,
+		"token_type": tokenType,
+	}
+
+	// Instances older than GitLab 15.5 cannot describe the token; their
+	// scopes are only checked when ingestion first reads from them
+	tokenInfo, err := gitlab.GetTokenInfo(ctx, req.AccessToken)
+	if err == nil {
+		if !hasGitLabReadScope(tokenInfo.Scopes) {
+			return nil, fmt.Errorf("invalid access token: GitLab token needs the read_api or api scope")
+		}
+		configuration["token_name"] = tokenInfo.Name
+		configuration["scopes"] = tokenInfo.Scopes
+		if tokenInfo.ExpiresAt != nil {
+			configuration["token_expires_at"] = *tokenInfo.ExpiresAt
+		}
+	} else if !IsGitLabStatus(err, http.StatusNotFound) {
+		return nil, fmt.Errorf("failed to validate GitLab access token: %w", err)
+	}
+
+	// Encrypt credentials
+	encryptedToken, err := g.encryptSvc.Encrypt(ctx, req.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+
+	// Create integration record
+	integration := &models.ProjectIntegration{
+		ID:              generateID(),
+		ProjectID:       req.ProjectID,
+		Platform:        string(models.PlatformGitLab),
+		IntegrationType: string(models.IntegrationTypeAccessToken),
+		Status:          string(models.IntegrationStatusActive),
+		Configuration:   configuration,
+		Credentials: map[string]interface{}{
+			"access_token": encryptedToken,
+			"token_type":   tokenType,
+		},
+		LastSyncAt:     nil,
+		LastSyncStatus: nil,
+		ErrorMessage:   nil,
+		SyncCheckpoint: map[string]interface{}{},
+		CreatedBy:      userID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	// Save integration
+	if err := g.store.CreateProjectIntegration(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to create integration: %w", err)
+	}
+
+	g.logger.Info("GitLab integration created", map[string]interface{}{
+		"project_id":     req.ProjectID,
+		"integration_id": integration.ID,
+		"base_url":       baseURL,
+		"username":       user.Username,
+		"token_type":     tokenType,
+		"user_id":        userID,
+	})
+
+	return integration, nil
+}
+
+// GetAvailableRepositories gets the GitLab projects the token can read, for selection
+func (g *GitLabIntegrationServiceImpl) GetAvailableRepositories(ctx context.Context, projectID, integrationID string) ([]GitLabRepositoryInfo, error) {
+	integration, gitlab, accessToken, err := g.getIntegrationClient(ctx, projectID, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := gitlab.ListProjects(ctx, accessToken, maxGitLabRepositories)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repositories from GitLab: %w", err)
+	}
+
+	// Get already selected repositories
+	selectedRepos, err := g.store.GetProjectDataSourcesByIntegration(ctx, integration.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get selected repositories: %w", err)
+	}
+
+	selectedMap := make(map[string]bool)
+	for _, repo := range selectedRepos {
+		selectedMap[repo.SourceID] = true
+	}
+
+	repositories := make([]GitLabRepositoryInfo, len(projects))
+	for i, project := range projects {
+		repositories[i] = GitLabRepositoryInfo{
+			ID:                project.ID,
+			Name:              project.Name,
+			PathWithNamespace: project.PathWithNamespace,
+			Description:       project.Description,
+			Visibility:        project.Visibility,
+			DefaultBranch:     project.DefaultBranch,
+			WebURL:            project.WebURL,
+			LastActivityAt:    project.LastActivityAt,
+			Selected:          selectedMap[strconv.FormatInt(project.ID, 10)],
+		}
+	}
+
+	return repositories, nil
+}
+
+// SelectRepositories selects GitLab projects, by numeric ID or full path, for the integration
+func (g *GitLabIntegrationServiceImpl) SelectRepositories(ctx context.Context, req *RepositorySelectionRequest, userID string) ([]models.ProjectDataSource, error) {
+	_, gitlab, accessToken, err := g.getIntegrationClient(ctx, req.ProjectID, req.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataSources []models.ProjectDataSource
+	for _, repoID := range req.RepositoryIDs {
+		project, err := gitlab.GetProject(ctx, accessToken, repoID)
+		if err != nil {
+			if IsGitLabStatus(err, http.StatusNotFound) {
+				return nil, fmt.Errorf("invalid repository ID: %s", repoID)
+			}
+			return nil, fmt.Errorf("failed to get repository details for %s: %w", repoID, err)
+		}
+
+		// Paths are resolved to IDs so a project is selected once however it was named
+		sourceID := strconv.FormatInt(project.ID, 10)
+
+		// Check if data source already exists
+		existing, _ := g.store.GetProjectDataSource(ctx, generateDataSourceID(req.IntegrationID, sourceID))
+		if existing != nil {
+			continue // Skip if already exists
+		}
+
+		dataSource := models.ProjectDataSource{
+			ID:            generateDataSourceID(req.IntegrationID, sourceID),
+			ProjectID:     req.ProjectID,
+			IntegrationID: req.IntegrationID,
+			SourceType:    string(models.SourceTypeRepository),
+			SourceID:      sourceID,
+			SourceName:    project.PathWithNamespace,
+			Configuration: map[string]interface{}{
+				"gitlab_project_id":   project.ID,
+				"repository_name":     project.Name,
+				"path_with_namespace": project.PathWithNamespace,
+				"visibility":          project.Visibility,
+				"default_branch":      project.DefaultBranch,
+				"web_url":             project.WebURL,
+			},
+			IsActive:        true,
+			LastIngestionAt: nil,
+			IngestionStatus: nil,
+			ErrorMessage:    nil,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+
+		if err := g.store.CreateProjectDataSource(ctx, &dataSource); err != nil {
+			return nil, fmt.Errorf("failed to create data source for repository %s: %w", repoID, err)
+		}
+
+		dataSources = append(dataSources, dataSource)
+	}
+
+	g.logger.Info("GitLab repositories selected for integration", map[string]interface{}{
+		"project_id":     req.ProjectID,
+		"integration_id": req.IntegrationID,
+		"repository_ids": req.RepositoryIDs,
+		"user_id":        userID,
+	})
+
+	return dataSources, nil
+}
+
+// UpdateConfiguration updates integration configuration
+func (g *GitLabIntegrationServiceImpl) UpdateConfiguration(ctx context.Context, req *IntegrationConfigurationRequest, userID string) (*models.ProjectIntegration, error) {
+	integration, err := g.getProjectIntegration(ctx, req.ProjectID, req.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.validateConfiguration(req.Configuration); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if integration.Configuration == nil {
+		integration.Configuration = make(map[string]interface{})
+	}
+	for key, value := range req.Configuration {
+		integration.Configuration[key] = value
+	}
+
+	updates := map[string]interface{}{
+		"configuration": integration.Configuration,
+		"updated_at":    time.Now(),
+	}
+	if err := g.store.UpdateProjectIntegration(ctx, req.IntegrationID, updates); err != nil {
+		return nil, fmt.Errorf("failed to update integration: %w", err)
+	}
+
+	updatedIntegration, err := g.store.GetProjectIntegration(ctx, req.IntegrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated integration: %w", err)
+	}
+
+	g.logger.Info("Integration configuration updated", map[string]interface{}{
+		"project_id":     req.ProjectID,
+		"integration_id": req.IntegrationID,
+		"user_id":        userID,
+	})
+
+	return updatedIntegration, nil
+}
+
+// GetIntegrationStatus gets integration status and health
+func (g *GitLabIntegrationServiceImpl) GetIntegrationStatus(ctx context.Context, projectID, integrationID string) (*GitLabIntegrationStatus, error) {
+	integration, err := g.getProjectIntegration(ctx, projectID, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSources, err := g.store.GetProjectDataSourcesByIntegration(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data sources: %w", err)
+	}
+
+	status := &GitLabIntegrationStatus{
+		IntegrationID:   integrationID,
+		Status:          integration.Status,
+		LastSyncAt:      integration.LastSyncAt,
+		LastSyncStatus:  integration.LastSyncStatus,
+		ErrorMessage:    integration.ErrorMessage,
+		RepositoryCount: len(dataSources),
+		Configuration:   integration.Configuration,
+	}
+
+	// Check credentials validity
+	accessToken, err := g.getDecryptedAccessToken(ctx, integration)
+	if err == nil {
+		gitlab := NewGitLabService(gitLabBaseURL(integration))
+		if _, err := gitlab.GetCurrentUser(ctx, accessToken); err == nil {
+			status.CredentialsValid = true
+			if info, err := gitlab.GetTokenInfo(ctx, accessToken); err == nil {
+				status.TokenScopes = info.Scopes
+				status.TokenExpiresAt = info.ExpiresAt
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// DeleteIntegration deletes a GitLab integration
+func (g *GitLabIntegrationServiceImpl) DeleteIntegration(ctx context.Context, projectID, integrationID, userID string) error {
+	if _, err := g.getProjectIntegration(ctx, projectID, integrationID); err != nil {
+		return err
+	}
+
+	// Delete all data sources
+	dataSources, err := g.store.GetProjectDataSourcesByIntegration(ctx, integrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get data sources: %w", err)
+	}
+
+	for _, dataSource := range dataSources {
+		if err := g.store.DeleteProjectDataSource(ctx, dataSource.ID); err != nil {
+			g.logger.Error("Failed to delete data source", err, map[string]interface{}{
+				"data_source_id": dataSource.ID,
+			})
+		}
+	}
+
+	if err := g.store.DeleteProjectIntegration(ctx, integrationID); err != nil {
+		return fmt.Errorf("failed to delete integration: %w", err)
+	}
+
+	g.logger.Info("GitLab integration deleted", map[string]interface{}{
+		"project_id":     projectID,
+		"integration_id": integrationID,
+		"user_id":        userID,
+	})
+
+	return nil
+}
+
+// ValidateCredentials validates integration credentials
+func (g *GitLabIntegrationServiceImpl) ValidateCredentials(ctx context.Context, integrationID string) error {
+	integration, err := g.store.GetProjectIntegration(ctx, integrationID)
+	if err != nil || integration == nil {
+		return fmt.Errorf("integration not found: %v", err)
+	}
+
+	accessToken, err := g.getDecryptedAccessToken(ctx, integration)
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	if _, err := NewGitLabService(gitLabBaseURL(integration)).GetCurrentUser(ctx, accessToken); err != nil {
+		return fmt.Errorf("credentials are invalid: %w", err)
+	}
+
+	return nil
+}
+
+// Helper methods
+
+// getProjectIntegration gets an integration and checks it belongs to the project
+func (g *GitLabIntegrationServiceImpl) getProjectIntegration(ctx context.Context, projectID, integrationID string) (*models.ProjectIntegration, error) {
+	integration, err := g.store.GetProjectIntegration(ctx, integrationID)
+	if err != nil || integration == nil {
+		return nil, fmt.Errorf("integration not found: %v", err)
+	}
+
+	if integration.ProjectID != projectID {
+		return nil, fmt.Errorf("integration does not belong to project")
+	}
+
+	return integration, nil
+}
+
+// getIntegrationClient returns the integration with a client for its
+// instance and the decrypted access token
+func (g *GitLabIntegrationServiceImpl) getIntegrationClient(ctx context.Context, projectID, integrationID string) (*models.ProjectIntegration, GitLabService, string, error) {
+	integration, err := g.getProjectIntegration(ctx, projectID, integrationID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	accessToken, err := g.getDecryptedAccessToken(ctx, integration)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	return integration, NewGitLabService(gitLabBaseURL(integration)), accessToken, nil
+}
+
+// getDecryptedAccessToken gets and decrypts the access token from integration
+func (g *GitLabIntegrationServiceImpl) getDecryptedAccessToken(ctx context.Context, integration *models.ProjectIntegration) (string, error) {
+	encryptedToken, ok := integration.Credentials["access_token"].(string)
+	if !ok {
+		return "", fmt.Errorf("access token not found in credentials")
+	}
+
+	return g.encryptSvc.Decrypt(ctx, encryptedToken)
+}
+
+// validateConfiguration validates integration configuration. The instance
+// and token type are fixed by the token, so changing them means reinstalling.
+func (g *GitLabIntegrationServiceImpl) validateConfiguration(config map[string]interface{}) error {
+	for _, key := range []string{"base_url", "token_type", "user_id", "username"} {
+		if _, ok := config[key]; ok {
+			return fmt.Errorf("%s cannot be changed; reinstall the integration instead", key)
+		}
+	}
+	return nil
+}
+
+// gitLabBaseURL is the instance an integration was installed on
+func gitLabBaseURL(integration *models.ProjectIntegration) string {
+	if baseURL, ok := integration.Configuration["base_url"].(string); ok && baseURL != "" {
+		return baseURL
+	}
+	return DefaultGitLabBaseURL
+}
+
+// hasGitLabReadScope reports whether token scopes allow reading the API
+func hasGitLabReadScope(scopes []string) bool {
+	for _, scope := range scopes {
+		if scope == "read_api" || scope == "api" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitLabIntegrationStore keeps integrations and data sources in memory
+type gitLabIntegrationStore struct {
+	RepositoryStore
+	integrations map[string]*models.ProjectIntegration
+	dataSources  map[string]*models.ProjectDataSource
+}
+
+func newGitLabIntegrationStore() *gitLabIntegrationStore {
+	return &gitLabIntegrationStore{
+		integrations: make(map[string]*models.ProjectIntegration),
+		dataSources:  make(map[string]*models.ProjectDataSource),
+	}
+}
+
+func (s *gitLabIntegrationStore) CreateProjectIntegration(ctx context.Context, integration *models.ProjectIntegration) error {
+	s.integrations[integration.ID] = integration
+	return nil
+}
+
+func (s *gitLabIntegrationStore) GetProjectIntegration(ctx context.Context, integrationID string) (*models.ProjectIntegration, error) {
+	if integration, ok := s.integrations[integrationID]; ok {
+		return integration, nil
+	}
+	return nil, errors.New("no rows")
+}
+
+func (s *gitLabIntegrationStore) GetProjectIntegrationByPlatform(ctx context.Context, projectID, platform string) (*models.ProjectIntegration, error) {
+	for _, integration := range s.integrations {
+		if integration.ProjectID == projectID && integration.Platform == platform {
+			return integration, nil
+		}
+	}
+	return nil, errors.New("no rows")
+}
+
+func (s *gitLabIntegrationStore) GetProjectDataSource(ctx context.Context, dataSourceID string) (*models.ProjectDataSource, error) {
+	if dataSource, ok := s.dataSources[dataSourceID]; ok {
+		return dataSource, nil
+	}
+	return nil, errors.New("no rows")
+}
+
+func (s *gitLabIntegrationStore) GetProjectDataSourcesByIntegration(ctx context.Context, integrationID string) ([]models.ProjectDataSource, error) {
+	var dataSources []models.ProjectDataSource
+	for _, dataSource := range s.dataSources {
+		if dataSource.IntegrationID == integrationID {
+			dataSources = append(dataSources, *dataSource)
+		}
+	}
+	return dataSources, nil
+}
+
+func (s *gitLabIntegrationStore) CreateProjectDataSource(ctx context.Context, dataSource *models.ProjectDataSource) error {
+	s.dataSources[dataSource.ID] = dataSource
+	return nil
+}
+
+// newTestGitLabAPI serves the user, token and project endpoints of a GitLab
+// instance for a token with the given scopes
+func newTestGitLabAPI(t *testing.T, scopes string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+			return
+		}
+
+		project := `{"id":42,"name":"app","path_with_namespace":"group/app","visibility":"private","default_branch":"main","web_url":"https://gitlab.example.com/group/app"}`
+		switch r.URL.EscapedPath() {
+		case "/api/v4/user":
+			fmt.Fprint(w, `{"id":7,"username":"alice"}`)
+		case "/api/v4/personal_access_tokens/self":
+			fmt.Fprintf(w, `{"id":1,"name":"context-keeper","scopes":%s,"expires_at":"2030-01-31"}`, scopes)
+		case "/api/v4/projects":
+			fmt.Fprintf(w, `[%s,{"id":43,"name":"docs","path_with_namespace":"group/docs"}]`, project)
+		case "/api/v4/projects/42", "/api/v4/projects/group%2Fapp":
+			fmt.Fprint(w, project)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"404 Project Not Found"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGitLabIntegrationService_TokenInstallationAndSelection(t *testing.T) {
+	ctx := context.Background()
+	server := newTestGitLabAPI(t, `["read_api","read_repository"]`)
+	store := newGitLabIntegrationStore()
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	svc := NewGitLabIntegrationService(cfg, store, NewEncryptionService(cfg), &SimpleLogger{})
+
+	_, err := svc.ProcessTokenInstallation(ctx, &GitLabTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, AccessToken: "glpat-test", TokenType: "deploy"}, "u1")
+	assert.ErrorContains(t, err, "invalid token type")
+
+	integration, err := svc.ProcessTokenInstallation(ctx, &GitLabTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, AccessToken: "glpat-test"}, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PlatformGitLab), integration.Platform)
+	assert.Equal(t, string(models.IntegrationTypeAccessToken), integration.IntegrationType)
+	assert.Equal(t, server.URL, integration.Configuration["base_url"])
+	assert.Equal(t, "alice", integration.Configuration["username"])
+	assert.Equal(t, "2030-01-31", integration.Configuration["token_expires_at"])
+	assert.NotEqual(t, "glpat-test", integration.Credentials["access_token"], "the token is stored encrypted")
+
+	_, err = svc.ProcessTokenInstallation(ctx, &GitLabTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, AccessToken: "glpat-test"}, "u1")
+	assert.ErrorContains(t, err, "already exists")
+
+	// A project and its path select the same data source
+	dataSources, err := svc.SelectRepositories(ctx, &RepositorySelectionRequest{ProjectID: "p1", IntegrationID: integration.ID, RepositoryIDs: []string{"group/app", "42"}}, "u1")
+	require.NoError(t, err)
+	require.Len(t, dataSources, 1)
+	assert.Equal(t, "42", dataSources[0].SourceID)
+	assert.Equal(t, "group/app", dataSources[0].SourceName)
+	assert.Equal(t, string(models.SourceTypeRepository), dataSources[0].SourceType)
+
+	_, err = svc.SelectRepositories(ctx, &RepositorySelectionRequest{ProjectID: "p1", IntegrationID: integration.ID, RepositoryIDs: []string{"group/missing"}}, "u1")
+	assert.ErrorContains(t, err, "invalid repository ID")
+
+	repositories, err := svc.GetAvailableRepositories(ctx, "p1", integration.ID)
+	require.NoError(t, err)
+	require.Len(t, repositories, 2)
+	assert.True(t, repositories[0].Selected)
+	assert.False(t, repositories[1].Selected)
+
+	_, err = svc.GetAvailableRepositories(ctx, "p2", integration.ID)
+	assert.ErrorContains(t, err, "does not belong to project")
+
+	require.NoError(t, svc.ValidateCredentials(ctx, integration.ID))
+
+	status, err := svc.GetIntegrationStatus(ctx, "p1", integration.ID)
+	require.NoError(t, err)
+	assert.True(t, status.CredentialsValid)
+	assert.Equal(t, 1, status.RepositoryCount)
+	assert.Equal(t, []string{"read_api", "read_repository"}, status.TokenScopes)
+
+	_, err = svc.UpdateConfiguration(ctx, &IntegrationConfigurationRequest{ProjectID: "p1", IntegrationID: integration.ID, Configuration: map[string]interface{}{"base_url": "https://gitlab.com"}}, "u1")
+	assert.ErrorContains(t, err, "invalid configuration")
+}
+
+func TestGitLabIntegrationService_RejectsTokens(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{JWTSecret: "test-secret"}
+
+	// Tokens without API read access cannot ingest anything
+	server := newTestGitLabAPI(t, `["read_repository"]`)
+	svc := NewGitLabIntegrationService(cfg, newGitLabIntegrationStore(), NewEncryptionService(cfg), &SimpleLogger{})
+	_, err := svc.ProcessTokenInstallation(ctx, &GitLabTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, AccessToken: "glpat-test"}, "u1")
+	assert.ErrorContains(t, err, "read_api")
+
+	_, err = svc.ProcessTokenInstallation(ctx, &GitLabTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, AccessToken: "revoked"}, "u1")
+	assert.ErrorContains(t, err, "failed to validate")
+
+	_, err = svc.ProcessTokenInstallation(ctx, &GitLabTokenInstallationRequest{ProjectID: "p1", BaseURL: "gitlab.example.com", AccessToken: "glpat-test"}, "u1")
+	assert.ErrorContains(t, err, "invalid base URL")
+}
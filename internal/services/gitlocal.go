@@ -0,0 +1,434 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// gitRecordSeparator starts each commit of the log output, and
+	// gitFieldSeparator separates its fields
+	gitRecordSeparator = '\x1e'
+	gitFieldSeparator  = "\x1f"
+
+	// gitLogFormat prints the hash, parents, author, committer, tags, trailers
+	// and message of a commit. The file stats follow the last separator.
+	gitLogFormat = "%x1e%H%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%cn%x1f%cI%x1f%D%x1f%(trailers:only,unfold)%x1f%B%x1f"
+	gitLogFields = 10
+
+	// maxGitRecord bounds a single commit in the log output, which is large
+	// for commits touching many files
+	maxGitRecord = 64 << 20
+
+	// maxGitErrorOutput bounds how much of a failed command's stderr is kept
+	maxGitErrorOutput = 512
+)
+
+// GitLocalServiceImpl implements GitLocalService with the git command line
+type GitLocalServiceImpl struct {
+	gitPath string
+}
+
+// NewGitLocalService creates a git reader that runs the git binary at
+// gitPath, or the one on the PATH when gitPath is empty
+func NewGitLocalService(gitPath string) GitLocalService {
+	if gitPath == "" {
+		gitPath = "git"
+	}
+	return &GitLocalServiceImpl{gitPath: gitPath}
+}
+
+// GitLogOptions selects the commits WalkLog reads
+type GitLogOptions struct {
+	// Ref is the revision whose history is read, HEAD when empty
+	Ref string
+	// Since skips commits committed at or before it, unless zero
+	Since time.Time
+	// MaxCount stops the walk after that many commits, unless zero
+	MaxCount int
+	// Reverse walks from the oldest commit
+	Reverse bool
+	// Paths limits the walk, and the files reported, to commits touching
+	// these paths, unless empty
+	Paths []string
+}
+
+// GitLocalCommit is a commit read from a local repository
+type GitLocalCommit struct {
+	SHA           string
+	Parents       []string
+	AuthorName    string
+	AuthorEmail   string
+	AuthoredAt    time.Time
+	CommitterName string
+	CommittedAt   time.Time
+	Message       string
+	Tags          []string
+	Trailers      []GitTrailer
+	Files         []GitFileChange
+}
+
+// Subject returns the first line of the commit message
+func (c GitLocalCommit) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return strings.TrimSpace(subject)
+}
+
+// TrailerValues returns the values of the trailers with the given key, which
+// is matched case-insensitively as git does
+func (c GitLocalCommit) TrailerValues(key string) []string {
+	var values []string
+	for _, trailer := range c.Trailers {
+		if strings.EqualFold(trailer.Key, key) {
+			values = append(values, trailer.Value)
+		}
+	}
+	return values
+}
+
+// GitTrailer is a "Key: value" line at the end of a commit message, such as
+// Co-authored-by or Signed-off-by
+type GitTrailer struct {
+	Key   string
+	Value string
+}
+
+// GitFileChange is a file changed by a commit. OldPath is set for renames
+// and copies. Binary files have no line counts.
+type GitFileChange struct {
+	Path      string
+	OldPath   string
+	Additions int
+	Deletions int
+	Binary    bool
+}
+
+// GitCommandError is returned when a git command fails
+type GitCommandError struct {
+	Command  string
+	ExitCode int
+	Stderr   string
+}
+
+func (e *GitCommandError) Error() string {
+	return fmt.Sprintf("git %s failed with exit code %d: %s", e.Command, e.ExitCode, e.Stderr)
+}
+
+// Mirror keeps a mirror clone of remoteURL at repoPath, cloning it the first
+// time and fetching all refs afterwards
+func (s *GitLocalServiceImpl) Mirror(ctx context.Context, remoteURL, repoPath string) error {
+	if _, err := os.Stat(repoPath); errors.Is(err, os.ErrNotExist) {
+		_, err := s.run(ctx, "", "clone", "--mirror", "--quiet", "--", remoteURL, repoPath)
+		return err
+	}
+	_, err := s.run(ctx, repoPath, "remote", "update", "--prune")
+	return err
+}
+
+// ResolveRef returns the commit hash ref points to, which also checks that
+// repoPath is a readable repository
+func (s *GitLocalServiceImpl) ResolveRef(ctx context.Context, repoPath, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := s.run(ctx, repoPath, "rev-parse", "--verify", "--end-of-options", ref+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// WalkLog reads the history of a repository with git log and calls fn for
+// each commit, in order, as it is read. Renames are detected and reported
+// with their old path. The walk stops at the first error fn returns.
+func (s *GitLocalServiceImpl) WalkLog(ctx context.Context, repoPath string, opts GitLogOptions, fn func(GitLocalCommit) error) error {
+	args := []string{
+		"-c", "log.showSignature=false",
+		"log", "-z", "--numstat", "--find-renames", "--no-color", "--no-textconv",
+		"--decorate-refs=refs/tags/", "--format=" + gitLogFormat,
+	}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since="+opts.Since.UTC().Format(time.RFC3339))
+	}
+	if opts.MaxCount > 0 {
+		args = append(args, "--max-count="+strconv.Itoa(opts.MaxCount))
+	}
+	if opts.Reverse {
+		args = append(args, "--reverse")
+	}
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	args = append(args, "--end-of-options", ref, "--")
+	args = append(args, opts.Paths...)
+
+	walkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := s.command(walkCtx, repoPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &limitedWriter{buf: &stderr, limit: maxGitErrorOutput}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	walkErr := s.scanLog(stdout, opts.Since, fn)
+	if walkErr != nil {
+		// Stop git rather than wait for it to print the rest of the history
+		cancel()
+	}
+	waitErr := cmd.Wait()
+	if walkErr != nil {
+		return walkErr
+	}
+	if waitErr != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return gitCommandError("log", waitErr, stderr.String())
+	}
+	return nil
+}
+
+// ListFiles returns the paths of the files under dir, or of all files when
+// dir is empty, in the tree of ref
+func (s *GitLocalServiceImpl) ListFiles(ctx context.Context, repoPath, ref, dir string) ([]string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	args := []string{"ls-tree", "-r", "-z", "--name-only", "--full-tree", "--end-of-options", ref}
+	if dir != "" {
+		// A lone "--" would be read as a path matching nothing
+		args = append(args, "--", dir)
+	}
+	out, err := s.run(ctx, repoPath, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(string(out), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// ReadFile returns the content of the file at path in the tree of ref
+func (s *GitLocalServiceImpl) ReadFile(ctx context.Context, repoPath, ref, path string) ([]byte, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return s.run(ctx, repoPath, "cat-file", "blob", "--end-of-options", ref+":"+path)
+}
+
+// scanLog parses log output into commits. git log applies --since loosely,
+// so commits are filtered by their commit time again here.
+func (s *GitLocalServiceImpl) scanLog(r io.Reader, since time.Time, fn func(GitLocalCommit) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxGitRecord)
+	scanner.Split(splitGitRecords)
+
+	for scanner.Scan() {
+		record := scanner.Text()
+		if record == "" {
+			continue
+		}
+		commit, err := parseGitRecord(record)
+		if err != nil {
+			return err
+		}
+		if !since.IsZero() && !commit.CommittedAt.After(since) {
+			continue
+		}
+		if err := fn(commit); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// splitGitRecords is a bufio.SplitFunc splitting log output into commits
+func splitGitRecords(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	// Records start with the separator, so the first one is skipped
+	if i := bytes.IndexByte(data[1:], gitRecordSeparator); i >= 0 {
+		return i + 1, bytes.TrimPrefix(data[:i+1], []byte{gitRecordSeparator}), nil
+	}
+	if atEOF {
+		return len(data), bytes.TrimPrefix(data, []byte{gitRecordSeparator}), nil
+	}
+	return 0, nil, nil
+}
+
+// parseGitRecord parses a commit printed with gitLogFormat
+func parseGitRecord(record string) (GitLocalCommit, error) {
+	fields := strings.SplitN(record, gitFieldSeparator, gitLogFields+1)
+	if len(fields) != gitLogFields+1 {
+		return GitLocalCommit{}, fmt.Errorf("malformed git log record: %.40q", record)
+	}
+
+	authoredAt, err := time.Parse(time.RFC3339, fields[4])
+	if err != nil {
+		return GitLocalCommit{}, fmt.Errorf("invalid author date of commit %s: %w", fields[0], err)
+	}
+	committedAt, err := time.Parse(time.RFC3339, fields[6])
+	if err != nil {
+		return GitLocalCommit{}, fmt.Errorf("invalid commit date of commit %s: %w", fields[0], err)
+	}
+
+	commit := GitLocalCommit{
+		SHA:           fields[0],
+		Parents:       strings.Fields(fields[1]),
+		AuthorName:    fields[2],
+		AuthorEmail:   fields[3],
+		AuthoredAt:    authoredAt,
+		CommitterName: fields[5],
+		CommittedAt:   committedAt,
+		Tags:          parseGitTags(fields[7]),
+		Trailers:      parseGitTrailers(fields[8]),
+		Message:       strings.TrimRight(fields[9], "\n"),
+	}
+
+	commit.Files, err = parseGitNumstat(fields[10])
+	if err != nil {
+		return GitLocalCommit{}, fmt.Errorf("invalid file stats of commit %s: %w", commit.SHA, err)
+	}
+	return commit, nil
+}
+
+// parseGitTags reads tag names from ref decorations such as "tag: v1.2.0"
+func parseGitTags(decorations string) []string {
+	var tags []string
+	for _, decoration := range strings.Split(decorations, ", ") {
+		if tag, ok := strings.CutPrefix(strings.TrimSpace(decoration), "tag: "); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseGitTrailers reads the unfolded "Key: value" lines git prints for
+// %(trailers:only,unfold)
+func parseGitTrailers(block string) []GitTrailer {
+	var trailers []GitTrailer
+	for _, line := range strings.Split(block, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(key) == "" {
+			continue
+		}
+		trailers = append(trailers, GitTrailer{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)})
+	}
+	return trailers
+}
+
+// parseGitNumstat reads the NUL-terminated output of --numstat -z. A change
+// is "added\tdeleted\tpath", or "added\tdeleted\t" followed by the old and
+// new path for renames.
+func parseGitNumstat(stats string) ([]GitFileChange, error) {
+	tokens := strings.Split(strings.TrimRight(strings.TrimLeft(stats, "\x00\n"), "\x00"), "\x00")
+	var files []GitFileChange
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimLeft(tokens[i], "\n")
+		if token == "" {
+			continue
+		}
+		parts := strings.SplitN(token, "\t", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed stat %q", token)
+		}
+
+		change := GitFileChange{Path: parts[2]}
+		if parts[0] == "-" && parts[1] == "-" {
+			change.Binary = true
+		} else {
+			var err error
+			if change.Additions, err = strconv.Atoi(parts[0]); err != nil {
+				return nil, fmt.Errorf("malformed stat %q", token)
+			}
+			if change.Deletions, err = strconv.Atoi(parts[1]); err != nil {
+				return nil, fmt.Errorf("malformed stat %q", token)
+			}
+		}
+		if change.Path == "" {
+			if i+2 >= len(tokens) {
+				return nil, fmt.Errorf("truncated rename %q", token)
+			}
+			change.OldPath, change.Path = tokens[i+1], tokens[i+2]
+			i += 2
+		}
+		files = append(files, change)
+	}
+	return files, nil
+}
+
+// run runs a git command in repoPath and returns its output
+func (s *GitLocalServiceImpl) run(ctx context.Context, repoPath string, args ...string) ([]byte, error) {
+	cmd := s.command(ctx, repoPath, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &limitedWriter{buf: &stderr, limit: maxGitErrorOutput}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, gitCommandError(args[0], err, stderr.String())
+	}
+	return out, nil
+}
+
+// command prepares a git command that never prompts, since there is no one
+// to answer
+func (s *GitLocalServiceImpl) command(ctx context.Context, repoPath string, args ...string) *exec.Cmd {
+	if repoPath != "" {
+		args = append([]string{"-C", repoPath}, args...)
+	}
+	cmd := exec.CommandContext(ctx, s.gitPath, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+	return cmd
+}
+
+// gitCommandError wraps the failure of a git command. Failures to start git
+// at all, such as a missing binary, are returned as they are.
+func gitCommandError(command string, err error, stderr string) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	return &GitCommandError{
+		Command:  command,
+		ExitCode: exitErr.ExitCode(),
+		Stderr:   strings.TrimSpace(stderr),
+	}
+}
+
+// limitedWriter keeps the first limit bytes written to it and drops the rest
+type limitedWriter struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.limit - w.buf.Len(); room > 0 {
+		if len(p) > room {
+			w.buf.Write(p[:room])
+		} else {
+			w.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitRecord(t *testing.T) {
+	record := strings.Join([]string{
+		"c0ffee", "abc123 def456", "Alice", "alice@example.com", "2024-05-01T10:00:00+02:00",
+		"Bob", "2024-05-01T11:00:00+02:00", "HEAD -> main, tag: v1.0.0, tag: stable",
+		"Refs: PAY-1\nco-authored-by: Carol <carol@example.com>\n",
+		"Merge retries\n\nRefs: PAY-1\nco-authored-by: Carol <carol@example.com>\n",
+		"\x00\n3\t1\tdocs/retries.md\x00-\t-\tlogo.png\x000\t0\t\x00old name.go\x00new name.go\x00",
+	}, gitFieldSeparator)
+
+	commit, err := parseGitRecord(record)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abc123", "def456"}, commit.Parents)
+	assert.True(t, commit.CommittedAt.Equal(time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "Merge retries", commit.Subject())
+	assert.Equal(t, []string{"v1.0.0", "stable"}, commit.Tags)
+	assert.Equal(t, []string{"Carol <carol@example.com>"}, commit.TrailerValues("Co-authored-by"))
+
+	require.Len(t, commit.Files, 3)
+	assert.Equal(t, GitFileChange{Path: "docs/retries.md", Additions: 3, Deletions: 1}, commit.Files[0])
+	assert.Equal(t, GitFileChange{Path: "logo.png", Binary: true}, commit.Files[1])
+	assert.Equal(t, GitFileChange{Path: "new name.go", OldPath: "old name.go"}, commit.Files[2])
+
+	_, err = parseGitRecord("c0ffee" + gitFieldSeparator + "truncated")
+	assert.ErrorContains(t, err, "malformed")
+
+	_, err = parseGitNumstat("3\t1\t\x00only-old.go\x00")
+	assert.ErrorContains(t, err, "truncated rename")
+}
+
+func TestGitLocalService_MirrorTreatsTheRemoteAsARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "marker")
+
+	// A remote that looks like an option must not be parsed as one
+	err := NewGitLocalService("").Mirror(context.Background(), "--upload-pack=touch "+marker, filepath.Join(dir, "mirror.git"))
+	var gitErr *GitCommandError
+	require.ErrorAs(t, err, &gitErr)
+	assert.Contains(t, gitErr.Stderr, "repository '--upload-pack=touch "+marker+"' does not exist")
+	assert.NoFileExists(t, marker)
+	_, statErr := os.Stat(filepath.Join(dir, "mirror.git"))
+	assert.True(t, os.IsNotExist(statErr))
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+const (
+	// rrfK damps the advantage of top ranks in reciprocal rank fusion; 60 is
+	// the value from the original RRF paper and works well without tuning
+	rrfK = 60
+
+	// defaultHybridSearchLimit matches the repository's default result limit
+	defaultHybridSearchLimit = 50
+
+	// hybridCandidateFactor sets how many candidates each ranking contributes
+	// per requested result, so entities ranked low by one ranking but high by
+	// the other still make it into the fused list
+	hybridCandidateFactor = 4
+	maxHybridCandidates   = 200
+
+	// recencyHalfLife is the age at which the recency boost halves
+	recencyHalfLife = 30 * 24 * time.Hour
+
+	// defaultAuthorityWeight is the authority boost when the query sets none
+	defaultAuthorityWeight = 0.5
+)
+
+// hybridSearch runs the full-text and vector searches in parallel and fuses
+// their rankings. Without an embedder, or when one search fails, the other
+// ranking is used on its own.
+func (kg *KnowledgeGraphServiceImpl) hybridSearch(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultHybridSearchLimit
+	}
+	candidates := *query
+	candidates.Limit = max(minInt(limit*hybridCandidateFactor, maxHybridCandidates), limit)
+
+	var (
+		wg                      sync.WaitGroup
+		lexical, semantic       []models.SearchResult
+		lexicalErr, semanticErr error
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		lexical, lexicalErr = kg.repository.SearchKnowledgeEntitiesByProject(ctx, projectID, &candidates)
+	}()
+
+	semanticEnabled := kg.embedder != nil && strings.TrimSpace(query.Query) != ""
+	if semanticEnabled {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semantic, semanticErr = kg.semanticSearch(ctx, projectID, &candidates)
+		}()
+	}
+	wg.Wait()
+
+	switch {
+	case lexicalErr != nil && (!semanticEnabled || semanticErr != nil):
+		if semanticErr != nil {
+			return nil, fmt.Errorf("full-text search failed: %v; vector search failed: %w", lexicalErr, semanticErr)
+		}
+		return nil, lexicalErr
+	case lexicalErr != nil:
+		kg.loggerFor(ctx).Error("Full-text search failed, using vector search only", lexicalErr, map[string]interface{}{
+			"project_id": projectID,
+		})
+		lexical = nil
+	case semanticErr != nil:
+		kg.loggerFor(ctx).Error("Vector search failed, using full-text search only", semanticErr, map[string]interface{}{
+			"project_id": projectID,
+		})
+		semantic = nil
+	}
+
+	var degrees map[string]int
+	if query.DegreeWeight > 0 {
+		degrees = kg.relationshipDegrees(ctx, lexical, semantic)
+	}
+
+	return fuseSearchResults(lexical, semantic, query, degrees, time.Now(), limit), nil
+}
+
+// semanticSearch embeds the query text and finds the nearest entities
+func (kg *KnowledgeGraphServiceImpl) semanticSearch(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	embeddings, err := kg.embedder.Embed(ctx, []string{query.Query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(embeddings) != 1 {
+		return nil, fmt.Errorf("embedder returned %d embeddings for one query", len(embeddings))
+	}
+	return kg.repository.SearchKnowledgeEntitiesByEmbedding(ctx, projectID, embeddings[0], kg.embedder.Model(), query)
+}
+
+// relationshipDegrees counts the relationships of every candidate. Counting
+// only affects the boost, so a failure is logged and the boost skipped.
+func (kg *KnowledgeGraphServiceImpl) relationshipDegrees(ctx context.Context, rankings ...[]models.SearchResult) map[string]int {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, ranking := range rankings {
+		for _, result := range ranking {
+			if !seen[result.Entity.ID] {
+				seen[result.Entity.ID] = true
+				ids = append(ids, result.Entity.ID)
+			}
+		}
+	}
+
+	degrees, err := kg.repository.CountKnowledgeRelationships(ctx, ids)
+	if err != nil {
+		kg.loggerFor(ctx).Error("Failed to count relationships for search boost", err, map[string]interface{}{
+			"candidates": len(ids),
+		})
+		return nil
+	}
+	return degrees
+}
+
+// fuseSearchResults merges the full-text and vector rankings by reciprocal
+// rank fusion: an entity scores weight/(rrfK+rank) for each ranking it
+// appears in. The score is then multiplied by the optional boosts
+//
+//	1 + RecencyWeight*0.5^(age/recencyHalfLife) + DegreeWeight*log(1+degree)/log(1+maxDegree)
+//
+// and, for authoritative entities such as ADRs, by 1 + AuthorityWeight.
+// Similarity is the fused score relative to the best score possible, so it
+// stays between 0 and 1 whatever the weights.
+func fuseSearchResults(lexical, semantic []models.SearchResult, query *models.KnowledgeGraphQuery, degrees map[string]int, now time.Time, limit int) []models.SearchResult {
+	lexicalWeight := searchWeight(query.LexicalWeight)
+	semanticWeight := searchWeight(query.SemanticWeight)
+	recencyWeight := math.Max(query.RecencyWeight, 0)
+	degreeWeight := math.Max(query.DegreeWeight, 0)
+	authorityWeight := defaultAuthorityWeight
+	if query.AuthorityWeight != nil {
+		authorityWeight = math.Max(*query.AuthorityWeight, 0)
+	}
+
+	type fused struct {
+		entity models.KnowledgeEntity
+		score  float64
+	}
+	byID := make(map[string]*fused)
+	var order []*fused
+	add := func(ranking []models.SearchResult, weight float64) {
+		for i, result := range ranking {
+			entry, ok := byID[result.Entity.ID]
+			if !ok {
+				entry = &fused{entity: result.Entity}
+				byID[result.Entity.ID] = entry
+				order = append(order, entry)
+			}
+			entry.score += weight / float64(rrfK+i+1)
+		}
+	}
+	add(lexical, lexicalWeight)
+	add(semantic, semanticWeight)
+
+	maxDegree := 0
+	for _, entry := range order {
+		if degrees[entry.entity.ID] > maxDegree {
+			maxDegree = degrees[entry.entity.ID]
+		}
+	}
+	if maxDegree == 0 {
+		degreeWeight = 0
+	}
+
+	for _, entry := range order {
+		boost := 1.0
+		if recencyWeight > 0 {
+			updated := entry.entity.UpdatedAt
+			if updated.IsZero() {
+				updated = entry.entity.CreatedAt
+			}
+			age := math.Max(now.Sub(updated).Hours(), 0)
+			boost += recencyWeight * math.Pow(0.5, age/recencyHalfLife.Hours())
+		}
+		if degreeWeight > 0 {
+			boost += degreeWeight * math.Log1p(float64(degrees[entry.entity.ID])) / math.Log1p(float64(maxDegree))
+		}
+		if authoritative, _ := entry.entity.Metadata["authoritative"].(bool); authoritative {
+			boost *= 1 + authorityWeight
+		}
+		entry.score *= boost
+	}
+
+	// Ties keep the order entities were first seen in, full-text ranking first
+	sort.SliceStable(order, func(i, j int) bool { return order[i].score > order[j].score })
+	if len(order) > limit {
+		order = order[:limit]
+	}
+
+	// Only rankings that returned anything count toward the best possible score
+	best := 0.0
+	if len(lexical) > 0 {
+		best += lexicalWeight
+	}
+	if len(semantic) > 0 {
+		best += semanticWeight
+	}
+	best = best / float64(rrfK+1) * (1 + recencyWeight + degreeWeight)
+
+	results := make([]models.SearchResult, len(order))
+	for i, entry := range order {
+		similarity := 0.0
+		if best > 0 {
+			similarity = math.Min(entry.score/best, 1)
+		}
+		results[i] = models.SearchResult{
+			Entity:     entry.entity,
+			Similarity: similarity,
+			Rank:       i + 1,
+		}
+	}
+	return results
+}
+
+// searchWeight returns a ranking's weight, 1 when unset and never negative
+func searchWeight(weight *float64) float64 {
+	if weight == nil {
+		return 1
+	}
+	return math.Max(*weight, 0)
+}
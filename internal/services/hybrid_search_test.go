@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rankedResults(ids ...string) []models.SearchResult {
+	results := make([]models.SearchResult, len(ids))
+	for i, id := range ids {
+		results[i] = models.SearchResult{Entity: models.KnowledgeEntity{ID: id}, Rank: i + 1}
+	}
+	return results
+}
+
+func resultIDs(results []models.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Entity.ID
+	}
+	return ids
+}
+
+func TestFuseSearchResults(t *testing.T) {
+	lexical := rankedResults("a", "b", "c")
+	semantic := rankedResults("c", "d", "a")
+
+	results := fuseSearchResults(lexical, semantic, &models.KnowledgeGraphQuery{}, nil, time.Now(), 10)
+	// a and c appear in both rankings, so they lead
+	assert.Equal(t, []string{"a", "c", "b", "d"}, resultIDs(results))
+	for i, result := range results {
+		assert.Equal(t, i+1, result.Rank)
+		assert.True(t, result.Similarity > 0 && result.Similarity <= 1)
+	}
+
+	semanticOnly := 0.0
+	results = fuseSearchResults(lexical, semantic, &models.KnowledgeGraphQuery{LexicalWeight: &semanticOnly}, nil, time.Now(), 2)
+	assert.Equal(t, []string{"c", "d"}, resultIDs(results))
+	assert.InDelta(t, 1.0, results[0].Similarity, 1e-9, "the top of the only weighted ranking is a perfect match")
+}
+
+func TestFuseSearchResults_Boosts(t *testing.T) {
+	now := time.Now()
+	lexical := rankedResults("old", "new", "hub")
+	lexical[0].Entity.UpdatedAt = now.Add(-365 * 24 * time.Hour)
+	lexical[1].Entity.UpdatedAt = now
+	lexical[2].Entity.UpdatedAt = now.Add(-365 * 24 * time.Hour)
+
+	results := fuseSearchResults(lexical, nil, &models.KnowledgeGraphQuery{RecencyWeight: 1}, nil, now, 10)
+	assert.Equal(t, "new", results[0].Entity.ID)
+
+	degrees := map[string]int{"hub": 12, "old": 1}
+	results = fuseSearchResults(lexical, nil, &models.KnowledgeGraphQuery{DegreeWeight: 1}, degrees, now, 10)
+	assert.Equal(t, "hub", results[0].Entity.ID)
+
+	// Without any relationships the degree boost does not lower similarities
+	plain := fuseSearchResults(lexical, nil, &models.KnowledgeGraphQuery{}, nil, now, 10)
+	boosted := fuseSearchResults(lexical, nil, &models.KnowledgeGraphQuery{DegreeWeight: 1}, map[string]int{}, now, 10)
+	assert.Equal(t, plain, boosted)
+
+	// Written decisions outrank mined ones unless the boost is turned off
+	lexical = rankedResults("mined", "adr")
+	lexical[1].Entity.Metadata = map[string]interface{}{"authoritative": true}
+	results = fuseSearchResults(lexical, nil, &models.KnowledgeGraphQuery{}, nil, now, 10)
+	assert.Equal(t, []string{"adr", "mined"}, resultIDs(results))
+	assert.LessOrEqual(t, results[0].Similarity, 1.0)
+	noAuthority := 0.0
+	results = fuseSearchResults(lexical, nil, &models.KnowledgeGraphQuery{AuthorityWeight: &noAuthority}, nil, now, 10)
+	assert.Equal(t, []string{"mined", "adr"}, resultIDs(results))
+}
+
+// hybridRepositoryStub serves fixed full-text and vector rankings
+type hybridRepositoryStub struct {
+	RepositoryStore
+	lexical, semantic       []models.SearchResult
+	lexicalErr, semanticErr error
+	degrees                 map[string]int
+	model                   string
+	candidateLimit          int
+}
+
+func (r *hybridRepositoryStub) SearchKnowledgeEntitiesByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	r.candidateLimit = query.Limit
+	return r.lexical, r.lexicalErr
+}
+
+func (r *hybridRepositoryStub) SearchKnowledgeEntitiesByEmbedding(ctx context.Context, projectID string, embedding []float32, model string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	r.model = model
+	return r.semantic, r.semanticErr
+}
+
+func (r *hybridRepositoryStub) CountKnowledgeRelationships(ctx context.Context, entityIDs []string) (map[string]int, error) {
+	return r.degrees, nil
+}
+
+func TestKnowledgeGraphService_SearchKnowledgeByProject_Hybrid(t *testing.T) {
+	repo := &hybridRepositoryStub{
+		lexical:  rankedResults("webhook-retry"),
+		semantic: rankedResults("stripe-timeouts", "webhook-retry"),
+	}
+	kg := NewKnowledgeGraphService(repo, nil, nil, &SimpleLogger{})
+
+	// Without an embedder only full-text search runs
+	results, err := kg.SearchKnowledgeByProject(context.Background(), "p1", &models.KnowledgeGraphQuery{Query: "invoice retries", Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"webhook-retry"}, resultIDs(results))
+	assert.Equal(t, 20, repo.candidateLimit)
+	assert.Empty(t, repo.model)
+
+	kg.SetEmbedder(NewHashingEmbedder())
+	results, err = kg.SearchKnowledgeByProject(context.Background(), "p1", &models.KnowledgeGraphQuery{Query: "invoice retries", Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"webhook-retry", "stripe-timeouts"}, resultIDs(results))
+	assert.Equal(t, HashingEmbeddingModel, repo.model)
+
+	// Either search failing leaves the other
+	repo.semanticErr = errors.New("pgvector unavailable")
+	results, err = kg.SearchKnowledgeByProject(context.Background(), "p1", &models.KnowledgeGraphQuery{Query: "invoice retries"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"webhook-retry"}, resultIDs(results))
+
+	repo.semanticErr = nil
+	repo.lexicalErr = errors.New("bad tsquery")
+	results, err = kg.SearchKnowledgeByProject(context.Background(), "p1", &models.KnowledgeGraphQuery{Query: "invoice retries"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stripe-timeouts", "webhook-retry"}, resultIDs(results))
+
+	repo.semanticErr = errors.New("pgvector unavailable")
+	_, err = kg.SearchKnowledgeByProject(context.Background(), "p1", &models.KnowledgeGraphQuery{Query: "invoice retries"})
+	assert.Error(t, err)
+}
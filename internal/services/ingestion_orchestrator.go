@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -9,6 +10,10 @@ import (
 	"github.com/DevAnuragT/context_keeper/internal/models"
 )
 
+// ErrIngestionAlreadyRunning is returned when a sync is started while one is
+// already running. A project sync returns it only if every integration was.
+var ErrIngestionAlreadyRunning = errors.New("ingestion already running")
+
 // PlatformConnectorManager defines the interface for managing platform connectors
 type PlatformConnectorManager interface {
 	GetConnector(platform string) (PlatformConnector, error)
@@ -131,22 +136,33 @@ func (io *IngestionOrchestratorImpl) StartProjectIngestion(ctx context.Context,
 		return fmt.Errorf("failed to get project integrations: %w", err)
 	}
 
-	var errors []error
+	var errs []error
+	started, running := 0, 0
 	for _, integration := range integrations {
 		if integration.Status == string(models.IntegrationStatusActive) {
 			if err := io.StartIntegrationIngestion(ctx, integration.ID); err != nil {
-				errors = append(errors, fmt.Errorf("failed to start integration %s: %w", integration.ID, err))
+				if errors.Is(err, ErrIngestionAlreadyRunning) {
+					running++
+					continue
+				}
+				errs = append(errs, fmt.Errorf("failed to start integration %s: %w", integration.ID, err))
 				io.logger.Error("Failed to start integration ingestion", err, map[string]interface{}{
 					"project_id":     projectID,
 					"integration_id": integration.ID,
 					"platform":       integration.Platform,
 				})
+				continue
 			}
+			started++
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to start some integrations: %v", errors)
+	// Integrations that are already syncing are not failures, unless nothing else could be synced
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to start some integrations: %v", errs)
+	}
+	if running > 0 && started == 0 {
+		return fmt.Errorf("%w for every integration of project %s", ErrIngestionAlreadyRunning, projectID)
 	}
 
 	io.logger.Info("Started project ingestion", map[string]interface{}{
@@ -163,7 +179,7 @@ func (io *IngestionOrchestratorImpl) StartIntegrationIngestion(ctx context.Conte
 	io.mu.RLock()
 	if task, exists := io.activeIngestions[integrationID]; exists {
 		io.mu.RUnlock()
-		return fmt.Errorf("ingestion already running for integration %s (status: %s)", integrationID, task.Status)
+		return fmt.Errorf("%w for integration %s (status: %s)", ErrIngestionAlreadyRunning, integrationID, task.Status)
 	}
 	io.mu.RUnlock()
 
@@ -282,25 +298,7 @@ func (io *IngestionOrchestratorImpl) runIntegrationIngestion(ctx context.Context
 	}
 
 	// Convert connector normalized events to service normalized events
-	normalizedEvents := make([]NormalizedEvent, len(connectorNormalizedEvents))
-	for i, ce := range connectorNormalizedEvents {
-		normalizedEvents[i] = NormalizedEvent{
-			PlatformID:  ce.PlatformID,
-			EventType:   EventType(ce.EventType),
-			Timestamp:   ce.Timestamp,
-			Author:      ce.Author,
-			Content:     ce.Content,
-			Title:       ce.Title,
-			ThreadID:    ce.ThreadID,
-			ParentID:    ce.ParentID,
-			FileRefs:    ce.FileRefs,
-			FeatureRefs: ce.FeatureRefs,
-			Labels:      ce.Labels,
-			State:       ce.State,
-			Metadata:    ce.Metadata,
-			Platform:    ce.Platform,
-		}
-	}
+	normalizedEvents := toNormalizedEvents(connectorNormalizedEvents)
 
 	// Process events through context processor
 	if io.contextProcessor != nil {
@@ -344,6 +342,104 @@ func (io *IngestionOrchestratorImpl) runIntegrationIngestion(ctx context.Context
 	})
 }
 
+// IngestIntegrationEvents queues events pushed by an integration's platform
+// and processes them in the background. Unlike a sync it leaves the last sync
+// time alone, so the next sync still covers everything since the previous one;
+// the events are recorded as processed so that sync skips them.
+func (io *IngestionOrchestratorImpl) IngestIntegrationEvents(ctx context.Context, integrationID string, events []PlatformEvent) error {
+	integration, err := io.store.GetProjectIntegration(ctx, integrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get integration: %w", err)
+	}
+
+	if integration.Status != string(models.IntegrationStatusActive) {
+		return fmt.Errorf("integration is not active (status: %s)", integration.Status)
+	}
+
+	connector, err := io.connectorManager.GetConnector(integration.Platform)
+	if err != nil {
+		return fmt.Errorf("failed to get connector: %w", err)
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	go io.processPushedEvents(context.Background(), integration, connector, events)
+
+	return nil
+}
+
+// processPushedEvents normalizes and processes events pushed for integration
+func (io *IngestionOrchestratorImpl) processPushedEvents(ctx context.Context, integration *models.ProjectIntegration, connector PlatformConnector, events []PlatformEvent) {
+	connectorNormalizedEvents, err := connector.NormalizeData(ctx, events)
+	if err != nil {
+		io.logger.Error("Failed to normalize pushed events", err, map[string]interface{}{
+			"integration_id": integration.ID,
+			"event_count":    len(events),
+		})
+		return
+	}
+	normalizedEvents := toNormalizedEvents(connectorNormalizedEvents)
+
+	if io.contextProcessor != nil {
+		if _, err := io.contextProcessor.ProcessEvents(ctx, normalizedEvents); err != nil {
+			io.logger.Error("Failed to process pushed events", err, map[string]interface{}{
+				"integration_id": integration.ID,
+				"event_count":    len(normalizedEvents),
+			})
+			return
+		}
+	}
+
+	checkpoint := make(map[string]interface{}, len(integration.SyncCheckpoint))
+	for key, value := range integration.SyncCheckpoint {
+		checkpoint[key] = value
+	}
+	lastSync, synced := checkpoint["last_sync_time"]
+	checkpoint = io.updateCheckpoint(checkpoint, events, normalizedEvents)
+	if synced {
+		checkpoint["last_sync_time"] = lastSync
+	} else {
+		delete(checkpoint, "last_sync_time")
+	}
+	if err := io.UpdateSyncCheckpoint(ctx, integration.ID, checkpoint); err != nil {
+		io.logger.Error("Failed to update sync checkpoint", err, map[string]interface{}{
+			"integration_id": integration.ID,
+		})
+	}
+
+	io.logger.Info("Processed pushed events", map[string]interface{}{
+		"integration_id": integration.ID,
+		"platform":       integration.Platform,
+		"event_count":    len(events),
+	})
+}
+
+// toNormalizedEvents converts connector normalized events to service ones
+func toNormalizedEvents(events []PlatformNormalizedEvent) []NormalizedEvent {
+	normalized := make([]NormalizedEvent, len(events))
+	for i, ce := range events {
+		normalized[i] = NormalizedEvent{
+			PlatformID:  ce.PlatformID,
+			EventType:   EventType(ce.EventType),
+			Timestamp:   ce.Timestamp,
+			Author:      ce.Author,
+			Content:     ce.Content,
+			Title:       ce.Title,
+			ThreadID:    ce.ThreadID,
+			ParentID:    ce.ParentID,
+			FileRefs:    ce.FileRefs,
+			FeatureRefs: ce.FeatureRefs,
+			Labels:      ce.Labels,
+			State:       ce.State,
+			Metadata:    ce.Metadata,
+			Platform:    ce.Platform,
+		}
+	}
+	return normalized
+}
+
 // StartDataSourceIngestion starts ingestion for a specific data source
 func (io *IngestionOrchestratorImpl) StartDataSourceIngestion(ctx context.Context, dataSourceID string) error {
 	// Get data source
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pushIntegrationStore holds one integration and reports checkpoint updates
+type pushIntegrationStore struct {
+	RepositoryStore
+	integration models.ProjectIntegration
+	updates     chan map[string]interface{}
+}
+
+func (s *pushIntegrationStore) GetProjectIntegration(ctx context.Context, integrationID string) (*models.ProjectIntegration, error) {
+	integration := s.integration
+	return &integration, nil
+}
+
+func (s *pushIntegrationStore) UpdateProjectIntegration(ctx context.Context, integrationID string, updates map[string]interface{}) error {
+	s.updates <- updates
+	return nil
+}
+
+// pushConnector normalizes events one to one
+type pushConnector struct{}
+
+func (pushConnector) FetchEvents(ctx context.Context, since time.Time, limit int) ([]PlatformEvent, error) {
+	return nil, nil
+}
+
+func (pushConnector) NormalizeData(ctx context.Context, events []PlatformEvent) ([]PlatformNormalizedEvent, error) {
+	normalized := make([]PlatformNormalizedEvent, len(events))
+	for i, event := range events {
+		normalized[i] = PlatformNormalizedEvent{PlatformID: event.ID, EventType: event.Type, Timestamp: event.Timestamp, Platform: event.Platform}
+	}
+	return normalized, nil
+}
+
+type pushConnectorManager struct{}
+
+func (pushConnectorManager) GetConnector(platform string) (PlatformConnector, error) {
+	return pushConnector{}, nil
+}
+
+// pushProcessor records the events it processes
+type pushProcessor struct {
+	events chan []NormalizedEvent
+}
+
+func (p *pushProcessor) ProcessEvents(ctx context.Context, events []NormalizedEvent) (*ProcessingResult, error) {
+	p.events <- events
+	return &ProcessingResult{}, nil
+}
+
+func TestIngestIntegrationEvents_ProcessesPushedEventsWithoutMovingTheSyncWindow(t *testing.T) {
+	lastSync := "2024-05-01T10:00:00Z"
+	store := &pushIntegrationStore{
+		integration: models.ProjectIntegration{
+			ID:             "i1",
+			ProjectID:      "p1",
+			Platform:       "linear",
+			Status:         string(models.IntegrationStatusActive),
+			SyncCheckpoint: map[string]interface{}{"last_sync_time": lastSync},
+		},
+		updates: make(chan map[string]interface{}, 1),
+	}
+	processor := &pushProcessor{events: make(chan []NormalizedEvent, 1)}
+	orchestrator := NewIngestionOrchestrator(store, pushConnectorManager{}, processor, nil, &SimpleLogger{})
+
+	events := []PlatformEvent{{ID: "issue_iss-1", Type: "issue", Timestamp: time.Now(), Platform: "linear"}}
+	require.NoError(t, orchestrator.IngestIntegrationEvents(context.Background(), "i1", events))
+
+	select {
+	case processed := <-processor.events:
+		require.Len(t, processed, 1)
+		assert.Equal(t, "issue_iss-1", processed[0].PlatformID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("pushed events were not processed")
+	}
+
+	select {
+	case updates := <-store.updates:
+		checkpoint := updates["sync_checkpoint"].(map[string]interface{})
+		assert.Equal(t, lastSync, checkpoint["last_sync_time"], "a push must not move the next sync's window")
+		assert.Equal(t, []string{"issue_iss-1"}, checkpoint["processed_event_ids"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("sync checkpoint was not updated")
+	}
+	assert.Equal(t, lastSync, store.integration.SyncCheckpoint["last_sync_time"])
+}
+
+func TestIngestIntegrationEvents_RejectsInactiveIntegrations(t *testing.T) {
+	store := &pushIntegrationStore{
+		integration: models.ProjectIntegration{ID: "i1", Platform: "linear", Status: "inactive"},
+	}
+	orchestrator := NewIngestionOrchestrator(store, pushConnectorManager{}, nil, nil, &SimpleLogger{})
+
+	err := orchestrator.IngestIntegrationEvents(context.Background(), "i1", []PlatformEvent{{ID: "issue_iss-1"}})
+	assert.Error(t, err)
+}
@@ -32,6 +32,7 @@ const (
 	EventTypeReaction       EventType = "reaction"
 	EventTypeFileChange     EventType = "file_change"
 	EventTypeDiscussion     EventType = "discussion"
+	EventTypeDecisionRecord EventType = "decision_record" // a written decision, such as an ADR
 )
 
 // NormalizedEvent represents a platform event in common format
@@ -88,6 +89,49 @@ type GitHubService interface {
 	GetUserInfo(ctx context.Context, token string) (*models.User, error)
 }
 
+// GitLabService reads from the GitLab API with a personal, project or group access token
+type GitLabService interface {
+	GetCurrentUser(ctx context.Context, token string) (*GitLabUser, error)
+	GetTokenInfo(ctx context.Context, token string) (*GitLabTokenInfo, error)
+	ListProjects(ctx context.Context, token string, limit int) ([]GitLabProject, error)
+	GetProject(ctx context.Context, token, projectID string) (*GitLabProject, error)
+	ListMergeRequests(ctx context.Context, token, projectID string, since time.Time, limit int) ([]GitLabMergeRequest, error)
+	GetMergeRequestDiffs(ctx context.Context, token, projectID string, iid int64) ([]GitLabDiff, error)
+	ListMergeRequestDiscussions(ctx context.Context, token, projectID string, iid int64) ([]GitLabDiscussion, error)
+	ListIssues(ctx context.Context, token, projectID string, since time.Time, limit int) ([]GitLabIssue, error)
+	ListCommits(ctx context.Context, token, projectID string, since time.Time, limit int) ([]GitLabCommit, error)
+	GetCommitDiff(ctx context.Context, token, projectID, sha string) ([]GitLabDiff, error)
+}
+
+// JiraService reads from the Jira REST API of Jira Cloud or Jira Data Center
+type JiraService interface {
+	GetCurrentUser(ctx context.Context, creds JiraCredentials) (*JiraUser, error)
+	ListProjects(ctx context.Context, creds JiraCredentials, limit int) ([]JiraProject, error)
+	GetProject(ctx context.Context, creds JiraCredentials, projectKey string) (*JiraProject, error)
+	SearchIssues(ctx context.Context, creds JiraCredentials, jql string, limit int) ([]JiraIssue, error)
+	ListIssueComments(ctx context.Context, creds JiraCredentials, issueKey string) ([]JiraComment, error)
+}
+
+// LinearService reads from the Linear GraphQL API. Lists are filtered by
+// LinearFilter and follow Linear's cursor pagination up to limit items.
+type LinearService interface {
+	GetViewer(ctx context.Context, apiKey string) (*LinearUser, error)
+	ListIssues(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearIssue, error)
+	ListComments(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearComment, error)
+	ListProjects(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearProject, error)
+	ListCycles(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearCycle, error)
+}
+
+// GitLocalService reads history from git repositories on the local disk, so
+// that it is available without a hosting platform's API
+type GitLocalService interface {
+	Mirror(ctx context.Context, remoteURL, repoPath string) error
+	ResolveRef(ctx context.Context, repoPath, ref string) (string, error)
+	WalkLog(ctx context.Context, repoPath string, opts GitLogOptions, fn func(GitLocalCommit) error) error
+	ListFiles(ctx context.Context, repoPath, ref, dir string) ([]string, error)
+	ReadFile(ctx context.Context, repoPath, ref, path string) ([]byte, error)
+}
+
 // JobService handles background ingestion jobs
 type JobService interface {
 	CreateIngestionJob(ctx context.Context, repoID int64, userID string) (*models.IngestionJob, error)
@@ -117,6 +161,40 @@ type KnowledgeGraphService interface {
 	GetContextForFileByProject(ctx context.Context, projectID, filePath string) (*FileContextResponse, error)
 	GetDecisionHistoryByProject(ctx context.Context, projectID, target string) (*DecisionHistoryResponse, error)
 	GetRecentArchitectureDiscussionsByProject(ctx context.Context, projectID string, limit int) ([]models.DiscussionSummary, error)
+
+	// Project-scoped record lookups
+	ListKnowledgeEntitiesByProject(ctx context.Context, projectID string, entityTypes []string, limit int) ([]models.KnowledgeEntity, error)
+	GetDecisionRecordByProject(ctx context.Context, projectID, decisionID string) (*models.DecisionRecord, error)
+	GetDiscussionSummaryByProject(ctx context.Context, projectID, summaryID string) (*models.DiscussionSummary, error)
+	GetFeatureContextByProject(ctx context.Context, projectID, featureID string) (*models.FeatureContext, error)
+	GetFileContextHistoryByProject(ctx context.Context, projectID, filePath string) ([]models.FileContextHistory, error)
+
+	// Project-scoped graph exploration
+	ResolveKnowledgeEntityByProject(ctx context.Context, projectID, idOrTitle string) (*models.KnowledgeEntity, error)
+	TraverseRelationshipsByProject(ctx context.Context, projectID, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error)
+
+	// Project-scoped writes attributed to a user
+	RecordDecisionByProject(ctx context.Context, projectID, userID string, decision *DecisionRecord) (*models.DecisionRecord, error)
+	AnnotateFileContextByProject(ctx context.Context, projectID, userID string, fileContext *FileContextHistory) (*models.FileContextHistory, error)
+	LinkEntitiesByProject(ctx context.Context, projectID, userID, sourceEntityID, targetEntityID, relationshipType string, strength float64) (*models.KnowledgeRelationship, error)
+
+	// Project-scoped argument completion
+	SuggestFilePathsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error)
+	SuggestFeatureNamesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error)
+	SuggestDecisionTargetsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error)
+}
+
+// MCPUsageService meters MCP tool calls and enforces project usage quotas
+type MCPUsageService interface {
+	// Quota enforcement before a call runs; an admitted call counts against the
+	// quotas until release is called, once the call has been recorded
+	CheckQuota(ctx context.Context, projectID, userID string) (release func(), err error)
+	
+	// Metering after a call finishes
+	RecordToolCall(ctx context.Context, call *models.MCPToolCall) error
+	
+	// Usage reporting for project admins
+	GetUsageSummary(ctx context.Context, projectID string, since time.Time) (*models.MCPUsageSummary, error)
 }
 
 // PermissionService handles project-level permission checks
@@ -148,6 +226,9 @@ type IngestionOrchestrator interface {
 	// Start ingestion for a specific data source
 	StartDataSourceIngestion(ctx context.Context, dataSourceID string) error
 	
+	// Ingest events pushed by an integration's platform, such as webhook deliveries
+	IngestIntegrationEvents(ctx context.Context, integrationID string, events []PlatformEvent) error
+	
 	// Stop ingestion for a project
 	StopProjectIngestion(ctx context.Context, projectID string) error
 	
@@ -276,15 +357,25 @@ type RepositoryStore interface {
 	UpdateProjectDataSource(ctx context.Context, dataSourceID string, updates map[string]interface{}) error
 	DeleteProjectDataSource(ctx context.Context, dataSourceID string) error
 	
+	// MCP usage operations
+	CreateMCPToolCall(ctx context.Context, call *models.MCPToolCall) error
+	GetMCPToolCallTotals(ctx context.Context, projectID, userID string, since time.Time) (*models.MCPToolCallTotals, error)
+	GetMCPToolUsageByProject(ctx context.Context, projectID string, since time.Time) ([]models.MCPToolUsage, error)
+	
 	// Knowledge Graph operations
 	CreateKnowledgeEntity(ctx context.Context, entity *models.KnowledgeEntity) error
 	GetKnowledgeEntity(ctx context.Context, id string) (*models.KnowledgeEntity, error)
 	UpdateKnowledgeEntity(ctx context.Context, entity *models.KnowledgeEntity) error
 	DeleteKnowledgeEntity(ctx context.Context, id string) error
+	ListKnowledgeEntitiesNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.KnowledgeEntity, error)
+	UpdateKnowledgeEntityEmbedding(ctx context.Context, id string, embedding []float32, model string, contentUpdatedAt time.Time) error
 	
 	// Project-scoped knowledge graph operations
 	GetKnowledgeEntitiesByProject(ctx context.Context, projectID string, entityTypes []string, limit int) ([]models.KnowledgeEntity, error)
 	GetKnowledgeEntityByIDAndProject(ctx context.Context, id, projectID string) (*models.KnowledgeEntity, error)
+	ListFilePathsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error)
+	ListFeatureNamesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error)
+	ListDecisionTitlesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error)
 	
 	CreateKnowledgeRelationship(ctx context.Context, relationship *models.KnowledgeRelationship) error
 	GetKnowledgeRelationships(ctx context.Context, entityID string) ([]models.KnowledgeRelationship, error)
@@ -311,6 +402,8 @@ type RepositoryStore interface {
 	// Project-scoped semantic search operations
 	SearchKnowledgeEntitiesByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error)
 	SearchSimilarEntitiesByProject(ctx context.Context, projectID string, embedding []float32, entityTypes []string, limit int) ([]models.SearchResult, error)
+	SearchKnowledgeEntitiesByEmbedding(ctx context.Context, projectID string, embedding []float32, model string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error)
+	CountKnowledgeRelationships(ctx context.Context, entityIDs []string) (map[string]int, error)
 	
 	// Graph traversal operations
 	TraverseKnowledgeGraph(ctx context.Context, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error)
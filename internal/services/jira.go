@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// jiraPageSize is the largest page Jira returns for searches with fields
+	jiraPageSize = 100
+
+	// maxJiraErrorBody bounds how much of an error response is kept
+	maxJiraErrorBody = 512
+
+	// jiraTimeLayout is the timestamp format of the Jira REST API
+	jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+)
+
+// jiraIssueFields are the issue fields ingestion reads
+var jiraIssueFields = []string{
+	"summary", "description", "issuetype", "status", "reporter", "assignee",
+	"labels", "created", "updated", "resolutiondate", "parent", "issuelinks", "project",
+}
+
+// JiraServiceImpl implements JiraService against the REST API (v2) of Jira
+// Cloud or Jira Data Center. Version 2 returns descriptions and comments as
+// text rather than Atlassian Document Format.
+type JiraServiceImpl struct {
+	httpClient *http.Client
+	apiURL     string
+}
+
+// NewJiraService creates a Jira API client for the site at baseURL, such as
+// https://example.atlassian.net
+func NewJiraService(baseURL string) JiraService {
+	return &JiraServiceImpl{
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		apiURL: strings.TrimRight(strings.TrimSpace(baseURL), "/") + "/rest/api/2",
+	}
+}
+
+// JiraCredentials authenticate Jira requests. Jira Cloud takes an account
+// email with an API token; Jira Data Center takes a personal access token
+// on its own.
+type JiraCredentials struct {
+	Email    string
+	APIToken string
+}
+
+// JiraAPIError is returned for Jira API responses with an error status
+type JiraAPIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *JiraAPIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("Jira API request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("Jira API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// JiraTime is a timestamp as Jira formats it, e.g. 2024-05-01T10:00:00.000+0000
+type JiraTime struct {
+	time.Time
+}
+
+// UnmarshalJSON parses Jira timestamps, leaving null and empty ones zero
+func (t *JiraTime) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range []string{jiraTimeLayout, time.RFC3339Nano} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid Jira timestamp %q", value)
+}
+
+// JiraUser represents a Jira user. Jira Cloud identifies users by account
+// ID, Jira Data Center by name.
+type JiraUser struct {
+	AccountID    string `json:"accountId"`
+	Name         string `json:"name"`
+	DisplayName  string `json:"displayName"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// JiraProject represents a Jira project from API
+type JiraProject struct {
+	ID             string `json:"id"`
+	Key            string `json:"key"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	ProjectTypeKey string `json:"projectTypeKey"`
+}
+
+// JiraIssueType is the type of an issue, such as Story, Bug or Epic
+type JiraIssueType struct {
+	Name    string `json:"name"`
+	Subtask bool   `json:"subtask"`
+}
+
+// JiraStatus is the workflow status of an issue
+type JiraStatus struct {
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"` // new, indeterminate or done
+	} `json:"statusCategory"`
+}
+
+// JiraIssueRef is an issue referenced from another, as its parent or a link
+type JiraIssueRef struct {
+	ID     string `json:"id"`
+	Key    string `json:"key"`
+	Fields struct {
+		Summary   string        `json:"summary"`
+		IssueType JiraIssueType `json:"issuetype"`
+	} `json:"fields"`
+}
+
+// JiraIssueLink links two issues. Exactly one of InwardIssue and
+// OutwardIssue is set; the link reads "<this issue> <Outward> <OutwardIssue>"
+// or "<this issue> <Inward> <InwardIssue>".
+type JiraIssueLink struct {
+	ID   string `json:"id"`
+	Type struct {
+		Name    string `json:"name"`
+		Inward  string `json:"inward"`
+		Outward string `json:"outward"`
+	} `json:"type"`
+	InwardIssue  *JiraIssueRef `json:"inwardIssue"`
+	OutwardIssue *JiraIssueRef `json:"outwardIssue"`
+}
+
+// JiraIssueFields are the fields of an issue ingestion reads
+type JiraIssueFields struct {
+	Summary        string          `json:"summary"`
+	Description    string          `json:"description"`
+	IssueType      JiraIssueType   `json:"issuetype"`
+	Status         JiraStatus      `json:"status"`
+	Reporter       *JiraUser       `json:"reporter"`
+	Assignee       *JiraUser       `json:"assignee"`
+	Labels         []string        `json:"labels"`
+	Created        JiraTime        `json:"created"`
+	Updated        JiraTime        `json:"updated"`
+	ResolutionDate JiraTime        `json:"resolutiondate"`
+	Parent         *JiraIssueRef   `json:"parent"`
+	IssueLinks     []JiraIssueLink `json:"issuelinks"`
+	Project        struct {
+		Key string `json:"key"`
+	} `json:"project"`
+}
+
+// JiraIssue represents a Jira issue with its changelog
+type JiraIssue struct {
+	ID        string          `json:"id"`
+	Key       string          `json:"key"`
+	Fields    JiraIssueFields `json:"fields"`
+	Changelog struct {
+		Histories []JiraChangelogHistory `json:"histories"`
+	} `json:"changelog"`
+}
+
+// JiraChangelogHistory is one edit of an issue, changing one or more fields
+type JiraChangelogHistory struct {
+	ID      string              `json:"id"`
+	Author  JiraUser            `json:"author"`
+	Created JiraTime            `json:"created"`
+	Items   []JiraChangelogItem `json:"items"`
+}
+
+// JiraChangelogItem is the change of one field
+type JiraChangelogItem struct {
+	Field      string `json:"field"`
+	FromString string `json:"fromString"`
+	ToString   string `json:"toString"`
+}
+
+// JiraComment is a comment on an issue
+type JiraComment struct {
+	ID      string   `json:"id"`
+	Author  JiraUser `json:"author"`
+	Body    string   `json:"body"`
+	Created JiraTime `json:"created"`
+	Updated JiraTime `json:"updated"`
+}
+
+// GetCurrentUser returns the user the credentials belong to
+func (j *JiraServiceImpl) GetCurrentUser(ctx context.Context, creds JiraCredentials) (*JiraUser, error) {
+	var user JiraUser
+	if err := j.get(ctx, creds, j.apiURL+"/myself", &user); err != nil {
+		return nil, fmt.Errorf("failed to get Jira user: %w", err)
+	}
+	return &user, nil
+}
+
+// ListProjects returns up to limit projects the user can browse
+func (j *JiraServiceImpl) ListProjects(ctx context.Context, creds JiraCredentials, limit int) ([]JiraProject, error) {
+	var projects []JiraProject
+	for startAt := 0; ; {
+		query := url.Values{
+			"startAt":    {strconv.Itoa(startAt)},
+			"maxResults": {strconv.Itoa(jiraPageSize)},
+			"orderBy":    {"key"},
+		}
+		var page struct {
+			Values []JiraProject `json:"values"`
+			IsLast bool          `json:"isLast"`
+		}
+		err := j.get(ctx, creds, j.apiURL+"/project/search?"+query.Encode(), &page)
+		if IsJiraStatus(err, http.StatusNotFound) && startAt == 0 {
+			// Jira Data Center lists every project at once
+			err = j.get(ctx, creds, j.apiURL+"/project", &projects)
+			page.IsLast = true
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Jira projects: %w", err)
+		}
+
+		projects = append(projects, page.Values...)
+		if limit > 0 && len(projects) >= limit {
+			return projects[:limit], nil
+		}
+		if page.IsLast || len(page.Values) == 0 {
+			return projects, nil
+		}
+		startAt += len(page.Values)
+	}
+}
+
+// GetProject returns a project by key or numeric ID
+func (j *JiraServiceImpl) GetProject(ctx context.Context, creds JiraCredentials, projectKey string) (*JiraProject, error) {
+	var project JiraProject
+	if err := j.get(ctx, creds, j.apiURL+"/project/"+url.PathEscape(projectKey), &project); err != nil {
+		return nil, fmt.Errorf("failed to get Jira project %s: %w", projectKey, err)
+	}
+	return &project, nil
+}
+
+// SearchIssues returns up to limit issues matching the JQL query, with their
+// changelogs. Jira Cloud pages its search with tokens; Jira Data Center only
+// has the older offset search, which is used when the other is missing.
+func (j *JiraServiceImpl) SearchIssues(ctx context.Context, creds JiraCredentials, jql string, limit int) ([]JiraIssue, error) {
+	issues, err := j.searchIssuesByToken(ctx, creds, jql, limit)
+	if IsJiraStatus(err, http.StatusNotFound) {
+		issues, err = j.searchIssuesByOffset(ctx, creds, jql, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search Jira issues: %w", err)
+	}
+	return issues, nil
+}
+
+func (j *JiraServiceImpl) searchIssuesByToken(ctx context.Context, creds JiraCredentials, jql string, limit int) ([]JiraIssue, error) {
+	query := jiraSearchQuery(jql, limit)
+
+	var issues []JiraIssue
+	for {
+		var page struct {
+			Issues        []JiraIssue `json:"issues"`
+			NextPageToken string      `json:"nextPageToken"`
+			IsLast        bool        `json:"isLast"`
+		}
+		if err := j.get(ctx, creds, j.apiURL+"/search/jql?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, page.Issues...)
+		if limit > 0 && len(issues) >= limit {
+			return issues[:limit], nil
+		}
+		if page.IsLast || page.NextPageToken == "" || len(page.Issues) == 0 {
+			return issues, nil
+		}
+		query.Set("nextPageToken", page.NextPageToken)
+	}
+}
+
+func (j *JiraServiceImpl) searchIssuesByOffset(ctx context.Context, creds JiraCredentials, jql string, limit int) ([]JiraIssue, error) {
+	query := jiraSearchQuery(jql, limit)
+
+	var issues []JiraIssue
+	for {
+		query.Set("startAt", strconv.Itoa(len(issues)))
+		var page struct {
+			Issues []JiraIssue `json:"issues"`
+			Total  int         `json:"total"`
+		}
+		if err := j.get(ctx, creds, j.apiURL+"/search?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, page.Issues...)
+		if limit > 0 && len(issues) >= limit {
+			return issues[:limit], nil
+		}
+		if len(issues) >= page.Total || len(page.Issues) == 0 {
+			return issues, nil
+		}
+	}
+}
+
+// ListIssueComments returns every comment on an issue, oldest first
+func (j *JiraServiceImpl) ListIssueComments(ctx context.Context, creds JiraCredentials, issueKey string) ([]JiraComment, error) {
+	var comments []JiraComment
+	for {
+		query := url.Values{
+			"startAt":    {strconv.Itoa(len(comments))},
+			"maxResults": {strconv.Itoa(jiraPageSize)},
+			"orderBy":    {"created"},
+		}
+		var page struct {
+			Comments []JiraComment `json:"comments"`
+			Total    int           `json:"total"`
+		}
+		if err := j.get(ctx, creds, j.apiURL+"/issue/"+url.PathEscape(issueKey)+"/comment?"+query.Encode(), &page); err != nil {
+			return nil, fmt.Errorf("failed to list comments of %s: %w", issueKey, err)
+		}
+
+		comments = append(comments, page.Comments...)
+		if len(comments) >= page.Total || len(page.Comments) == 0 {
+			return comments, nil
+		}
+	}
+}
+
+// jiraSearchQuery is the query of an issue search page
+func jiraSearchQuery(jql string, limit int) url.Values {
+	pageSize := jiraPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+	return url.Values{
+		"jql":        {jql},
+		"maxResults": {strconv.Itoa(pageSize)},
+		"fields":     {strings.Join(jiraIssueFields, ",")},
+		"expand":     {"changelog"},
+	}
+}
+
+// JiraJQLString quotes a value for use in a JQL query
+func JiraJQLString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// get makes an authenticated GET request and decodes the JSON response
+func (j *JiraServiceImpl) get(ctx context.Context, creds JiraCredentials, requestURL string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+
+	if creds.Email != "" {
+		req.SetBasicAuth(creds.Email, creds.APIToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+creds.APIToken)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "ContextKeeper/1.0")
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxJiraErrorBody))
+		apiErr := &JiraAPIError{StatusCode: resp.StatusCode, Message: jiraErrorMessage(body)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode Jira response: %w", err)
+	}
+	return nil
+}
+
+// jiraErrorMessage extracts the messages from a Jira error body, which is
+// {"errorMessages": [...], "errors": {field: message}}
+func jiraErrorMessage(body []byte) string {
+	var payload struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		messages := payload.ErrorMessages
+		fields := make([]string, 0, len(payload.Errors))
+		for field := range payload.Errors {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			messages = append(messages, field+": "+payload.Errors[field])
+		}
+		if len(messages) > 0 {
+			return strings.Join(messages, "; ")
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// IsJiraStatus reports whether err is a Jira API error with the status
+func IsJiraStatus(err error, status int) bool {
+	var apiErr *JiraAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,460 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// maxJiraProjects bounds the projects listed for selection
+const maxJiraProjects = 1000
+
+// JiraIntegrationService handles Jira integration operations
+type JiraIntegrationService interface {
+	// API token installation flow
+	ProcessTokenInstallation(ctx context.Context, req *JiraTokenInstallationRequest, userID string) (*models.ProjectIntegration, error)
+
+	// Project management
+	GetAvailableProjects(ctx context.Context, projectID, integrationID string) ([]JiraProjectInfo, error)
+	SelectProjects(ctx context.Context, req *JiraProjectSelectionRequest, userID string) ([]models.ProjectDataSource, error)
+
+	// Configuration management
+	UpdateConfiguration(ctx context.Context, req *IntegrationConfigurationRequest, userID string) (*models.ProjectIntegration, error)
+
+	// Status and health
+	GetIntegrationStatus(ctx context.Context, projectID, integrationID string) (*JiraIntegrationStatus, error)
+
+	// Integration lifecycle
+	DeleteIntegration(ctx context.Context, projectID, integrationID, userID string) error
+
+	// Credential management
+	ValidateCredentials(ctx context.Context, integrationID string) error
+}
+
+// JiraIntegrationServiceImpl implements JiraIntegrationService
+type JiraIntegrationServiceImpl struct {
+	config     *config.Config
+	store      RepositoryStore
+	encryptSvc EncryptionService
+	logger     Logger
+}
+
+// NewJiraIntegrationService creates a new Jira integration service
+func NewJiraIntegrationService(
+	cfg *config.Config,
+	store RepositoryStore,
+	encryptSvc EncryptionService,
+	logger Logger,
+) JiraIntegrationService {
+	return &JiraIntegrationServiceImpl{
+		config:     cfg,
+		store:      store,
+		encryptSvc: encryptSvc,
+		logger:     logger,
+	}
+}
+
+// Request/Response types
+type JiraTokenInstallationRequest struct {
+	ProjectID string `json:"project_id"`
+	BaseURL   string `json:"base_url"` // e.g. https://example.atlassian.net
+	Email     string `json:"email"`    // Jira Cloud only; omit for a Data Center personal access token
+	APIToken  string `json:"api_token"`
+}
+
+type JiraProjectSelectionRequest struct {
+	ProjectID     string   `json:"project_id"`
+	IntegrationID string   `json:"integration_id"`
+	ProjectKeys   []string `json:"project_keys"`
+}
+
+type JiraProjectInfo struct {
+	ID             string `json:"id"`
+	Key            string `json:"key"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	ProjectTypeKey string `json:"project_type_key"`
+	Selected       bool   `json:"selected"`
+}
+
+type JiraIntegrationStatus struct {
+	IntegrationID    string                 `json:"integration_id"`
+	Status           string                 `json:"status"`
+	LastSyncAt       *time.Time             `json:"last_sync_at"`
+	LastSyncStatus   *string                `json:"last_sync_status"`
+	ErrorMessage     *string                `json:"error_message"`
+	CredentialsValid bool                   `json:"credentials_valid"`
+	ProjectCount     int                    `json:"project_count"`
+	Configuration    map[string]interface{} `json:"configuration"`
+}
+
+// ProcessTokenInstallation connects a Jira site with an API token and account
+// email, or a Data Center personal access token
+func (j *JiraIntegrationServiceImpl) ProcessTokenInstallation(ctx context.Context, req *JiraTokenInstallationRequest, userID string) (*models.ProjectIntegration, error) {
+	if req.APIToken == "" {
+		return nil, fmt.Errorf("API token required")
+	}
+
+	baseURL := strings.TrimRight(req.BaseURL, "/")
+	if parsed, err := url.Parse(baseURL); baseURL == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: expected an http or https URL", req.BaseURL)
+	}
+
+	// Check if integration already exists for this project
+	existing, err := j.store.GetProjectIntegrationByPlatform(ctx, req.ProjectID, string(models.PlatformJira))
+	if err == nil && existing != nil {
+		return nil, fmt.Errorf("Jira integration already exists for project")
+	}
+
+	// Validate the token against the site
+	creds := JiraCredentials{Email: req.Email, APIToken: req.APIToken}
+	user, err := NewJiraService(baseURL).GetCurrentUser(ctx, creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate Jira API token: %w", err)
+	}
+
+	// Encrypt credentials
+	encryptedToken, err := j.encryptSvc.Encrypt(ctx, req.APIToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt API token: %w", err)
+	}
+
+	// Create integration record
+	integration := &models.ProjectIntegration{
+		ID:              generateID(),
+		ProjectID:       req.ProjectID,
+		Platform:        string(models.PlatformJira),
+		IntegrationType: string(models.IntegrationTypeAPIToken),
+		Status:          string(models.IntegrationStatusActive),
+		Configuration: map[string]interface{}{
+			"base_url":     baseURL,
+			"account_id":   user.AccountID,
+			"display_name": user.DisplayName,
+		},
+		Credentials: map[string]interface{}{
+			"email":     req.Email,
+			"api_token": encryptedToken,
+		},
+		LastSyncAt:     nil,
+		LastSyncStatus: nil,
+		ErrorMessage:   nil,
+		SyncCheckpoint: map[string]interface{}{},
+		CreatedBy:      userID,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	// Save integration
+	if err := j.store.CreateProjectIntegration(ctx, integration); err != nil {
+		return nil, fmt.Errorf("failed to create integration: %w", err)
+	}
+
+	j.logger.Info("Jira integration created", map[string]interface{}{
+		"project_id":     req.ProjectID,
+		"integration_id": integration.ID,
+		"base_url":       baseURL,
+		"display_name":   user.DisplayName,
+		"user_id":        userID,
+	})
+
+	return integration, nil
+}
+
+// GetAvailableProjects gets the Jira projects the token can browse, for selection
+func (j *JiraIntegrationServiceImpl) GetAvailableProjects(ctx context.Context, projectID, integrationID string) ([]JiraProjectInfo, error) {
+	integration, jira, creds, err := j.getIntegrationClient(ctx, projectID, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := jira.ListProjects(ctx, creds, maxJiraProjects)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects from Jira: %w", err)
+	}
+
+	// Get already selected projects
+	selectedProjects, err := j.store.GetProjectDataSourcesByIntegration(ctx, integration.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get selected projects: %w", err)
+	}
+
+	selectedMap := make(map[string]bool)
+	for _, project := range selectedProjects {
+		selectedMap[project.SourceID] = true
+	}
+
+	infos := make([]JiraProjectInfo, len(projects))
+	for i, project := range projects {
+		infos[i] = JiraProjectInfo{
+			ID:             project.ID,
+			Key:            project.Key,
+			Name:           project.Name,
+			Description:    project.Description,
+			ProjectTypeKey: project.ProjectTypeKey,
+			Selected:       selectedMap[project.Key],
+		}
+	}
+
+	return infos, nil
+}
+
+// SelectProjects selects Jira projects, by key, for the integration
+func (j *JiraIntegrationServiceImpl) SelectProjects(ctx context.Context, req *JiraProjectSelectionRequest, userID string) ([]models.ProjectDataSource, error) {
+	integration, jira, creds, err := j.getIntegrationClient(ctx, req.ProjectID, req.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataSources []models.ProjectDataSource
+	for _, projectKey := range req.ProjectKeys {
+		project, err := jira.GetProject(ctx, creds, projectKey)
+		if err != nil {
+			if IsJiraStatus(err, http.StatusNotFound) {
+				return nil, fmt.Errorf("invalid project key: %s", projectKey)
+			}
+			return nil, fmt.Errorf("failed to get project details for %s: %w", projectKey, err)
+		}
+
+		// Jira resolves keys case-insensitively; the canonical key names the data source
+		dataSourceID := generateDataSourceID(req.IntegrationID, project.Key)
+
+		// Check if data source already exists
+		existing, _ := j.store.GetProjectDataSource(ctx, dataSourceID)
+		if existing != nil {
+			continue // Skip if already exists
+		}
+
+		dataSource := models.ProjectDataSource{
+			ID:            dataSourceID,
+			ProjectID:     req.ProjectID,
+			IntegrationID: req.IntegrationID,
+			SourceType:    string(models.SourceTypeJiraProject),
+			SourceID:      project.Key,
+			SourceName:    project.Name,
+			Configuration: map[string]interface{}{
+				"jira_project_id":  project.ID,
+				"project_key":      project.Key,
+				"project_type_key": project.ProjectTypeKey,
+				"web_url":          jiraBaseURL(integration) + "/browse/" + project.Key,
+			},
+			IsActive:        true,
+			LastIngestionAt: nil,
+			IngestionStatus: nil,
+			ErrorMessage:    nil,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+
+		if err := j.store.CreateProjectDataSource(ctx, &dataSource); err != nil {
+			return nil, fmt.Errorf("failed to create data source for project %s: %w", projectKey, err)
+		}
+
+		dataSources = append(dataSources, dataSource)
+	}
+
+	j.logger.Info("Jira projects selected for integration", map[string]interface{}{
+		"project_id":     req.ProjectID,
+		"integration_id": req.IntegrationID,
+		"project_keys":   req.ProjectKeys,
+		"user_id":        userID,
+	})
+
+	return dataSources, nil
+}
+
+// UpdateConfiguration updates integration configuration
+func (j *JiraIntegrationServiceImpl) UpdateConfiguration(ctx context.Context, req *IntegrationConfigurationRequest, userID string) (*models.ProjectIntegration, error) {
+	integration, err := j.getProjectIntegration(ctx, req.ProjectID, req.IntegrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := j.validateConfiguration(req.Configuration); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if integration.Configuration == nil {
+		integration.Configuration = make(map[string]interface{})
+	}
+	for key, value := range req.Configuration {
+		integration.Configuration[key] = value
+	}
+
+	updates := map[string]interface{}{
+		"configuration": integration.Configuration,
+		"updated_at":    time.Now(),
+	}
+	if err := j.store.UpdateProjectIntegration(ctx, req.IntegrationID, updates); err != nil {
+		return nil, fmt.Errorf("failed to update integration: %w", err)
+	}
+
+	updatedIntegration, err := j.store.GetProjectIntegration(ctx, req.IntegrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated integration: %w", err)
+	}
+
+	j.logger.Info("Integration configuration updated", map[string]interface{}{
+		"project_id":     req.ProjectID,
+		"integration_id": req.IntegrationID,
+		"user_id":        userID,
+	})
+
+	return updatedIntegration, nil
+}
+
+// GetIntegrationStatus gets integration status and health
+func (j *JiraIntegrationServiceImpl) GetIntegrationStatus(ctx context.Context, projectID, integrationID string) (*JiraIntegrationStatus, error) {
+	integration, err := j.getProjectIntegration(ctx, projectID, integrationID)
+	if err != nil {
+		return nil, err
+	}
+
+	dataSources, err := j.store.GetProjectDataSourcesByIntegration(ctx, integrationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data sources: %w", err)
+	}
+
+	status := &JiraIntegrationStatus{
+		IntegrationID:  integrationID,
+		Status:         integration.Status,
+		LastSyncAt:     integration.LastSyncAt,
+		LastSyncStatus: integration.LastSyncStatus,
+		ErrorMessage:   integration.ErrorMessage,
+		ProjectCount:   len(dataSources),
+		Configuration:  integration.Configuration,
+	}
+
+	// Check credentials validity
+	creds, err := j.getDecryptedCredentials(ctx, integration)
+	if err == nil {
+		if _, err := NewJiraService(jiraBaseURL(integration)).GetCurrentUser(ctx, creds); err == nil {
+			status.CredentialsValid = true
+		}
+	}
+
+	return status, nil
+}
+
+// DeleteIntegration deletes a Jira integration
+func (j *JiraIntegrationServiceImpl) DeleteIntegration(ctx context.Context, projectID, integrationID, userID string) error {
+	if _, err := j.getProjectIntegration(ctx, projectID, integrationID); err != nil {
+		return err
+	}
+
+	// Delete all data sources
+	dataSources, err := j.store.GetProjectDataSourcesByIntegration(ctx, integrationID)
+	if err != nil {
+		return fmt.Errorf("failed to get data sources: %w", err)
+	}
+
+	for _, dataSource := range dataSources {
+		if err := j.store.DeleteProjectDataSource(ctx, dataSource.ID); err != nil {
+			j.logger.Error("Failed to delete data source", err, map[string]interface{}{
+				"data_source_id": dataSource.ID,
+			})
+		}
+	}
+
+	if err := j.store.DeleteProjectIntegration(ctx, integrationID); err != nil {
+		return fmt.Errorf("failed to delete integration: %w", err)
+	}
+
+	j.logger.Info("Jira integration deleted", map[string]interface{}{
+		"project_id":     projectID,
+		"integration_id": integrationID,
+		"user_id":        userID,
+	})
+
+	return nil
+}
+
+// ValidateCredentials validates integration credentials
+func (j *JiraIntegrationServiceImpl) ValidateCredentials(ctx context.Context, integrationID string) error {
+	integration, err := j.store.GetProjectIntegration(ctx, integrationID)
+	if err != nil || integration == nil {
+		return fmt.Errorf("integration not found: %v", err)
+	}
+
+	creds, err := j.getDecryptedCredentials(ctx, integration)
+	if err != nil {
+		return fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	if _, err := NewJiraService(jiraBaseURL(integration)).GetCurrentUser(ctx, creds); err != nil {
+		return fmt.Errorf("credentials are invalid: %w", err)
+	}
+
+	return nil
+}
+
+// Helper methods
+
+// getProjectIntegration gets an integration and checks it belongs to the project
+func (j *JiraIntegrationServiceImpl) getProjectIntegration(ctx context.Context, projectID, integrationID string) (*models.ProjectIntegration, error) {
+	integration, err := j.store.GetProjectIntegration(ctx, integrationID)
+	if err != nil || integration == nil {
+		return nil, fmt.Errorf("integration not found: %v", err)
+	}
+
+	if integration.ProjectID != projectID {
+		return nil, fmt.Errorf("integration does not belong to project")
+	}
+
+	return integration, nil
+}
+
+// getIntegrationClient returns the integration with a client for its site
+// and the decrypted credentials
+func (j *JiraIntegrationServiceImpl) getIntegrationClient(ctx context.Context, projectID, integrationID string) (*models.ProjectIntegration, JiraService, JiraCredentials, error) {
+	integration, err := j.getProjectIntegration(ctx, projectID, integrationID)
+	if err != nil {
+		return nil, nil, JiraCredentials{}, err
+	}
+
+	creds, err := j.getDecryptedCredentials(ctx, integration)
+	if err != nil {
+		return nil, nil, JiraCredentials{}, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	return integration, NewJiraService(jiraBaseURL(integration)), creds, nil
+}
+
+// getDecryptedCredentials gets the account email and decrypts the API token
+// from integration
+func (j *JiraIntegrationServiceImpl) getDecryptedCredentials(ctx context.Context, integration *models.ProjectIntegration) (JiraCredentials, error) {
+	encryptedToken, ok := integration.Credentials["api_token"].(string)
+	if !ok {
+		return JiraCredentials{}, fmt.Errorf("API token not found in credentials")
+	}
+
+	token, err := j.encryptSvc.Decrypt(ctx, encryptedToken)
+	if err != nil {
+		return JiraCredentials{}, err
+	}
+
+	email, _ := integration.Credentials["email"].(string)
+	return JiraCredentials{Email: email, APIToken: token}, nil
+}
+
+// validateConfiguration validates integration configuration. The site and
+// account are fixed by the token, so changing them means reinstalling.
+func (j *JiraIntegrationServiceImpl) validateConfiguration(config map[string]interface{}) error {
+	for _, key := range []string{"base_url", "account_id", "display_name"} {
+		if _, ok := config[key]; ok {
+			return fmt.Errorf("%s cannot be changed; reinstall the integration instead", key)
+		}
+	}
+	return nil
+}
+
+// jiraBaseURL is the site an integration was installed on
+func jiraBaseURL(integration *models.ProjectIntegration) string {
+	baseURL, _ := integration.Configuration["base_url"].(string)
+	return baseURL
+}
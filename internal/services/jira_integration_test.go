@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestJiraAPI serves the user and project endpoints of a Jira site. Data
+// Center sites list projects without paging.
+func newTestJiraAPI(t *testing.T, dataCenter bool) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		email, token, ok := r.BasicAuth()
+		if dataCenter {
+			ok, token = r.Header.Get("Authorization") == "Bearer jira-token", "jira-token"
+		}
+		if !ok || token != "jira-token" || (!dataCenter && email != "alice@example.com") {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errorMessages":["You are not authenticated"]}`)
+			return
+		}
+
+		pay := `{"id":"10000","key":"PAY","name":"Payments","projectTypeKey":"software"}`
+		ops := `{"id":"10001","key":"OPS","name":"Operations","projectTypeKey":"service_desk"}`
+		switch r.URL.Path {
+		case "/rest/api/2/myself":
+			fmt.Fprint(w, `{"accountId":"acc-1","name":"alice","displayName":"Alice"}`)
+		case "/rest/api/2/project/search":
+			if dataCenter {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, `{"values":[%s,%s],"isLast":true}`, pay, ops)
+		case "/rest/api/2/project":
+			fmt.Fprintf(w, `[%s,%s]`, pay, ops)
+		case "/rest/api/2/project/PAY", "/rest/api/2/project/pay":
+			fmt.Fprint(w, pay)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errorMessages":["No project could be found"]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestJiraIntegrationService_TokenInstallationAndSelection(t *testing.T) {
+	ctx := context.Background()
+	server := newTestJiraAPI(t, false)
+	store := newGitLabIntegrationStore()
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	svc := NewJiraIntegrationService(cfg, store, NewEncryptionService(cfg), &SimpleLogger{})
+
+	integration, err := svc.ProcessTokenInstallation(ctx, &JiraTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL + "/", Email: "alice@example.com", APIToken: "jira-token"}, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, string(models.PlatformJira), integration.Platform)
+	assert.Equal(t, string(models.IntegrationTypeAPIToken), integration.IntegrationType)
+	assert.Equal(t, server.URL, integration.Configuration["base_url"])
+	assert.Equal(t, "acc-1", integration.Configuration["account_id"])
+	assert.NotEqual(t, "jira-token", integration.Credentials["api_token"], "the token is stored encrypted")
+
+	_, err = svc.ProcessTokenInstallation(ctx, &JiraTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, Email: "alice@example.com", APIToken: "jira-token"}, "u1")
+	assert.ErrorContains(t, err, "already exists")
+
+	// A key in any case selects the same data source
+	dataSources, err := svc.SelectProjects(ctx, &JiraProjectSelectionRequest{ProjectID: "p1", IntegrationID: integration.ID, ProjectKeys: []string{"pay", "PAY"}}, "u1")
+	require.NoError(t, err)
+	require.Len(t, dataSources, 1)
+	assert.Equal(t, "PAY", dataSources[0].SourceID)
+	assert.Equal(t, "Payments", dataSources[0].SourceName)
+	assert.Equal(t, string(models.SourceTypeJiraProject), dataSources[0].SourceType)
+
+	_, err = svc.SelectProjects(ctx, &JiraProjectSelectionRequest{ProjectID: "p1", IntegrationID: integration.ID, ProjectKeys: []string{"MISSING"}}, "u1")
+	assert.ErrorContains(t, err, "invalid project key")
+
+	projects, err := svc.GetAvailableProjects(ctx, "p1", integration.ID)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.True(t, projects[0].Selected)
+	assert.False(t, projects[1].Selected)
+
+	_, err = svc.GetAvailableProjects(ctx, "p2", integration.ID)
+	assert.ErrorContains(t, err, "does not belong to project")
+
+	require.NoError(t, svc.ValidateCredentials(ctx, integration.ID))
+
+	status, err := svc.GetIntegrationStatus(ctx, "p1", integration.ID)
+	require.NoError(t, err)
+	assert.True(t, status.CredentialsValid)
+	assert.Equal(t, 1, status.ProjectCount)
+
+	_, err = svc.UpdateConfiguration(ctx, &IntegrationConfigurationRequest{ProjectID: "p1", IntegrationID: integration.ID, Configuration: map[string]interface{}{"base_url": "https://other.atlassian.net"}}, "u1")
+	assert.ErrorContains(t, err, "invalid configuration")
+}
+
+func TestJiraIntegrationService_DataCenterToken(t *testing.T) {
+	ctx := context.Background()
+	server := newTestJiraAPI(t, true)
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	svc := NewJiraIntegrationService(cfg, newGitLabIntegrationStore(), NewEncryptionService(cfg), &SimpleLogger{})
+
+	// Personal access tokens are sent alone, as bearer tokens
+	integration, err := svc.ProcessTokenInstallation(ctx, &JiraTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, APIToken: "jira-token"}, "u1")
+	require.NoError(t, err)
+
+	projects, err := svc.GetAvailableProjects(ctx, "p1", integration.ID)
+	require.NoError(t, err)
+	assert.Len(t, projects, 2)
+}
+
+func TestJiraIntegrationService_RejectsTokens(t *testing.T) {
+	ctx := context.Background()
+	server := newTestJiraAPI(t, false)
+	cfg := &config.Config{JWTSecret: "test-secret"}
+	svc := NewJiraIntegrationService(cfg, newGitLabIntegrationStore(), NewEncryptionService(cfg), &SimpleLogger{})
+
+	_, err := svc.ProcessTokenInstallation(ctx, &JiraTokenInstallationRequest{ProjectID: "p1", BaseURL: server.URL, Email: "alice@example.com", APIToken: "revoked"}, "u1")
+	assert.ErrorContains(t, err, "failed to validate")
+
+	_, err = svc.ProcessTokenInstallation(ctx, &JiraTokenInstallationRequest{ProjectID: "p1", BaseURL: "example.atlassian.net", APIToken: "jira-token"}, "u1")
+	assert.ErrorContains(t, err, "invalid base URL")
+
+	_, err = svc.ProcessTokenInstallation(ctx, &JiraTokenInstallationRequest{ProjectID: "p1", APIToken: "jira-token"}, "u1")
+	assert.ErrorContains(t, err, "invalid base URL")
+}
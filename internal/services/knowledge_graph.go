@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ type KnowledgeGraphServiceImpl struct {
 	repository    RepositoryStore
 	permissionSvc PermissionService
 	processor     *ContextProcessor
+	embedder      Embedder
 	logger        Logger
 }
 
@@ -27,6 +29,12 @@ func NewKnowledgeGraphService(repository RepositoryStore, permissionSvc Permissi
 	}
 }
 
+// SetEmbedder enables vector search alongside full-text search. The embedder
+// must be the one knowledge entities are embedded with.
+func (kg *KnowledgeGraphServiceImpl) SetEmbedder(embedder Embedder) {
+	kg.embedder = embedder
+}
+
 // ProcessAndStoreEvents processes events and stores the resulting knowledge entities
 func (kg *KnowledgeGraphServiceImpl) ProcessAndStoreEvents(ctx context.Context, events []NormalizedEvent) (*ProcessingResult, error) {
 	kg.logger.Info("Processing and storing events in knowledge graph", map[string]interface{}{
@@ -56,6 +64,34 @@ func (kg *KnowledgeGraphServiceImpl) ProcessAndStoreEvents(ctx context.Context,
 	return result, nil
 }
 
+// ProcessAndStoreProjectEvents processes events of one project and stores the
+// resulting knowledge entities scoped to that project
+func (kg *KnowledgeGraphServiceImpl) ProcessAndStoreProjectEvents(ctx context.Context, projectID string, events []NormalizedEvent) (*ProcessingResult, error) {
+	result, err := kg.processor.ProcessEvents(ctx, events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process events: %w", err)
+	}
+
+	for i := range result.DecisionRecords {
+		result.DecisionRecords[i].ProjectID = projectID
+	}
+	for i := range result.DiscussionSummaries {
+		result.DiscussionSummaries[i].ProjectID = projectID
+	}
+	for i := range result.FeatureContexts {
+		result.FeatureContexts[i].ProjectID = projectID
+	}
+	for i := range result.FileContexts {
+		result.FileContexts[i].ProjectID = projectID
+	}
+
+	if err := kg.storeProcessingResult(ctx, result); err != nil {
+		return nil, fmt.Errorf("failed to store processing result: %w", err)
+	}
+
+	return result, nil
+}
+
 // storeProcessingResult stores all entities and relationships from processing result
 func (kg *KnowledgeGraphServiceImpl) storeProcessingResult(ctx context.Context, result *ProcessingResult) error {
 	// Store decision records
@@ -131,6 +167,15 @@ func (kg *KnowledgeGraphServiceImpl) storeDecisionRecord(ctx context.Context, de
 			"project_id":    decision.ProjectID, // Add project scoping
 		},
 	}
+	if decision.RecordedBy != "" {
+		entity.Metadata["recorded_by"] = decision.RecordedBy
+	}
+	if decision.Authoritative {
+		entity.Metadata["authoritative"] = true
+	}
+	if len(decision.Supersedes) > 0 {
+		entity.Metadata["supersedes"] = decision.Supersedes
+	}
 
 	if err := kg.repository.CreateKnowledgeEntity(ctx, entity); err != nil {
 		return fmt.Errorf("failed to create knowledge entity for decision: %w", err)
@@ -146,6 +191,8 @@ func (kg *KnowledgeGraphServiceImpl) storeDecisionRecord(ctx context.Context, de
 		Alternatives:   models.StringList(decision.Alternatives),
 		Consequences:   models.StringList(decision.Consequences),
 		Status:         decision.Status,
+		Supersedes:     models.StringList(decision.Supersedes),
+		Authoritative:  decision.Authoritative,
 		PlatformSource: decision.PlatformSource,
 		SourceEventIDs: models.StringList(decision.SourceEventIDs),
 		Participants:   models.StringList(decision.Participants),
@@ -258,6 +305,9 @@ func (kg *KnowledgeGraphServiceImpl) storeFileContextHistory(ctx context.Context
 			"project_id":         fileContext.ProjectID, // Add project scoping
 		},
 	}
+	if fileContext.RecordedBy != "" {
+		entity.Metadata["recorded_by"] = fileContext.RecordedBy
+	}
 
 	if err := kg.repository.CreateKnowledgeEntity(ctx, entity); err != nil {
 		return fmt.Errorf("failed to create knowledge entity for file context: %w", err)
@@ -430,6 +480,7 @@ func (kg *KnowledgeGraphServiceImpl) GetDecisionHistory(ctx context.Context, tar
 		}
 		decisions = append(decisions, *decision)
 	}
+	sortAuthoritativeFirst(decisions)
 
 	return &DecisionHistoryResponse{
 		Target:    target,
@@ -502,9 +553,10 @@ func (kg *KnowledgeGraphServiceImpl) GetRecentArchitectureDiscussions(ctx contex
 	return discussions, nil
 }
 
-// SearchKnowledgeByProject performs semantic search within a specific project
+// SearchKnowledgeByProject performs hybrid full-text and vector search within
+// a specific project
 func (kg *KnowledgeGraphServiceImpl) SearchKnowledgeByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
-	kg.logger.Info("Performing project-scoped knowledge graph search", map[string]interface{}{
+	kg.loggerFor(ctx).Info("Performing project-scoped knowledge graph search", map[string]interface{}{
 		"project_id":   projectID,
 		"query":        query.Query,
 		"entity_types": query.EntityTypes,
@@ -512,12 +564,12 @@ func (kg *KnowledgeGraphServiceImpl) SearchKnowledgeByProject(ctx context.Contex
 		"limit":        query.Limit,
 	})
 
-	results, err := kg.repository.SearchKnowledgeEntitiesByProject(ctx, projectID, query)
+	results, err := kg.hybridSearch(ctx, projectID, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search knowledge entities by project: %w", err)
 	}
 
-	kg.logger.Info("Project-scoped knowledge graph search completed", map[string]interface{}{
+	kg.loggerFor(ctx).Info("Project-scoped knowledge graph search completed", map[string]interface{}{
 		"project_id":    projectID,
 		"results_count": len(results),
 	})
@@ -527,27 +579,15 @@ func (kg *KnowledgeGraphServiceImpl) SearchKnowledgeByProject(ctx context.Contex
 
 // GetContextForFileByProject retrieves all context information for a specific file within a project
 func (kg *KnowledgeGraphServiceImpl) GetContextForFileByProject(ctx context.Context, projectID, filePath string) (*FileContextResponse, error) {
-	kg.logger.Info("Getting context for file in project", map[string]interface{}{
+	kg.loggerFor(ctx).Info("Getting context for file in project", map[string]interface{}{
 		"project_id": projectID,
 		"file_path":  filePath,
 	})
 
-	// Get file context history (this method needs to be project-scoped in the repository)
-	fileContexts, err := kg.repository.GetFileContextHistory(ctx, filePath)
+	// Keep only the file contexts whose entities belong to the project
+	projectFileContexts, err := kg.GetFileContextHistoryByProject(ctx, projectID, filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get file context history: %w", err)
-	}
-
-	// Filter file contexts to only include those from the specified project
-	var projectFileContexts []models.FileContextHistory
-	for _, fc := range fileContexts {
-		// Check if this file context belongs to the project
-		// This is a simplified check - in production you might want to store project_id directly
-		if projectSources, ok := fc.PlatformSources["project_id"]; ok {
-			if projectID == projectSources {
-				projectFileContexts = append(projectFileContexts, fc)
-			}
-		}
+		return nil, err
 	}
 
 	// Search for related entities within the project
@@ -584,7 +624,7 @@ func (kg *KnowledgeGraphServiceImpl) GetContextForFileByProject(ctx context.Cont
 
 // GetDecisionHistoryByProject retrieves decision history for a feature or file within a project
 func (kg *KnowledgeGraphServiceImpl) GetDecisionHistoryByProject(ctx context.Context, projectID, target string) (*DecisionHistoryResponse, error) {
-	kg.logger.Info("Getting decision history for project", map[string]interface{}{
+	kg.loggerFor(ctx).Info("Getting decision history for project", map[string]interface{}{
 		"project_id": projectID,
 		"target":     target,
 	})
@@ -607,13 +647,14 @@ func (kg *KnowledgeGraphServiceImpl) GetDecisionHistoryByProject(ctx context.Con
 	for _, result := range decisionResults {
 		decision, err := kg.repository.GetDecisionRecord(ctx, result.Entity.EntityID)
 		if err != nil {
-			kg.logger.Error("Failed to get decision record", err, map[string]interface{}{
+			kg.loggerFor(ctx).Error("Failed to get decision record", err, map[string]interface{}{
 				"decision_id": result.Entity.EntityID,
 			})
 			continue
 		}
 		decisions = append(decisions, *decision)
 	}
+	sortAuthoritativeFirst(decisions)
 
 	return &DecisionHistoryResponse{
 		Target:    target,
@@ -621,9 +662,17 @@ func (kg *KnowledgeGraphServiceImpl) GetDecisionHistoryByProject(ctx context.Con
 	}, nil
 }
 
+// sortAuthoritativeFirst moves decisions written down on purpose, such as
+// ADRs, ahead of mined ones, otherwise keeping the search order
+func sortAuthoritativeFirst(decisions []models.DecisionRecord) {
+	sort.SliceStable(decisions, func(i, j int) bool {
+		return decisions[i].Authoritative && !decisions[j].Authoritative
+	})
+}
+
 // GetRecentArchitectureDiscussionsByProject retrieves recent architecture-related discussions within a project
 func (kg *KnowledgeGraphServiceImpl) GetRecentArchitectureDiscussionsByProject(ctx context.Context, projectID string, limit int) ([]models.DiscussionSummary, error) {
-	kg.logger.Info("Getting recent architecture discussions for project", map[string]interface{}{
+	kg.loggerFor(ctx).Info("Getting recent architecture discussions for project", map[string]interface{}{
 		"project_id": projectID,
 		"limit":      limit,
 	})
@@ -653,7 +702,7 @@ func (kg *KnowledgeGraphServiceImpl) GetRecentArchitectureDiscussionsByProject(c
 	for _, result := range results {
 		discussion, err := kg.repository.GetDiscussionSummary(ctx, result.Entity.EntityID)
 		if err != nil {
-			kg.logger.Error("Failed to get discussion summary", err, map[string]interface{}{
+			kg.loggerFor(ctx).Error("Failed to get discussion summary", err, map[string]interface{}{
 				"summary_id": result.Entity.EntityID,
 			})
 			continue
@@ -664,6 +713,351 @@ func (kg *KnowledgeGraphServiceImpl) GetRecentArchitectureDiscussionsByProject(c
 	return discussions, nil
 }
 
+// ListKnowledgeEntitiesByProject lists knowledge entities of the given types within a project
+func (kg *KnowledgeGraphServiceImpl) ListKnowledgeEntitiesByProject(ctx context.Context, projectID string, entityTypes []string, limit int) ([]models.KnowledgeEntity, error) {
+	entities, err := kg.repository.GetKnowledgeEntitiesByProject(ctx, projectID, entityTypes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge entities by project: %w", err)
+	}
+
+	return entities, nil
+}
+
+// GetDecisionRecordByProject retrieves a decision record if it belongs to the project
+func (kg *KnowledgeGraphServiceImpl) GetDecisionRecordByProject(ctx context.Context, projectID, decisionID string) (*models.DecisionRecord, error) {
+	decision, err := kg.repository.GetDecisionRecord(ctx, decisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decision record: %w", err)
+	}
+
+	if err := kg.ensureEntityInProject(ctx, projectID, decision.EntityID); err != nil {
+		return nil, err
+	}
+
+	return decision, nil
+}
+
+// GetDiscussionSummaryByProject retrieves a discussion summary if it belongs to the project
+func (kg *KnowledgeGraphServiceImpl) GetDiscussionSummaryByProject(ctx context.Context, projectID, summaryID string) (*models.DiscussionSummary, error) {
+	summary, err := kg.repository.GetDiscussionSummary(ctx, summaryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get discussion summary: %w", err)
+	}
+
+	if err := kg.ensureEntityInProject(ctx, projectID, summary.EntityID); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// GetFeatureContextByProject retrieves a feature context if it belongs to the project
+func (kg *KnowledgeGraphServiceImpl) GetFeatureContextByProject(ctx context.Context, projectID, featureID string) (*models.FeatureContext, error) {
+	feature, err := kg.repository.GetFeatureContext(ctx, featureID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feature context: %w", err)
+	}
+
+	if err := kg.ensureEntityInProject(ctx, projectID, feature.EntityID); err != nil {
+		return nil, err
+	}
+
+	return feature, nil
+}
+
+// GetFileContextHistoryByProject retrieves the change history of a file within a project
+func (kg *KnowledgeGraphServiceImpl) GetFileContextHistoryByProject(ctx context.Context, projectID, filePath string) ([]models.FileContextHistory, error) {
+	fileContexts, err := kg.repository.GetFileContextHistory(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file context history: %w", err)
+	}
+
+	var projectFileContexts []models.FileContextHistory
+	for _, fc := range fileContexts {
+		if err := kg.ensureEntityInProject(ctx, projectID, fc.EntityID); err != nil {
+			continue
+		}
+		projectFileContexts = append(projectFileContexts, fc)
+	}
+
+	return projectFileContexts, nil
+}
+
+// ResolveKnowledgeEntityByProject finds an entity in a project by ID, falling back to its title
+func (kg *KnowledgeGraphServiceImpl) ResolveKnowledgeEntityByProject(ctx context.Context, projectID, idOrTitle string) (*models.KnowledgeEntity, error) {
+	if entity, err := kg.repository.GetKnowledgeEntityByIDAndProject(ctx, idOrTitle, projectID); err == nil {
+		return entity, nil
+	}
+
+	results, err := kg.repository.SearchKnowledgeEntitiesByProject(ctx, projectID, &models.KnowledgeGraphQuery{
+		Query: idOrTitle,
+		Limit: 10,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search knowledge entities by project: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no entity matching %q found in project %s", idOrTitle, projectID)
+	}
+
+	// Prefer an exact title match over the best search hit
+	for _, result := range results {
+		if strings.EqualFold(result.Entity.Title, idOrTitle) {
+			entity := result.Entity
+			return &entity, nil
+		}
+	}
+
+	entity := results[0].Entity
+	return &entity, nil
+}
+
+// TraverseRelationshipsByProject performs graph traversal within a project. Only
+// entities reachable from the start entity through the requested relationship
+// types within maxDepth hops are returned.
+func (kg *KnowledgeGraphServiceImpl) TraverseRelationshipsByProject(ctx context.Context, projectID, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error) {
+	kg.loggerFor(ctx).Info("Traversing project knowledge graph relationships", map[string]interface{}{
+		"project_id":         projectID,
+		"start_entity":       startEntityID,
+		"max_depth":          maxDepth,
+		"relationship_types": relationshipTypes,
+	})
+
+	result, err := kg.repository.TraverseKnowledgeGraphByProject(ctx, projectID, startEntityID, maxDepth, relationshipTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to traverse knowledge graph by project: %w", err)
+	}
+
+	// The traversal follows every relationship type and may reach an entity
+	// through several paths, so prune it to the requested subgraph
+	depths := traversalDepths(startEntityID, result.Relationships, maxDepth)
+
+	pruned := &models.GraphTraversalResult{}
+	seen := make(map[string]bool)
+	for _, entity := range result.Path {
+		if _, ok := depths[entity.ID]; !ok || seen[entity.ID] {
+			continue
+		}
+		seen[entity.ID] = true
+		pruned.Path = append(pruned.Path, entity)
+		if depths[entity.ID] > pruned.Depth {
+			pruned.Depth = depths[entity.ID]
+		}
+	}
+	for _, rel := range result.Relationships {
+		if seen[rel.SourceEntityID] && seen[rel.TargetEntityID] {
+			pruned.Relationships = append(pruned.Relationships, rel)
+			pruned.TotalStrength += rel.Strength
+		}
+	}
+
+	kg.loggerFor(ctx).Info("Project knowledge graph traversal completed", map[string]interface{}{
+		"project_id":        projectID,
+		"entities_found":    len(pruned.Path),
+		"relationships":     len(pruned.Relationships),
+		"max_depth_reached": pruned.Depth,
+	})
+
+	return pruned, nil
+}
+
+// traversalDepths returns the hop distance from the start entity of every entity
+// reachable through the given relationships within maxDepth hops
+func traversalDepths(startEntityID string, relationships []models.KnowledgeRelationship, maxDepth int) map[string]int {
+	neighbors := make(map[string][]string)
+	for _, rel := range relationships {
+		neighbors[rel.SourceEntityID] = append(neighbors[rel.SourceEntityID], rel.TargetEntityID)
+		neighbors[rel.TargetEntityID] = append(neighbors[rel.TargetEntityID], rel.SourceEntityID)
+	}
+
+	depths := map[string]int{startEntityID: 0}
+	frontier := []string{startEntityID}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbor := range neighbors[id] {
+				if _, ok := depths[neighbor]; !ok {
+					depths[neighbor] = depth
+					next = append(next, neighbor)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return depths
+}
+
+// RecordDecisionByProject stores a decision recorded directly by a user rather
+// than extracted from an ingested platform
+func (kg *KnowledgeGraphServiceImpl) RecordDecisionByProject(ctx context.Context, projectID, userID string, decision *DecisionRecord) (*models.DecisionRecord, error) {
+	if decision.ID == "" {
+		decision.ID = fmt.Sprintf("mcp_decision_%s", generateID())
+	}
+	if decision.Status == "" {
+		decision.Status = "active"
+	}
+	if decision.PlatformSource == "" {
+		decision.PlatformSource = "mcp"
+	}
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now()
+	}
+	decision.ProjectID = projectID
+	decision.RecordedBy = userID
+
+	kg.loggerFor(ctx).Info("Recording decision", map[string]interface{}{
+		"project_id":  projectID,
+		"user_id":     userID,
+		"decision_id": decision.ID,
+	})
+
+	if err := kg.storeDecisionRecord(ctx, decision); err != nil {
+		return nil, fmt.Errorf("failed to store decision record: %w", err)
+	}
+
+	return kg.GetDecisionRecordByProject(ctx, projectID, decision.ID)
+}
+
+// AnnotateFileContextByProject stores a file context note recorded directly by a user
+func (kg *KnowledgeGraphServiceImpl) AnnotateFileContextByProject(ctx context.Context, projectID, userID string, fileContext *FileContextHistory) (*models.FileContextHistory, error) {
+	if fileContext.ID == "" {
+		fileContext.ID = fmt.Sprintf("mcp_file_%s", generateID())
+	}
+	if fileContext.PlatformSources == nil {
+		fileContext.PlatformSources = map[string]interface{}{"mcp": true}
+	}
+	if fileContext.CreatedAt.IsZero() {
+		fileContext.CreatedAt = time.Now()
+	}
+	fileContext.ProjectID = projectID
+	fileContext.RecordedBy = userID
+
+	kg.loggerFor(ctx).Info("Annotating file context", map[string]interface{}{
+		"project_id": projectID,
+		"user_id":    userID,
+		"file_path":  fileContext.FilePath,
+	})
+
+	if err := kg.storeFileContextHistory(ctx, fileContext); err != nil {
+		return nil, fmt.Errorf("failed to store file context: %w", err)
+	}
+
+	fileContexts, err := kg.GetFileContextHistoryByProject(ctx, projectID, fileContext.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	for _, fc := range fileContexts {
+		if fc.ContextID == fileContext.ID {
+			return &fc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("file context %s not found after storing", fileContext.ID)
+}
+
+// LinkEntitiesByProject creates or updates a relationship between two entities of a project
+func (kg *KnowledgeGraphServiceImpl) LinkEntitiesByProject(ctx context.Context, projectID, userID, sourceEntityID, targetEntityID, relationshipType string, strength float64) (*models.KnowledgeRelationship, error) {
+	if sourceEntityID == targetEntityID {
+		return nil, fmt.Errorf("cannot link an entity to itself")
+	}
+	if strength <= 0 || strength > 1 {
+		return nil, fmt.Errorf("strength must be greater than 0 and at most 1")
+	}
+	if err := kg.ensureEntityInProject(ctx, projectID, sourceEntityID); err != nil {
+		return nil, err
+	}
+	if err := kg.ensureEntityInProject(ctx, projectID, targetEntityID); err != nil {
+		return nil, err
+	}
+
+	relationship := &models.KnowledgeRelationship{
+		SourceEntityID:   sourceEntityID,
+		TargetEntityID:   targetEntityID,
+		RelationshipType: relationshipType,
+		Strength:         strength,
+		Metadata: map[string]interface{}{
+			"project_id":  projectID,
+			"recorded_by": userID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	kg.loggerFor(ctx).Info("Linking knowledge entities", map[string]interface{}{
+		"project_id":        projectID,
+		"user_id":           userID,
+		"source_entity_id":  sourceEntityID,
+		"target_entity_id":  targetEntityID,
+		"relationship_type": relationshipType,
+	})
+
+	if err := kg.repository.CreateKnowledgeRelationship(ctx, relationship); err != nil {
+		return nil, fmt.Errorf("failed to create knowledge relationship: %w", err)
+	}
+
+	return relationship, nil
+}
+
+// SuggestFilePathsByProject lists indexed file paths in a project that contain the given text
+func (kg *KnowledgeGraphServiceImpl) SuggestFilePathsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	paths, err := kg.repository.ListFilePathsByProject(ctx, projectID, contains, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list file paths: %w", err)
+	}
+	return paths, nil
+}
+
+// SuggestFeatureNamesByProject lists feature names in a project that contain the given text
+func (kg *KnowledgeGraphServiceImpl) SuggestFeatureNamesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	names, err := kg.repository.ListFeatureNamesByProject(ctx, projectID, contains, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature names: %w", err)
+	}
+	return names, nil
+}
+
+// SuggestDecisionTargetsByProject lists feature names followed by decision titles
+// in a project that contain the given text, without case-insensitive duplicates
+func (kg *KnowledgeGraphServiceImpl) SuggestDecisionTargetsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	features, err := kg.SuggestFeatureNamesByProject(ctx, projectID, contains, limit)
+	if err != nil {
+		return nil, err
+	}
+	titles, err := kg.repository.ListDecisionTitlesByProject(ctx, projectID, contains, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decision titles: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var targets []string
+	for _, target := range append(features, titles...) {
+		key := strings.ToLower(target)
+		if target == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		targets = append(targets, target)
+		if limit > 0 && len(targets) == limit {
+			break
+		}
+	}
+
+	return targets, nil
+}
+
+// loggerFor returns the logger for the request in ctx
+func (kg *KnowledgeGraphServiceImpl) loggerFor(ctx context.Context) Logger {
+	return LoggerFromContext(ctx, kg.logger)
+}
+
+// ensureEntityInProject verifies that a knowledge entity belongs to the project
+func (kg *KnowledgeGraphServiceImpl) ensureEntityInProject(ctx context.Context, projectID, entityID string) error {
+	if _, err := kg.repository.GetKnowledgeEntityByIDAndProject(ctx, entityID, projectID); err != nil {
+		return fmt.Errorf("entity %s not found in project %s", entityID, projectID)
+	}
+	return nil
+}
+
 // Helper functions
 func timePtr(t time.Time) *time.Time {
 	return &t
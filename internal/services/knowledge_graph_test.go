@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// projectEventsRepositoryStub keeps written rows in memory and finds nothing to relate
+type projectEventsRepositoryStub struct {
+	*writeRepositoryStub
+}
+
+func (r *projectEventsRepositoryStub) SearchKnowledgeEntities(ctx context.Context, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	return nil, nil
+}
+
+func TestProcessAndStoreProjectEvents_ScopesEntitiesToTheProject(t *testing.T) {
+	repository := &projectEventsRepositoryStub{writeRepositoryStub: newWriteRepositoryStub()}
+	kg := NewKnowledgeGraphService(repository, nil, NewContextProcessor(nil, &SimpleLogger{}), &SimpleLogger{})
+
+	events := []NormalizedEvent{{
+		PlatformID:  "adr-docs/adr/0005-queue-webhooks.md",
+		EventType:   EventTypeDecisionRecord,
+		Timestamp:   time.Now(),
+		Author:      "Bob",
+		Title:       "Queue webhooks",
+		Content:     "# 5. Queue webhooks\n\n## Status\n\nAccepted\n\n## Context\n\nRetries block webhook delivery.\n\n## Decision\n\nWe will queue deliveries.\n",
+		FileRefs:    []string{"internal/webhooks/queue.go"},
+		FeatureRefs: []string{"webhook delivery"},
+		Metadata:    map[string]interface{}{"adr_path": "docs/adr/0005-queue-webhooks.md", "repository": "payments"},
+		Platform:    "gitlocal",
+	}}
+
+	result, err := kg.ProcessAndStoreProjectEvents(context.Background(), "p9", events)
+	require.NoError(t, err)
+	require.Len(t, result.DecisionRecords, 1)
+	assert.Equal(t, "p9", result.DecisionRecords[0].ProjectID)
+
+	stored := 0
+	for id, entity := range repository.entities {
+		if id == "file-entity" || id == "other" {
+			continue
+		}
+		stored++
+		assert.Equal(t, "p9", entity.Metadata["project_id"], "entity %s", id)
+	}
+	assert.NotZero(t, stored)
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLinearAPIURL is the GraphQL endpoint of Linear
+	DefaultLinearAPIURL = "https://api.linear.app/graphql"
+
+	// linearPageSize is the page size of list queries; Linear allows up to 250
+	linearPageSize = 100
+
+	// maxLinearErrorBody bounds how much of an error response is kept
+	maxLinearErrorBody = 512
+)
+
+// Selections of the fields ingestion reads, shared by list queries
+const (
+	linearIssueFields = `id identifier title description url priority priorityLabel createdAt updatedAt completedAt canceledAt
+		state { name type } team { id key name } creator { id name displayName } assignee { id name displayName }
+		labels { nodes { name } } project { id name } cycle { id number name } parent { id identifier title }`
+	linearCommentFields = `id body url createdAt updatedAt user { id name displayName } issue { id identifier title } parent { id }`
+	linearProjectFields = `id name description url state startDate targetDate createdAt updatedAt lead { id name displayName }
+		teams { nodes { id key name } }`
+	linearCycleFields = `id number name description startsAt endsAt completedAt progress createdAt updatedAt team { id key name }`
+)
+
+// LinearServiceImpl implements LinearService
+type LinearServiceImpl struct {
+	httpClient *http.Client
+	apiURL     string
+}
+
+// NewLinearService creates a Linear client for the GraphQL endpoint at
+// apiURL, or Linear's own when apiURL is empty
+func NewLinearService(apiURL string) LinearService {
+	if apiURL == "" {
+		apiURL = DefaultLinearAPIURL
+	}
+	return &LinearServiceImpl{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiURL:     apiURL,
+	}
+}
+
+// LinearFilter narrows list queries to teams, by key, and to items updated
+// after a time. Empty fields do not filter.
+type LinearFilter struct {
+	TeamKeys     []string
+	UpdatedAfter time.Time
+}
+
+// LinearAPIError is returned for failed Linear API requests. Linear reports
+// most failures as GraphQL errors with a code; those are given the HTTP
+// status they stand for, so callers handle both alike.
+type LinearAPIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *LinearAPIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("Linear API request failed with status %d (%s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("Linear API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// LinearNodes is a list that Linear returns as a {nodes: [...]} connection
+// from the API and as a plain array in webhook payloads
+type LinearNodes[T any] []T
+
+// UnmarshalJSON accepts either form of the list
+func (n *LinearNodes[T]) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return json.Unmarshal(data, (*[]T)(n))
+	}
+	var connection struct {
+		Nodes []T `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &connection); err != nil {
+		return err
+	}
+	*n = connection.Nodes
+	return nil
+}
+
+// LinearUser represents a Linear user
+type LinearUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+// LinearTeam represents a Linear team
+type LinearTeam struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// LinearIssue represents a Linear issue
+type LinearIssue struct {
+	ID            string     `json:"id"`
+	Identifier    string     `json:"identifier"` // e.g. ENG-42
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	URL           string     `json:"url"`
+	Priority      float64    `json:"priority"`
+	PriorityLabel string     `json:"priorityLabel"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	CompletedAt   *time.Time `json:"completedAt"`
+	CanceledAt    *time.Time `json:"canceledAt"`
+	State         struct {
+		Name string `json:"name"`
+		Type string `json:"type"` // triage, backlog, unstarted, started, completed or canceled
+	} `json:"state"`
+	Team     LinearTeam  `json:"team"`
+	Creator  *LinearUser `json:"creator"`
+	Assignee *LinearUser `json:"assignee"`
+	Labels   LinearNodes[struct {
+		Name string `json:"name"`
+	}] `json:"labels"`
+	Project *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Cycle *struct {
+		ID     string `json:"id"`
+		Number int    `json:"number"`
+		Name   string `json:"name"`
+	} `json:"cycle"`
+	Parent *LinearIssueRef `json:"parent"`
+}
+
+// LinearIssueRef identifies an issue from another object
+type LinearIssueRef struct {
+	ID         string `json:"id"`
+	Identifier string `json:"identifier"`
+	Title      string `json:"title"`
+}
+
+// LinearComment represents a comment on a Linear issue
+type LinearComment struct {
+	ID        string          `json:"id"`
+	Body      string          `json:"body"`
+	URL       string          `json:"url"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	User      *LinearUser     `json:"user"`
+	Issue     *LinearIssueRef `json:"issue"`
+	IssueID   string          `json:"issueId"` // webhook payloads only
+	Parent    *struct {
+		ID string `json:"id"`
+	} `json:"parent"`
+}
+
+// LinearProject represents a Linear project
+type LinearProject struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	URL         string                  `json:"url"`
+	State       string                  `json:"state"` // planned, started, paused, completed or canceled
+	StartDate   string                  `json:"startDate"`
+	TargetDate  string                  `json:"targetDate"`
+	CreatedAt   time.Time               `json:"createdAt"`
+	UpdatedAt   time.Time               `json:"updatedAt"`
+	Lead        *LinearUser             `json:"lead"`
+	Teams       LinearNodes[LinearTeam] `json:"teams"`
+}
+
+// LinearCycle represents a Linear cycle, a team's time-boxed iteration
+type LinearCycle struct {
+	ID          string     `json:"id"`
+	Number      int        `json:"number"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      time.Time  `json:"endsAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	Progress    float64    `json:"progress"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	Team        LinearTeam `json:"team"`
+}
+
+// GetViewer returns the user the API key belongs to
+func (l *LinearServiceImpl) GetViewer(ctx context.Context, apiKey string) (*LinearUser, error) {
+	var data struct {
+		Viewer LinearUser `json:"viewer"`
+	}
+	if err := l.query(ctx, apiKey, `query { viewer { id name displayName email } }`, nil, &data); err != nil {
+		return nil, fmt.Errorf("failed to get Linear user: %w", err)
+	}
+	return &data.Viewer, nil
+}
+
+// ListIssues returns the issues matching the filter, least recently updated first
+func (l *LinearServiceImpl) ListIssues(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearIssue, error) {
+	issues, err := linearList[LinearIssue](ctx, l, apiKey, "issues", "IssueFilter", linearIssueFields, linearTeamFilter(filter, "team"), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Linear issues: %w", err)
+	}
+	return issues, nil
+}
+
+// ListComments returns the comments on issues matching the filter
+func (l *LinearServiceImpl) ListComments(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearComment, error) {
+	variables := linearTeamFilter(LinearFilter{UpdatedAfter: filter.UpdatedAfter}, "")
+	if len(filter.TeamKeys) > 0 {
+		variables["issue"] = map[string]interface{}{"team": map[string]interface{}{"key": map[string]interface{}{"in": filter.TeamKeys}}}
+	}
+
+	comments, err := linearList[LinearComment](ctx, l, apiKey, "comments", "CommentFilter", linearCommentFields, variables, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Linear comments: %w", err)
+	}
+	return comments, nil
+}
+
+// ListProjects returns the projects matching the filter. A project can be
+// shared by several teams, and is kept if any of them is in the filter.
+func (l *LinearServiceImpl) ListProjects(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearProject, error) {
+	projects, err := linearList[LinearProject](ctx, l, apiKey, "projects", "ProjectFilter", linearProjectFields, linearTeamFilter(LinearFilter{UpdatedAfter: filter.UpdatedAfter}, ""), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Linear projects: %w", err)
+	}
+	if len(filter.TeamKeys) == 0 {
+		return projects, nil
+	}
+
+	teams := make(map[string]bool, len(filter.TeamKeys))
+	for _, key := range filter.TeamKeys {
+		teams[key] = true
+	}
+	var matching []LinearProject
+	for _, project := range projects {
+		for _, team := range project.Teams {
+			if teams[team.Key] {
+				matching = append(matching, project)
+				break
+			}
+		}
+	}
+	return matching, nil
+}
+
+// ListCycles returns the cycles of the teams matching the filter
+func (l *LinearServiceImpl) ListCycles(ctx context.Context, apiKey string, filter LinearFilter, limit int) ([]LinearCycle, error) {
+	cycles, err := linearList[LinearCycle](ctx, l, apiKey, "cycles", "CycleFilter", linearCycleFields, linearTeamFilter(filter, "team"), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Linear cycles: %w", err)
+	}
+	return cycles, nil
+}
+
+// linearList follows the cursor pagination of a list query until limit items
+// (all when limit is not positive) or the last page
+func linearList[T any](ctx context.Context, l *LinearServiceImpl, apiKey, field, filterType, fields string, filter map[string]interface{}, limit int) ([]T, error) {
+	query := fmt.Sprintf(`query List($filter: %s, $first: Int!, $after: String) {
+	%s(filter: $filter, first: $first, after: $after, orderBy: updatedAt) {
+		nodes { %s }
+		pageInfo { hasNextPage endCursor }
+	}
+}`, filterType, field, fields)
+
+	pageSize := linearPageSize
+	if limit > 0 && limit < pageSize {
+		pageSize = limit
+	}
+	variables := map[string]interface{}{"filter": filter, "first": pageSize}
+
+	var items []T
+	for {
+		var data map[string]struct {
+			Nodes    []T `json:"nodes"`
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+		}
+		if err := l.query(ctx, apiKey, query, variables, &data); err != nil {
+			return nil, err
+		}
+
+		page := data[field]
+		items = append(items, page.Nodes...)
+		if limit > 0 && len(items) >= limit {
+			return items[:limit], nil
+		}
+		if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == "" || len(page.Nodes) == 0 {
+			return items, nil
+		}
+		variables["after"] = page.PageInfo.EndCursor
+	}
+}
+
+// linearTeamFilter builds a list filter, naming the team relation of the
+// filtered type; an empty relation leaves teams to the caller
+func linearTeamFilter(filter LinearFilter, teamRelation string) map[string]interface{} {
+	variables := map[string]interface{}{}
+	if !filter.UpdatedAfter.IsZero() {
+		variables["updatedAt"] = map[string]interface{}{"gt": filter.UpdatedAfter.UTC().Format(time.RFC3339Nano)}
+	}
+	if teamRelation != "" && len(filter.TeamKeys) > 0 {
+		variables[teamRelation] = map[string]interface{}{"key": map[string]interface{}{"in": filter.TeamKeys}}
+	}
+	return variables
+}
+
+// query runs a GraphQL query and decodes its data into result
+func (l *LinearServiceImpl) query(ctx context.Context, apiKey, query string, variables map[string]interface{}, result any) error {
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	// Personal API keys are sent as they are, OAuth access tokens as bearer tokens
+	if strings.HasPrefix(apiKey, "lin_api_") {
+		req.Header.Set("Authorization", apiKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ContextKeeper/1.0")
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var body struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		if resp.StatusCode >= 400 {
+			return &LinearAPIError{StatusCode: resp.StatusCode, Message: linearErrorBody(raw), RetryAfter: retryAfter}
+		}
+		return fmt.Errorf("failed to decode Linear response: %w", err)
+	}
+
+	if len(body.Errors) > 0 {
+		first := body.Errors[0]
+		messages := make([]string, len(body.Errors))
+		for i, e := range body.Errors {
+			messages[i] = e.Message
+		}
+		return &LinearAPIError{
+			StatusCode: linearErrorStatus(resp.StatusCode, first.Extensions.Code),
+			Code:       first.Extensions.Code,
+			Message:    strings.Join(messages, "; "),
+			RetryAfter: retryAfter,
+		}
+	}
+	if resp.StatusCode >= 400 {
+		return &LinearAPIError{StatusCode: resp.StatusCode, Message: linearErrorBody(raw), RetryAfter: retryAfter}
+	}
+
+	if err := json.Unmarshal(body.Data, result); err != nil {
+		return fmt.Errorf("failed to decode Linear response: %w", err)
+	}
+	return nil
+}
+
+// linearErrorStatus is the HTTP status a GraphQL error code stands for.
+// Linear answers most errors, rate limiting included, with 400 or even 200.
+func linearErrorStatus(status int, code string) int {
+	switch code {
+	case "AUTHENTICATION_ERROR":
+		return http.StatusUnauthorized
+	case "FORBIDDEN":
+		return http.StatusForbidden
+	case "RATELIMITED":
+		return http.StatusTooManyRequests
+	case "ENTITY_NOT_FOUND":
+		return http.StatusNotFound
+	}
+	if status < 400 {
+		return http.StatusBadRequest
+	}
+	return status
+}
+
+// linearErrorBody is the start of an error response that is not GraphQL
+func linearErrorBody(body []byte) string {
+	if len(body) > maxLinearErrorBody {
+		body = body[:maxLinearErrorBody]
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// IsLinearStatus reports whether err is a Linear API error with the status
+func IsLinearStatus(err error, status int) bool {
+	var apiErr *LinearAPIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// MCPProjectHeader selects the default project for MCP requests
+const MCPProjectHeader = "X-Project-ID"
+
+type mcpUserContextKey struct{}
+
+type mcpProjectContextKey struct{}
+
+// WithMCPUser returns a context carrying the authenticated MCP user
+func WithMCPUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, mcpUserContextKey{}, user)
+}
+
+// MCPUserFromContext returns the authenticated MCP user, if any
+func MCPUserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(mcpUserContextKey{}).(*models.User)
+	return user, ok && user != nil
+}
+
+// WithMCPProject returns a context carrying the default project for MCP tools
+func WithMCPProject(ctx context.Context, projectID string) context.Context {
+	return context.WithValue(ctx, mcpProjectContextKey{}, projectID)
+}
+
+// MCPProjectFromContext returns the default project for MCP tools, if any
+func MCPProjectFromContext(ctx context.Context) (string, bool) {
+	projectID, ok := ctx.Value(mcpProjectContextKey{}).(string)
+	return projectID, ok && projectID != ""
+}
+
+// defaultProject returns the project selected for the connection, either with
+// the project header or from the client's workspace folders
+func (m *MCPServer) defaultProject(ctx context.Context) (string, bool) {
+	if projectID, ok := MCPProjectFromContext(ctx); ok {
+		return projectID, true
+	}
+
+	if session, ok := MCPSessionFromContext(ctx); ok {
+		if workspace := session.waitForWorkspace(ctx, mcpRootsTimeout); workspace != nil {
+			return workspace.ProjectID, true
+		}
+	}
+
+	return "", false
+}
+
+// resolveProject determines the project a tool call targets and verifies the
+// caller can read it. A project_id argument takes precedence over the project
+// selected for the connection.
+func (m *MCPServer) resolveProject(ctx context.Context, arguments map[string]interface{}) (string, error) {
+	projectID := getStringParam(arguments, "project_id", "")
+	if projectID == "" {
+		projectID, _ = m.defaultProject(ctx)
+	}
+	if projectID == "" {
+		if _, ok := MCPUserFromContext(ctx); !ok {
+			return "", fmt.Errorf("authentication required")
+		}
+		return "", fmt.Errorf("project_id is required (pass it as an argument or set the %s header)", MCPProjectHeader)
+	}
+
+	if err := m.authorizeProject(ctx, projectID); err != nil {
+		return "", err
+	}
+
+	return projectID, nil
+}
+
+// authorizeProject verifies the authenticated user can read the project
+func (m *MCPServer) authorizeProject(ctx context.Context, projectID string) error {
+	user, ok := MCPUserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("authentication required")
+	}
+
+	if m.permissionSvc == nil {
+		return fmt.Errorf("permission service not configured")
+	}
+
+	canRead, err := m.permissionSvc.CanReadProject(ctx, user.ID, projectID)
+	if err != nil {
+		m.loggerFor(ctx).Error("Failed to check MCP project permissions", err, map[string]interface{}{
+			"user_id":    user.ID,
+			"project_id": projectID,
+		})
+		return fmt.Errorf("failed to check permissions")
+	}
+	if !canRead {
+		return fmt.Errorf("read access to project %s is required", projectID)
+	}
+
+	// A metered tool call is charged to the project it reads
+	return m.chargeToolCall(ctx, projectID)
+}
+
+// resolveWritableProject resolves the project like resolveProject and also
+// verifies the caller can write to it
+func (m *MCPServer) resolveWritableProject(ctx context.Context, arguments map[string]interface{}) (string, *models.User, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return "", nil, err
+	}
+
+	user, _ := MCPUserFromContext(ctx)
+	canWrite, err := m.permissionSvc.CanWriteProject(ctx, user.ID, projectID)
+	if err != nil {
+		m.loggerFor(ctx).Error("Failed to check MCP project permissions", err, map[string]interface{}{
+			"user_id":    user.ID,
+			"project_id": projectID,
+		})
+		return "", nil, fmt.Errorf("failed to check permissions")
+	}
+	if !canWrite {
+		return "", nil, fmt.Errorf("write access to project %s is required", projectID)
+	}
+
+	return projectID, user, nil
+}
+
+// projectAccessError wraps a project resolution failure as a tool error result
+func projectAccessError(err error) *MCPToolResult {
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Error: %v", err),
+		}},
+		IsError: true,
+	}
+}
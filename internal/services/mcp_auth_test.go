@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scopedKnowledgeGraphStub records the project each project-scoped call was made with.
+// Unscoped methods are left unimplemented so any use of them fails the test.
+type scopedKnowledgeGraphStub struct {
+	KnowledgeGraphService
+	projects []string
+}
+
+func (s *scopedKnowledgeGraphStub) SearchKnowledgeByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	s.projects = append(s.projects, projectID)
+	return []models.SearchResult{}, nil
+}
+
+func (s *scopedKnowledgeGraphStub) GetContextForFileByProject(ctx context.Context, projectID, filePath string) (*FileContextResponse, error) {
+	s.projects = append(s.projects, projectID)
+	return &FileContextResponse{FilePath: filePath}, nil
+}
+
+func (s *scopedKnowledgeGraphStub) GetDecisionHistoryByProject(ctx context.Context, projectID, target string) (*DecisionHistoryResponse, error) {
+	s.projects = append(s.projects, projectID)
+	return &DecisionHistoryResponse{Target: target}, nil
+}
+
+func (s *scopedKnowledgeGraphStub) GetRecentArchitectureDiscussionsByProject(ctx context.Context, projectID string, limit int) ([]models.DiscussionSummary, error) {
+	s.projects = append(s.projects, projectID)
+	return []models.DiscussionSummary{}, nil
+}
+
+// readPermissionStub grants read and write access to fixed sets of projects
+type readPermissionStub struct {
+	PermissionService
+	readable map[string]bool
+	writable map[string]bool
+	reads    atomic.Int32
+}
+
+func (p *readPermissionStub) CanReadProject(ctx context.Context, userID, projectID string) (bool, error) {
+	p.reads.Add(1)
+	return p.readable[projectID], nil
+}
+
+func (p *readPermissionStub) CanWriteProject(ctx context.Context, userID, projectID string) (bool, error) {
+	return p.writable[projectID], nil
+}
+
+// workspaceRepositoryStub serves every project workspace with the same settings
+type workspaceRepositoryStub struct {
+	RepositoryStore
+	settings map[string]interface{}
+}
+
+func (r *workspaceRepositoryStub) GetProjectWorkspace(ctx context.Context, projectID string) (*models.ProjectWorkspace, error) {
+	return &models.ProjectWorkspace{ID: projectID, Settings: r.settings}, nil
+}
+
+// newMCPTestServer builds an MCP server over kg and repository, returning it
+// with a context for user u1 in project p1. Without perms only p1 is readable.
+func newMCPTestServer(kg KnowledgeGraphService, repository RepositoryStore, perms *readPermissionStub) (*MCPServer, context.Context) {
+	if perms == nil {
+		perms = &readPermissionStub{readable: map[string]bool{"p1": true}}
+	}
+	server := NewMCPServer(kg, nil, perms, repository, &SimpleLogger{})
+
+	ctx := WithMCPProject(WithMCPUser(context.Background(), &models.User{ID: "u1", Email: "dev@example.com"}), "p1")
+	return server, ctx
+}
+
+func TestMCPTools_RequireAuthenticatedUser(t *testing.T) {
+	kg := &scopedKnowledgeGraphStub{}
+	server := NewMCPServer(kg, nil, &readPermissionStub{}, nil, &SimpleLogger{})
+
+	result, err := server.callTool(WithMCPProject(context.Background(), "p1"), "search_project_knowledge", map[string]interface{}{"query": "auth"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Empty(t, kg.projects)
+}
+
+func TestMCPTools_ScopeCallsToReadableProject(t *testing.T) {
+	kg := &scopedKnowledgeGraphStub{}
+	server, ctx := newMCPTestServer(kg, nil, &readPermissionStub{readable: map[string]bool{"p1": true, "p2": true}})
+
+	tools := map[string]map[string]interface{}{
+		"search_project_knowledge":             {"query": "auth"},
+		"get_context_for_file":                 {"file_path": "main.go"},
+		"get_decision_history":                 {"target": "auth"},
+		"list_recent_architecture_discussions": {},
+		"explain_why_code_exists":              {"file_path": "main.go"},
+	}
+
+	for name, args := range tools {
+		kg.projects = nil
+		result, err := server.callTool(ctx, name, args)
+		require.NoError(t, err, name)
+		assert.False(t, result.IsError, name)
+		require.NotEmpty(t, kg.projects, name)
+		for _, projectID := range kg.projects {
+			assert.Equal(t, "p1", projectID, name)
+		}
+	}
+
+	// A project_id argument overrides the connection default
+	kg.projects = nil
+	result, err := server.callTool(ctx, "get_decision_history", map[string]interface{}{"target": "auth", "project_id": "p2"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, []string{"p2"}, kg.projects)
+}
+
+func TestMCPTools_RejectUnreadableOrMissingProject(t *testing.T) {
+	kg := &scopedKnowledgeGraphStub{}
+	perms := &readPermissionStub{readable: map[string]bool{"p1": true}}
+	server := NewMCPServer(kg, nil, perms, nil, &SimpleLogger{})
+
+	ctx := WithMCPUser(context.Background(), &models.User{ID: "u1"})
+
+	result, err := server.callTool(ctx, "get_context_for_file", map[string]interface{}{"file_path": "main.go"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "project_id is required")
+
+	result, err = server.callTool(ctx, "get_context_for_file", map[string]interface{}{"file_path": "main.go", "project_id": "other"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Empty(t, kg.projects)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxCompletionValues is the most values MCP allows in one completion/complete result
+const maxCompletionValues = 100
+
+// completionSource lists project values that contain the text typed so far
+type completionSource func(ctx context.Context, projectID, contains string, limit int) ([]string, error)
+
+// completionSources returns the value source for an argument of a tool, prompt
+// or resource template, keyed by reference type, reference name and argument name
+func (m *MCPServer) completionSources() map[string]map[string]map[string]completionSource {
+	filePaths := m.knowledgeGraph.SuggestFilePathsByProject
+	return map[string]map[string]map[string]completionSource{
+		"ref/tool": {
+			"get_context_for_file":    {"file_path": filePaths},
+			"explain_why_code_exists": {"file_path": filePaths},
+			"annotate_file_context":   {"file_path": filePaths},
+			"get_decision_history":    {"target": m.knowledgeGraph.SuggestDecisionTargetsByProject},
+		},
+		"ref/prompt": {
+			"review_file_against_decisions": {"file_path": filePaths},
+			"resume_feature":                {"feature": m.knowledgeGraph.SuggestFeatureNamesByProject},
+		},
+		"ref/resource": {
+			mcpResourceScheme + "project/{project_id}/file/{path}": {"path": filePaths},
+		},
+	}
+}
+
+// handleCompletionComplete suggests values for a tool, prompt or resource
+// template argument from what the project's knowledge graph has indexed
+func (m *MCPServer) handleCompletionComplete(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	ref, _ := req.Params["ref"].(map[string]interface{})
+	argument, _ := req.Params["argument"].(map[string]interface{})
+	argumentName := getStringParam(argument, "name", "")
+	if ref == nil || argumentName == "" {
+		return newJSONRPCErrorResponse(InvalidParams, "Missing completion ref or argument", req.ID)
+	}
+
+	refType := getStringParam(ref, "type", "")
+	refName := getStringParam(ref, "name", "")
+	if refType == "ref/resource" {
+		refName = getStringParam(ref, "uri", "")
+	}
+
+	values := []string{}
+	source, ok := m.completionSources()[refType][refName][argumentName]
+	if !ok {
+		return completionResponse(req.ID, values, false)
+	}
+
+	// Arguments the client already filled in, such as project_id, scope the suggestions
+	completionContext, _ := req.Params["context"].(map[string]interface{})
+	contextArgs, _ := completionContext["arguments"].(map[string]interface{})
+	projectID, err := m.resolveProject(ctx, contextArgs)
+	if err != nil {
+		return newJSONRPCErrorResponse(InvalidParams, err.Error(), req.ID)
+	}
+
+	// Ask for one extra value to learn whether the list was cut short
+	found, err := source(ctx, projectID, getStringParam(argument, "value", ""), maxCompletionValues+1)
+	if err != nil {
+		m.loggerFor(ctx).Error("Failed to complete MCP argument", err, map[string]interface{}{
+			"project_id": projectID,
+			"ref":        refName,
+			"argument":   argumentName,
+		})
+		return newJSONRPCErrorResponse(InternalError, fmt.Sprintf("Failed to complete %s", argumentName), req.ID)
+	}
+
+	hasMore := len(found) > maxCompletionValues
+	if hasMore {
+		found = found[:maxCompletionValues]
+	}
+	values = append(values, found...)
+
+	return completionResponse(req.ID, values, hasMore)
+}
+
+// completionResponse builds a completion/complete result; the total is only
+// known when nothing was left out
+func completionResponse(id interface{}, values []string, hasMore bool) *JSONRPCResponse {
+	completion := map[string]interface{}{
+		"values":  values,
+		"hasMore": hasMore,
+	}
+	if !hasMore {
+		completion["total"] = len(values)
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  map[string]interface{}{"completion": completion},
+		ID:      id,
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// completionRepositoryStub serves completion values per project
+type completionRepositoryStub struct {
+	RepositoryStore
+	filePaths map[string][]string
+	features  map[string][]string
+	decisions map[string][]string
+}
+
+func filterContaining(values []string, contains string, limit int) []string {
+	var matches []string
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), strings.ToLower(contains)) {
+			matches = append(matches, value)
+		}
+	}
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func (r *completionRepositoryStub) ListFilePathsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	return filterContaining(r.filePaths[projectID], contains, limit), nil
+}
+
+func (r *completionRepositoryStub) ListFeatureNamesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	return filterContaining(r.features[projectID], contains, limit), nil
+}
+
+func (r *completionRepositoryStub) ListDecisionTitlesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) {
+	return filterContaining(r.decisions[projectID], contains, limit), nil
+}
+
+func complete(t *testing.T, server *MCPServer, ctx context.Context, params string) JSONRPCResponse {
+	raw, err := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":`+params+`}`))
+	require.NoError(t, err)
+
+	var resp JSONRPCResponse
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	return resp
+}
+
+func completionValues(t *testing.T, resp JSONRPCResponse) ([]string, map[string]interface{}) {
+	require.Nil(t, resp.Error)
+	completion := resp.Result.(map[string]interface{})["completion"].(map[string]interface{})
+
+	var values []string
+	for _, value := range completion["values"].([]interface{}) {
+		values = append(values, value.(string))
+	}
+	return values, completion
+}
+
+func TestMCPCompletion_FilePathsAreProjectScoped(t *testing.T) {
+	repo := &completionRepositoryStub{
+		filePaths: map[string][]string{
+			"p1": {"billing/client.go", "billing/retry.go", "auth/session.go"},
+			"p2": {"billing/secret.go"},
+		},
+	}
+	server, ctx := newMCPTestServer(NewKnowledgeGraphService(repo, nil, nil, &SimpleLogger{}), nil, nil)
+
+	for _, params := range []string{
+		`{"ref":{"type":"ref/tool","name":"get_context_for_file"},"argument":{"name":"file_path","value":"billing"}}`,
+		`{"ref":{"type":"ref/prompt","name":"review_file_against_decisions"},"argument":{"name":"file_path","value":"billing"}}`,
+		`{"ref":{"type":"ref/resource","uri":"ckeeper://project/{project_id}/file/{path}"},"argument":{"name":"path","value":"billing"},"context":{"arguments":{"project_id":"p1"}}}`,
+	} {
+		values, completion := completionValues(t, complete(t, server, ctx, params))
+		assert.Equal(t, []string{"billing/client.go", "billing/retry.go"}, values, params)
+		assert.Equal(t, float64(2), completion["total"])
+		assert.Equal(t, false, completion["hasMore"])
+	}
+
+	// Another project's paths need read access to that project
+	resp := complete(t, server, ctx, `{"ref":{"type":"ref/tool","name":"explain_why_code_exists"},"argument":{"name":"file_path","value":"billing"},"context":{"arguments":{"project_id":"p2"}}}`)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, InvalidParams, resp.Error.Code)
+}
+
+func TestMCPCompletion_DecisionTargets(t *testing.T) {
+	repo := &completionRepositoryStub{
+		features:  map[string][]string{"p1": {"Stripe billing", "Auth"}},
+		decisions: map[string][]string{"p1": {"Use Stripe for billing", "stripe billing"}},
+	}
+	server, ctx := newMCPTestServer(NewKnowledgeGraphService(repo, nil, nil, &SimpleLogger{}), nil, nil)
+
+	values, _ := completionValues(t, complete(t, server, ctx, `{"ref":{"type":"ref/tool","name":"get_decision_history"},"argument":{"name":"target","value":"stripe"}}`))
+	assert.Equal(t, []string{"Stripe billing", "Use Stripe for billing"}, values)
+
+	values, _ = completionValues(t, complete(t, server, ctx, `{"ref":{"type":"ref/prompt","name":"resume_feature"},"argument":{"name":"feature","value":""}}`))
+	assert.Equal(t, []string{"Stripe billing", "Auth"}, values)
+}
+
+func TestMCPCompletion_LimitsAndUnknownArguments(t *testing.T) {
+	var paths []string
+	for i := 0; i < maxCompletionValues+20; i++ {
+		paths = append(paths, fmt.Sprintf("pkg/file%03d.go", i))
+	}
+	repo := &completionRepositoryStub{filePaths: map[string][]string{"p1": paths}}
+	server, ctx := newMCPTestServer(NewKnowledgeGraphService(repo, nil, nil, &SimpleLogger{}), nil, nil)
+
+	values, completion := completionValues(t, complete(t, server, ctx, `{"ref":{"type":"ref/tool","name":"get_context_for_file"},"argument":{"name":"file_path","value":"pkg/"}}`))
+	assert.Len(t, values, maxCompletionValues)
+	assert.Equal(t, true, completion["hasMore"])
+	assert.NotContains(t, completion, "total")
+
+	values, completion = completionValues(t, complete(t, server, ctx, `{"ref":{"type":"ref/tool","name":"search_project_knowledge"},"argument":{"name":"query","value":"pkg"}}`))
+	assert.Empty(t, values)
+	assert.Equal(t, float64(0), completion["total"])
+
+	resp := complete(t, server, ctx, `{"argument":{"name":"file_path","value":"pkg"}}`)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, InvalidParams, resp.Error.Code)
+}
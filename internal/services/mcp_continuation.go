@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultMCPResultTTL is how long the remaining pages of a large tool result stay available
+	defaultMCPResultTTL = 15 * time.Minute
+
+	// maxMCPCachedResults bounds the number of paged results held in memory
+	maxMCPCachedResults = 1000
+)
+
+// mcpCachedResult holds every page of a tool result that was too large for one response
+type mcpCachedResult struct {
+	userID    string
+	projectID string
+	pages     []string
+	cursors   []string
+	expiresAt time.Time
+}
+
+// mcpCursor points at one page of a cached result
+type mcpCursor struct {
+	result *mcpCachedResult
+	page   int
+}
+
+// mcpResultCache stores paged tool results behind opaque cursors
+type mcpResultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	results []*mcpCachedResult
+	cursors map[string]mcpCursor
+}
+
+func newMCPResultCache(ttl time.Duration) *mcpResultCache {
+	return &mcpResultCache{
+		ttl:     ttl,
+		cursors: make(map[string]mcpCursor),
+	}
+}
+
+// store caches the pages of a result and returns the cursor for each page after the first
+func (c *mcpResultCache) store(userID, projectID string, pages []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	c.cleanupExpired(now)
+	for len(c.results) >= maxMCPCachedResults {
+		c.evict(c.results[0])
+	}
+
+	result := &mcpCachedResult{
+		userID:    userID,
+		projectID: projectID,
+		pages:     pages,
+		cursors:   make([]string, len(pages)),
+		expiresAt: now.Add(c.ttl),
+	}
+	for page := 1; page < len(pages); page++ {
+		cursor := generateID()
+		result.cursors[page] = cursor
+		c.cursors[cursor] = mcpCursor{result: result, page: page}
+	}
+	c.results = append(c.results, result)
+
+	return result.cursors
+}
+
+// lookup returns the cached result and page a cursor points at
+func (c *mcpResultCache) lookup(cursor string) (*mcpCachedResult, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.cursors[cursor]
+	if !ok {
+		return nil, 0, false
+	}
+	if time.Now().After(entry.result.expiresAt) {
+		c.evict(entry.result)
+		return nil, 0, false
+	}
+	return entry.result, entry.page, true
+}
+
+// cleanupExpired drops expired results; results are kept in expiry order
+func (c *mcpResultCache) cleanupExpired(now time.Time) {
+	for len(c.results) > 0 && now.After(c.results[0].expiresAt) {
+		c.evict(c.results[0])
+	}
+}
+
+// evict removes a result and all of its cursors
+func (c *mcpResultCache) evict(result *mcpCachedResult) {
+	for _, cursor := range result.cursors {
+		delete(c.cursors, cursor)
+	}
+	for i, cached := range c.results {
+		if cached == result {
+			c.results = append(c.results[:i], c.results[i+1:]...)
+			break
+		}
+	}
+}
+
+// pagedToolResult returns content as a tool result. Content over the response
+// token limit is split into pages; the first page is returned and the rest are
+// cached behind a cursor that continue_result accepts.
+func (m *MCPServer) pagedToolResult(ctx context.Context, projectID, content string) *MCPToolResult {
+	if m.responseOptimizer.CountTokens(content) <= m.responseOptimizer.maxTokens {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: content,
+			}},
+		}
+	}
+
+	chunks := m.responseOptimizer.CreateStreamedResponse(content, m.responseOptimizer.maxTokens)
+	pages := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		pages = append(pages, chunk.Content[0].Text)
+	}
+
+	userID := ""
+	if user, ok := MCPUserFromContext(ctx); ok {
+		userID = user.ID
+	}
+	cursors := m.results.store(userID, projectID, pages)
+
+	return resultPage(pages, cursors, 0)
+}
+
+// packedToolResult renders a tool's items as markdown. Given a max_tokens
+// argument, the most relevant items that fit are returned and the rest are
+// named in a note; otherwise every item is returned, paged rather than
+// truncated when too large, and callers attach their structured data whole
+// to the first page.
+func (m *MCPServer) packedToolResult(ctx context.Context, projectID string, arguments map[string]interface{}, header string, items []PackItem, footer string) (*MCPToolResult, *PackResult) {
+	maxTokens := getIntParam(arguments, "max_tokens", 0)
+	content, packed := m.responseOptimizer.PackMarkdown(header, items, footer, maxTokens)
+	if maxTokens <= 0 {
+		return m.pagedToolResult(ctx, projectID, content), packed
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: content,
+		}},
+	}, packed
+}
+
+// addPackingSummary reports a max_tokens budget and what it left out in a
+// tool's structured content
+func addPackingSummary(structured map[string]interface{}, packed *PackResult) {
+	if packed.MaxTokens <= 0 {
+		return
+	}
+
+	omitted := make([]map[string]interface{}, 0, len(packed.Omitted))
+	for _, item := range packed.Omitted {
+		omitted = append(omitted, map[string]interface{}{
+			"id":     item.ID,
+			"title":  item.Title,
+			"tokens": item.Tokens,
+			"score":  item.Score,
+		})
+	}
+	structured["packing"] = map[string]interface{}{
+		"max_tokens":  packed.MaxTokens,
+		"used_tokens": packed.UsedTokens,
+		"omitted":     omitted,
+	}
+}
+
+// resultPage renders one page of a cached result with a pointer to the next page
+func resultPage(pages, cursors []string, page int) *MCPToolResult {
+	text := pages[page]
+	result := &MCPToolResult{}
+
+	if page+1 < len(pages) {
+		next := cursors[page+1]
+		text += fmt.Sprintf("\n---\n*Part %d of %d. Call continue_result with cursor \"%s\" to read the next part.*\n", page+1, len(pages), next)
+		result.Meta = map[string]interface{}{"nextCursor": next}
+	} else {
+		text += fmt.Sprintf("\n---\n*Part %d of %d (end of result).*\n", page+1, len(pages))
+	}
+
+	result.Content = []MCPContent{{
+		Type: "text",
+		Text: text,
+	}}
+	return result
+}
+
+// continueResult implements the continue_result MCP tool
+func (m *MCPServer) continueResult(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	cursor := getStringParam(arguments, "cursor", "")
+	if cursor == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: "Error: cursor parameter is required",
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	user, ok := MCPUserFromContext(ctx)
+	if !ok {
+		return projectAccessError(fmt.Errorf("authentication required")), nil
+	}
+
+	result, page, ok := m.results.lookup(cursor)
+	if !ok || result.userID != user.ID {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: "Error: cursor is unknown or has expired; call the original tool again",
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	// Access may have been revoked since the result was produced
+	if err := m.authorizeProject(ctx, result.projectID); err != nil {
+		return projectAccessError(err), nil
+	}
+
+	return resultPage(result.pages, result.cursors, page), nil
+}
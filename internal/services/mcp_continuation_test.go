@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeDecisionHistoryStub returns a decision history far larger than one response
+type largeDecisionHistoryStub struct {
+	KnowledgeGraphService
+	decisions int
+}
+
+func (s *largeDecisionHistoryStub) GetDecisionHistoryByProject(ctx context.Context, projectID, target string) (*DecisionHistoryResponse, error) {
+	decisions := make([]models.DecisionRecord, 0, s.decisions)
+	for i := 0; i < s.decisions; i++ {
+		decisions = append(decisions, models.DecisionRecord{
+			Title:    fmt.Sprintf("Decision %03d", i),
+			Decision: strings.Repeat("Keep the payment client stateless. ", 40),
+			Status:   "accepted",
+		})
+	}
+	return &DecisionHistoryResponse{Target: target, Decisions: decisions}, nil
+}
+
+func TestMCPContinuation_PagesThroughFullResult(t *testing.T) {
+	server, ctx := newMCPTestServer(&largeDecisionHistoryStub{decisions: 20}, nil, nil)
+
+	result, err := server.callTool(ctx, "get_decision_history", map[string]interface{}{"target": "billing"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	require.NotNil(t, result.Meta)
+
+	var full strings.Builder
+	full.WriteString(result.Content[0].Text)
+
+	pages := 1
+	for result.Meta != nil {
+		cursor := result.Meta["nextCursor"].(string)
+		assert.Contains(t, result.Content[0].Text, cursor)
+
+		result, err = server.callTool(ctx, "continue_result", map[string]interface{}{"cursor": cursor})
+		require.NoError(t, err)
+		require.False(t, result.IsError, result.Content[0].Text)
+		full.WriteString(result.Content[0].Text)
+		pages++
+	}
+
+	assert.Greater(t, pages, 1)
+	assert.Contains(t, result.Content[0].Text, "end of result")
+	for i := 0; i < 20; i++ {
+		assert.Contains(t, full.String(), fmt.Sprintf("Decision %03d", i))
+	}
+	assert.NotContains(t, full.String(), "truncated")
+}
+
+func TestMCPContinuation_CursorIsBoundToUserAndExpires(t *testing.T) {
+	server, ctx := newMCPTestServer(&largeDecisionHistoryStub{decisions: 20}, nil, nil)
+
+	result, err := server.callTool(ctx, "get_decision_history", map[string]interface{}{"target": "billing"})
+	require.NoError(t, err)
+	cursor := result.Meta["nextCursor"].(string)
+
+	otherCtx := WithMCPUser(context.Background(), &models.User{ID: "u2"})
+	result, err = server.callTool(otherCtx, "continue_result", map[string]interface{}{"cursor": cursor})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	result, err = server.callTool(ctx, "continue_result", map[string]interface{}{"cursor": "unknown"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	server.results = newMCPResultCache(time.Millisecond)
+	result, err = server.callTool(ctx, "get_decision_history", map[string]interface{}{"target": "billing"})
+	require.NoError(t, err)
+	cursor = result.Meta["nextCursor"].(string)
+
+	time.Sleep(5 * time.Millisecond)
+	result, err = server.callTool(ctx, "continue_result", map[string]interface{}{"cursor": cursor})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "expired")
+}
+
+func TestMCPContinuation_SmallResultsAreNotPaged(t *testing.T) {
+	server, ctx := newMCPTestServer(&largeDecisionHistoryStub{decisions: 1}, nil, nil)
+
+	result, err := server.callTool(ctx, "get_decision_history", map[string]interface{}{"target": "billing"})
+	require.NoError(t, err)
+	assert.Nil(t, result.Meta)
+	assert.NotContains(t, result.Content[0].Text, "continue_result")
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphRepositoryStub serves a fixed project graph the way the repository
+// traversal does: every relationship type is followed and entities can repeat
+type graphRepositoryStub struct {
+	RepositoryStore
+	entities      map[string]models.KnowledgeEntity
+	relationships []models.KnowledgeRelationship
+	traversals    []string
+}
+
+func (r *graphRepositoryStub) GetKnowledgeEntityByIDAndProject(ctx context.Context, id, projectID string) (*models.KnowledgeEntity, error) {
+	if entity, ok := r.entities[id]; ok && projectID == "p1" {
+		return &entity, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *graphRepositoryStub) SearchKnowledgeEntitiesByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	return []models.SearchResult{
+		{Entity: r.entities["slack"]},
+		{Entity: r.entities["file"]},
+	}, nil
+}
+
+func (r *graphRepositoryStub) TraverseKnowledgeGraphByProject(ctx context.Context, projectID, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error) {
+	r.traversals = append(r.traversals, startEntityID)
+
+	var relationships []models.KnowledgeRelationship
+	for _, rel := range r.relationships {
+		if len(relationshipTypes) == 0 || containsString(relationshipTypes, rel.RelationshipType) {
+			relationships = append(relationships, rel)
+		}
+	}
+	return &models.GraphTraversalResult{
+		Path: []models.KnowledgeEntity{
+			r.entities[startEntityID], r.entities["decision"], r.entities["slack"], r.entities["decision"], r.entities["pr"],
+		},
+		Relationships: relationships,
+	}, nil
+}
+
+func newExploreTestServer() (*MCPServer, *graphRepositoryStub, context.Context) {
+	repo := &graphRepositoryStub{
+		entities: map[string]models.KnowledgeEntity{
+			"file":     {ID: "file", EntityType: "file_context", Title: "internal/auth/jwt.go"},
+			"decision": {ID: "decision", EntityType: "decision", Title: "Use JWT", SourceEventIDs: models.StringList{"evt-9"}},
+			"slack":    {ID: "slack", EntityType: "discussion", Title: "Auth thread"},
+			"pr":       {ID: "pr", EntityType: "discussion", Title: "PR #12"},
+		},
+		relationships: []models.KnowledgeRelationship{
+			{SourceEntityID: "file", TargetEntityID: "decision", RelationshipType: "relates_to", Strength: 0.8},
+			{SourceEntityID: "decision", TargetEntityID: "slack", RelationshipType: "discussed_in", Strength: 0.6},
+			{SourceEntityID: "file", TargetEntityID: "pr", RelationshipType: "modified_by", Strength: 0.9},
+		},
+	}
+	server, ctx := newMCPTestServer(NewKnowledgeGraphService(repo, nil, nil, &SimpleLogger{}), nil, nil)
+	return server, repo, ctx
+}
+
+func TestExploreRelatedKnowledge_WalksFileToDecisionToThread(t *testing.T) {
+	server, repo, ctx := newExploreTestServer()
+
+	result, err := server.callTool(ctx, "explore_related_knowledge", map[string]interface{}{
+		"entity":             "file",
+		"max_depth":          2,
+		"relationship_types": []interface{}{"relates_to", "discussed_in"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+	assert.Equal(t, []string{"file"}, repo.traversals)
+
+	text := result.Content[0].Text
+	assert.Contains(t, text, "Use JWT")
+	assert.Contains(t, text, "Auth thread")
+	assert.Contains(t, text, "evt-9")
+	assert.Contains(t, text, "internal/auth/jwt.go --relates_to (0.80)--> Use JWT")
+	assert.NotContains(t, text, "PR #12", "modified_by was not requested")
+
+	structured := result.StructuredContent.(map[string]interface{})
+	entities := structured["entities"].([]map[string]interface{})
+	require.Len(t, entities, 3, "repeated entities are listed once")
+	depths := map[string]int{}
+	for _, e := range entities {
+		depths[e["entity"].(models.KnowledgeEntity).ID] = e["depth"].(int)
+	}
+	assert.Equal(t, map[string]int{"file": 0, "decision": 1, "slack": 2}, depths)
+	assert.InDelta(t, 1.4, structured["total_strength"], 0.0001)
+}
+
+func TestExploreRelatedKnowledge_ResolvesTitleAndLimitsDepth(t *testing.T) {
+	server, repo, ctx := newExploreTestServer()
+
+	result, err := server.callTool(ctx, "explore_related_knowledge", map[string]interface{}{
+		"entity":    "internal/auth/jwt.go",
+		"max_depth": 1,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+	assert.Equal(t, []string{"file"}, repo.traversals, "exact title match wins over the best search hit")
+	assert.Contains(t, result.Content[0].Text, "PR #12")
+	assert.NotContains(t, result.Content[0].Text, "Auth thread", "thread is two hops away")
+
+	result, err = server.callTool(ctx, "explore_related_knowledge", map[string]interface{}{
+		"entity":             "file",
+		"relationship_types": []interface{}{"owned_by"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestExploreRelatedKnowledge_CutsLongContentOnARuneBoundary(t *testing.T) {
+	server, repo, ctx := newExploreTestServer()
+	decision := repo.entities["decision"]
+	decision.Content = "x" + strings.Repeat("é", 300)
+	repo.entities["decision"] = decision
+
+	result, err := server.callTool(ctx, "explore_related_knowledge", map[string]interface{}{"entity": "file"})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+
+	text := result.Content[0].Text
+	assert.True(t, utf8.ValidString(text))
+	assert.Contains(t, text, "x"+strings.Repeat("é", 149)+"...")
+}
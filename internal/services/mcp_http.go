@@ -0,0 +1,203 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// sseKeepAliveInterval controls how often an idle SSE stream receives a comment to keep proxies from closing it
+const sseKeepAliveInterval = 25 * time.Second
+
+// ServeHTTP implements the MCP Streamable HTTP transport.
+// POST delivers client messages, GET opens the server-to-client SSE stream
+// for a session, and DELETE terminates a session.
+func (m *MCPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		m.handleHTTPPost(w, r)
+	case http.MethodGet:
+		m.handleHTTPStream(w, r)
+	case http.MethodDelete:
+		m.handleHTTPDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleHTTPPost processes a JSON-RPC message sent by the client
+func (m *MCPServer) handleHTTPPost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		m.sendError(w, ParseError, "Parse error", nil, nil)
+		return
+	}
+
+	ctx := r.Context()
+	if projectID := r.Header.Get(MCPProjectHeader); projectID != "" {
+		ctx = WithMCPProject(ctx, projectID)
+	}
+
+	// Sessions are optional for plain request/response clients, but an unknown
+	// or expired session must be reported so the client can re-initialize
+	sessionID := r.Header.Get(MCPSessionHeader)
+	var session *MCPSession
+	if sessionID != "" {
+		var ok bool
+		session, ok = m.lookupSession(r, sessionID)
+		if !ok {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+	} else if isInitializeMessage(body) {
+		session = m.sessions.Create(requestUserID(r))
+		w.Header().Set(MCPSessionHeader, session.ID)
+	}
+
+	if session != nil {
+		ctx = WithMCPSession(ctx, session)
+	}
+
+	response, err := m.HandleMessage(ctx, body)
+	if err != nil {
+		m.sendError(w, InternalError, "Internal error", nil, nil)
+		return
+	}
+
+	// Notifications and client responses have nothing to return
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(response)
+}
+
+// handleHTTPStream opens an SSE stream that carries server-to-client messages for a session.
+// Clients reconnecting with Last-Event-ID receive any buffered events they missed.
+func (m *MCPServer) handleHTTPStream(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "Accept header must include text/event-stream", http.StatusNotAcceptable)
+		return
+	}
+
+	sessionID := r.Header.Get(MCPSessionHeader)
+	if sessionID == "" {
+		http.Error(w, "Missing "+MCPSessionHeader+" header", http.StatusBadRequest)
+		return
+	}
+
+	session, ok := m.lookupSession(r, sessionID)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	replay, events, unsubscribe := session.Subscribe(r.Header.Get("Last-Event-ID"))
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set(MCPSessionHeader, session.ID)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for _, event := range replay {
+		if err := writeSSEEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if err := writeSSEEvent(w, event); err != nil {
+				m.logger.Debug("MCP SSE stream closed", map[string]interface{}{
+					"session_id": session.ID,
+					"error":      err.Error(),
+				})
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			session.Touch()
+			flusher.Flush()
+		}
+	}
+}
+
+// handleHTTPDelete terminates a session at the client's request
+func (m *MCPServer) handleHTTPDelete(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.Header.Get(MCPSessionHeader)
+	if sessionID == "" {
+		http.Error(w, "Missing "+MCPSessionHeader+" header", http.StatusBadRequest)
+		return
+	}
+
+	if _, ok := m.lookupSession(r, sessionID); !ok || !m.sessions.Delete(sessionID) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// lookupSession returns an active session owned by the requesting user.
+// Sessions belonging to another user are reported as not found.
+func (m *MCPServer) lookupSession(r *http.Request, sessionID string) (*MCPSession, bool) {
+	session, ok := m.sessions.Get(sessionID)
+	if !ok || session.UserID != requestUserID(r) {
+		return nil, false
+	}
+	return session, true
+}
+
+// requestUserID returns the authenticated user's ID for the request, or "" when unauthenticated
+func requestUserID(r *http.Request) string {
+	if user, ok := MCPUserFromContext(r.Context()); ok {
+		return user.ID
+	}
+	return ""
+}
+
+// writeSSEEvent writes a single event in text/event-stream format
+func writeSSEEvent(w io.Writer, event MCPSessionEvent) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", event.ID, event.Data)
+	return err
+}
+
+// isInitializeMessage reports whether a raw JSON-RPC message is an initialize request
+func isInitializeMessage(message []byte) bool {
+	var req struct {
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(message, &req); err != nil {
+		return false
+	}
+	return req.Method == "initialize"
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postMCP(t *testing.T, url, sessionID, body string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(MCPSessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestMCPHTTP_InitializeCreatesSession(t *testing.T) {
+	server := NewMCPServer(nil, nil, nil, nil, &SimpleLogger{})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp := postMCP(t, ts.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	sessionID := resp.Header.Get(MCPSessionHeader)
+	require.NotEmpty(t, sessionID)
+
+	resp = postMCP(t, ts.URL, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = postMCP(t, ts.URL, "unknown-session", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL, nil)
+	req.Header.Set(MCPSessionHeader, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = postMCP(t, ts.URL, sessionID, `{"jsonrpc":"2.0","id":4,"method":"tools/list"}`)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestMCPHTTP_StreamDeliversAndResumesEvents(t *testing.T) {
+	server := NewMCPServer(nil, nil, nil, nil, &SimpleLogger{})
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	session := server.sessions.Create("")
+	require.NoError(t, session.Notify("notifications/message", map[string]interface{}{"data": "first"}))
+	require.NoError(t, session.Notify("notifications/message", map[string]interface{}{"data": "second"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(MCPSessionHeader, session.ID)
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, string) {
+		var id, data string
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "id: "):
+				id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && id != "":
+				return id, data
+			}
+		}
+	}
+
+	// Only the event after Last-Event-ID is replayed
+	id, data := readEvent()
+	assert.Equal(t, "2", id)
+	assert.Contains(t, data, "second")
+
+	require.NoError(t, session.Notify("notifications/message", map[string]interface{}{"data": "live"}))
+	id, data = readEvent()
+	assert.Equal(t, "3", id)
+	assert.Contains(t, data, "live")
+}
+
+func TestMCPHTTP_StreamRequiresSession(t *testing.T) {
+	server := NewMCPServer(nil, nil, nil, nil, &SimpleLogger{})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set(MCPSessionHeader, "abc")
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotAcceptable, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/mcp", nil)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMCPSyncInterval is how often a project, or one of its data sources,
+// can be synced on request
+const defaultMCPSyncInterval = 5 * time.Minute
+
+// mcpSyncLimiter rate-limits on-demand syncs by project and data source
+type mcpSyncLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+	now      func() time.Time
+}
+
+func newMCPSyncLimiter(interval time.Duration) *mcpSyncLimiter {
+	return &mcpSyncLimiter{
+		interval: interval,
+		last:     make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// reserve records a sync under key unless one covering any of keys was
+// requested within the interval, in which case it returns how long to wait.
+// Checking and recording under one lock keeps concurrent requests, such as
+// those of a JSON-RPC batch, from both passing.
+func (l *mcpSyncLimiter) reserve(key string, keys []string) (time.Time, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, k := range keys {
+		if last, ok := l.last[k]; ok && last.Add(l.interval).Sub(now) > wait {
+			wait = last.Add(l.interval).Sub(now)
+		}
+	}
+	if wait > 0 {
+		return now, wait
+	}
+
+	for k, last := range l.last {
+		if now.Sub(last) >= l.interval {
+			delete(l.last, k)
+		}
+	}
+	l.last[key] = now
+	return now, 0
+}
+
+// release forgets the reservation made at reservedAt, for a sync that did not
+// start, unless key was reserved again since
+func (l *mcpSyncLimiter) release(key string, reservedAt time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if last, ok := l.last[key]; ok && last.Equal(reservedAt) {
+		delete(l.last, key)
+	}
+}
+
+// SetIngestionOrchestrator enables the ingestion status and sync tools,
+// which are not listed or callable without one
+func (m *MCPServer) SetIngestionOrchestrator(ingestion IngestionOrchestrator) {
+	m.ingestion = ingestion
+}
+
+// isIngestionTool reports whether a tool needs an ingestion orchestrator
+func isIngestionTool(name string) bool {
+	return name == "get_ingestion_status" || name == "request_sync"
+}
+
+// ingestionUnavailable is returned by request_sync when the server cannot look up data sources
+func ingestionUnavailable() *MCPToolResult {
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: "Error: ingestion is not available on this server",
+		}},
+		IsError: true,
+	}
+}
+
+// getIngestionStatus implements the get_ingestion_status MCP tool
+func (m *MCPServer) getIngestionStatus(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
+	health, err := m.ingestion.GetIngestionHealth(ctx, projectID)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error getting ingestion status: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+	if health.Integrations == nil {
+		health.Integrations = []IntegrationHealthStatus{}
+	}
+
+	now := time.Now()
+	var content strings.Builder
+	content.WriteString("# Ingestion Status\n\n")
+	content.WriteString(fmt.Sprintf("**Overall:** %s\n", health.OverallStatus))
+	content.WriteString(fmt.Sprintf("**Last sync:** %s\n", formatSyncTime(health.LastSyncAt, now)))
+	content.WriteString(fmt.Sprintf("**Integrations:** %d active, %d healthy, %d failed\n\n",
+		health.ActiveIntegrations, health.HealthyIntegrations, health.FailedIntegrations))
+
+	if len(health.Integrations) == 0 {
+		content.WriteString("No integrations are connected, so the project's context only contains what was recorded directly.\n")
+	}
+
+	for _, integration := range health.Integrations {
+		content.WriteString(fmt.Sprintf("## %s\n\n", integration.Platform))
+		content.WriteString(fmt.Sprintf("- **Integration:** %s\n", integration.IntegrationID))
+		content.WriteString(fmt.Sprintf("- **Status:** %s\n", integration.Status))
+		lastSync := formatSyncTime(integration.LastSyncAt, now)
+		if integration.LastSyncStatus != nil {
+			lastSync += fmt.Sprintf(" (%s)", *integration.LastSyncStatus)
+		}
+		content.WriteString(fmt.Sprintf("- **Last sync:** %s\n", lastSync))
+		content.WriteString(fmt.Sprintf("- **Data sources:** %d of %d active\n", integration.ActiveDataSources, integration.DataSourceCount))
+		if integration.NextSyncScheduled != nil {
+			content.WriteString(fmt.Sprintf("- **Next sync:** %s\n", integration.NextSyncScheduled.Format(time.RFC3339)))
+		}
+		if integration.ErrorMessage != nil && *integration.ErrorMessage != "" {
+			content.WriteString(fmt.Sprintf("- **Error:** %s\n", *integration.ErrorMessage))
+		}
+		content.WriteString("\n")
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: content.String(),
+		}},
+		StructuredContent: map[string]interface{}{
+			"project_id": projectID,
+			"health":     health,
+		},
+	}, nil
+}
+
+// requestSync implements the request_sync MCP tool
+func (m *MCPServer) requestSync(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, user, err := m.resolveWritableProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
+	dataSourceID := strings.TrimSpace(getStringParam(arguments, "data_source_id", ""))
+	target := "project " + projectID
+	key := "project:" + projectID
+	keys := []string{key}
+	if dataSourceID != "" {
+		if m.repository == nil {
+			return ingestionUnavailable(), nil
+		}
+		dataSource, err := m.repository.GetProjectDataSource(ctx, dataSourceID)
+		if err != nil || dataSource.ProjectID != projectID {
+			return &MCPToolResult{
+				Content: []MCPContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error: data source %s not found in project %s", dataSourceID, projectID),
+				}},
+				IsError: true,
+			}, nil
+		}
+		target = fmt.Sprintf("data source %s", dataSource.SourceName)
+		key = "data_source:" + dataSourceID
+		keys = append(keys, key)
+	}
+
+	reservedAt, wait := m.syncLimiter.reserve(key, keys)
+	if wait > 0 {
+		retryAt := reservedAt.Add(wait)
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error: a sync of %s was requested recently; try again after %s", target, retryAt.Format(time.RFC3339)),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	if dataSourceID != "" {
+		err = m.ingestion.StartDataSourceIngestion(ctx, dataSourceID)
+	} else {
+		err = m.ingestion.StartProjectIngestion(ctx, projectID)
+	}
+
+	// A sync that is already under way satisfies the request
+	status := "started"
+	if err != nil {
+		if !errors.Is(err, ErrIngestionAlreadyRunning) {
+			m.syncLimiter.release(key, reservedAt)
+			return &MCPToolResult{
+				Content: []MCPContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error starting sync: %v", err),
+				}},
+				IsError: true,
+			}, nil
+		}
+		status = "already_running"
+	}
+
+	m.loggerFor(ctx).Info("Sync requested over MCP", map[string]interface{}{
+		"project_id":     projectID,
+		"data_source_id": dataSourceID,
+		"user_id":        user.ID,
+		"status":         status,
+	})
+
+	text := fmt.Sprintf("Started a sync of %s. New context appears as it is processed; check get_ingestion_status for progress.\n", target)
+	if status == "already_running" {
+		text = fmt.Sprintf("A sync of %s is already running. Check get_ingestion_status for progress.\n", target)
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: text,
+		}},
+		StructuredContent: map[string]interface{}{
+			"project_id":     projectID,
+			"data_source_id": dataSourceID,
+			"status":         status,
+		},
+	}, nil
+}
+
+// formatSyncTime renders a sync time with how long ago it was
+func formatSyncTime(at *time.Time, now time.Time) string {
+	if at == nil {
+		return "never"
+	}
+
+	age := now.Sub(*at)
+	var ago string
+	switch {
+	case age < time.Minute:
+		ago = "just now"
+	case age < time.Hour:
+		ago = fmt.Sprintf("%d minutes ago", int(age/time.Minute))
+	case age < 48*time.Hour:
+		ago = fmt.Sprintf("%d hours ago", int(age/time.Hour))
+	default:
+		ago = fmt.Sprintf("%d days ago", int(age/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%s (%s)", at.Format(time.RFC3339), ago)
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ingestionOrchestratorStub records the syncs it is asked to start
+type ingestionOrchestratorStub struct {
+	IngestionOrchestrator
+	health      *IngestionHealthStatus
+	startErr    error
+	projects    []string
+	dataSources []string
+}
+
+func (s *ingestionOrchestratorStub) GetIngestionHealth(ctx context.Context, projectID string) (*IngestionHealthStatus, error) {
+	if s.health == nil {
+		return &IngestionHealthStatus{ProjectID: projectID, OverallStatus: "healthy"}, nil
+	}
+	return s.health, nil
+}
+
+func (s *ingestionOrchestratorStub) StartProjectIngestion(ctx context.Context, projectID string) error {
+	s.projects = append(s.projects, projectID)
+	return s.startErr
+}
+
+func (s *ingestionOrchestratorStub) StartDataSourceIngestion(ctx context.Context, dataSourceID string) error {
+	s.dataSources = append(s.dataSources, dataSourceID)
+	return s.startErr
+}
+
+// dataSourceRepositoryStub knows data sources ds1 in p1 and ds2 in p2. It
+// lists the integrations it is given but cannot load any of them.
+type dataSourceRepositoryStub struct {
+	RepositoryStore
+	integrations []models.ProjectIntegration
+}
+
+func (r *dataSourceRepositoryStub) GetProjectIntegrations(ctx context.Context, projectID string) ([]models.ProjectIntegration, error) {
+	return r.integrations, nil
+}
+
+func (r *dataSourceRepositoryStub) GetProjectIntegration(ctx context.Context, integrationID string) (*models.ProjectIntegration, error) {
+	return nil, errors.New("not found")
+}
+
+func (r *dataSourceRepositoryStub) GetProjectDataSource(ctx context.Context, dataSourceID string) (*models.ProjectDataSource, error) {
+	switch dataSourceID {
+	case "ds1":
+		return &models.ProjectDataSource{ID: "ds1", ProjectID: "p1", SourceName: "org/api"}, nil
+	case "ds2":
+		return &models.ProjectDataSource{ID: "ds2", ProjectID: "p2", SourceName: "org/web"}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func newIngestionTestServer(orchestrator IngestionOrchestrator) *MCPServer {
+	perms := &readPermissionStub{
+		readable: map[string]bool{"p1": true, "p2": true},
+		writable: map[string]bool{"p1": true},
+	}
+	server, _ := newMCPTestServer(&scopedKnowledgeGraphStub{}, &dataSourceRepositoryStub{}, perms)
+	server.usage = nil
+	server.SetIngestionOrchestrator(orchestrator)
+	return server
+}
+
+func TestGetIngestionStatus_ReportsFreshness(t *testing.T) {
+	lastSync := time.Now().Add(-3 * time.Hour)
+	syncStatus := "completed"
+	errorMessage := "token expired"
+	orchestrator := &ingestionOrchestratorStub{health: &IngestionHealthStatus{
+		ProjectID:           "p1",
+		OverallStatus:       "degraded",
+		ActiveIntegrations:  2,
+		HealthyIntegrations: 1,
+		FailedIntegrations:  1,
+		LastSyncAt:          &lastSync,
+		Integrations: []IntegrationHealthStatus{
+			{IntegrationID: "i1", Platform: "github", Status: "healthy", LastSyncAt: &lastSync, LastSyncStatus: &syncStatus, DataSourceCount: 2, ActiveDataSources: 2},
+			{IntegrationID: "i2", Platform: "slack", Status: "failed", ErrorMessage: &errorMessage, DataSourceCount: 1},
+		},
+	}}
+	server := newIngestionTestServer(orchestrator)
+	ctx := WithMCPProject(WithMCPUser(context.Background(), &models.User{ID: "u1"}), "p1")
+
+	result, err := server.callTool(ctx, "get_ingestion_status", map[string]interface{}{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	text := result.Content[0].Text
+	assert.Contains(t, text, "**Overall:** degraded")
+	assert.Contains(t, text, "3 hours ago) (completed)")
+	assert.Contains(t, text, "## slack")
+	assert.Contains(t, text, "**Last sync:** never")
+	assert.Contains(t, text, "**Error:** token expired")
+
+	structured := structuredJSON(t, result)
+	assert.Equal(t, "p1", structured["project_id"])
+	assert.Len(t, structured["health"].(map[string]interface{})["integrations"], 2)
+}
+
+func TestRequestSync_RequiresWriteAccess(t *testing.T) {
+	orchestrator := &ingestionOrchestratorStub{}
+	server := newIngestionTestServer(orchestrator)
+	ctx := WithMCPUser(context.Background(), &models.User{ID: "u1"})
+
+	// Readers can see status but not start syncs
+	result, err := server.callTool(ctx, "get_ingestion_status", map[string]interface{}{"project_id": "p2"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+
+	result, err = server.callTool(ctx, "request_sync", map[string]interface{}{"project_id": "p2"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "write access")
+
+	// Data sources of other projects are not found
+	result, err = server.callTool(ctx, "request_sync", map[string]interface{}{"project_id": "p1", "data_source_id": "ds2"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "not found in project p1")
+
+	assert.Empty(t, orchestrator.projects)
+	assert.Empty(t, orchestrator.dataSources)
+}
+
+func TestRequestSync_IsRateLimited(t *testing.T) {
+	orchestrator := &ingestionOrchestratorStub{}
+	server := newIngestionTestServer(orchestrator)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	server.syncLimiter.now = func() time.Time { return now }
+	ctx := WithMCPProject(WithMCPUser(context.Background(), &models.User{ID: "u1"}), "p1")
+
+	result, err := server.callTool(ctx, "request_sync", map[string]interface{}{"data_source_id": "ds1"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "Started a sync of data source org/api")
+	assert.Equal(t, []string{"ds1"}, orchestrator.dataSources)
+
+	// The data source was just synced, but the rest of the project can be
+	result, err = server.callTool(ctx, "request_sync", map[string]interface{}{"data_source_id": "ds1"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "try again after 2026-03-02T12:05:00Z")
+
+	now = now.Add(time.Minute)
+	result, err = server.callTool(ctx, "request_sync", map[string]interface{}{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, []string{"p1"}, orchestrator.projects)
+
+	// A project sync covers its data sources
+	now = now.Add(defaultMCPSyncInterval - time.Minute)
+	result, err = server.callTool(ctx, "request_sync", map[string]interface{}{"data_source_id": "ds1"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "try again after 2026-03-02T12:06:00Z")
+
+	now = now.Add(time.Minute)
+	result, err = server.callTool(ctx, "request_sync", map[string]interface{}{"data_source_id": "ds1"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Len(t, orchestrator.dataSources, 2)
+}
+
+func TestRequestSync_AlreadyRunning(t *testing.T) {
+	orchestrator := &ingestionOrchestratorStub{startErr: fmt.Errorf("%w for every integration of project p1", ErrIngestionAlreadyRunning)}
+	server := newIngestionTestServer(orchestrator)
+	ctx := WithMCPProject(WithMCPUser(context.Background(), &models.User{ID: "u1"}), "p1")
+
+	result, err := server.callTool(ctx, "request_sync", map[string]interface{}{})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "already running")
+	assert.Equal(t, "already_running", structuredJSON(t, result)["status"])
+
+	// Other failures are reported, even alongside a running sync, and do not use up the interval
+	for _, startErr := range []error{
+		errors.New("integration is not active (status: error)"),
+		errors.New("failed to start some integrations: [failed to start integration i2: bad credentials]"),
+	} {
+		orchestrator.startErr = startErr
+		server = newIngestionTestServer(orchestrator)
+		result, err = server.callTool(ctx, "request_sync", map[string]interface{}{})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].Text, "Error starting sync")
+	}
+
+	orchestrator.startErr = nil
+	result, err = server.callTool(ctx, "request_sync", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
+
+func TestStartProjectIngestion_AlreadyRunningOnlyWhenEveryIntegrationIs(t *testing.T) {
+	store := &dataSourceRepositoryStub{integrations: []models.ProjectIntegration{
+		{ID: "i1", ProjectID: "p1", Platform: "github", Status: string(models.IntegrationStatusActive)},
+	}}
+	orchestrator := NewIngestionOrchestrator(store, nil, nil, nil, &SimpleLogger{}).(*IngestionOrchestratorImpl)
+	orchestrator.activeIngestions["i1"] = &IngestionTask{IntegrationID: "i1", Status: TaskStatusRunning}
+
+	err := orchestrator.StartProjectIngestion(context.Background(), "p1")
+	assert.ErrorIs(t, err, ErrIngestionAlreadyRunning)
+
+	// An integration that fails to start is reported rather than hidden behind the running one
+	store.integrations = append(store.integrations, models.ProjectIntegration{ID: "i2", ProjectID: "p1", Platform: "gitlab", Status: string(models.IntegrationStatusActive)})
+	err = orchestrator.StartProjectIngestion(context.Background(), "p1")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrIngestionAlreadyRunning)
+	assert.Contains(t, err.Error(), "i2")
+}
+
+func TestIngestionTools_RequireOrchestrator(t *testing.T) {
+	server := newIngestionTestServer(nil)
+	ctx := WithMCPProject(WithMCPUser(context.Background(), &models.User{ID: "u1"}), "p1")
+
+	resp := server.handleRequest(ctx, &JSONRPCRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+	require.Nil(t, resp.Error)
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]MCPTool) {
+		assert.False(t, isIngestionTool(tool.Name), "%s is listed without an orchestrator", tool.Name)
+	}
+
+	for _, name := range []string{"get_ingestion_status", "request_sync"} {
+		_, err := server.callTool(ctx, name, map[string]interface{}{})
+		assert.ErrorContains(t, err, "unknown tool", name)
+	}
+}
+
+func TestMCPSyncLimiter_ReservesOnce(t *testing.T) {
+	limiter := newMCPSyncLimiter(defaultMCPSyncInterval)
+
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, wait := limiter.reserve("project:p1", []string{"project:p1"}); wait == 0 {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), allowed.Load(), "concurrent requests must not both pass")
+
+	// A sync that did not start frees its reservation
+	reservedAt, wait := limiter.reserve("data_source:ds1", []string{"project:p2", "data_source:ds1"})
+	require.Zero(t, wait)
+	limiter.release("data_source:ds1", reservedAt)
+	_, wait = limiter.reserve("data_source:ds1", []string{"project:p2", "data_source:ds1"})
+	assert.Zero(t, wait)
+}
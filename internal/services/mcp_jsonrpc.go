@@ -0,0 +1,230 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// errMCPRequestCancelled is the cancellation cause for requests aborted by notifications/cancelled
+var errMCPRequestCancelled = errors.New("request cancelled by client")
+
+// rawJSONRPCMessage keeps the raw id so requests can be told apart from notifications,
+// which have no id member at all
+type rawJSONRPCMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// HandleMessage processes a raw JSON-RPC message independent of the transport.
+// Batches are processed concurrently. A nil response means the message only
+// contained notifications or client responses and nothing should be sent back.
+func (m *MCPServer) HandleMessage(ctx context.Context, message []byte) ([]byte, error) {
+	message = bytes.TrimSpace(message)
+
+	if len(message) > 0 && message[0] == '[' {
+		var batch []json.RawMessage
+		if err := json.Unmarshal(message, &batch); err != nil {
+			return json.Marshal(newJSONRPCErrorResponse(ParseError, "Parse error", nil))
+		}
+		if len(batch) == 0 {
+			return json.Marshal(newJSONRPCErrorResponse(InvalidRequest, "Invalid Request", nil))
+		}
+
+		responses := m.handleBatch(ctx, batch)
+		if len(responses) == 0 {
+			return nil, nil
+		}
+		return json.Marshal(responses)
+	}
+
+	response := m.handleRawMessage(ctx, message)
+	if response == nil {
+		return nil, nil
+	}
+	return json.Marshal(response)
+}
+
+// handleBatch processes every message of a batch concurrently and returns the
+// responses in request order, omitting notifications
+func (m *MCPServer) handleBatch(ctx context.Context, batch []json.RawMessage) []*JSONRPCResponse {
+	results := make([]*JSONRPCResponse, len(batch))
+
+	var wg sync.WaitGroup
+	for i, message := range batch {
+		wg.Add(1)
+		go func(i int, message json.RawMessage) {
+			defer wg.Done()
+			results[i] = m.handleRawMessage(ctx, message)
+		}(i, message)
+	}
+	wg.Wait()
+
+	responses := make([]*JSONRPCResponse, 0, len(results))
+	for _, response := range results {
+		if response != nil {
+			responses = append(responses, response)
+		}
+	}
+	return responses
+}
+
+// handleRawMessage processes a single JSON-RPC message
+func (m *MCPServer) handleRawMessage(ctx context.Context, message []byte) *JSONRPCResponse {
+	var raw rawJSONRPCMessage
+	if err := json.Unmarshal(message, &raw); err != nil {
+		return newJSONRPCErrorResponse(ParseError, "Parse error", nil)
+	}
+
+	var id interface{}
+	if len(raw.ID) > 0 {
+		if err := json.Unmarshal(raw.ID, &id); err != nil {
+			return newJSONRPCErrorResponse(InvalidRequest, "Invalid Request", nil)
+		}
+	}
+
+	// Validate JSON-RPC version
+	if raw.JSONRPC != "2.0" {
+		return newJSONRPCErrorResponse(InvalidRequest, "Invalid Request", id)
+	}
+
+	// Responses to server-initiated requests carry no method
+	if raw.Method == "" {
+		if len(raw.ID) > 0 && (len(raw.Result) > 0 || len(raw.Error) > 0) {
+			m.handleClientResponse(ctx, id, raw.Result, raw.Error)
+			return nil
+		}
+		return newJSONRPCErrorResponse(InvalidRequest, "Invalid Request", id)
+	}
+
+	req := &JSONRPCRequest{
+		JSONRPC: raw.JSONRPC,
+		Method:  raw.Method,
+		ID:      id,
+	}
+	if len(raw.Params) > 0 && !bytes.Equal(raw.Params, []byte("null")) {
+		if err := json.Unmarshal(raw.Params, &req.Params); err != nil {
+			if len(raw.ID) == 0 {
+				return nil
+			}
+			return newJSONRPCErrorResponse(InvalidParams, "Invalid params", id)
+		}
+	}
+
+	// Notifications never get a response, not even an error
+	if len(raw.ID) == 0 {
+		m.handleNotification(ctx, req)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	key := inflightRequestKey(ctx, id)
+	m.inflight.add(key, cancel)
+	defer m.inflight.remove(key)
+
+	response := m.handleRequest(m.withSessionLogging(ctx), req)
+
+	// The client has already given up on a cancelled request
+	if errors.Is(context.Cause(ctx), errMCPRequestCancelled) {
+		return nil
+	}
+	return response
+}
+
+// handleNotification processes a client notification
+func (m *MCPServer) handleNotification(ctx context.Context, req *JSONRPCRequest) {
+	switch req.Method {
+	case "notifications/initialized":
+		m.logger.Debug("MCP client initialized", nil)
+		m.startWorkspaceRootsRefresh(ctx)
+	case "notifications/roots/list_changed":
+		m.startWorkspaceRootsRefresh(ctx)
+	case "notifications/cancelled":
+		requestID, ok := req.Params["requestId"]
+		if !ok {
+			return
+		}
+		if m.inflight.cancel(inflightRequestKey(ctx, requestID)) {
+			m.logger.Info("Cancelled MCP request", map[string]interface{}{
+				"request_id": requestID,
+				"reason":     getStringParam(req.Params, "reason", ""),
+			})
+		}
+	default:
+		m.logger.Debug("Ignoring MCP notification", map[string]interface{}{
+			"method": req.Method,
+		})
+	}
+}
+
+// handleClientResponse processes a client's response to a server-initiated request
+func (m *MCPServer) handleClientResponse(ctx context.Context, id interface{}, result, rpcError json.RawMessage) {
+	if session, ok := MCPSessionFromContext(ctx); ok {
+		if requestID, ok := id.(string); ok && session.deliverResponse(requestID, result, rpcError) {
+			return
+		}
+	}
+
+	m.logger.Debug("Ignoring unsolicited MCP client response", map[string]interface{}{
+		"id": id,
+	})
+}
+
+// mcpInflightRequests tracks running requests so they can be cancelled by the client
+type mcpInflightRequests struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelCauseFunc
+}
+
+func newMCPInflightRequests() *mcpInflightRequests {
+	return &mcpInflightRequests{
+		cancels: make(map[string]context.CancelCauseFunc),
+	}
+}
+
+func (r *mcpInflightRequests) add(key string, cancel context.CancelCauseFunc) {
+	r.mu.Lock()
+	r.cancels[key] = cancel
+	r.mu.Unlock()
+}
+
+func (r *mcpInflightRequests) remove(key string) {
+	r.mu.Lock()
+	delete(r.cancels, key)
+	r.mu.Unlock()
+}
+
+// cancel aborts a running request and reports whether it was found
+func (r *mcpInflightRequests) cancel(key string) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancels[key]
+	delete(r.cancels, key)
+	r.mu.Unlock()
+
+	if ok {
+		cancel(errMCPRequestCancelled)
+	}
+	return ok
+}
+
+// inflightRequestKey scopes a request ID to its session, or to its user for
+// sessionless clients, so one client cannot cancel another's requests
+func inflightRequestKey(ctx context.Context, id interface{}) string {
+	scope := ""
+	if session, ok := MCPSessionFromContext(ctx); ok {
+		scope = "session:" + session.ID
+	} else if user, ok := MCPUserFromContext(ctx); ok {
+		scope = "user:" + user.ID
+	}
+
+	normalizedID, _ := json.Marshal(id)
+	return scope + "|" + string(normalizedID)
+}
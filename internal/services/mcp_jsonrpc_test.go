@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingKnowledgeGraphStub blocks searches until their context is cancelled
+type blockingKnowledgeGraphStub struct {
+	KnowledgeGraphService
+	started chan struct{}
+	aborted chan error
+}
+
+func (s *blockingKnowledgeGraphStub) SearchKnowledgeByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	close(s.started)
+	<-ctx.Done()
+	s.aborted <- context.Cause(ctx)
+	return nil, ctx.Err()
+}
+
+func TestMCPJSONRPC_BatchPreservesOrderAndSkipsNotifications(t *testing.T) {
+	server, ctx := newMCPTestServer(&scopedKnowledgeGraphStub{}, nil, nil)
+
+	batch := `[
+		{"jsonrpc":"2.0","id":"a","method":"tools/list"},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":2,"method":"unknown/method"},
+		{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_decision_history","arguments":{"target":"auth"}}}
+	]`
+
+	raw, err := server.HandleMessage(ctx, []byte(batch))
+	require.NoError(t, err)
+
+	var responses []JSONRPCResponse
+	require.NoError(t, json.Unmarshal(raw, &responses))
+	require.Len(t, responses, 3)
+
+	assert.Equal(t, "a", responses[0].ID)
+	assert.Nil(t, responses[0].Error)
+	assert.Equal(t, float64(2), responses[1].ID)
+	require.NotNil(t, responses[1].Error)
+	assert.Equal(t, MethodNotFound, responses[1].Error.Code)
+	assert.Equal(t, float64(3), responses[2].ID)
+	assert.Nil(t, responses[2].Error)
+}
+
+func TestMCPJSONRPC_NotificationsAndEmptyBatch(t *testing.T) {
+	server, ctx := newMCPTestServer(&scopedKnowledgeGraphStub{}, nil, nil)
+
+	for _, message := range []string{
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":99}}`,
+		`{"jsonrpc":"2.0","method":"tools/list"}`,
+		`[{"jsonrpc":"2.0","method":"notifications/initialized"}]`,
+		`{"jsonrpc":"2.0","id":7,"result":{}}`,
+	} {
+		raw, err := server.HandleMessage(ctx, []byte(message))
+		require.NoError(t, err, message)
+		assert.Nil(t, raw, message)
+	}
+
+	raw, err := server.HandleMessage(ctx, []byte(`[]`))
+	require.NoError(t, err)
+	var response JSONRPCResponse
+	require.NoError(t, json.Unmarshal(raw, &response))
+	require.NotNil(t, response.Error)
+	assert.Equal(t, InvalidRequest, response.Error.Code)
+}
+
+func TestMCPJSONRPC_CancelledNotificationAbortsRunningTool(t *testing.T) {
+	kg := &blockingKnowledgeGraphStub{started: make(chan struct{}), aborted: make(chan error, 1)}
+	server, ctx := newMCPTestServer(kg, nil, nil)
+
+	type result struct {
+		raw []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		raw, err := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":"search-1","method":"tools/call","params":{"name":"search_project_knowledge","arguments":{"query":"auth"}}}`))
+		done <- result{raw, err}
+	}()
+
+	select {
+	case <-kg.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool call never started")
+	}
+
+	// A different user cannot cancel the request
+	otherCtx := WithMCPUser(context.Background(), &models.User{ID: "u2"})
+	_, err := server.HandleMessage(otherCtx, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"search-1"}}`))
+	require.NoError(t, err)
+	select {
+	case <-kg.aborted:
+		t.Fatal("request was cancelled by another user")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"search-1","reason":"user aborted"}}`))
+	require.NoError(t, err)
+
+	select {
+	case cause := <-kg.aborted:
+		assert.ErrorIs(t, cause, errMCPRequestCancelled)
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool context was not cancelled")
+	}
+
+	res := <-done
+	require.NoError(t, res.err)
+	assert.Nil(t, res.raw, "cancelled requests get no response")
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// mcpLogLevels orders the syslog severities MCP uses for logging/setLevel
+var mcpLogLevels = map[string]int{
+	"debug":     0,
+	"info":      1,
+	"notice":    2,
+	"warning":   3,
+	"error":     4,
+	"critical":  5,
+	"alert":     6,
+	"emergency": 7,
+}
+
+// mcpLoggerName identifies this server in notifications/message
+const mcpLoggerName = "context-keeper"
+
+type requestLoggerContextKey struct{}
+
+type mcpProgressContextKey struct{}
+
+// WithRequestLogger returns a context whose request-scoped log messages go to logger
+func WithRequestLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, requestLoggerContextKey{}, logger)
+}
+
+// LoggerFromContext returns the request-scoped logger, or fallback when there is none
+func LoggerFromContext(ctx context.Context, fallback Logger) Logger {
+	if logger, ok := ctx.Value(requestLoggerContextKey{}).(Logger); ok && logger != nil {
+		return logger
+	}
+	return fallback
+}
+
+// loggerFor returns the logger for the request in ctx
+func (m *MCPServer) loggerFor(ctx context.Context) Logger {
+	return LoggerFromContext(ctx, m.logger)
+}
+
+// withSessionLogging forwards the request's log messages to the client once it
+// has chosen a level with logging/setLevel
+func (m *MCPServer) withSessionLogging(ctx context.Context) context.Context {
+	session, ok := MCPSessionFromContext(ctx)
+	if !ok || session.LogLevel() == "" {
+		return ctx
+	}
+	return WithRequestLogger(ctx, &mcpSessionLogger{next: m.logger, session: session})
+}
+
+// mcpSessionLogger logs locally and mirrors messages to the client as notifications/message
+type mcpSessionLogger struct {
+	next    Logger
+	session *MCPSession
+}
+
+func (l *mcpSessionLogger) Info(msg string, fields map[string]interface{}) {
+	l.next.Info(msg, fields)
+	l.forward("info", msg, nil, fields)
+}
+
+func (l *mcpSessionLogger) Error(msg string, err error, fields map[string]interface{}) {
+	l.next.Error(msg, err, fields)
+	l.forward("error", msg, err, fields)
+}
+
+func (l *mcpSessionLogger) Debug(msg string, fields map[string]interface{}) {
+	l.next.Debug(msg, fields)
+	l.forward("debug", msg, nil, fields)
+}
+
+func (l *mcpSessionLogger) forward(level, msg string, err error, fields map[string]interface{}) {
+	minLevel, ok := mcpLogLevels[l.session.LogLevel()]
+	if !ok || mcpLogLevels[level] < minLevel {
+		return
+	}
+
+	data := map[string]interface{}{"message": msg}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+	for key, value := range fields {
+		if _, reserved := data[key]; !reserved {
+			data[key] = value
+		}
+	}
+
+	// Delivery is best effort; a closed session has no one to tell
+	_ = l.session.Notify("notifications/message", map[string]interface{}{
+		"level":  level,
+		"logger": mcpLoggerName,
+		"data":   data,
+	})
+}
+
+// handleLoggingSetLevel sets the minimum level of log messages sent to the client
+func (m *MCPServer) handleLoggingSetLevel(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	level := getStringParam(req.Params, "level", "")
+	if _, ok := mcpLogLevels[level]; !ok {
+		return newJSONRPCErrorResponse(InvalidParams, fmt.Sprintf("Invalid log level: %q", level), req.ID)
+	}
+
+	session, ok := MCPSessionFromContext(ctx)
+	if !ok {
+		return newJSONRPCErrorResponse(InvalidRequest, "Logging requires an MCP session", req.ID)
+	}
+	session.SetLogLevel(level)
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  map[string]interface{}{},
+		ID:      req.ID,
+	}
+}
+
+// mcpProgress reports progress for a request that carried _meta.progressToken
+type mcpProgress struct {
+	mu      sync.Mutex
+	session *MCPSession
+	token   interface{}
+	last    float64
+}
+
+// withProgress attaches a progress reporter to ctx when the request asked for progress
+func withProgress(ctx context.Context, params map[string]interface{}) context.Context {
+	meta, ok := params["_meta"].(map[string]interface{})
+	if !ok {
+		return ctx
+	}
+	token, ok := meta["progressToken"]
+	if !ok || token == nil {
+		return ctx
+	}
+	session, ok := MCPSessionFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, mcpProgressContextKey{}, &mcpProgress{session: session, token: token, last: -1})
+}
+
+// reportProgress sends notifications/progress for the request in ctx. Progress
+// must increase with every call; stale updates are dropped.
+func reportProgress(ctx context.Context, progress, total float64, message string) {
+	reporter, ok := ctx.Value(mcpProgressContextKey{}).(*mcpProgress)
+	if !ok {
+		return
+	}
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+
+	if progress <= reporter.last {
+		return
+	}
+	reporter.last = progress
+
+	params := map[string]interface{}{
+		"progressToken": reporter.token,
+		"progress":      progress,
+	}
+	if total > 0 {
+		params["total"] = total
+	}
+	if message != "" {
+		params["message"] = message
+	}
+
+	_ = reporter.session.Notify("notifications/progress", params)
+}
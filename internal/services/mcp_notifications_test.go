@@ -0,0 +1,96 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sessionNotification struct {
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// sessionNotifications returns every notification sent on the session so far
+func sessionNotifications(t *testing.T, session *MCPSession, method string) []sessionNotification {
+	replay, _, unsubscribe := session.Subscribe("0")
+	defer unsubscribe()
+
+	var notifications []sessionNotification
+	for _, event := range replay {
+		var notification sessionNotification
+		require.NoError(t, json.Unmarshal(event.Data, &notification))
+		if notification.Method == method {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications
+}
+
+func TestMCPNotifications_ProgressForToolCall(t *testing.T) {
+	server, ctx := newMCPTestServer(&scopedKnowledgeGraphStub{}, nil, nil)
+	session := NewMCPSession("s1")
+	ctx = WithMCPSession(ctx, session)
+
+	raw, err := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"explain_why_code_exists","arguments":{"file_path":"main.go"},"_meta":{"progressToken":"tok-1"}}}`))
+	require.NoError(t, err)
+	require.NotNil(t, raw)
+
+	progress := sessionNotifications(t, session, "notifications/progress")
+	require.Len(t, progress, 3)
+	for i, notification := range progress {
+		assert.Equal(t, "tok-1", notification.Params["progressToken"])
+		assert.Equal(t, float64(i), notification.Params["progress"])
+		assert.Equal(t, float64(3), notification.Params["total"])
+		assert.NotEmpty(t, notification.Params["message"])
+	}
+
+	// Without a progress token nothing is reported
+	_, err = server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"explain_why_code_exists","arguments":{"file_path":"main.go"}}}`))
+	require.NoError(t, err)
+	assert.Len(t, sessionNotifications(t, session, "notifications/progress"), 3)
+}
+
+func TestMCPNotifications_LoggingFollowsSetLevel(t *testing.T) {
+	server, ctx := newMCPTestServer(&scopedKnowledgeGraphStub{}, nil, nil)
+	session := NewMCPSession("s1")
+	ctx = WithMCPSession(ctx, session)
+	call := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_decision_history","arguments":{"target":"auth"}}}`
+
+	_, err := server.HandleMessage(ctx, []byte(call))
+	require.NoError(t, err)
+	assert.Empty(t, sessionNotifications(t, session, "notifications/message"), "logging is off until the client sets a level")
+
+	raw, err := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"logging/setLevel","params":{"level":"loud"}}`))
+	require.NoError(t, err)
+	var resp JSONRPCResponse
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, InvalidParams, resp.Error.Code)
+
+	raw, err = server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":3,"method":"logging/setLevel","params":{"level":"info"}}`))
+	require.NoError(t, err)
+	resp = JSONRPCResponse{}
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	require.Nil(t, resp.Error)
+
+	_, err = server.HandleMessage(ctx, []byte(call))
+	require.NoError(t, err)
+
+	messages := sessionNotifications(t, session, "notifications/message")
+	require.NotEmpty(t, messages)
+	assert.Equal(t, "info", messages[0].Params["level"])
+	assert.Equal(t, mcpLoggerName, messages[0].Params["logger"])
+	data := messages[0].Params["data"].(map[string]interface{})
+	assert.Equal(t, "Executing MCP tool", data["message"])
+	assert.Equal(t, "get_decision_history", data["tool"])
+
+	// Messages below the chosen level are not forwarded
+	session.SetLogLevel("error")
+	before := len(sessionNotifications(t, session, "notifications/message"))
+	_, err = server.HandleMessage(ctx, []byte(call))
+	require.NoError(t, err)
+	assert.Len(t, sessionNotifications(t, session, "notifications/message"), before)
+}
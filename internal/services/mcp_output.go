@@ -0,0 +1,277 @@
+package services
+
+import (
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// Output schemas describe the structuredContent each tool returns next to its
+// markdown. Property names follow the JSON tags of the underlying models.
+
+func nullableSchema(schemaType string) map[string]interface{} {
+	return map[string]interface{}{"type": []string{schemaType, "null"}}
+}
+
+func stringListSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":  []string{"array", "null"},
+		"items": map[string]interface{}{"type": "string"},
+	}
+}
+
+func arraySchema(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":  "array",
+		"items": items,
+	}
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func knowledgeEntitySchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"id":               map[string]interface{}{"type": "string"},
+		"entity_type":      map[string]interface{}{"type": "string"},
+		"entity_id":        map[string]interface{}{"type": "string"},
+		"title":            map[string]interface{}{"type": "string"},
+		"content":          map[string]interface{}{"type": "string"},
+		"metadata":         nullableSchema("object"),
+		"platform_source":  nullableSchema("string"),
+		"source_event_ids": stringListSchema(),
+		"participants":     stringListSchema(),
+		"created_at":       map[string]interface{}{"type": "string", "format": "date-time"},
+		"updated_at":       map[string]interface{}{"type": "string", "format": "date-time"},
+	}, "id", "entity_type", "entity_id", "title")
+}
+
+func searchResultSchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"entity":     knowledgeEntitySchema(),
+		"similarity": map[string]interface{}{"type": "number"},
+		"rank":       map[string]interface{}{"type": "integer"},
+	}, "entity", "similarity")
+}
+
+func decisionRecordSchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"id":               map[string]interface{}{"type": "string"},
+		"entity_id":        map[string]interface{}{"type": "string"},
+		"decision_id":      map[string]interface{}{"type": "string"},
+		"title":            map[string]interface{}{"type": "string"},
+		"decision":         map[string]interface{}{"type": "string"},
+		"rationale":        nullableSchema("string"),
+		"alternatives":     stringListSchema(),
+		"consequences":     stringListSchema(),
+		"status":           map[string]interface{}{"type": "string"},
+		"supersedes":       stringListSchema(),
+		"authoritative":    map[string]interface{}{"type": "boolean"},
+		"platform_source":  map[string]interface{}{"type": "string"},
+		"source_event_ids": stringListSchema(),
+		"participants":     stringListSchema(),
+		"created_at":       map[string]interface{}{"type": "string", "format": "date-time"},
+		"updated_at":       map[string]interface{}{"type": "string", "format": "date-time"},
+	}, "id", "decision_id", "title", "decision", "status")
+}
+
+func fileContextHistorySchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"id":                 map[string]interface{}{"type": "string"},
+		"entity_id":          map[string]interface{}{"type": "string"},
+		"context_id":         map[string]interface{}{"type": "string"},
+		"file_path":          map[string]interface{}{"type": "string"},
+		"change_reason":      nullableSchema("string"),
+		"discussion_context": map[string]interface{}{"type": "string"},
+		"related_decisions":  stringListSchema(),
+		"contributors":       stringListSchema(),
+		"platform_sources":   nullableSchema("object"),
+		"created_at":         map[string]interface{}{"type": "string", "format": "date-time"},
+	}, "id", "file_path", "discussion_context")
+}
+
+func discussionSummarySchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"id":                 map[string]interface{}{"type": "string"},
+		"entity_id":          map[string]interface{}{"type": "string"},
+		"summary_id":         map[string]interface{}{"type": "string"},
+		"thread_id":          nullableSchema("string"),
+		"platform":           map[string]interface{}{"type": "string"},
+		"participants":       stringListSchema(),
+		"summary":            map[string]interface{}{"type": "string"},
+		"key_points":         stringListSchema(),
+		"action_items":       stringListSchema(),
+		"file_references":    stringListSchema(),
+		"feature_references": stringListSchema(),
+		"created_at":         map[string]interface{}{"type": "string", "format": "date-time"},
+	}, "id", "summary_id", "platform", "summary")
+}
+
+func knowledgeRelationshipSchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"id":                map[string]interface{}{"type": "string"},
+		"source_entity_id":  map[string]interface{}{"type": "string"},
+		"target_entity_id":  map[string]interface{}{"type": "string"},
+		"relationship_type": map[string]interface{}{"type": "string"},
+		"strength":          map[string]interface{}{"type": "number"},
+		"metadata":          nullableSchema("object"),
+		"created_at":        map[string]interface{}{"type": "string", "format": "date-time"},
+	}, "source_entity_id", "target_entity_id", "relationship_type", "strength")
+}
+
+// packingSchema describes the token budget report of a tool called with max_tokens
+func packingSchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"max_tokens":  map[string]interface{}{"type": "integer"},
+		"used_tokens": map[string]interface{}{"type": "integer"},
+		"omitted": arraySchema(objectSchema(map[string]interface{}{
+			"id":     map[string]interface{}{"type": "string"},
+			"title":  map[string]interface{}{"type": "string"},
+			"tokens": map[string]interface{}{"type": "integer"},
+			"score":  map[string]interface{}{"type": "number"},
+		}, "id", "title", "tokens", "score")),
+	}, "max_tokens", "used_tokens", "omitted")
+}
+
+func ingestionHealthSchema() map[string]interface{} {
+	return objectSchema(map[string]interface{}{
+		"project_id":           map[string]interface{}{"type": "string"},
+		"overall_status":       map[string]interface{}{"type": "string"},
+		"active_integrations":  map[string]interface{}{"type": "integer"},
+		"healthy_integrations": map[string]interface{}{"type": "integer"},
+		"failed_integrations":  map[string]interface{}{"type": "integer"},
+		"last_sync_at":         nullableSchema("string"),
+		"integrations": arraySchema(objectSchema(map[string]interface{}{
+			"integration_id":      map[string]interface{}{"type": "string"},
+			"platform":            map[string]interface{}{"type": "string"},
+			"status":              map[string]interface{}{"type": "string"},
+			"last_sync_at":        nullableSchema("string"),
+			"last_sync_status":    nullableSchema("string"),
+			"error_message":       nullableSchema("string"),
+			"error_count":         map[string]interface{}{"type": "integer"},
+			"data_source_count":   map[string]interface{}{"type": "integer"},
+			"active_data_sources": map[string]interface{}{"type": "integer"},
+			"sync_checkpoint":     nullableSchema("object"),
+			"next_sync_scheduled": nullableSchema("string"),
+		}, "integration_id", "platform", "status")),
+	}, "project_id", "overall_status", "integrations")
+}
+
+// toolOutputSchemas returns the outputSchema of every tool that returns structuredContent
+func toolOutputSchemas() map[string]map[string]interface{} {
+	schemas := map[string]map[string]interface{}{
+		"search_project_knowledge": objectSchema(map[string]interface{}{
+			"project_id": map[string]interface{}{"type": "string"},
+			"query":      map[string]interface{}{"type": "string"},
+			"results":    arraySchema(searchResultSchema()),
+		}, "project_id", "query", "results"),
+		"get_context_for_file": objectSchema(map[string]interface{}{
+			"project_id":        map[string]interface{}{"type": "string"},
+			"file_path":         map[string]interface{}{"type": "string"},
+			"file_contexts":     arraySchema(fileContextHistorySchema()),
+			"related_entities":  arraySchema(searchResultSchema()),
+			"related_decisions": arraySchema(searchResultSchema()),
+		}, "project_id", "file_path", "file_contexts", "related_entities", "related_decisions"),
+		"get_decision_history": objectSchema(map[string]interface{}{
+			"project_id":      map[string]interface{}{"type": "string"},
+			"target":          map[string]interface{}{"type": "string"},
+			"total_decisions": map[string]interface{}{"type": "integer"},
+			"decisions":       arraySchema(decisionRecordSchema()),
+		}, "project_id", "target", "total_decisions", "decisions"),
+		"list_recent_architecture_discussions": objectSchema(map[string]interface{}{
+			"project_id":  map[string]interface{}{"type": "string"},
+			"days_back":   map[string]interface{}{"type": "integer"},
+			"discussions": arraySchema(discussionSummarySchema()),
+		}, "project_id", "days_back", "discussions"),
+		"explain_why_code_exists": objectSchema(map[string]interface{}{
+			"project_id": map[string]interface{}{"type": "string"},
+			"file_path":  map[string]interface{}{"type": "string"},
+			"line_range": objectSchema(map[string]interface{}{
+				"start": map[string]interface{}{"type": "integer"},
+				"end":   map[string]interface{}{"type": "integer"},
+			}),
+			"file_contexts":    arraySchema(fileContextHistorySchema()),
+			"decisions":        arraySchema(decisionRecordSchema()),
+			"related_entities": arraySchema(searchResultSchema()),
+		}, "project_id", "file_path", "file_contexts", "decisions", "related_entities"),
+		"explore_related_knowledge": objectSchema(map[string]interface{}{
+			"project_id":      map[string]interface{}{"type": "string"},
+			"start_entity_id": map[string]interface{}{"type": "string"},
+			"max_depth":       map[string]interface{}{"type": "integer"},
+			"entities": arraySchema(objectSchema(map[string]interface{}{
+				"depth":  map[string]interface{}{"type": "integer"},
+				"entity": knowledgeEntitySchema(),
+			}, "depth", "entity")),
+			"relationships":  arraySchema(knowledgeRelationshipSchema()),
+			"total_strength": map[string]interface{}{"type": "number"},
+		}, "project_id", "start_entity_id", "max_depth", "entities", "relationships", "total_strength"),
+		"record_decision": objectSchema(map[string]interface{}{
+			"project_id": map[string]interface{}{"type": "string"},
+			"decision":   decisionRecordSchema(),
+		}, "project_id", "decision"),
+		"annotate_file_context": objectSchema(map[string]interface{}{
+			"project_id":   map[string]interface{}{"type": "string"},
+			"file_context": fileContextHistorySchema(),
+		}, "project_id", "file_context"),
+		"link_entities": objectSchema(map[string]interface{}{
+			"project_id":   map[string]interface{}{"type": "string"},
+			"relationship": knowledgeRelationshipSchema(),
+		}, "project_id", "relationship"),
+		"get_ingestion_status": objectSchema(map[string]interface{}{
+			"project_id": map[string]interface{}{"type": "string"},
+			"health":     ingestionHealthSchema(),
+		}, "project_id", "health"),
+		"request_sync": objectSchema(map[string]interface{}{
+			"project_id":     map[string]interface{}{"type": "string"},
+			"data_source_id": map[string]interface{}{"type": "string"},
+			"status":         map[string]interface{}{"type": "string", "enum": []string{"started", "already_running"}},
+		}, "project_id", "status"),
+	}
+
+	// Tools that pack their results report what a max_tokens budget left out
+	for _, name := range packedResultTools {
+		schemas[name]["properties"].(map[string]interface{})["packing"] = packingSchema()
+	}
+
+	return schemas
+}
+
+// structuredSearchResults copies search results for structured output without
+// their embeddings, which are large and of no use to clients
+func structuredSearchResults(results []models.SearchResult) []models.SearchResult {
+	structured := make([]models.SearchResult, len(results))
+	for i, result := range results {
+		structured[i] = result
+		structured[i].Entity.Embedding = nil
+	}
+	return structured
+}
+
+// nonNilDecisions, nonNilFileContexts and nonNilDiscussions keep empty lists
+// from being encoded as null, which the output schemas do not allow
+func nonNilDecisions(decisions []models.DecisionRecord) []models.DecisionRecord {
+	if decisions == nil {
+		return []models.DecisionRecord{}
+	}
+	return decisions
+}
+
+func nonNilFileContexts(contexts []models.FileContextHistory) []models.FileContextHistory {
+	if contexts == nil {
+		return []models.FileContextHistory{}
+	}
+	return contexts
+}
+
+func nonNilDiscussions(discussions []models.DiscussionSummary) []models.DiscussionSummary {
+	if discussions == nil {
+		return []models.DiscussionSummary{}
+	}
+	return discussions
+}
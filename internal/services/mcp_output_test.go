@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type structuredKnowledgeGraphStub struct {
+	scopedKnowledgeGraphStub
+}
+
+func (s *structuredKnowledgeGraphStub) SearchKnowledgeByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	return []models.SearchResult{{
+		Entity: models.KnowledgeEntity{
+			ID:             "e1",
+			EntityType:     "decision",
+			EntityID:       "d1",
+			Title:          "Use JWT",
+			SourceEventIDs: models.StringList{"evt-1", "evt-2"},
+			Embedding:      []float32{0.1, 0.2},
+		},
+		Similarity: 0.9,
+		Rank:       1,
+	}}, nil
+}
+
+func (s *structuredKnowledgeGraphStub) GetDecisionHistoryByProject(ctx context.Context, projectID, target string) (*DecisionHistoryResponse, error) {
+	return &DecisionHistoryResponse{Target: target, Decisions: []models.DecisionRecord{
+		{ID: "r1", DecisionID: "d1", Title: "Use JWT", Decision: "Adopt JWT", Status: "accepted", SourceEventIDs: models.StringList{"evt-1"}},
+		{ID: "r2", DecisionID: "d2", Title: "Rotate keys", Decision: "Rotate monthly", Status: "accepted"},
+	}}, nil
+}
+
+func (s *structuredKnowledgeGraphStub) ResolveKnowledgeEntityByProject(ctx context.Context, projectID, idOrTitle string) (*models.KnowledgeEntity, error) {
+	return &models.KnowledgeEntity{ID: "e1", EntityType: "decision", Title: idOrTitle}, nil
+}
+
+func (s *structuredKnowledgeGraphStub) TraverseRelationshipsByProject(ctx context.Context, projectID, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error) {
+	return &models.GraphTraversalResult{Path: []models.KnowledgeEntity{{ID: startEntityID, EntityType: "decision"}}}, nil
+}
+
+func (s *structuredKnowledgeGraphStub) RecordDecisionByProject(ctx context.Context, projectID, userID string, decision *DecisionRecord) (*models.DecisionRecord, error) {
+	return &models.DecisionRecord{ID: "r3", DecisionID: "d3", Title: decision.Title, Decision: decision.Decision, Status: decision.Status}, nil
+}
+
+func (s *structuredKnowledgeGraphStub) AnnotateFileContextByProject(ctx context.Context, projectID, userID string, fileContext *FileContextHistory) (*models.FileContextHistory, error) {
+	return &models.FileContextHistory{ID: "f1", ContextID: "c1", FilePath: fileContext.FilePath, DiscussionContext: fileContext.DiscussionContext}, nil
+}
+
+func (s *structuredKnowledgeGraphStub) LinkEntitiesByProject(ctx context.Context, projectID, userID, sourceEntityID, targetEntityID, relationshipType string, strength float64) (*models.KnowledgeRelationship, error) {
+	return &models.KnowledgeRelationship{SourceEntityID: sourceEntityID, TargetEntityID: targetEntityID, RelationshipType: relationshipType, Strength: strength}, nil
+}
+
+// structuredJSON round-trips structured content the way a client receives it
+func structuredJSON(t *testing.T, result *MCPToolResult) map[string]interface{} {
+	raw, err := json.Marshal(result)
+	require.NoError(t, err)
+
+	var decoded struct {
+		StructuredContent map[string]interface{} `json:"structuredContent"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	require.NotNil(t, decoded.StructuredContent)
+	return decoded.StructuredContent
+}
+
+func TestMCPOutput_ToolsDeclareOutputSchemas(t *testing.T) {
+	server := NewMCPServer(nil, nil, nil, nil, &SimpleLogger{})
+	server.SetIngestionOrchestrator(&ingestionOrchestratorStub{})
+
+	resp := server.handleRequest(context.Background(), &JSONRPCRequest{JSONRPC: "2.0", Method: "tools/list", ID: 1})
+	require.Nil(t, resp.Error)
+
+	schemas := toolOutputSchemas()
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]MCPTool) {
+		if tool.Name == "continue_result" {
+			assert.Nil(t, tool.OutputSchema)
+			continue
+		}
+		require.NotNil(t, tool.OutputSchema, tool.Name)
+		assert.Equal(t, "object", tool.OutputSchema["type"], tool.Name)
+		delete(schemas, tool.Name)
+	}
+	assert.Empty(t, schemas, "every output schema belongs to a listed tool")
+}
+
+func TestMCPOutput_ToolsReturnStructuredContent(t *testing.T) {
+	perms := &readPermissionStub{readable: map[string]bool{"p1": true}, writable: map[string]bool{"p1": true}}
+	server, ctx := newMCPTestServer(&structuredKnowledgeGraphStub{}, nil, perms)
+	server.SetIngestionOrchestrator(&ingestionOrchestratorStub{})
+
+	result, err := server.callTool(ctx, "search_project_knowledge", map[string]interface{}{"query": "auth"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "Use JWT", "markdown is kept as a fallback")
+
+	structured := structuredJSON(t, result)
+	assert.Equal(t, "p1", structured["project_id"])
+	entity := structured["results"].([]interface{})[0].(map[string]interface{})["entity"].(map[string]interface{})
+	assert.Equal(t, "d1", entity["entity_id"])
+	assert.Equal(t, []interface{}{"evt-1", "evt-2"}, entity["source_event_ids"])
+	assert.NotContains(t, entity, "embedding")
+
+	result, err = server.callTool(ctx, "get_decision_history", map[string]interface{}{"target": "auth", "limit": 1})
+	require.NoError(t, err)
+	structured = structuredJSON(t, result)
+	assert.Equal(t, float64(2), structured["total_decisions"])
+	decisions := structured["decisions"].([]interface{})
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "d1", decisions[0].(map[string]interface{})["decision_id"])
+
+	// Empty lists are encoded as arrays, as the schemas require
+	result, err = server.callTool(ctx, "list_recent_architecture_discussions", map[string]interface{}{})
+	require.NoError(t, err)
+	structured = structuredJSON(t, result)
+	assert.Equal(t, []interface{}{}, structured["discussions"])
+
+	for name, schema := range toolOutputSchemas() {
+		args := map[string]interface{}{
+			"query": "auth", "file_path": "main.go", "target": "auth", "entity": "Use JWT",
+			"title": "Use JWT", "decision": "Adopt JWT", "note": "Token parsing", "source": "e1", "relationship_type": "relates_to",
+		}
+		result, err := server.callTool(ctx, name, args)
+		require.NoError(t, err, name)
+		structured := structuredJSON(t, result)
+		for _, key := range schema["required"].([]string) {
+			assert.Contains(t, structured, key, name)
+		}
+	}
+}
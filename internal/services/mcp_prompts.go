@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// projectPromptsSettingsKey is the ProjectWorkspace.Settings key holding custom MCP prompts
+const projectPromptsSettingsKey = "mcp_prompts"
+
+// MCPPrompt describes a prompt template offered to clients
+type MCPPrompt struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Arguments   []MCPPromptArgument `json:"arguments,omitempty"`
+}
+
+// MCPPromptArgument describes an argument accepted by a prompt
+type MCPPromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// MCPPromptMessage is a single message in a rendered prompt
+type MCPPromptMessage struct {
+	Role    string     `json:"role"`
+	Content MCPContent `json:"content"`
+}
+
+// customPromptDefinition is a project-defined prompt stored in project settings.
+// Template and ContextQuery may reference arguments as {{name}}; Template may
+// also use {{context}} for knowledge retrieved with ContextQuery.
+type customPromptDefinition struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Arguments    []MCPPromptArgument `json:"arguments"`
+	Template     string              `json:"template"`
+	ContextQuery string              `json:"context_query"`
+}
+
+// promptBuilder renders a built-in prompt for a project
+type promptBuilder func(ctx context.Context, projectID string, args map[string]string) (string, error)
+
+// builtinPrompts lists the prompt templates shipped with the server
+var builtinPrompts = []MCPPrompt{
+	{
+		Name:        "resume_feature",
+		Description: "Resume work on a feature with its decisions and discussions so far",
+		Arguments: []MCPPromptArgument{
+			{Name: "feature", Description: "Feature name; defaults to the most recently active feature"},
+		},
+	},
+	{
+		Name:        "review_file_against_decisions",
+		Description: "Review a file against the decisions that shaped it",
+		Arguments: []MCPPromptArgument{
+			{Name: "file_path", Description: "Path to the file relative to repository root", Required: true},
+		},
+	},
+	{
+		Name:        "onboard_to_module",
+		Description: "Get onboarded to a module: what it does, why it is built this way and where to start",
+		Arguments: []MCPPromptArgument{
+			{Name: "module", Description: "Module name or directory path", Required: true},
+		},
+	},
+}
+
+// handlePromptsList returns built-in prompts plus any defined by the connection's project
+func (m *MCPServer) handlePromptsList(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	prompts := append([]MCPPrompt{}, builtinPrompts...)
+
+	if projectID, ok := m.defaultProject(ctx); ok && m.authorizeProject(ctx, projectID) == nil {
+		for _, custom := range m.loadCustomPrompts(ctx, projectID) {
+			prompts = append(prompts, MCPPrompt{
+				Name:        custom.Name,
+				Description: custom.Description,
+				Arguments:   custom.Arguments,
+			})
+		}
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"prompts": prompts,
+		},
+		ID: req.ID,
+	}
+}
+
+// handlePromptsGet renders a prompt with knowledge graph context for the project
+func (m *MCPServer) handlePromptsGet(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	name := getStringParam(req.Params, "name", "")
+	if name == "" {
+		return newJSONRPCErrorResponse(InvalidParams, "Missing prompt name", req.ID)
+	}
+
+	rawArgs, _ := req.Params["arguments"].(map[string]interface{})
+	m.rewriteFilePathArgument(ctx, rawArgs)
+	args := make(map[string]string, len(rawArgs))
+	for key, value := range rawArgs {
+		if str, ok := value.(string); ok {
+			args[key] = str
+		}
+	}
+
+	projectID, err := m.resolveProject(ctx, rawArgs)
+	if err != nil {
+		return newJSONRPCErrorResponse(InvalidParams, err.Error(), req.ID)
+	}
+
+	var description, text string
+	if builder, prompt, ok := m.builtinPrompt(name); ok {
+		description = prompt.Description
+		text, err = builder(ctx, projectID, args)
+	} else if custom, ok := m.findCustomPrompt(ctx, projectID, name); ok {
+		description = custom.Description
+		text, err = m.renderCustomPrompt(ctx, projectID, custom, args)
+	} else {
+		return newJSONRPCErrorResponse(InvalidParams, fmt.Sprintf("Unknown prompt: %s", name), req.ID)
+	}
+
+	if err != nil {
+		return newJSONRPCErrorResponse(InvalidParams, err.Error(), req.ID)
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"description": description,
+			"messages": []MCPPromptMessage{{
+				Role: "user",
+				Content: MCPContent{
+					Type: "text",
+					Text: text,
+				},
+			}},
+		},
+		ID: req.ID,
+	}
+}
+
+// builtinPrompt looks up a built-in prompt and its renderer
+func (m *MCPServer) builtinPrompt(name string) (promptBuilder, MCPPrompt, bool) {
+	builders := map[string]promptBuilder{
+		"resume_feature":                m.buildResumeFeaturePrompt,
+		"review_file_against_decisions": m.buildReviewFilePrompt,
+		"onboard_to_module":             m.buildOnboardModulePrompt,
+	}
+
+	for _, prompt := range builtinPrompts {
+		if prompt.Name == name {
+			return builders[name], prompt, true
+		}
+	}
+	return nil, MCPPrompt{}, false
+}
+
+// buildResumeFeaturePrompt renders the resume_feature prompt
+func (m *MCPServer) buildResumeFeaturePrompt(ctx context.Context, projectID string, args map[string]string) (string, error) {
+	feature := args["feature"]
+	if feature == "" {
+		// Pre-fill with the most recently recorded feature
+		entities, err := m.knowledgeGraph.ListKnowledgeEntitiesByProject(ctx, projectID, []string{"feature"}, 1)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up recent features: %w", err)
+		}
+		if len(entities) == 0 {
+			return "", fmt.Errorf("no features recorded for this project; pass the feature argument")
+		}
+		feature = featureNameFromEntity(entities[0])
+	}
+
+	features, err := m.knowledgeGraph.SearchKnowledgeByProject(ctx, projectID, &models.KnowledgeGraphQuery{
+		Query:          feature,
+		EntityTypes:    []string{"feature"},
+		Limit:          3,
+		IncludeContent: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search features: %w", err)
+	}
+
+	decisions, err := m.knowledgeGraph.GetDecisionHistoryByProject(ctx, projectID, feature)
+	if err != nil {
+		return "", fmt.Errorf("failed to get decision history: %w", err)
+	}
+
+	discussions, err := m.knowledgeGraph.SearchKnowledgeByProject(ctx, projectID, &models.KnowledgeGraphQuery{
+		Query:          feature,
+		EntityTypes:    []string{"discussion"},
+		Limit:          5,
+		IncludeContent: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search discussions: %w", err)
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("I'm resuming work on the feature \"%s\". ", feature))
+	content.WriteString("Using the project history below, summarize where the work stands, what was decided and why, ")
+	content.WriteString("which questions are still open, and the most sensible next steps.\n\n")
+
+	writePromptSearchResults(&content, "Feature Context", features)
+	writePromptDecisions(&content, decisions.Decisions, 5)
+	writePromptSearchResults(&content, "Related Discussions", discussions)
+
+	return content.String(), nil
+}
+
+// buildReviewFilePrompt renders the review_file_against_decisions prompt
+func (m *MCPServer) buildReviewFilePrompt(ctx context.Context, projectID string, args map[string]string) (string, error) {
+	filePath := args["file_path"]
+	if filePath == "" {
+		return "", fmt.Errorf("file_path argument is required")
+	}
+
+	fileContext, err := m.knowledgeGraph.GetContextForFileByProject(ctx, projectID, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file context: %w", err)
+	}
+
+	decisions, err := m.knowledgeGraph.GetDecisionHistoryByProject(ctx, projectID, filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get decision history: %w", err)
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Review `%s` against the decisions the team has made. ", filePath))
+	content.WriteString("Point out code that contradicts or drifts from these decisions, call out assumptions that no longer hold, ")
+	content.WriteString("and note any change that should be recorded as a new decision.\n\n")
+
+	writePromptDecisions(&content, decisions.Decisions, 10)
+
+	if len(fileContext.FileContexts) > 0 {
+		content.WriteString("## File History\n\n")
+		for i, fc := range fileContext.FileContexts {
+			if i == 5 {
+				break
+			}
+			content.WriteString(fmt.Sprintf("- %s: %s", fc.CreatedAt.Format("2006-01-02"), truncatePromptText(fc.DiscussionContext, 200)))
+			if fc.ChangeReason != nil && *fc.ChangeReason != "" {
+				content.WriteString(fmt.Sprintf(" (reason: %s)", *fc.ChangeReason))
+			}
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+	}
+
+	return content.String(), nil
+}
+
+// buildOnboardModulePrompt renders the onboard_to_module prompt
+func (m *MCPServer) buildOnboardModulePrompt(ctx context.Context, projectID string, args map[string]string) (string, error) {
+	module := args["module"]
+	if module == "" {
+		return "", fmt.Errorf("module argument is required")
+	}
+
+	knowledge, err := m.knowledgeGraph.SearchKnowledgeByProject(ctx, projectID, &models.KnowledgeGraphQuery{
+		Query:          module,
+		Limit:          10,
+		IncludeContent: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to search knowledge: %w", err)
+	}
+
+	decisions, err := m.knowledgeGraph.GetDecisionHistoryByProject(ctx, projectID, module)
+	if err != nil {
+		return "", fmt.Errorf("failed to get decision history: %w", err)
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("I'm new to the `%s` module. ", module))
+	content.WriteString("Using the project knowledge below, explain what it does, why it is built the way it is, ")
+	content.WriteString("the key decisions behind it, who has worked on it, and where I should start reading.\n\n")
+
+	writePromptDecisions(&content, decisions.Decisions, 5)
+	writePromptSearchResults(&content, "Related Knowledge", knowledge)
+
+	return content.String(), nil
+}
+
+// loadCustomPrompts reads prompt definitions from the project's settings
+func (m *MCPServer) loadCustomPrompts(ctx context.Context, projectID string) []customPromptDefinition {
+	if m.repository == nil {
+		return nil
+	}
+
+	workspace, err := m.repository.GetProjectWorkspace(ctx, projectID)
+	if err != nil {
+		m.loggerFor(ctx).Error("Failed to load project for custom prompts", err, map[string]interface{}{
+			"project_id": projectID,
+		})
+		return nil
+	}
+
+	raw, ok := workspace.Settings[projectPromptsSettingsKey]
+	if !ok {
+		return nil
+	}
+
+	// Settings are stored as loosely typed JSON; round-trip into the typed definition
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+
+	var definitions []customPromptDefinition
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		m.loggerFor(ctx).Error("Invalid custom prompt settings", err, map[string]interface{}{
+			"project_id": projectID,
+		})
+		return nil
+	}
+
+	var prompts []customPromptDefinition
+	for _, definition := range definitions {
+		if definition.Name == "" || definition.Template == "" {
+			continue
+		}
+		// Built-in prompts cannot be overridden
+		if _, _, builtin := m.builtinPrompt(definition.Name); builtin {
+			continue
+		}
+		prompts = append(prompts, definition)
+	}
+
+	return prompts
+}
+
+// findCustomPrompt looks up a project-defined prompt by name
+func (m *MCPServer) findCustomPrompt(ctx context.Context, projectID, name string) (customPromptDefinition, bool) {
+	for _, custom := range m.loadCustomPrompts(ctx, projectID) {
+		if custom.Name == name {
+			return custom, true
+		}
+	}
+	return customPromptDefinition{}, false
+}
+
+// renderCustomPrompt fills a project-defined prompt template
+func (m *MCPServer) renderCustomPrompt(ctx context.Context, projectID string, custom customPromptDefinition, args map[string]string) (string, error) {
+	for _, argument := range custom.Arguments {
+		if argument.Required && args[argument.Name] == "" {
+			return "", fmt.Errorf("%s argument is required", argument.Name)
+		}
+	}
+
+	text := expandPromptTemplate(custom.Template, args)
+
+	if strings.Contains(text, "{{context}}") {
+		var knowledge strings.Builder
+		if query := expandPromptTemplate(custom.ContextQuery, args); strings.TrimSpace(query) != "" {
+			results, err := m.knowledgeGraph.SearchKnowledgeByProject(ctx, projectID, &models.KnowledgeGraphQuery{
+				Query:          query,
+				Limit:          10,
+				IncludeContent: true,
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to search knowledge: %w", err)
+			}
+			writePromptSearchResults(&knowledge, "Related Knowledge", results)
+		}
+		text = strings.ReplaceAll(text, "{{context}}", knowledge.String())
+	}
+
+	return text, nil
+}
+
+// expandPromptTemplate replaces {{name}} placeholders with argument values
+func expandPromptTemplate(template string, args map[string]string) string {
+	for key, value := range args {
+		template = strings.ReplaceAll(template, "{{"+key+"}}", value)
+	}
+	return template
+}
+
+// featureNameFromEntity extracts the feature name from a feature knowledge entity
+func featureNameFromEntity(entity models.KnowledgeEntity) string {
+	if name, ok := entity.Metadata["feature_name"].(string); ok && name != "" {
+		return name
+	}
+	return strings.TrimPrefix(entity.Title, "Feature: ")
+}
+
+// writePromptDecisions appends a decisions section to a prompt
+func writePromptDecisions(content *strings.Builder, decisions []models.DecisionRecord, limit int) {
+	if len(decisions) == 0 {
+		return
+	}
+
+	content.WriteString("## Decisions\n\n")
+	for i, decision := range decisions {
+		if i == limit {
+			break
+		}
+		content.WriteString(fmt.Sprintf("### %s (%s, %s)\n", decision.Title, decision.Status, decision.CreatedAt.Format("2006-01-02")))
+		content.WriteString(truncatePromptText(decision.Decision, 400))
+		content.WriteString("\n")
+		if decision.Rationale != nil && *decision.Rationale != "" {
+			content.WriteString(fmt.Sprintf("Rationale: %s\n", truncatePromptText(*decision.Rationale, 300)))
+		}
+		content.WriteString("\n")
+	}
+}
+
+// writePromptSearchResults appends a section of knowledge search results to a prompt
+func writePromptSearchResults(content *strings.Builder, title string, results []models.SearchResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	content.WriteString(fmt.Sprintf("## %s\n\n", title))
+	for _, result := range results {
+		content.WriteString(fmt.Sprintf("### %s (%s, %s)\n", result.Entity.Title, result.Entity.EntityType, result.Entity.CreatedAt.Format("2006-01-02")))
+		if result.Entity.Content != "" {
+			content.WriteString(truncatePromptText(result.Entity.Content, 300))
+			content.WriteString("\n")
+		}
+		if len(result.Entity.Participants) > 0 {
+			content.WriteString(fmt.Sprintf("Participants: %s\n", strings.Join(result.Entity.Participants, ", ")))
+		}
+		content.WriteString("\n")
+	}
+}
+
+// truncatePromptText shortens text for inclusion in a prompt, cutting at most
+// maxLen bytes on a rune boundary
+func truncatePromptText(text string, maxLen int) string {
+	if len(text) <= maxLen {
+		return text
+	}
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "..."
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type promptKnowledgeGraphStub struct {
+	KnowledgeGraphService
+	queries []string
+}
+
+func (s *promptKnowledgeGraphStub) ListKnowledgeEntitiesByProject(ctx context.Context, projectID string, entityTypes []string, limit int) ([]models.KnowledgeEntity, error) {
+	return []models.KnowledgeEntity{{
+		EntityType: "feature",
+		Title:      "Feature: billing",
+		Metadata:   map[string]interface{}{"feature_name": "billing"},
+	}}, nil
+}
+
+func (s *promptKnowledgeGraphStub) SearchKnowledgeByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	s.queries = append(s.queries, query.Query)
+	return []models.SearchResult{{
+		Entity: models.KnowledgeEntity{Title: "Invoice retries", EntityType: "discussion", Content: "Retry failed invoices nightly", CreatedAt: time.Now()},
+	}}, nil
+}
+
+func (s *promptKnowledgeGraphStub) GetDecisionHistoryByProject(ctx context.Context, projectID, target string) (*DecisionHistoryResponse, error) {
+	return &DecisionHistoryResponse{
+		Target:    target,
+		Decisions: []models.DecisionRecord{{Title: "Use Stripe", Decision: "Bill through Stripe", Status: "accepted"}},
+	}, nil
+}
+
+func (s *promptKnowledgeGraphStub) GetContextForFileByProject(ctx context.Context, projectID, filePath string) (*FileContextResponse, error) {
+	return &FileContextResponse{
+		FilePath:     filePath,
+		FileContexts: []models.FileContextHistory{{FilePath: filePath, DiscussionContext: "Split payment client"}},
+	}, nil
+}
+
+func newPromptTestServer(settings map[string]interface{}) (*MCPServer, *promptKnowledgeGraphStub, context.Context) {
+	kg := &promptKnowledgeGraphStub{}
+	server, ctx := newMCPTestServer(kg, &workspaceRepositoryStub{settings: settings}, nil)
+	return server, kg, ctx
+}
+
+func getPrompt(server *MCPServer, ctx context.Context, name string, args map[string]interface{}) *JSONRPCResponse {
+	return server.handleRequest(ctx, &JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "prompts/get",
+		Params:  map[string]interface{}{"name": name, "arguments": args},
+		ID:      1,
+	})
+}
+
+func promptText(t *testing.T, resp *JSONRPCResponse) string {
+	require.Nil(t, resp.Error)
+	messages := resp.Result.(map[string]interface{})["messages"].([]MCPPromptMessage)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "user", messages[0].Role)
+	return messages[0].Content.Text
+}
+
+func TestMCPPrompts_ListIncludesBuiltinAndProjectPrompts(t *testing.T) {
+	server, _, ctx := newPromptTestServer(map[string]interface{}{
+		"mcp_prompts": []interface{}{
+			map[string]interface{}{"name": "security_review", "description": "Security review", "template": "Review {{file_path}}"},
+			map[string]interface{}{"name": "resume_feature", "template": "cannot override built-ins"},
+		},
+	})
+
+	resp := server.handleRequest(ctx, &JSONRPCRequest{JSONRPC: "2.0", Method: "prompts/list", ID: 1})
+	require.Nil(t, resp.Error)
+
+	var names []string
+	for _, prompt := range resp.Result.(map[string]interface{})["prompts"].([]MCPPrompt) {
+		names = append(names, prompt.Name)
+	}
+	assert.Equal(t, []string{"resume_feature", "review_file_against_decisions", "onboard_to_module", "security_review"}, names)
+}
+
+func TestMCPPrompts_ResumeFeaturePrefillsMostRecentFeature(t *testing.T) {
+	server, kg, ctx := newPromptTestServer(nil)
+
+	text := promptText(t, getPrompt(server, ctx, "resume_feature", map[string]interface{}{}))
+	assert.Contains(t, text, `"billing"`)
+	assert.Contains(t, text, "Use Stripe")
+	assert.Contains(t, text, "Invoice retries")
+	assert.Contains(t, kg.queries, "billing")
+}
+
+func TestMCPPrompts_ReviewFileRequiresPath(t *testing.T) {
+	server, _, ctx := newPromptTestServer(nil)
+
+	resp := getPrompt(server, ctx, "review_file_against_decisions", map[string]interface{}{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, InvalidParams, resp.Error.Code)
+
+	text := promptText(t, getPrompt(server, ctx, "review_file_against_decisions", map[string]interface{}{"file_path": "billing/client.go"}))
+	assert.Contains(t, text, "`billing/client.go`")
+	assert.Contains(t, text, "Bill through Stripe")
+	assert.Contains(t, text, "Split payment client")
+}
+
+func TestMCPPrompts_CustomPromptTemplate(t *testing.T) {
+	server, kg, ctx := newPromptTestServer(map[string]interface{}{
+		"mcp_prompts": []interface{}{
+			map[string]interface{}{
+				"name":          "security_review",
+				"arguments":     []interface{}{map[string]interface{}{"name": "file_path", "required": true}},
+				"template":      "Check {{file_path}} for auth bypasses.\n\n{{context}}",
+				"context_query": "{{file_path}} security",
+			},
+		},
+	})
+
+	text := promptText(t, getPrompt(server, ctx, "security_review", map[string]interface{}{"file_path": "auth.go"}))
+	assert.Contains(t, text, "Check auth.go for auth bypasses.")
+	assert.Contains(t, text, "Invoice retries")
+	assert.Contains(t, kg.queries, "auth.go security")
+
+	resp := getPrompt(server, ctx, "security_review", map[string]interface{}{})
+	require.NotNil(t, resp.Error)
+
+	resp = getPrompt(server, ctx, "unknown_prompt", map[string]interface{}{})
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, InvalidParams, resp.Error.Code)
+}
+
+func TestTruncatePromptText_KeepsRunesWhole(t *testing.T) {
+	assert.Equal(t, "short", truncatePromptText("short", 10))
+	assert.Equal(t, "caf...", truncatePromptText("café au lait", 4))
+	assert.Equal(t, "café...", truncatePromptText("café au lait", 5))
+	assert.True(t, utf8.ValidString(truncatePromptText("日本語のテキスト", 7)))
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MCPRemoteClient forwards raw MCP JSON-RPC messages to a remote ContextKeeper API
+type MCPRemoteClient struct {
+	endpoint  string
+	token     string
+	projectID string
+	client    *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+// NewMCPRemoteClient creates a client that proxies MCP messages to baseURL/mcp.
+// projectID, when set, is sent as the default project for every request.
+func NewMCPRemoteClient(baseURL, token, projectID string) *MCPRemoteClient {
+	return &MCPRemoteClient{
+		endpoint:  strings.TrimRight(baseURL, "/") + "/mcp",
+		token:     token,
+		projectID: projectID,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// HandleMessage posts the message to the remote MCP endpoint and returns its response
+func (c *MCPRemoteClient) HandleMessage(ctx context.Context, message []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.projectID != "" {
+		req.Header.Set(MCPProjectHeader, c.projectID)
+	}
+	if sessionID := c.currentSession(); sessionID != "" {
+		req.Header.Set(MCPSessionHeader, sessionID)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote MCP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound && c.currentSession() != "" {
+		// The remote session expired; the client has to initialize again
+		c.setSession("")
+		return nil, fmt.Errorf("remote MCP session expired")
+	}
+
+	if resp.StatusCode == http.StatusAccepted {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("remote MCP endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if sessionID := resp.Header.Get(MCPSessionHeader); sessionID != "" {
+		c.setSession(sessionID)
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	return body, nil
+}
+
+func (c *MCPRemoteClient) currentSession() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sessionID
+}
+
+func (c *MCPRemoteClient) setSession(sessionID string) {
+	c.mu.Lock()
+	c.sessionID = sessionID
+	c.mu.Unlock()
+}
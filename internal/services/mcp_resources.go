@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// MCP resource URIs look like ckeeper://project/{project_id}/{kind}/{id}
+const mcpResourceScheme = "ckeeper://"
+
+// ResourceNotFound is the MCP error code for unknown resource URIs
+const ResourceNotFound = -32002
+
+// maxListedResources caps the number of resources returned by resources/list
+const maxListedResources = 200
+
+// MCPResource describes a readable resource
+type MCPResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// MCPResourceTemplate describes a parameterized resource URI
+type MCPResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// MCPResourceContents holds the contents of a resource
+type MCPResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// mcpResourceRef is a parsed resource URI
+type mcpResourceRef struct {
+	ProjectID string
+	Kind      string
+	ID        string
+}
+
+// Resource kinds and the knowledge entity types they map to
+var mcpResourceKinds = map[string]string{
+	"decision":   "decision",
+	"discussion": "discussion",
+	"feature":    "feature",
+	"file":       "file_context",
+}
+
+// buildResourceURI builds a resource URI for a project-scoped record.
+// File paths keep their slashes; each segment is escaped individually.
+func buildResourceURI(projectID, kind, id string) string {
+	segments := strings.Split(id, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%sproject/%s/%s/%s", mcpResourceScheme, url.PathEscape(projectID), kind, strings.Join(segments, "/"))
+}
+
+// parseResourceURI parses a ckeeper:// resource URI
+func parseResourceURI(uri string) (*mcpResourceRef, error) {
+	if !strings.HasPrefix(uri, mcpResourceScheme) {
+		return nil, fmt.Errorf("unsupported resource URI scheme: %s", uri)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(uri, mcpResourceScheme), "/", 4)
+	if len(parts) != 4 || parts[0] != "project" || parts[1] == "" || parts[3] == "" {
+		return nil, fmt.Errorf("invalid resource URI: %s", uri)
+	}
+
+	if _, ok := mcpResourceKinds[parts[2]]; !ok {
+		return nil, fmt.Errorf("unknown resource kind: %s", parts[2])
+	}
+
+	projectID, err := url.PathUnescape(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid project ID in resource URI: %w", err)
+	}
+
+	id, err := url.PathUnescape(parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource ID in resource URI: %w", err)
+	}
+
+	return &mcpResourceRef{
+		ProjectID: projectID,
+		Kind:      parts[2],
+		ID:        id,
+	}, nil
+}
+
+// handleResourcesTemplatesList returns the resource URI templates
+func (m *MCPServer) handleResourcesTemplatesList(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	templates := []MCPResourceTemplate{
+		{
+			URITemplate: mcpResourceScheme + "project/{project_id}/decision/{decision_id}",
+			Name:        "Decision record",
+			Description: "An architectural or technical decision with rationale, alternatives and consequences",
+			MimeType:    "text/markdown",
+		},
+		{
+			URITemplate: mcpResourceScheme + "project/{project_id}/discussion/{summary_id}",
+			Name:        "Discussion summary",
+			Description: "A summarized conversation thread with key points and action items",
+			MimeType:    "text/markdown",
+		},
+		{
+			URITemplate: mcpResourceScheme + "project/{project_id}/feature/{feature_id}",
+			Name:        "Feature context",
+			Description: "The development history of a feature",
+			MimeType:    "text/markdown",
+		},
+		{
+			URITemplate: mcpResourceScheme + "project/{project_id}/file/{path}",
+			Name:        "File context history",
+			Description: "The change and discussion history of a file",
+			MimeType:    "text/markdown",
+		},
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"resourceTemplates": templates,
+		},
+		ID: req.ID,
+	}
+}
+
+// handleResourcesList lists resources in the project selected for the connection
+func (m *MCPServer) handleResourcesList(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	resources := []MCPResource{}
+
+	// Without a default project there is nothing to enumerate; clients can
+	// still read resources through the templates
+	projectID, ok := m.defaultProject(ctx)
+	if !ok {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			Result:  map[string]interface{}{"resources": resources},
+			ID:      req.ID,
+		}
+	}
+
+	if err := m.authorizeProject(ctx, projectID); err != nil {
+		return newJSONRPCErrorResponse(InvalidParams, err.Error(), req.ID)
+	}
+
+	entities, err := m.knowledgeGraph.ListKnowledgeEntitiesByProject(ctx, projectID, []string{"decision", "discussion", "feature", "file_context"}, maxListedResources)
+	if err != nil {
+		m.loggerFor(ctx).Error("Failed to list MCP resources", err, map[string]interface{}{
+			"project_id": projectID,
+		})
+		return newJSONRPCErrorResponse(InternalError, "Failed to list resources", req.ID)
+	}
+
+	seenFiles := make(map[string]bool)
+	for _, entity := range entities {
+		switch entity.EntityType {
+		case "decision", "discussion", "feature":
+			resources = append(resources, MCPResource{
+				URI:      buildResourceURI(projectID, entity.EntityType, entity.EntityID),
+				Name:     entity.Title,
+				MimeType: "text/markdown",
+			})
+		case "file_context":
+			filePath, _ := entity.Metadata["file_path"].(string)
+			if filePath == "" || seenFiles[filePath] {
+				continue
+			}
+			seenFiles[filePath] = true
+			resources = append(resources, MCPResource{
+				URI:         buildResourceURI(projectID, "file", filePath),
+				Name:        filePath,
+				Description: "Change and discussion history",
+				MimeType:    "text/markdown",
+			})
+		}
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result:  map[string]interface{}{"resources": resources},
+		ID:      req.ID,
+	}
+}
+
+// handleResourcesRead reads a single resource by URI
+func (m *MCPServer) handleResourcesRead(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	uri := getStringParam(req.Params, "uri", "")
+	if uri == "" {
+		return newJSONRPCErrorResponse(InvalidParams, "Missing resource uri", req.ID)
+	}
+
+	ref, err := parseResourceURI(uri)
+	if err != nil {
+		return newJSONRPCErrorResponse(InvalidParams, err.Error(), req.ID)
+	}
+
+	if err := m.authorizeProject(ctx, ref.ProjectID); err != nil {
+		return newJSONRPCErrorResponse(InvalidParams, err.Error(), req.ID)
+	}
+
+	text, err := m.readResource(ctx, ref)
+	if err != nil {
+		m.loggerFor(ctx).Debug("MCP resource not found", map[string]interface{}{
+			"uri":   uri,
+			"error": err.Error(),
+		})
+		response := newJSONRPCErrorResponse(ResourceNotFound, "Resource not found", req.ID)
+		response.Error.Data = map[string]interface{}{"uri": uri}
+		return response
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		Result: map[string]interface{}{
+			"contents": []MCPResourceContents{{
+				URI:      uri,
+				MimeType: "text/markdown",
+				Text:     text,
+			}},
+		},
+		ID: req.ID,
+	}
+}
+
+// readResource loads and renders the record behind a resource reference
+func (m *MCPServer) readResource(ctx context.Context, ref *mcpResourceRef) (string, error) {
+	switch ref.Kind {
+	case "decision":
+		decision, err := m.knowledgeGraph.GetDecisionRecordByProject(ctx, ref.ProjectID, ref.ID)
+		if err != nil {
+			return "", err
+		}
+		return formatDecisionMarkdown(decision), nil
+	case "discussion":
+		summary, err := m.knowledgeGraph.GetDiscussionSummaryByProject(ctx, ref.ProjectID, ref.ID)
+		if err != nil {
+			return "", err
+		}
+		return formatDiscussionMarkdown(summary), nil
+	case "feature":
+		feature, err := m.knowledgeGraph.GetFeatureContextByProject(ctx, ref.ProjectID, ref.ID)
+		if err != nil {
+			return "", err
+		}
+		return formatFeatureMarkdown(feature), nil
+	case "file":
+		history, err := m.knowledgeGraph.GetFileContextHistoryByProject(ctx, ref.ProjectID, ref.ID)
+		if err != nil {
+			return "", err
+		}
+		if len(history) == 0 {
+			return "", fmt.Errorf("no history for file %s", ref.ID)
+		}
+		return formatFileHistoryMarkdown(ref.ID, history), nil
+	default:
+		return "", fmt.Errorf("unknown resource kind: %s", ref.Kind)
+	}
+}
+
+// formatDecisionMarkdown renders a decision record
+func formatDecisionMarkdown(decision *models.DecisionRecord) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Decision: %s\n\n", decision.Title))
+	content.WriteString(fmt.Sprintf("**Status:** %s\n", decision.Status))
+	if decision.Authoritative {
+		content.WriteString("**Authoritative:** written decision record\n")
+	}
+	if len(decision.Supersedes) > 0 {
+		content.WriteString(fmt.Sprintf("**Supersedes:** %s\n", strings.Join(decision.Supersedes, ", ")))
+	}
+	content.WriteString(fmt.Sprintf("**Platform:** %s\n", decision.PlatformSource))
+	content.WriteString(fmt.Sprintf("**Created:** %s\n\n", decision.CreatedAt.Format("2006-01-02 15:04")))
+
+	content.WriteString("## Decision\n\n")
+	content.WriteString(decision.Decision)
+	content.WriteString("\n\n")
+
+	if decision.Rationale != nil && *decision.Rationale != "" {
+		content.WriteString("## Rationale\n\n")
+		content.WriteString(*decision.Rationale)
+		content.WriteString("\n\n")
+	}
+
+	if len(decision.Alternatives) > 0 {
+		content.WriteString("## Alternatives Considered\n\n")
+		for _, alt := range decision.Alternatives {
+			content.WriteString(fmt.Sprintf("- %s\n", alt))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(decision.Consequences) > 0 {
+		content.WriteString("## Consequences\n\n")
+		for _, cons := range decision.Consequences {
+			content.WriteString(fmt.Sprintf("- %s\n", cons))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(decision.Participants) > 0 {
+		content.WriteString(fmt.Sprintf("**Participants:** %s\n", strings.Join(decision.Participants, ", ")))
+	}
+
+	return content.String()
+}
+
+// formatDiscussionMarkdown renders a discussion summary
+func formatDiscussionMarkdown(summary *models.DiscussionSummary) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Discussion from %s\n\n", summary.CreatedAt.Format("2006-01-02 15:04")))
+	content.WriteString(fmt.Sprintf("**Platform:** %s\n", summary.Platform))
+	if summary.ThreadID != nil {
+		content.WriteString(fmt.Sprintf("**Thread ID:** %s\n", *summary.ThreadID))
+	}
+	content.WriteString("\n## Summary\n\n")
+	content.WriteString(summary.Summary)
+	content.WriteString("\n\n")
+
+	if len(summary.KeyPoints) > 0 {
+		content.WriteString("## Key Points\n\n")
+		for _, point := range summary.KeyPoints {
+			content.WriteString(fmt.Sprintf("- %s\n", point))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(summary.ActionItems) > 0 {
+		content.WriteString("## Action Items\n\n")
+		for _, item := range summary.ActionItems {
+			content.WriteString(fmt.Sprintf("- %s\n", item))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(summary.Participants) > 0 {
+		content.WriteString(fmt.Sprintf("**Participants:** %s\n", strings.Join(summary.Participants, ", ")))
+	}
+	if len(summary.FileReferences) > 0 {
+		content.WriteString(fmt.Sprintf("**File References:** %s\n", strings.Join(summary.FileReferences, ", ")))
+	}
+
+	return content.String()
+}
+
+// formatFeatureMarkdown renders a feature context
+func formatFeatureMarkdown(feature *models.FeatureContext) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Feature: %s\n\n", feature.FeatureName))
+	content.WriteString(fmt.Sprintf("**Status:** %s\n", feature.Status))
+	content.WriteString(fmt.Sprintf("**Updated:** %s\n\n", feature.UpdatedAt.Format("2006-01-02 15:04")))
+
+	if feature.Description != nil && *feature.Description != "" {
+		content.WriteString(*feature.Description)
+		content.WriteString("\n\n")
+	}
+
+	if len(feature.RelatedFiles) > 0 {
+		content.WriteString("## Related Files\n\n")
+		for _, file := range feature.RelatedFiles {
+			content.WriteString(fmt.Sprintf("- %s\n", file))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(feature.Decisions) > 0 {
+		content.WriteString(fmt.Sprintf("**Decisions:** %s\n", strings.Join(feature.Decisions, ", ")))
+	}
+	if len(feature.Discussions) > 0 {
+		content.WriteString(fmt.Sprintf("**Discussions:** %s\n", strings.Join(feature.Discussions, ", ")))
+	}
+	if len(feature.Contributors) > 0 {
+		content.WriteString(fmt.Sprintf("**Contributors:** %s\n", strings.Join(feature.Contributors, ", ")))
+	}
+
+	return content.String()
+}
+
+// formatFileHistoryMarkdown renders the change history of a file
+func formatFileHistoryMarkdown(filePath string, history []models.FileContextHistory) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# File History: %s\n\n", filePath))
+
+	for i, fc := range history {
+		content.WriteString(fmt.Sprintf("## %d. %s\n", i+1, fc.CreatedAt.Format("2006-01-02 15:04")))
+		if fc.ChangeReason != nil && *fc.ChangeReason != "" {
+			content.WriteString(fmt.Sprintf("**Change Reason:** %s\n", *fc.ChangeReason))
+		}
+		content.WriteString(fmt.Sprintf("**Discussion Context:** %s\n", fc.DiscussionContext))
+		if len(fc.Contributors) > 0 {
+			content.WriteString(fmt.Sprintf("**Contributors:** %s\n", strings.Join(fc.Contributors, ", ")))
+		}
+		if len(fc.RelatedDecisions) > 0 {
+			content.WriteString(fmt.Sprintf("**Related Decisions:** %s\n", strings.Join(fc.RelatedDecisions, ", ")))
+		}
+		content.WriteString("\n")
+	}
+
+	return content.String()
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type resourceKnowledgeGraphStub struct {
+	KnowledgeGraphService
+	entities  []models.KnowledgeEntity
+	decisions map[string]*models.DecisionRecord
+	files     map[string][]models.FileContextHistory
+}
+
+func (s *resourceKnowledgeGraphStub) ListKnowledgeEntitiesByProject(ctx context.Context, projectID string, entityTypes []string, limit int) ([]models.KnowledgeEntity, error) {
+	return s.entities, nil
+}
+
+func (s *resourceKnowledgeGraphStub) GetDecisionRecordByProject(ctx context.Context, projectID, decisionID string) (*models.DecisionRecord, error) {
+	if decision, ok := s.decisions[decisionID]; ok {
+		return decision, nil
+	}
+	return nil, fmt.Errorf("not found")
+}
+
+func (s *resourceKnowledgeGraphStub) GetFileContextHistoryByProject(ctx context.Context, projectID, filePath string) ([]models.FileContextHistory, error) {
+	return s.files[filePath], nil
+}
+
+func newResourceTestServer() (*MCPServer, context.Context) {
+	rationale := "Stateless auth scales horizontally"
+	kg := &resourceKnowledgeGraphStub{
+		entities: []models.KnowledgeEntity{
+			{EntityType: "decision", EntityID: "d1", Title: "Use JWT"},
+			{EntityType: "file_context", EntityID: "f1", Metadata: map[string]interface{}{"file_path": "internal/auth/jwt.go"}},
+			{EntityType: "file_context", EntityID: "f2", Metadata: map[string]interface{}{"file_path": "internal/auth/jwt.go"}},
+		},
+		decisions: map[string]*models.DecisionRecord{
+			"d1": {DecisionID: "d1", Title: "Use JWT", Decision: "Adopt JWT tokens", Rationale: &rationale, Status: "accepted"},
+		},
+		files: map[string][]models.FileContextHistory{
+			"internal/auth/jwt.go": {{FilePath: "internal/auth/jwt.go", DiscussionContext: "Added token refresh"}},
+		},
+	}
+	return newMCPTestServer(kg, nil, nil)
+}
+
+func TestResourceURI_RoundTrip(t *testing.T) {
+	uri := buildResourceURI("p1", "file", "docs/design notes/auth.md")
+	assert.Equal(t, "ckeeper://project/p1/file/docs/design%20notes/auth.md", uri)
+
+	ref, err := parseResourceURI(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "p1", ref.ProjectID)
+	assert.Equal(t, "file", ref.Kind)
+	assert.Equal(t, "docs/design notes/auth.md", ref.ID)
+
+	for _, bad := range []string{"https://x/y", "ckeeper://project/p1/unknown/x", "ckeeper://project/p1/decision/", "ckeeper://repo/p1/decision/d1"} {
+		_, err := parseResourceURI(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestMCPResources_ListUsesDefaultProject(t *testing.T) {
+	server, ctx := newResourceTestServer()
+
+	resp := server.handleRequest(ctx, &JSONRPCRequest{JSONRPC: "2.0", Method: "resources/list", ID: 1})
+	require.Nil(t, resp.Error)
+
+	resources := resp.Result.(map[string]interface{})["resources"].([]MCPResource)
+	require.Len(t, resources, 2, "file contexts for the same path are listed once")
+	assert.Equal(t, "ckeeper://project/p1/decision/d1", resources[0].URI)
+	assert.Equal(t, "ckeeper://project/p1/file/internal/auth/jwt.go", resources[1].URI)
+
+	// Without a selected project there is nothing to enumerate
+	resp = server.handleRequest(WithMCPUser(context.Background(), &models.User{ID: "u1"}), &JSONRPCRequest{JSONRPC: "2.0", Method: "resources/list", ID: 2})
+	require.Nil(t, resp.Error)
+	assert.Empty(t, resp.Result.(map[string]interface{})["resources"])
+}
+
+func TestMCPResources_Read(t *testing.T) {
+	server, ctx := newResourceTestServer()
+
+	read := func(uri string) *JSONRPCResponse {
+		return server.handleRequest(ctx, &JSONRPCRequest{
+			JSONRPC: "2.0",
+			Method:  "resources/read",
+			Params:  map[string]interface{}{"uri": uri},
+			ID:      1,
+		})
+	}
+
+	resp := read("ckeeper://project/p1/decision/d1")
+	require.Nil(t, resp.Error)
+	contents := resp.Result.(map[string]interface{})["contents"].([]MCPResourceContents)
+	require.Len(t, contents, 1)
+	assert.Contains(t, contents[0].Text, "Adopt JWT tokens")
+	assert.Contains(t, contents[0].Text, "Stateless auth")
+
+	resp = read("ckeeper://project/p1/file/internal/auth/jwt.go")
+	require.Nil(t, resp.Error)
+	contents = resp.Result.(map[string]interface{})["contents"].([]MCPResourceContents)
+	assert.Contains(t, contents[0].Text, "Added token refresh")
+
+	resp = read("ckeeper://project/p1/decision/missing")
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, ResourceNotFound, resp.Error.Code)
+
+	resp = read("ckeeper://project/p2/decision/d1")
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, InvalidParams, resp.Error.Code)
+}
+
+func TestMCPResources_TemplatesAndCapability(t *testing.T) {
+	server, ctx := newResourceTestServer()
+
+	resp := server.handleRequest(ctx, &JSONRPCRequest{JSONRPC: "2.0", Method: "resources/templates/list", ID: 1})
+	require.Nil(t, resp.Error)
+	templates := resp.Result.(map[string]interface{})["resourceTemplates"].([]MCPResourceTemplate)
+	assert.Len(t, templates, 4)
+
+	resp = server.handleRequest(ctx, &JSONRPCRequest{JSONRPC: "2.0", Method: "initialize", ID: 2})
+	capabilities := resp.Result.(map[string]interface{})["capabilities"].(map[string]interface{})
+	assert.Contains(t, capabilities, "resources")
+}
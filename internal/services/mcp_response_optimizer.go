@@ -2,247 +2,256 @@ package services
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 	"time"
 )
 
-// ResponseOptimizer handles response size management and summarization
+// ResponseOptimizer handles response size management: it packs the most
+// relevant items of a response into a token budget and splits oversized
+// content into pages
 type ResponseOptimizer struct {
 	maxTokens int
+	tokenizer Tokenizer
+	weights   PackWeights
 	logger    Logger
 }
 
-// NewResponseOptimizer creates a new response optimizer
+// NewResponseOptimizer creates a new response optimizer using the default tokenizer
 func NewResponseOptimizer(maxTokens int, logger Logger) *ResponseOptimizer {
 	return &ResponseOptimizer{
 		maxTokens: maxTokens,
+		tokenizer: defaultTokenizer,
+		weights:   DefaultPackWeights,
 		logger:    logger,
 	}
 }
 
-// OptimizeResponse optimizes response content for token limits
-func (ro *ResponseOptimizer) OptimizeResponse(content string, contentType string) string {
-	// Estimate token count (rough approximation: 1 token ≈ 4 characters)
-	estimatedTokens := len(content) / 4
-	
-	if estimatedTokens <= ro.maxTokens {
-		return content
-	}
+// SetTokenizer replaces the tokenizer used to measure responses
+func (ro *ResponseOptimizer) SetTokenizer(tokenizer Tokenizer) {
+	ro.tokenizer = tokenizer
+}
 
-	ro.logger.Info("Response exceeds token limit, optimizing", map[string]interface{}{
-		"estimated_tokens": estimatedTokens,
-		"max_tokens":       ro.maxTokens,
-		"content_type":     contentType,
-	})
+// CountTokens measures text with the optimizer's tokenizer
+func (ro *ResponseOptimizer) CountTokens(text string) int {
+	return ro.tokenizer.CountTokens(text)
+}
 
-	// Apply optimization based on content type
-	switch contentType {
-	case "search_results":
-		return ro.optimizeSearchResults(content)
-	case "file_context":
-		return ro.optimizeFileContext(content)
-	case "decision_history":
-		return ro.optimizeDecisionHistory(content)
-	case "architecture_discussions":
-		return ro.optimizeArchitectureDiscussions(content)
-	case "code_explanation":
-		return ro.optimizeCodeExplanation(content)
-	default:
-		return ro.genericOptimization(content)
-	}
+// PackWeights sets how much each relevance signal counts toward an item's score
+type PackWeights struct {
+	Rank       float64
+	Similarity float64
+	Recency    float64
+	Strength   float64
+
+	// RecencyHalfLife is the age at which an item's recency signal halves
+	RecencyHalfLife time.Duration
 }
 
-// optimizeSearchResults optimizes search result responses
-func (ro *ResponseOptimizer) optimizeSearchResults(content string) string {
-	lines := strings.Split(content, "\n")
-	var optimized strings.Builder
-	
-	// Keep header and summary
-	for idx, line := range lines {
-		if idx < 5 || strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
-			optimized.WriteString(line + "\n")
-		} else if strings.HasPrefix(line, "**Content:**") {
-			// Truncate content sections
-			optimized.WriteString("**Content:** [Content truncated for brevity]\n")
-			// Skip until next section
-			for j := idx + 1; j < len(lines) && !strings.HasPrefix(lines[j], "**"); j++ {
-				idx = j
-			}
-		} else {
-			optimized.WriteString(line + "\n")
+// DefaultPackWeights favours the order and similarity the query already computed
+var DefaultPackWeights = PackWeights{
+	Rank:            0.4,
+	Similarity:      0.3,
+	Recency:         0.15,
+	Strength:        0.15,
+	RecencyHalfLife: 30 * 24 * time.Hour,
+}
+
+// PackItem is one candidate piece of a response, such as a search result or
+// a decision, rendered as markdown. Signals an item does not have are left zero.
+type PackItem struct {
+	ID         string    // identifies the item to the caller
+	Title      string    // names the item when it is omitted
+	Section    string    // heading the item is listed under, if any
+	Text       string    // rendered markdown
+	Rank       int       // position in the source ordering, 0 first
+	Similarity float64   // similarity to the query, 0 to 1
+	Timestamp  time.Time // when the knowledge was created
+	Strength   float64   // relationship strength, 0 to 1
+
+	Score  float64 // set by the packer
+	Tokens int     // set by the packer
+}
+
+// PackResult describes which items made it into a packed response
+type PackResult struct {
+	MaxTokens  int        // budget, or zero when nothing was limited
+	UsedTokens int        // tokens of the rendered response
+	Included   []PackItem // in their original order
+	Omitted    []PackItem // most relevant first
+}
+
+// Includes reports whether the item with the given ID was packed
+func (p *PackResult) Includes(id string) bool {
+	for _, item := range p.Included {
+		if item.ID == id {
+			return true
 		}
 	}
-	
-	optimized.WriteString("\n*Note: Some content has been truncated due to response size limits. Use more specific queries for detailed information.*\n")
-	return optimized.String()
+	return false
 }
 
-// optimizeFileContext optimizes file context responses
-func (ro *ResponseOptimizer) optimizeFileContext(content string) string {
-	lines := strings.Split(content, "\n")
-	var optimized strings.Builder
-	
-	// Keep headers and first few context entries
-	contextCount := 0
-	maxContexts := 3
-	
-	for _, line := range lines {
-		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
-			optimized.WriteString(line + "\n")
-		} else if strings.HasPrefix(line, "### ") && strings.Contains(content, "File Context History") {
-			if contextCount < maxContexts {
-				optimized.WriteString(line + "\n")
-				contextCount++
-			} else {
-				optimized.WriteString("### [Additional contexts truncated]\n")
-				break
-			}
-		} else if contextCount <= maxContexts {
-			optimized.WriteString(line + "\n")
+// packItemID builds a PackItem ID from the kind of item and its index
+func packItemID(kind string, index int) string {
+	return fmt.Sprintf("%s:%d", kind, index)
+}
+
+// maxOmittedTitles bounds how many omitted items the omission note names
+const maxOmittedTitles = 5
+
+// PackMarkdown renders header, the items that fit within maxTokens and footer.
+// Items are scored on their relevance signals and packed whole, best first,
+// skipping any that no longer fit; the packed items keep their original order
+// under their section headings and a note names what was left out. A
+// maxTokens of zero or less keeps every item.
+func (ro *ResponseOptimizer) PackMarkdown(header string, items []PackItem, footer string, maxTokens int) (string, *PackResult) {
+	scored := make([]PackItem, len(items))
+	now := time.Now()
+	for i, item := range items {
+		item.Score = ro.score(item, now)
+		item.Tokens = ro.tokenizer.CountTokens(item.Text)
+		scored[i] = item
+	}
+
+	included := make([]bool, len(scored))
+	if maxTokens <= 0 {
+		for i := range included {
+			included[i] = true
 		}
+		content := renderPacked(header, scored, included, footer, 0)
+		return content, &PackResult{UsedTokens: ro.tokenizer.CountTokens(content), Included: scored}
 	}
-	
-	optimized.WriteString("\n*Note: File context has been truncated. Use get_decision_history for more detailed information.*\n")
-	return optimized.String()
-}
 
-// optimizeDecisionHistory optimizes decision history responses
-func (ro *ResponseOptimizer) optimizeDecisionHistory(content string) string {
-	lines := strings.Split(content, "\n")
-	var optimized strings.Builder
-	
-	// Keep headers and first few decisions
-	decisionCount := 0
-	maxDecisions := 5
-	
-	for i, line := range lines {
-		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "Found ") {
-			optimized.WriteString(line + "\n")
-		} else if strings.HasPrefix(line, "## ") {
-			if decisionCount < maxDecisions {
-				optimized.WriteString(line + "\n")
-				decisionCount++
-			} else {
-				optimized.WriteString("## [Additional decisions truncated]\n")
-				break
-			}
-		} else if decisionCount <= maxDecisions {
-			// Truncate long rationale and consequence sections
-			if strings.HasPrefix(line, "**Rationale:**") || strings.HasPrefix(line, "**Consequences:**") {
-				optimized.WriteString(line + "\n")
-				// Add next line but truncate if too long
-				if i+1 < len(lines) {
-					nextLine := lines[i+1]
-					if len(nextLine) > 200 {
-						nextLine = nextLine[:200] + "..."
-					}
-					optimized.WriteString(nextLine + "\n")
-					i++ // Skip the next line since we processed it
-				}
-			} else {
-				optimized.WriteString(line + "\n")
-			}
+	// Best first; ties keep the source order
+	order := make([]int, len(scored))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scored[order[a]].Score > scored[order[b]].Score
+	})
+
+	remaining := maxTokens - ro.tokenizer.CountTokens(header) - ro.tokenizer.CountTokens(footer)
+	openSections := make(map[string]bool)
+	for _, i := range order {
+		cost := scored[i].Tokens
+		if section := scored[i].Section; section != "" && !openSections[section] {
+			cost += ro.tokenizer.CountTokens(sectionHeading(section))
 		}
+		if cost > remaining {
+			continue
+		}
+		included[i] = true
+		openSections[scored[i].Section] = true
+		remaining -= cost
 	}
-	
-	optimized.WriteString("\n*Note: Decision history has been truncated. Query specific decisions for full details.*\n")
-	return optimized.String()
-}
 
-// optimizeArchitectureDiscussions optimizes architecture discussion responses
-func (ro *ResponseOptimizer) optimizeArchitectureDiscussions(content string) string {
-	lines := strings.Split(content, "\n")
-	var optimized strings.Builder
-	
-	// Keep headers and first few discussions
-	discussionCount := 0
-	maxDiscussions := 3
-	
-	for idx, line := range lines {
-		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "Found ") {
-			optimized.WriteString(line + "\n")
-		} else if strings.HasPrefix(line, "## ") {
-			if discussionCount < maxDiscussions {
-				optimized.WriteString(line + "\n")
-				discussionCount++
-			} else {
-				optimized.WriteString("## [Additional discussions truncated]\n")
+	// The omission note and separators are only known once rendered; drop the
+	// least relevant items until the whole response fits
+	content := renderPacked(header, scored, included, footer, maxTokens)
+	for used := ro.tokenizer.CountTokens(content); used > maxTokens; used = ro.tokenizer.CountTokens(content) {
+		dropped := false
+		for j := len(order) - 1; j >= 0; j-- {
+			if included[order[j]] {
+				included[order[j]] = false
+				dropped = true
 				break
 			}
-		} else if discussionCount <= maxDiscussions {
-			// Truncate long summaries
-			if strings.HasPrefix(line, "**Summary:**") {
-				optimized.WriteString(line + "\n")
-				// Add next few lines but truncate
-				for j := idx + 1; j < len(lines) && j < idx+4 && !strings.HasPrefix(lines[j], "**"); j++ {
-					optimized.WriteString(lines[j] + "\n")
-					idx = j
-				}
-				if idx+1 < len(lines) && !strings.HasPrefix(lines[idx+1], "**") {
-					optimized.WriteString("[Summary truncated...]\n")
-				}
-			} else {
-				optimized.WriteString(line + "\n")
-			}
 		}
+		if !dropped {
+			break
+		}
+		content = renderPacked(header, scored, included, footer, maxTokens)
+	}
+
+	result := &PackResult{MaxTokens: maxTokens, UsedTokens: ro.tokenizer.CountTokens(content)}
+	for i, item := range scored {
+		if included[i] {
+			result.Included = append(result.Included, item)
+		}
+	}
+	for _, i := range order {
+		if !included[i] {
+			result.Omitted = append(result.Omitted, scored[i])
+		}
+	}
+
+	if len(result.Omitted) > 0 {
+		ro.logger.Info("Packed response into token budget", map[string]interface{}{
+			"max_tokens":  maxTokens,
+			"used_tokens": result.UsedTokens,
+			"included":    len(result.Included),
+			"omitted":     len(result.Omitted),
+		})
 	}
-	
-	optimized.WriteString("\n*Note: Architecture discussions have been truncated. Use search_project_knowledge for specific topics.*\n")
-	return optimized.String()
+
+	return content, result
 }
 
-// optimizeCodeExplanation optimizes code explanation responses
-func (ro *ResponseOptimizer) optimizeCodeExplanation(content string) string {
-	lines := strings.Split(content, "\n")
-	var optimized strings.Builder
-	
-	// Keep headers and most important sections
-	for _, line := range lines {
-		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
-			optimized.WriteString(line + "\n")
-		} else if strings.HasPrefix(line, "### ") {
-			// Keep first few subsections
-			optimized.WriteString(line + "\n")
-		} else {
-			// Truncate long paragraphs
-			if len(line) > 300 {
-				line = line[:300] + "..."
-			}
-			optimized.WriteString(line + "\n")
+// score combines an item's relevance signals using the optimizer's weights
+func (ro *ResponseOptimizer) score(item PackItem, now time.Time) float64 {
+	w := ro.weights
+	score := w.Rank/float64(1+max(item.Rank, 0)) +
+		w.Similarity*clampUnit(item.Similarity) +
+		w.Strength*clampUnit(item.Strength)
+
+	if !item.Timestamp.IsZero() && w.RecencyHalfLife > 0 {
+		age := now.Sub(item.Timestamp)
+		recency := 1.0
+		if age > 0 {
+			recency = math.Pow(0.5, float64(age)/float64(w.RecencyHalfLife))
 		}
+		score += w.Recency * recency
 	}
-	
-	optimized.WriteString("\n*Note: Code explanation has been summarized. Use get_context_for_file for detailed context.*\n")
-	return optimized.String()
+
+	return score
 }
 
-// genericOptimization applies generic content truncation
-func (ro *ResponseOptimizer) genericOptimization(content string) string {
-	// Simple truncation with ellipsis
-	targetLength := ro.maxTokens * 4 // Rough character estimate
-	
-	if len(content) <= targetLength {
-		return content
+// renderPacked writes the included items between header and footer, followed
+// by a note on the omitted ones
+func renderPacked(header string, items []PackItem, included []bool, footer string, maxTokens int) string {
+	var content strings.Builder
+	content.WriteString(header)
+
+	section := ""
+	var omitted []string
+	for i, item := range items {
+		if !included[i] {
+			omitted = append(omitted, item.Title)
+			continue
+		}
+		if item.Section != "" && item.Section != section {
+			content.WriteString(sectionHeading(item.Section))
+		}
+		section = item.Section
+		content.WriteString(item.Text)
 	}
-	
-	// Find a good break point (end of sentence or paragraph)
-	truncated := content[:targetLength]
-	
-	// Look for last sentence ending
-	lastPeriod := strings.LastIndex(truncated, ".")
-	lastNewline := strings.LastIndex(truncated, "\n\n")
-	
-	breakPoint := targetLength
-	if lastNewline > targetLength-200 {
-		breakPoint = lastNewline
-	} else if lastPeriod > targetLength-100 {
-		breakPoint = lastPeriod + 1
+
+	content.WriteString(footer)
+
+	if len(omitted) > 0 {
+		names := omitted
+		if len(names) > maxOmittedTitles {
+			names = names[:maxOmittedTitles]
+		}
+		note := fmt.Sprintf("\n*Omitted %d of %d items to stay within %d tokens: %s", len(omitted), len(items), maxTokens, strings.Join(names, "; "))
+		if len(omitted) > len(names) {
+			note += fmt.Sprintf("; and %d more", len(omitted)-len(names))
+		}
+		content.WriteString(note + ". Raise max_tokens or narrow the request to see them.*\n")
 	}
-	
-	result := content[:breakPoint]
-	result += "\n\n*Note: Response has been truncated due to size limits. Use more specific queries for detailed information.*"
-	
-	return result
+
+	return content.String()
+}
+
+// sectionHeading renders the heading packed items are grouped under
+func sectionHeading(section string) string {
+	return "## " + section + "\n\n"
+}
+
+func clampUnit(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
 }
 
 // StreamResponse handles streaming responses for large content
@@ -253,9 +262,10 @@ type StreamResponse struct {
 	Token   string       `json:"token,omitempty"`
 }
 
-// CreateStreamedResponse creates a streamed response for large content
+// CreateStreamedResponse splits content into chunks of at most chunkSize
+// tokens, breaking between lines
 func (ro *ResponseOptimizer) CreateStreamedResponse(content string, chunkSize int) []StreamResponse {
-	if len(content) <= chunkSize*4 { // If content is small enough, return single response
+	if ro.tokenizer.CountTokens(content) <= chunkSize { // If content is small enough, return single response
 		return []StreamResponse{{
 			Content: []MCPContent{{
 				Type: "text",
@@ -268,15 +278,15 @@ func (ro *ResponseOptimizer) CreateStreamedResponse(content string, chunkSize in
 	// Split content into chunks
 	var chunks []StreamResponse
 	lines := strings.Split(content, "\n")
-	
+
 	var currentChunk strings.Builder
 	currentSize := 0
 	chunkIndex := 0
-	
+
 	for _, line := range lines {
-		lineSize := len(line) + 1 // +1 for newline
-		
-		if currentSize+lineSize > chunkSize*4 && currentChunk.Len() > 0 {
+		lineSize := ro.tokenizer.CountTokens(line + "\n")
+
+		if currentSize+lineSize > chunkSize && currentChunk.Len() > 0 {
 			// Create chunk
 			chunks = append(chunks, StreamResponse{
 				Content: []MCPContent{{
@@ -286,17 +296,17 @@ func (ro *ResponseOptimizer) CreateStreamedResponse(content string, chunkSize in
 				HasMore: true,
 				Token:   generateStreamToken(chunkIndex),
 			})
-			
+
 			// Reset for next chunk
 			currentChunk.Reset()
 			currentSize = 0
 			chunkIndex++
 		}
-		
+
 		currentChunk.WriteString(line + "\n")
 		currentSize += lineSize
 	}
-	
+
 	// Add final chunk
 	if currentChunk.Len() > 0 {
 		chunks = append(chunks, StreamResponse{
@@ -308,12 +318,12 @@ func (ro *ResponseOptimizer) CreateStreamedResponse(content string, chunkSize in
 			Token:   generateStreamToken(chunkIndex),
 		})
 	}
-	
+
 	ro.logger.Info("Created streamed response", map[string]interface{}{
 		"total_chunks": len(chunks),
 		"content_size": len(content),
 	})
-	
+
 	return chunks
 }
 
@@ -322,9 +332,7 @@ func generateStreamToken(index int) string {
 	return fmt.Sprintf("stream_%d_%d", time.Now().Unix(), index)
 }
 
-// Helper function to estimate token count
+// EstimateTokenCount estimates the tokens in text with the default tokenizer
 func EstimateTokenCount(text string) int {
-	// Rough approximation: 1 token ≈ 4 characters
-	// This is a simplified estimate; real tokenization would be more accurate
-	return len(text) / 4
-}
\ No newline at end of file
+	return defaultTokenizer.CountTokens(text)
+}
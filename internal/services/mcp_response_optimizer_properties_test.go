@@ -1,8 +1,10 @@
 package services
 
 import (
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"pgregory.net/rapid"
@@ -12,11 +14,10 @@ import (
 // **Validates: Requirements 7.6, 7.7**
 //
 // This property verifies that the response optimizer:
-// 1. Correctly estimates token counts and applies optimization when needed
-// 2. Maintains content structure and readability after optimization
-// 3. Provides appropriate truncation messages to users
-// 4. Handles different content types with appropriate strategies
-// 5. Creates properly structured streaming responses for large content
+// 1. Correctly estimates token counts and packs whole items into the token budget
+// 2. Maintains content structure and readability after packing
+// 3. Tells users which items were omitted, most relevant first
+// 4. Creates properly structured streaming responses for large content
 func TestProperty_ResponseSizeManagement(t *testing.T) {
 	rapid.Check(t, func(t *rapid.T) {
 		// Create response optimizer with small token limit for testing
@@ -38,74 +39,23 @@ func TestProperty_ResponseSizeManagement(t *testing.T) {
 		contentSize := rapid.IntRange(50, 5000).Draw(t, "content_size")
 		content := generateTestContent(t, contentType, contentSize)
 
-		// Test 1: Response optimization should respect token limits
-		optimizedContent := optimizer.OptimizeResponse(content, contentType)
-		
-		// If original content was large, optimized should be smaller or equal to limit
-		originalTokens := EstimateTokenCount(content)
-		if originalTokens > maxTokens {
-			// The optimizer should attempt to reduce content size, but may add truncation messages
-			// For very large content, we expect some optimization to occur
-			if len(content) > maxTokens * 8 { // Very large content
-				// Should show some sign of optimization (either smaller or with truncation indicators)
-				containsTruncationIndicator := strings.Contains(optimizedContent, "truncated") ||
-					strings.Contains(optimizedContent, "summarized") ||
-					strings.Contains(optimizedContent, "brevity") ||
-					strings.Contains(optimizedContent, "...") ||
-					strings.Contains(optimizedContent, "[Additional") ||
-					strings.Contains(optimizedContent, "[Summary")
-				
-				if len(optimizedContent) >= len(content) {
-					assert.True(t, containsTruncationIndicator, "Large content that wasn't reduced should contain truncation indicators")
-				}
-				
-				// Should not be more than double the original size (accounting for added messages)
-				assert.Less(t, len(optimizedContent), len(content)*2, "Optimized content should not be excessively larger")
-			}
-		} else {
-			// If original was within limits, it should be unchanged or minimally changed
-			assert.LessOrEqual(t, len(optimizedContent), len(content)*2, "Small content should not be significantly expanded")
+		// Test 1: Packed responses should respect the token budget with whole items
+		items := generateTestItems(t, contentSize)
+		packedContent, packed := optimizer.PackMarkdown("# Search Results\n\n", items, "", maxTokens)
+
+		assert.LessOrEqual(t, packed.UsedTokens, maxTokens, "Packed response should fit the token budget")
+		assert.Equal(t, EstimateTokenCount(packedContent), packed.UsedTokens, "Used tokens should be measured on the rendered response")
+		assert.Equal(t, len(items), len(packed.Included)+len(packed.Omitted), "Every item should be either included or omitted")
+		for _, item := range packed.Included {
+			assert.Contains(t, packedContent, item.Text, "Included items should never be cut")
 		}
 
-		// Test 2: Optimized content should maintain structure
-		if originalTokens > maxTokens {
-			// Should contain some form of truncation notice or optimization indicator
-			containsTruncationIndicator := strings.Contains(optimizedContent, "truncated") ||
-				strings.Contains(optimizedContent, "summarized") ||
-				strings.Contains(optimizedContent, "brevity") ||
-				strings.Contains(optimizedContent, "...") ||
-				strings.Contains(optimizedContent, "[Additional") ||
-				strings.Contains(optimizedContent, "[Summary")
-			
-			assert.True(t, containsTruncationIndicator, "Large content should contain some truncation or optimization indicator")
-			
-			// Should maintain markdown structure for appropriate content types
-			if strings.Contains(content, "#") {
-				assert.Contains(t, optimizedContent, "#", "Should preserve markdown headers")
-			}
-			
-			// Should maintain basic content structure
-			switch contentType {
-			case "search_results":
-				if strings.Contains(content, "Search Results") {
-					assert.Contains(t, optimizedContent, "Search Results", "Should preserve search results header")
-				}
-			case "file_context":
-				if strings.Contains(content, "Context for File") {
-					assert.Contains(t, optimizedContent, "Context for File", "Should preserve file context header")
-				}
-			case "decision_history":
-				if strings.Contains(content, "Decision History") {
-					assert.Contains(t, optimizedContent, "Decision History", "Should preserve decision history header")
-				}
-			case "architecture_discussions":
-				if strings.Contains(content, "Architecture Discussions") {
-					assert.Contains(t, optimizedContent, "Architecture Discussions", "Should preserve discussions header")
-				}
-			case "code_explanation":
-				if strings.Contains(content, "Why") && strings.Contains(content, "Exists") {
-					assert.True(t, strings.Contains(optimizedContent, "Why") || strings.Contains(optimizedContent, "Exists"), "Should preserve explanation context")
-				}
+		// Test 2: Packed content should maintain structure and report omissions
+		assert.Contains(t, packedContent, "# Search Results", "Should preserve the header")
+		if len(packed.Omitted) > 0 {
+			assert.Contains(t, packedContent, "Omitted", "Should tell the user what was left out")
+			for i := 1; i < len(packed.Omitted); i++ {
+				assert.GreaterOrEqual(t, packed.Omitted[i-1].Score, packed.Omitted[i].Score, "Omitted items should be listed most relevant first")
 			}
 		}
 
@@ -136,7 +86,7 @@ func TestProperty_ResponseSizeManagement(t *testing.T) {
 		}
 		
 		// Reconstructed content should match original (for small content) or be reasonable (for large content)
-		if len(content) <= chunkSize*4 {
+		if EstimateTokenCount(content) <= chunkSize {
 			// Small content should be in single response
 			assert.Equal(t, 1, len(streamResponses), "Small content should result in single response")
 			assert.Equal(t, content, streamResponses[0].Content[0].Text, "Small content should be unchanged")
@@ -149,13 +99,31 @@ func TestProperty_ResponseSizeManagement(t *testing.T) {
 
 		// Test 4: Token estimation accuracy
 		estimatedTokens := EstimateTokenCount(content)
-		// Token estimation should be reasonable (rough approximation)
-		expectedTokens := len(content) / 4 // Our estimation formula
-		assert.Equal(t, expectedTokens, estimatedTokens, "Token estimation should match expected formula")
+		// Every pre-token costs at least one token and no more than its bytes
+		assert.Equal(t, NewBPETokenizer().CountTokens(content), estimatedTokens, "Token estimation should use the default tokenizer")
 		assert.Greater(t, estimatedTokens, 0, "Token count should be positive for non-empty content")
+		assert.LessOrEqual(t, estimatedTokens, len(content), "Token count should not exceed the byte count")
 	})
 }
 
+// Helper function to generate scored search result items of various sizes
+func generateTestItems(t *rapid.T, targetSize int) []PackItem {
+	count := rapid.IntRange(0, 12).Draw(t, "item_count")
+	items := make([]PackItem, 0, count)
+	for i := 0; i < count; i++ {
+		words := rapid.IntRange(1, targetSize/20+1).Draw(t, "item_words")
+		items = append(items, PackItem{
+			ID:         packItemID("result", i),
+			Title:      fmt.Sprintf("Result %d", i+1),
+			Text:       fmt.Sprintf("## %d. Result\n", i+1) + strings.Repeat("Sample content for search results. ", words) + "\n\n",
+			Rank:       i,
+			Similarity: rapid.Float64Range(0, 1).Draw(t, "similarity"),
+			Timestamp:  time.Now().Add(-time.Duration(rapid.IntRange(0, 1000).Draw(t, "age_hours")) * time.Hour),
+		})
+	}
+	return items
+}
+
 // Helper function to generate test content of various types and sizes
 func generateTestContent(t *rapid.T, contentType string, targetSize int) string {
 	var content strings.Builder
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordTokenizer counts whitespace-separated words, which makes budgets easy to reason about
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func newWordPacker() *ResponseOptimizer {
+	optimizer := NewResponseOptimizer(4000, &SimpleLogger{})
+	optimizer.SetTokenizer(wordTokenizer{})
+	return optimizer
+}
+
+func TestBPETokenizer_FollowsPreTokenizerSplits(t *testing.T) {
+	tokenizer := NewBPETokenizer()
+	for text, expected := range map[string]int{
+		"":                   0,
+		"hello world":        2,
+		"getUserFromContext": 4,
+		"It's   done.\n":     5,
+		"    return nil":     3,
+		"12345":              2,
+		"\n\n":               1,
+		"日本語":                3,
+	} {
+		assert.Equal(t, expected, tokenizer.CountTokens(text), "%q", text)
+	}
+
+	// Much closer to BPE than a quarter of the length for code and markdown
+	assert.Equal(t, 13, EstimateTokenCount("## 1. Use JWT\n**Type:** decision\n"))
+}
+
+func TestPackMarkdown_FitsWholeItemsByRelevance(t *testing.T) {
+	items := []PackItem{
+		{ID: "a", Title: "A", Text: "alpha one two three four five six seven\n", Rank: 0, Similarity: 0.9},
+		{ID: "b", Title: "B", Text: "bravo one two\n", Rank: 1, Similarity: 0.8},
+		{ID: "c", Title: "C", Text: strings.Repeat("charlie ", 30), Rank: 2, Similarity: 0.1},
+		{ID: "d", Title: "D", Text: "delta one two three\n", Rank: 3, Similarity: 0.7},
+	}
+
+	content, packed := newWordPacker().PackMarkdown("# Results\n", items, "", 45)
+
+	// A, B and D are the most relevant and fit with the omission note; C is
+	// the least relevant and too large for what is left
+	assert.True(t, packed.Includes("a"))
+	assert.True(t, packed.Includes("b"))
+	assert.True(t, packed.Includes("d"))
+	require.Len(t, packed.Omitted, 1)
+	assert.Equal(t, "c", packed.Omitted[0].ID)
+	assert.LessOrEqual(t, packed.UsedTokens, 45)
+	assert.Equal(t, len(strings.Fields(content)), packed.UsedTokens)
+
+	// Packed items keep their original order and the note names what was left out
+	assert.Less(t, strings.Index(content, "bravo"), strings.Index(content, "delta"))
+	assert.NotContains(t, content, "charlie")
+	assert.Contains(t, content, "Omitted 1 of 4 items to stay within 45 tokens: C.")
+}
+
+func TestPackMarkdown_SkipsItemsThatDoNotFit(t *testing.T) {
+	items := []PackItem{
+		{ID: "big", Title: "Big", Text: strings.Repeat("word ", 50), Rank: 0},
+		{ID: "small", Title: "Small", Text: "small item\n", Rank: 1},
+	}
+
+	content, packed := newWordPacker().PackMarkdown("", items, "", 40)
+
+	assert.False(t, packed.Includes("big"), "items are never cut")
+	assert.True(t, packed.Includes("small"), "smaller items still fill the budget")
+	assert.Contains(t, content, "small item")
+	assert.Equal(t, 50, packed.Omitted[0].Tokens)
+}
+
+func TestPackMarkdown_ScoresRecencyAndStrength(t *testing.T) {
+	optimizer := newWordPacker()
+	now := time.Now()
+	text := strings.Repeat("text ", 30)
+
+	// Room for one item and the omission note
+	_, packed := optimizer.PackMarkdown("", []PackItem{
+		{ID: "old", Title: "Old", Text: text, Timestamp: now.AddDate(-1, 0, 0)},
+		{ID: "new", Title: "New", Text: text, Timestamp: now.Add(-time.Hour)},
+	}, "", 55)
+	assert.Equal(t, []string{"new"}, packedIDs(packed.Included))
+
+	_, packed = optimizer.PackMarkdown("", []PackItem{
+		{ID: "weak", Title: "Weak", Text: text, Strength: 0.1},
+		{ID: "strong", Title: "Strong", Text: text, Strength: 0.9},
+	}, "", 55)
+	assert.Equal(t, []string{"strong"}, packedIDs(packed.Included))
+}
+
+func TestPackMarkdown_SectionsAndUnlimitedBudget(t *testing.T) {
+	items := []PackItem{
+		{ID: "c0", Section: "History", Text: "### change\n", Rank: 0},
+		{ID: "d0", Section: "Decisions", Text: "### decision\n", Rank: 0},
+		{ID: "d1", Section: "Decisions", Text: "### another decision\n", Rank: 1},
+	}
+
+	content, packed := newWordPacker().PackMarkdown("# File\n\n", items, "footer\n", 0)
+	assert.Equal(t, "# File\n\n## History\n\n### change\n## Decisions\n\n### decision\n### another decision\nfooter\n", content)
+	assert.Len(t, packed.Included, 3)
+	assert.Empty(t, packed.Omitted)
+	assert.Zero(t, packed.MaxTokens)
+}
+
+func packedIDs(items []PackItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+// rankedSearchStub returns search results of decreasing similarity
+type rankedSearchStub struct {
+	KnowledgeGraphService
+}
+
+func (s *rankedSearchStub) SearchKnowledgeByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	var results []models.SearchResult
+	for i, title := range []string{"Use JWT", "Session store", "Token refresh", "Login audit"} {
+		results = append(results, models.SearchResult{
+			Entity: models.KnowledgeEntity{
+				ID:         title,
+				EntityType: "decision",
+				Title:      title,
+				Content:    strings.Repeat("Details about "+title+". ", 20),
+				CreatedAt:  time.Now(),
+			},
+			Similarity: 0.9 - float64(i)*0.2,
+		})
+	}
+	return results, nil
+}
+
+func TestSearchProjectKnowledge_MaxTokens(t *testing.T) {
+	server, ctx := newMCPTestServer(&rankedSearchStub{}, nil, nil)
+
+	result, err := server.callTool(ctx, "search_project_knowledge", map[string]interface{}{"query": "auth", "max_tokens": float64(300)})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Nil(t, result.Meta, "a packed result is not paged")
+
+	text := result.Content[0].Text
+	assert.LessOrEqual(t, EstimateTokenCount(text), 300)
+	assert.Contains(t, text, "Use JWT")
+	assert.NotContains(t, text, "## 4. Login audit")
+	assert.Contains(t, text, "Raise max_tokens")
+
+	structured := result.StructuredContent.(map[string]interface{})
+	packing := structured["packing"].(map[string]interface{})
+	assert.Equal(t, 300, packing["max_tokens"])
+	omitted := packing["omitted"].([]map[string]interface{})
+	require.NotEmpty(t, omitted)
+	assert.Equal(t, "Login audit", omitted[len(omitted)-1]["title"], "least relevant result goes first")
+	assert.Len(t, structured["results"], 4-len(omitted))
+
+	// Without a budget every result is returned
+	result, err = server.callTool(ctx, "search_project_knowledge", map[string]interface{}{"query": "auth"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].Text, "Login audit")
+	assert.NotContains(t, result.StructuredContent, "packing")
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// mcpRootsTimeout bounds how long the server waits for the client's roots and
+// how long project lookups wait for them to be matched
+const mcpRootsTimeout = 10 * time.Second
+
+// mcpRoot is a workspace folder reported by the client in roots/list
+type mcpRoot struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// mcpWorkspace is the project selected from the client's workspace folders
+type mcpWorkspace struct {
+	ProjectID string
+	Roots     []mcpWorkspaceRoot
+}
+
+// mcpWorkspaceRoot is a workspace folder checked out from one of the project's repositories
+type mcpWorkspaceRoot struct {
+	Path       string
+	Repository string
+}
+
+// expectWorkspaceRoots records the client's capabilities at initialize. Clients
+// that expose roots get their project selected from them, so project lookups
+// wait for that to finish rather than racing it.
+func (m *MCPServer) expectWorkspaceRoots(ctx context.Context, capabilities map[string]interface{}) {
+	session, ok := MCPSessionFromContext(ctx)
+	if !ok {
+		return
+	}
+	session.SetClientCapabilities(capabilities)
+
+	if _, ok := MCPUserFromContext(ctx); ok && m.repository != nil && session.ClientSupports("roots") {
+		session.expectWorkspace()
+	}
+}
+
+// startWorkspaceRootsRefresh fetches the client's roots in the background; the
+// request that delivered the notification may end before the client answers
+func (m *MCPServer) startWorkspaceRootsRefresh(ctx context.Context) {
+	session, ok := MCPSessionFromContext(ctx)
+	if !ok || !session.ClientSupports("roots") {
+		return
+	}
+	go m.refreshWorkspaceRoots(context.WithoutCancel(ctx), session)
+}
+
+// refreshWorkspaceRoots asks the client for its workspace folders and selects
+// the project whose repositories they are checked out from
+func (m *MCPServer) refreshWorkspaceRoots(ctx context.Context, session *MCPSession) {
+	ctx, cancel := context.WithTimeout(ctx, mcpRootsTimeout)
+	defer cancel()
+
+	var workspace *mcpWorkspace
+	defer func() { session.setWorkspace(workspace) }()
+
+	raw, err := session.Request(ctx, "roots/list", nil)
+	if err != nil {
+		m.loggerFor(ctx).Error("Failed to list MCP client roots", err, map[string]interface{}{
+			"session_id": session.ID,
+		})
+		return
+	}
+
+	var result struct {
+		Roots []mcpRoot `json:"roots"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		m.loggerFor(ctx).Error("Invalid MCP roots/list result", err, map[string]interface{}{
+			"session_id": session.ID,
+		})
+		return
+	}
+
+	workspace, err = m.matchWorkspaceRoots(ctx, session, result.Roots)
+	if err != nil {
+		m.loggerFor(ctx).Error("Failed to match MCP client roots to a project", err, map[string]interface{}{
+			"session_id": session.ID,
+		})
+		return
+	}
+
+	if workspace != nil {
+		m.loggerFor(ctx).Info("Selected MCP project from client roots", map[string]interface{}{
+			"session_id": session.ID,
+			"project_id": workspace.ProjectID,
+			"roots":      len(workspace.Roots),
+		})
+	}
+}
+
+// matchWorkspaceRoots finds the caller's project for the given workspace
+// folders. Folders are matched by their git remotes against the project's
+// repositories and repository data sources, falling back to the folder name
+// when it identifies exactly one repository. The first matching folder picks
+// the project; it returns nil when nothing matches.
+func (m *MCPServer) matchWorkspaceRoots(ctx context.Context, session *MCPSession, roots []mcpRoot) (*mcpWorkspace, error) {
+	user, ok := MCPUserFromContext(ctx)
+	if !ok || m.repository == nil || len(roots) == 0 {
+		return nil, nil
+	}
+
+	projects, err := m.repository.GetProjectWorkspacesByUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	// Repository slugs and bare repository names, each mapped to the projects using them
+	bySlug := make(map[string]map[string]string)
+	byName := make(map[string]map[string]string)
+	addRepository := func(projectID, fullName string) {
+		slug := normalizeRepositorySlug(fullName)
+		if slug == "" {
+			return
+		}
+		addRepositoryMatch(bySlug, slug, projectID, fullName)
+		addRepositoryMatch(byName, path.Base(slug), projectID, fullName)
+	}
+
+	for _, project := range projects {
+		repos, err := m.repository.GetReposByProject(ctx, project.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of project %s: %w", project.ID, err)
+		}
+		for _, repo := range repos {
+			addRepository(project.ID, repo.FullName)
+		}
+
+		dataSources, err := m.repository.GetProjectDataSources(ctx, project.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list data sources of project %s: %w", project.ID, err)
+		}
+		for _, dataSource := range dataSources {
+			if dataSource.SourceType != string(models.SourceTypeRepository) {
+				continue
+			}
+			addRepository(project.ID, dataSource.SourceID)
+			addRepository(project.ID, dataSource.SourceName)
+			if fullName, ok := dataSource.Configuration["full_name"].(string); ok {
+				addRepository(project.ID, fullName)
+			}
+		}
+	}
+
+	var workspace *mcpWorkspace
+	for _, root := range roots {
+		dir, ok := rootPath(root.URI)
+		if !ok {
+			continue
+		}
+
+		projectID, repository := matchRootRepository(dir, root.Name, session.localClient, bySlug, byName)
+		if projectID == "" {
+			continue
+		}
+		if workspace == nil {
+			workspace = &mcpWorkspace{ProjectID: projectID}
+		}
+		if projectID == workspace.ProjectID {
+			workspace.Roots = append(workspace.Roots, mcpWorkspaceRoot{Path: dir, Repository: repository})
+		}
+	}
+
+	return workspace, nil
+}
+
+// matchRootRepository returns the project and repository a workspace folder
+// was checked out from. The folder's git config is only read when the client
+// shares this machine's filesystem.
+func matchRootRepository(dir, name string, local bool, bySlug, byName map[string]map[string]string) (string, string) {
+	if local {
+		for _, remote := range gitRemoteURLs(dir) {
+			if projectID, repository, ok := soleProject(bySlug[normalizeRepositorySlug(remote)]); ok {
+				return projectID, repository
+			}
+		}
+	}
+
+	// Without access to the checkout, fall back to the folder name when only
+	// one repository has it
+	for _, candidate := range []string{filepath.Base(dir), name} {
+		if projectID, repository, ok := soleProject(byName[strings.ToLower(candidate)]); ok {
+			return projectID, repository
+		}
+	}
+
+	return "", ""
+}
+
+// addRepositoryMatch records that key identifies a repository of the project
+func addRepositoryMatch(index map[string]map[string]string, key, projectID, repository string) {
+	if index[key] == nil {
+		index[key] = make(map[string]string)
+	}
+	index[key][projectID] = repository
+}
+
+// soleProject returns the project in matches when there is exactly one
+func soleProject(matches map[string]string) (string, string, bool) {
+	if len(matches) != 1 {
+		return "", "", false
+	}
+	for projectID, repository := range matches {
+		return projectID, repository, true
+	}
+	return "", "", false
+}
+
+// rootPath returns the local path of a file:// root URI
+func rootPath(uri string) (string, bool) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" || parsed.Path == "" {
+		return "", false
+	}
+	return filepath.Clean(filepath.FromSlash(parsed.Path)), true
+}
+
+// gitRemoteURLs reads the remote URLs of the git checkout at dir, with origin first
+func gitRemoteURLs(dir string) []string {
+	gitDir := filepath.Join(dir, ".git")
+
+	// Worktrees and submodules have a .git file pointing at the real git directory
+	if data, err := os.ReadFile(gitDir); err == nil {
+		target := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		gitDir = target
+		if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+			commonDir := strings.TrimSpace(string(common))
+			if !filepath.IsAbs(commonDir) {
+				commonDir = filepath.Join(gitDir, commonDir)
+			}
+			gitDir = commonDir
+		}
+	}
+
+	file, err := os.Open(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var origin, others []string
+	remote := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			remote = ""
+			if strings.HasPrefix(line, "[remote ") {
+				remote = strings.Trim(strings.TrimPrefix(line, "[remote "), `"]`)
+			}
+			continue
+		}
+		if remote == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != "url" {
+			continue
+		}
+		if remote == "origin" {
+			origin = append(origin, strings.TrimSpace(value))
+		} else {
+			others = append(others, strings.TrimSpace(value))
+		}
+	}
+
+	return append(origin, others...)
+}
+
+// normalizeRepositorySlug reduces a repository full name or git remote URL to
+// a lowercase owner/name path, such as "devanuragt/context_keeper"
+func normalizeRepositorySlug(value string) string {
+	slug := strings.ToLower(strings.TrimSpace(value))
+
+	if strings.Contains(slug, "://") {
+		parsed, err := url.Parse(slug)
+		if err != nil {
+			return ""
+		}
+		slug = parsed.Path
+	} else if at := strings.Index(slug, ":"); at >= 0 && !strings.Contains(slug[:at], "/") {
+		// scp-like syntax: git@github.com:owner/name.git
+		slug = slug[at+1:]
+	}
+
+	slug = strings.Trim(slug, "/")
+	slug = strings.TrimSuffix(slug, ".git")
+	if !strings.Contains(slug, "/") {
+		return ""
+	}
+	return slug
+}
+
+// repoRelativePath rewrites an absolute path or file:// URI inside one of the
+// session's workspace folders to a path relative to that folder
+func (m *MCPServer) repoRelativePath(ctx context.Context, filePath string) string {
+	session, ok := MCPSessionFromContext(ctx)
+	if !ok {
+		return filePath
+	}
+	workspace := session.waitForWorkspace(ctx, mcpRootsTimeout)
+	if workspace == nil {
+		return filePath
+	}
+
+	local := filePath
+	if strings.HasPrefix(local, "file://") {
+		dir, ok := rootPath(local)
+		if !ok {
+			return filePath
+		}
+		local = dir
+	}
+	if !filepath.IsAbs(local) {
+		return filePath
+	}
+
+	for _, root := range workspace.Roots {
+		rel, err := filepath.Rel(root.Path, filepath.Clean(local))
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	return filePath
+}
+
+// rewriteFilePathArgument makes a file_path argument repo-relative
+func (m *MCPServer) rewriteFilePathArgument(ctx context.Context, arguments map[string]interface{}) {
+	if filePath, ok := arguments["file_path"].(string); ok && filePath != "" {
+		arguments["file_path"] = m.repoRelativePath(ctx, filePath)
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rootsRepositoryStub lists two projects: p1 with a repository row, p2 with a GitHub data source
+type rootsRepositoryStub struct {
+	RepositoryStore
+}
+
+func (r *rootsRepositoryStub) GetProjectWorkspacesByUser(ctx context.Context, userID string) ([]models.ProjectWorkspace, error) {
+	return []models.ProjectWorkspace{{ID: "p1"}, {ID: "p2"}}, nil
+}
+
+func (r *rootsRepositoryStub) GetReposByProject(ctx context.Context, projectID string) ([]models.Repository, error) {
+	if projectID == "p1" {
+		return []models.Repository{{FullName: "acme/website"}}, nil
+	}
+	return nil, nil
+}
+
+func (r *rootsRepositoryStub) GetProjectDataSources(ctx context.Context, projectID string) ([]models.ProjectDataSource, error) {
+	if projectID == "p2" {
+		return []models.ProjectDataSource{{
+			SourceType:    string(models.SourceTypeRepository),
+			SourceID:      "4242",
+			SourceName:    "Acme/Billing",
+			Configuration: map[string]interface{}{"full_name": "Acme/Billing"},
+		}}, nil
+	}
+	return nil, nil
+}
+
+func (r *rootsRepositoryStub) GetProjectWorkspace(ctx context.Context, projectID string) (*models.ProjectWorkspace, error) {
+	return &models.ProjectWorkspace{ID: projectID}, nil
+}
+
+func (r *rootsRepositoryStub) CreateMCPToolCall(ctx context.Context, call *models.MCPToolCall) error {
+	return nil
+}
+
+func writeGitConfig(t *testing.T, dir, config string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "config"), []byte(config), 0o644))
+}
+
+func TestNormalizeRepositorySlug(t *testing.T) {
+	for input, expected := range map[string]string{
+		"git@github.com:Acme/Billing.git":                   "acme/billing",
+		"https://github.com/acme/billing":                   "acme/billing",
+		"ssh://git@gitlab.example.com:2222/group/sub/repo/": "group/sub/repo",
+		"Acme/Billing": "acme/billing",
+		"4242":         "",
+	} {
+		assert.Equal(t, expected, normalizeRepositorySlug(input), input)
+	}
+}
+
+func TestGitRemoteURLs_OriginFirst(t *testing.T) {
+	dir := t.TempDir()
+	writeGitConfig(t, dir, "[core]\n\tbare = false\n[remote \"upstream\"]\n\turl = https://github.com/upstream/billing.git\n[remote \"origin\"]\n\turl = git@github.com:acme/billing.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n")
+
+	assert.Equal(t, []string{"git@github.com:acme/billing.git", "https://github.com/upstream/billing.git"}, gitRemoteURLs(dir))
+
+	// A worktree's .git file points at the main checkout's git directory
+	worktree := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git", "worktrees", "wt"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "worktrees", "wt", "commondir"), []byte("../..\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+filepath.Join(dir, ".git", "worktrees", "wt")+"\n"), 0o644))
+	assert.Equal(t, gitRemoteURLs(dir), gitRemoteURLs(worktree))
+}
+
+func TestMCPRoots_SelectProjectAndRewritePaths(t *testing.T) {
+	checkout := filepath.Join(t.TempDir(), "billing-service")
+	writeGitConfig(t, checkout, "[remote \"origin\"]\n\turl = git@github.com:acme/billing.git\n")
+
+	kg := &scopedKnowledgeGraphStub{}
+	perms := &readPermissionStub{readable: map[string]bool{"p1": true, "p2": true}}
+	server := NewMCPServer(kg, nil, perms, &rootsRepositoryStub{}, &SimpleLogger{})
+
+	session := NewMCPSession("s1")
+	session.localClient = true
+	ctx := WithMCPSession(WithMCPUser(context.Background(), &models.User{ID: "u1"}), session)
+	_, events, unsubscribe := session.Subscribe("")
+	defer unsubscribe()
+
+	_, err := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"roots":{"listChanged":true}}}}`))
+	require.NoError(t, err)
+	_, err = server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	require.NoError(t, err)
+
+	// The server asks the client for its roots
+	var request struct {
+		ID     string `json:"id"`
+		Method string `json:"method"`
+	}
+	select {
+	case event := <-events:
+		require.NoError(t, json.Unmarshal(event.Data, &request))
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not request roots/list")
+	}
+	assert.Equal(t, "roots/list", request.Method)
+
+	// A tool call made before the client answers waits for the project to be selected
+	toolResponse := make(chan []byte, 1)
+	go func() {
+		raw, _ := server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_context_for_file","arguments":{"file_path":"`+filepath.ToSlash(filepath.Join(checkout, "pkg", "client.go"))+`"}}}`))
+		toolResponse <- raw
+	}()
+
+	answer, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      request.ID,
+		"result": map[string]interface{}{"roots": []map[string]string{
+			{"uri": "file:///somewhere/else", "name": "scratch"},
+			{"uri": "file://" + filepath.ToSlash(checkout), "name": "billing-service"},
+		}},
+	})
+	require.NoError(t, err)
+	raw, err := server.HandleMessage(ctx, answer)
+	require.NoError(t, err)
+	assert.Nil(t, raw, "client responses get no reply")
+
+	var resp struct {
+		Result MCPToolResult `json:"result"`
+		Error  *JSONRPCError `json:"error"`
+	}
+	select {
+	case raw = <-toolResponse:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool call did not finish")
+	}
+	require.NoError(t, json.Unmarshal(raw, &resp))
+	require.Nil(t, resp.Error)
+	require.False(t, resp.Result.IsError, resp.Result.Content[0].Text)
+
+	assert.Equal(t, []string{"p2"}, kg.projects)
+	structured := resp.Result.StructuredContent.(map[string]interface{})
+	assert.Equal(t, "p2", structured["project_id"])
+	assert.Equal(t, "pkg/client.go", structured["file_path"])
+
+	// Paths outside the workspace folders are left alone
+	assert.Equal(t, "/tmp/other/main.go", server.repoRelativePath(ctx, "/tmp/other/main.go"))
+}
+
+func TestMCPRoots_FolderNameFallbackForRemoteClients(t *testing.T) {
+	server := NewMCPServer(&scopedKnowledgeGraphStub{}, nil, &readPermissionStub{}, &rootsRepositoryStub{}, &SimpleLogger{})
+	session := NewMCPSession("s1")
+	ctx := WithMCPUser(context.Background(), &models.User{ID: "u1"})
+
+	workspace, err := server.matchWorkspaceRoots(ctx, session, []mcpRoot{
+		{URI: "https://example.com/website"},
+		{URI: "file:///home/dev/code/website"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, workspace)
+	assert.Equal(t, "p1", workspace.ProjectID)
+	assert.Equal(t, []mcpWorkspaceRoot{{Path: filepath.FromSlash("/home/dev/code/website"), Repository: "acme/website"}}, workspace.Roots)
+
+	workspace, err = server.matchWorkspaceRoots(ctx, session, []mcpRoot{{URI: "file:///home/dev/code/unrelated"}})
+	require.NoError(t, err)
+	assert.Nil(t, workspace)
+}
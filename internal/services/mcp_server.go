@@ -11,19 +11,40 @@ import (
 type MCPServer struct {
 	knowledgeGraph     KnowledgeGraphService
 	contextService     ContextService
+	permissionSvc      PermissionService
+	repository         RepositoryStore
 	responseOptimizer  *ResponseOptimizer
+	sessions           *MCPSessionManager
+	inflight           *mcpInflightRequests
+	results            *mcpResultCache
+	usage              MCPUsageService
+	ingestion          IngestionOrchestrator
+	syncLimiter        *mcpSyncLimiter
 	logger             Logger
 }
 
 // NewMCPServer creates a new MCP server instance
-func NewMCPServer(knowledgeGraph KnowledgeGraphService, contextService ContextService, logger Logger) *MCPServer {
+func NewMCPServer(knowledgeGraph KnowledgeGraphService, contextService ContextService, permissionSvc PermissionService, repository RepositoryStore, logger Logger) *MCPServer {
 	// Default to 4000 tokens max response size
 	responseOptimizer := NewResponseOptimizer(4000, logger)
+
+	// Tool calls are metered whenever there is somewhere to record them
+	var usage MCPUsageService
+	if repository != nil {
+		usage = NewMCPUsageService(repository)
+	}
 	
 	return &MCPServer{
 		knowledgeGraph:    knowledgeGraph,
 		contextService:    contextService,
+		permissionSvc:     permissionSvc,
+		repository:        repository,
 		responseOptimizer: responseOptimizer,
+		sessions:          NewMCPSessionManager(defaultMCPSessionTTL),
+		inflight:          newMCPInflightRequests(),
+		results:           newMCPResultCache(defaultMCPResultTTL),
+		usage:             usage,
+		syncLimiter:       newMCPSyncLimiter(defaultMCPSyncInterval),
 		logger:            logger,
 	}
 }
@@ -53,15 +74,18 @@ type JSONRPCError struct {
 
 // MCP Tool definition structure
 type MCPTool struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
 }
 
 // MCP Tool result structure
 type MCPToolResult struct {
-	Content []MCPContent `json:"content"`
-	IsError bool         `json:"isError,omitempty"`
+	Content           []MCPContent           `json:"content"`
+	StructuredContent interface{}            `json:"structuredContent,omitempty"`
+	IsError           bool                   `json:"isError,omitempty"`
+	Meta              map[string]interface{} `json:"_meta,omitempty"`
 }
 
 // MCP Content structure
@@ -70,6 +94,9 @@ type MCPContent struct {
 	Text string `json:"text"`
 }
 
+// supportedProtocolVersions lists MCP protocol versions this server speaks, newest first
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
 // Standard JSON-RPC error codes
 const (
 	ParseError     = -32700
@@ -79,32 +106,6 @@ const (
 	InternalError  = -32603
 )
 
-// ServeHTTP implements the HTTP handler for MCP JSON-RPC requests
-func (m *MCPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	var req JSONRPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		m.sendError(w, ParseError, "Parse error", nil, nil)
-		return
-	}
-
-	// Validate JSON-RPC version
-	if req.JSONRPC != "2.0" {
-		m.sendError(w, InvalidRequest, "Invalid Request", nil, req.ID)
-		return
-	}
-
-	ctx := r.Context()
-	response := m.handleRequest(ctx, &req)
-	
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
-
 // handleRequest processes a JSON-RPC request and returns a response
 func (m *MCPServer) handleRequest(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	switch req.Method {
@@ -114,6 +115,20 @@ func (m *MCPServer) handleRequest(ctx context.Context, req *JSONRPCRequest) *JSO
 		return m.handleToolsCall(ctx, req)
 	case "initialize":
 		return m.handleInitialize(ctx, req)
+	case "resources/list":
+		return m.handleResourcesList(ctx, req)
+	case "resources/read":
+		return m.handleResourcesRead(ctx, req)
+	case "resources/templates/list":
+		return m.handleResourcesTemplatesList(ctx, req)
+	case "prompts/list":
+		return m.handlePromptsList(ctx, req)
+	case "prompts/get":
+		return m.handlePromptsGet(ctx, req)
+	case "logging/setLevel":
+		return m.handleLoggingSetLevel(ctx, req)
+	case "completion/complete":
+		return m.handleCompletionComplete(ctx, req)
 	default:
 		return &JSONRPCResponse{
 			JSONRPC: "2.0",
@@ -128,10 +143,17 @@ func (m *MCPServer) handleRequest(ctx context.Context, req *JSONRPCRequest) *JSO
 
 // handleInitialize handles MCP initialization
 func (m *MCPServer) handleInitialize(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
+	capabilities, _ := req.Params["capabilities"].(map[string]interface{})
+	m.expectWorkspaceRoots(ctx, capabilities)
+
 	result := map[string]interface{}{
-		"protocolVersion": "2024-11-05",
+		"protocolVersion": negotiateProtocolVersion(getStringParam(req.Params, "protocolVersion", "")),
 		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{},
+			"tools":       map[string]interface{}{},
+			"resources":   map[string]interface{}{},
+			"prompts":     map[string]interface{}{},
+			"logging":     map[string]interface{}{},
+			"completions": map[string]interface{}{},
 		},
 		"serverInfo": map[string]interface{}{
 			"name":    "context-keeper-mcp",
@@ -146,6 +168,17 @@ func (m *MCPServer) handleInitialize(ctx context.Context, req *JSONRPCRequest) *
 	}
 }
 
+// negotiateProtocolVersion echoes the client's protocol version when supported,
+// otherwise it falls back to the latest version this server implements
+func negotiateProtocolVersion(requested string) string {
+	for _, version := range supportedProtocolVersions {
+		if version == requested {
+			return version
+		}
+	}
+	return supportedProtocolVersions[0]
+}
+
 // handleToolsList returns the list of available MCP tools
 func (m *MCPServer) handleToolsList(ctx context.Context, req *JSONRPCRequest) *JSONRPCResponse {
 	tools := []MCPTool{
@@ -265,8 +298,203 @@ func (m *MCPServer) handleToolsList(ctx context.Context, req *JSONRPCRequest) *J
 				"required": []string{"file_path"},
 			},
 		},
+		{
+			Name:        "explore_related_knowledge",
+			Description: "Walk the knowledge graph from an entity to the decisions, discussions, features and files connected to it",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"entity": map[string]interface{}{
+						"type":        "string",
+						"description": "ID or title of the knowledge entity to start from",
+					},
+					"max_depth": map[string]interface{}{
+						"type":        "integer",
+						"description": "Maximum number of relationship hops to follow",
+						"default":     2,
+						"minimum":     1,
+						"maximum":     maxExploreDepth,
+					},
+					"relationship_types": map[string]interface{}{
+						"type":        "array",
+						"description": "Relationship types to follow; all types when omitted",
+						"items": map[string]interface{}{
+							"type": "string",
+							"enum": knowledgeRelationshipTypes,
+						},
+					},
+				},
+				"required": []string{"entity"},
+			},
+		},
+		{
+			Name:        "record_decision",
+			Description: "Record an engineering decision made outside the tracked platforms, attributed to you",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"title": map[string]interface{}{
+						"type":        "string",
+						"description": "Short title of the decision",
+					},
+					"decision": map[string]interface{}{
+						"type":        "string",
+						"description": "What was decided",
+					},
+					"rationale": map[string]interface{}{
+						"type":        "string",
+						"description": "Why it was decided",
+					},
+					"alternatives": map[string]interface{}{
+						"type":        "array",
+						"description": "Alternatives that were considered",
+						"items": map[string]interface{}{
+							"type": "string",
+						},
+					},
+					"consequences": map[string]interface{}{
+						"type":        "array",
+						"description": "Expected consequences of the decision",
+						"items": map[string]interface{}{
+							"type": "string",
+						},
+					},
+					"status": map[string]interface{}{
+						"type":        "string",
+						"description": "Decision status",
+						"enum":        decisionStatuses,
+						"default":     "active",
+					},
+				},
+				"required": []string{"title", "decision"},
+			},
+		},
+		{
+			Name:        "annotate_file_context",
+			Description: "Record why a file changed or what it is for, attributed to you",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"file_path": map[string]interface{}{
+						"type":        "string",
+						"description": "Path to the file relative to repository root",
+					},
+					"note": map[string]interface{}{
+						"type":        "string",
+						"description": "Context to record for the file",
+					},
+					"change_reason": map[string]interface{}{
+						"type":        "string",
+						"description": "Reason for the most recent change",
+					},
+					"related_decisions": map[string]interface{}{
+						"type":        "array",
+						"description": "IDs of decisions related to the file",
+						"items": map[string]interface{}{
+							"type": "string",
+						},
+					},
+				},
+				"required": []string{"file_path", "note"},
+			},
+		},
+		{
+			Name:        "link_entities",
+			Description: "Create or update a relationship between two knowledge entities",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"source": map[string]interface{}{
+						"type":        "string",
+						"description": "ID or title of the source entity",
+					},
+					"target": map[string]interface{}{
+						"type":        "string",
+						"description": "ID or title of the target entity",
+					},
+					"relationship_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of relationship",
+						"enum":        knowledgeRelationshipTypes,
+					},
+					"strength": map[string]interface{}{
+						"type":        "number",
+						"description": "Relationship strength between 0 and 1",
+						"default":     1.0,
+					},
+				},
+				"required": []string{"source", "target", "relationship_type"},
+			},
+		},
+		{
+			Name:        "get_ingestion_status",
+			Description: "Report when each connected platform was last synced and any ingestion errors, to judge how fresh the project's context is",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "request_sync",
+			Description: "Start syncing the project's integrations, or a single data source, to refresh its context",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"data_source_id": map[string]interface{}{
+						"type":        "string",
+						"description": "Data source to sync; syncs every integration of the project when omitted",
+					},
+				},
+			},
+		},
 	}
 
+	// Ingestion tools are only offered when the server can sync
+	if m.ingestion == nil {
+		available := tools[:0]
+		for _, tool := range tools {
+			if !isIngestionTool(tool.Name) {
+				available = append(available, tool)
+			}
+		}
+		tools = available
+	}
+
+	// Every tool is project-scoped, accepts an explicit project and returns
+	// structured data alongside its markdown
+	outputSchemas := toolOutputSchemas()
+	for i, tool := range tools {
+		tools[i].OutputSchema = outputSchemas[tool.Name]
+		if properties, ok := tool.InputSchema["properties"].(map[string]interface{}); ok {
+			properties["project_id"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Project to query; defaults to the project selected for the connection",
+			}
+			if containsString(packedResultTools, tool.Name) {
+				properties["max_tokens"] = map[string]interface{}{
+					"type":        "integer",
+					"description": "Token budget for the result; the most relevant items that fit are returned and the rest are listed as omitted",
+				}
+			}
+		}
+	}
+
+	// Paged results carry their project in the cursor
+	tools = append(tools, MCPTool{
+		Name:        "continue_result",
+		Description: "Fetch the next part of a tool result that was too large for a single response",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Cursor from the previous part of the result",
+				},
+			},
+			"required": []string{"cursor"},
+		},
+	})
+
 	result := map[string]interface{}{
 		"tools": tools,
 	}
@@ -298,7 +526,11 @@ func (m *MCPServer) handleToolsCall(ctx context.Context, req *JSONRPCRequest) *J
 		arguments = make(map[string]interface{})
 	}
 
-	// Execute the tool
+	// Paths inside the client's workspace folders are stored repo-relative
+	m.rewriteFilePathArgument(ctx, arguments)
+
+	// Execute the tool, reporting progress if the client asked for it
+	ctx = withProgress(ctx, req.Params)
 	result, err := m.callTool(ctx, name, arguments)
 	if err != nil {
 		return &JSONRPCResponse{
@@ -318,13 +550,26 @@ func (m *MCPServer) handleToolsCall(ctx context.Context, req *JSONRPCRequest) *J
 	}
 }
 
-// callTool executes a specific tool by name
+// callTool executes a specific tool by name, enforcing usage quotas and metering the call
 func (m *MCPServer) callTool(ctx context.Context, name string, arguments map[string]interface{}) (*MCPToolResult, error) {
-	m.logger.Info("Executing MCP tool", map[string]interface{}{
+	m.loggerFor(ctx).Info("Executing MCP tool", map[string]interface{}{
 		"tool":      name,
 		"arguments": arguments,
 	})
 
+	// Tools charge the call to their project when they resolve it
+	ctx, meter := m.startToolCall(ctx, name)
+	result, err := m.dispatchTool(ctx, name, arguments)
+	meter.finish(ctx, result, err)
+	return result, err
+}
+
+// dispatchTool runs the named tool
+func (m *MCPServer) dispatchTool(ctx context.Context, name string, arguments map[string]interface{}) (*MCPToolResult, error) {
+	if isIngestionTool(name) && m.ingestion == nil {
+		return nil, fmt.Errorf("unknown tool: %s", name)
+	}
+
 	switch name {
 	case "search_project_knowledge":
 		return m.searchProjectKnowledge(ctx, arguments)
@@ -336,6 +581,20 @@ func (m *MCPServer) callTool(ctx context.Context, name string, arguments map[str
 		return m.listRecentArchitectureDiscussions(ctx, arguments)
 	case "explain_why_code_exists":
 		return m.explainWhyCodeExists(ctx, arguments)
+	case "explore_related_knowledge":
+		return m.exploreRelatedKnowledge(ctx, arguments)
+	case "record_decision":
+		return m.recordDecision(ctx, arguments)
+	case "annotate_file_context":
+		return m.annotateFileContext(ctx, arguments)
+	case "link_entities":
+		return m.linkEntities(ctx, arguments)
+	case "get_ingestion_status":
+		return m.getIngestionStatus(ctx, arguments)
+	case "request_sync":
+		return m.requestSync(ctx, arguments)
+	case "continue_result":
+		return m.continueResult(ctx, arguments)
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
 	}
@@ -343,19 +602,24 @@ func (m *MCPServer) callTool(ctx context.Context, name string, arguments map[str
 
 // sendError sends a JSON-RPC error response
 func (m *MCPServer) sendError(w http.ResponseWriter, code int, message string, data interface{}, id interface{}) {
-	response := &JSONRPCResponse{
+	response := newJSONRPCErrorResponse(code, message, id)
+	response.Error.Data = data
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // JSON-RPC errors are still HTTP 200
+	json.NewEncoder(w).Encode(response)
+}
+
+// newJSONRPCErrorResponse builds a JSON-RPC error response
+func newJSONRPCErrorResponse(code int, message string, id interface{}) *JSONRPCResponse {
+	return &JSONRPCResponse{
 		JSONRPC: "2.0",
 		Error: &JSONRPCError{
 			Code:    code,
 			Message: message,
-			Data:    data,
 		},
 		ID: id,
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK) // JSON-RPC errors are still HTTP 200
-	json.NewEncoder(w).Encode(response)
 }
 
 // Helper function to get string parameter with default
@@ -397,4 +661,14 @@ func getStringArrayParam(params map[string]interface{}, key string) []string {
 		return result
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,428 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// MCPSessionHeader carries the session ID for the Streamable HTTP transport
+	MCPSessionHeader = "Mcp-Session-Id"
+
+	// defaultMCPSessionTTL is how long an idle session is kept before it expires
+	defaultMCPSessionTTL = 30 * time.Minute
+
+	// maxMCPSessionEvents bounds the per-session history kept for stream resumption
+	maxMCPSessionEvents = 500
+)
+
+// MCPSessionEvent is a server-to-client message delivered on a session stream
+type MCPSessionEvent struct {
+	ID   string
+	Data []byte
+}
+
+// MCPSession tracks a single client connection and its server-to-client event stream
+type MCPSession struct {
+	ID        string
+	UserID    string
+	CreatedAt time.Time
+
+	mu          sync.Mutex
+	lastActive  time.Time
+	nextEventID int64
+	events      []MCPSessionEvent
+	subscribers map[chan MCPSessionEvent]struct{}
+	logLevel    string
+	closed      bool
+
+	// localClient is set when the client shares this machine's filesystem
+	localClient bool
+
+	clientCapabilities map[string]interface{}
+	nextRequestID      int64
+	pendingRequests    map[string]chan mcpClientReply
+	workspace          *mcpWorkspace
+	workspaceReady     chan struct{}
+}
+
+// mcpClientReply is the client's answer to a server-initiated request
+type mcpClientReply struct {
+	result json.RawMessage
+	err    error
+}
+
+// NewMCPSession creates a session with the given ID
+func NewMCPSession(id string) *MCPSession {
+	now := time.Now()
+	return &MCPSession{
+		ID:              id,
+		CreatedAt:       now,
+		lastActive:      now,
+		subscribers:     make(map[chan MCPSessionEvent]struct{}),
+		pendingRequests: make(map[string]chan mcpClientReply),
+	}
+}
+
+// Touch marks the session as recently used
+func (s *MCPSession) Touch() {
+	s.mu.Lock()
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+}
+
+// LastActive returns when the session was last used
+func (s *MCPSession) LastActive() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastActive
+}
+
+// SetLogLevel sets the minimum level of log messages forwarded to the client
+func (s *MCPSession) SetLogLevel(level string) {
+	s.mu.Lock()
+	s.logLevel = level
+	s.mu.Unlock()
+}
+
+// LogLevel returns the level set with logging/setLevel, or "" if logging is off
+func (s *MCPSession) LogLevel() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logLevel
+}
+
+// SetClientCapabilities records the capabilities the client declared in initialize
+func (s *MCPSession) SetClientCapabilities(capabilities map[string]interface{}) {
+	s.mu.Lock()
+	s.clientCapabilities = capabilities
+	s.mu.Unlock()
+}
+
+// ClientSupports reports whether the client declared the named capability
+func (s *MCPSession) ClientSupports(capability string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.clientCapabilities[capability]
+	return ok
+}
+
+// expectWorkspace makes workspace lookups wait until the client's roots have been resolved
+func (s *MCPSession) expectWorkspace() {
+	s.mu.Lock()
+	if s.workspaceReady == nil {
+		s.workspaceReady = make(chan struct{})
+	}
+	s.mu.Unlock()
+}
+
+// setWorkspace stores the workspace resolved from the client's roots and
+// releases anyone waiting for it
+func (s *MCPSession) setWorkspace(workspace *mcpWorkspace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.workspace = workspace
+	if s.workspaceReady != nil {
+		select {
+		case <-s.workspaceReady:
+		default:
+			close(s.workspaceReady)
+		}
+	}
+}
+
+// waitForWorkspace returns the resolved workspace, waiting up to timeout while
+// the client's roots are still being resolved. It returns nil when no
+// workspace folder matched a project.
+func (s *MCPSession) waitForWorkspace(ctx context.Context, timeout time.Duration) *mcpWorkspace {
+	s.mu.Lock()
+	ready := s.workspaceReady
+	s.mu.Unlock()
+
+	if ready != nil {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-ready:
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.workspace
+}
+
+// Request sends a JSON-RPC request to the client over the session stream and
+// waits for the client to answer it
+func (s *MCPSession) Request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	s.mu.Lock()
+	s.nextRequestID++
+	id := "ck-" + strconv.FormatInt(s.nextRequestID, 10)
+	reply := make(chan mcpClientReply, 1)
+	s.pendingRequests[id] = reply
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pendingRequests, id)
+		s.mu.Unlock()
+	}()
+
+	request := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+	}
+	if params != nil {
+		request["params"] = params
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if err := s.Send(data); err != nil {
+		return nil, err
+	}
+
+	select {
+	case r := <-reply:
+		return r.result, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// deliverResponse hands a client response to the server-initiated request
+// waiting for it. It reports false when no such request is pending.
+func (s *MCPSession) deliverResponse(id string, result, rpcError json.RawMessage) bool {
+	s.mu.Lock()
+	reply, ok := s.pendingRequests[id]
+	delete(s.pendingRequests, id)
+	s.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	if len(rpcError) > 0 && !bytes.Equal(rpcError, []byte("null")) {
+		var clientErr JSONRPCError
+		if err := json.Unmarshal(rpcError, &clientErr); err != nil {
+			reply <- mcpClientReply{err: fmt.Errorf("invalid error from client: %w", err)}
+		} else {
+			reply <- mcpClientReply{err: fmt.Errorf("client returned error %d: %s", clientErr.Code, clientErr.Message)}
+		}
+		return true
+	}
+
+	reply <- mcpClientReply{result: result}
+	return true
+}
+
+// Notify sends a JSON-RPC notification to the client over the session stream
+func (s *MCPSession) Notify(method string, params interface{}) error {
+	notification := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		notification["params"] = params
+	}
+
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	return s.Send(data)
+}
+
+// Send queues a raw JSON-RPC message on the session stream. Messages are kept
+// in a bounded history so a reconnecting client can resume from Last-Event-ID.
+func (s *MCPSession) Send(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("session %s is closed", s.ID)
+	}
+
+	s.nextEventID++
+	event := MCPSessionEvent{
+		ID:   strconv.FormatInt(s.nextEventID, 10),
+		Data: data,
+	}
+
+	s.events = append(s.events, event)
+	if len(s.events) > maxMCPSessionEvents {
+		s.events = s.events[len(s.events)-maxMCPSessionEvents:]
+	}
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Slow consumer; it can recover the event by resuming from its last ID
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a listener for new events. Events after lastEventID that
+// are still in the history are returned for replay. The returned function must
+// be called to release the subscription.
+func (s *MCPSession) Subscribe(lastEventID string) ([]MCPSessionEvent, <-chan MCPSessionEvent, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var replay []MCPSessionEvent
+	if lastEventID != "" {
+		if last, err := strconv.ParseInt(lastEventID, 10, 64); err == nil {
+			for _, event := range s.events {
+				if id, _ := strconv.ParseInt(event.ID, 10, 64); id > last {
+					replay = append(replay, event)
+				}
+			}
+		}
+	}
+
+	ch := make(chan MCPSessionEvent, 64)
+	if s.closed {
+		close(ch)
+		return replay, ch, func() {}
+	}
+	s.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if _, ok := s.subscribers[ch]; ok {
+				delete(s.subscribers, ch)
+				close(ch)
+			}
+		})
+	}
+
+	return replay, ch, unsubscribe
+}
+
+// Close terminates the session and ends all open streams
+func (s *MCPSession) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// MCPSessionManager keeps track of active MCP sessions
+type MCPSessionManager struct {
+	mu       sync.RWMutex
+	sessions map[string]*MCPSession
+	ttl      time.Duration
+}
+
+// NewMCPSessionManager creates a session manager that expires idle sessions after ttl
+func NewMCPSessionManager(ttl time.Duration) *MCPSessionManager {
+	if ttl <= 0 {
+		ttl = defaultMCPSessionTTL
+	}
+	return &MCPSessionManager{
+		sessions: make(map[string]*MCPSession),
+		ttl:      ttl,
+	}
+}
+
+// Create starts a new session with a random ID owned by userID
+func (sm *MCPSessionManager) Create(userID string) *MCPSession {
+	sm.cleanupExpired()
+
+	session := NewMCPSession(generateID())
+	session.UserID = userID
+
+	sm.mu.Lock()
+	sm.sessions[session.ID] = session
+	sm.mu.Unlock()
+
+	return session
+}
+
+// Get returns an active session by ID
+func (sm *MCPSessionManager) Get(id string) (*MCPSession, bool) {
+	sm.mu.RLock()
+	session, ok := sm.sessions[id]
+	sm.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	if time.Since(session.LastActive()) > sm.ttl {
+		sm.Delete(id)
+		return nil, false
+	}
+
+	session.Touch()
+	return session, true
+}
+
+// Delete terminates and removes a session
+func (sm *MCPSessionManager) Delete(id string) bool {
+	sm.mu.Lock()
+	session, ok := sm.sessions[id]
+	delete(sm.sessions, id)
+	sm.mu.Unlock()
+
+	if ok {
+		session.Close()
+	}
+	return ok
+}
+
+// cleanupExpired removes sessions that have been idle longer than the TTL
+func (sm *MCPSessionManager) cleanupExpired() {
+	sm.mu.Lock()
+	var expired []*MCPSession
+	for id, session := range sm.sessions {
+		if time.Since(session.LastActive()) > sm.ttl {
+			expired = append(expired, session)
+			delete(sm.sessions, id)
+		}
+	}
+	sm.mu.Unlock()
+
+	for _, session := range expired {
+		session.Close()
+	}
+}
+
+type mcpSessionContextKey struct{}
+
+// WithMCPSession returns a context carrying the MCP session
+func WithMCPSession(ctx context.Context, session *MCPSession) context.Context {
+	return context.WithValue(ctx, mcpSessionContextKey{}, session)
+}
+
+// MCPSessionFromContext returns the MCP session for the current request, if any
+func MCPSessionFromContext(ctx context.Context) (*MCPSession, bool) {
+	session, ok := ctx.Value(mcpSessionContextKey{}).(*MCPSession)
+	return session, ok && session != nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxStdioMessageSize caps a single newline-delimited JSON-RPC message read from stdin
+const maxStdioMessageSize = 10 * 1024 * 1024
+
+// MCPMessageHandler handles a single raw JSON-RPC message and returns the raw response.
+// A nil response means nothing should be written back to the client.
+type MCPMessageHandler interface {
+	HandleMessage(ctx context.Context, message []byte) ([]byte, error)
+}
+
+// ServeMCPStdio runs an MCP message handler over newline-delimited JSON-RPC.
+// Messages are read from in until EOF or context cancellation and handled
+// concurrently; every response is written to out as a single line. The connection is treated as
+// one MCP session, so server-to-client notifications are interleaved on out.
+func ServeMCPStdio(ctx context.Context, handler MCPMessageHandler, in io.Reader, out io.Writer, logger Logger) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxStdioMessageSize)
+
+	writer := &stdioWriter{out: out}
+
+	session := NewMCPSession(generateID())
+	session.localClient = true
+	ctx = WithMCPSession(ctx, session)
+
+	_, events, unsubscribe := session.Subscribe("")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range events {
+			if err := writer.writeLine(event.Data); err != nil {
+				logger.Error("Failed to write MCP notification", err, nil)
+			}
+		}
+	}()
+	defer func() {
+		unsubscribe()
+		session.Close()
+		<-done
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each message is handled in its own goroutine so a long-running tool call
+	// does not block later messages, such as a cancellation for that same call
+	var (
+		inflight sync.WaitGroup
+		errOnce  sync.Once
+		writeErr error
+	)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		// The scanner reuses its buffer, so the message must be copied
+		message := append([]byte(nil), line...)
+
+		inflight.Add(1)
+		go func() {
+			defer inflight.Done()
+
+			// A failed message still gets an answer, or its requests would never complete
+			response, err := handler.HandleMessage(ctx, message)
+			if err != nil {
+				logger.Error("Failed to handle MCP stdio message", err, nil)
+				response = handlerErrorResponse(message, err)
+			}
+			if response == nil {
+				return
+			}
+
+			if err := writer.writeLine(response); err != nil {
+				errOnce.Do(func() {
+					writeErr = fmt.Errorf("failed to write MCP response: %w", err)
+					cancel()
+				})
+			}
+		}()
+	}
+
+	inflight.Wait()
+	if writeErr != nil {
+		return writeErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return scanner.Err()
+}
+
+// handlerErrorResponse answers each request in a message, or in a batch, with
+// an internal error carrying err. Notifications and client responses get
+// nothing, as does a message that cannot be parsed.
+func handlerErrorResponse(message []byte, err error) []byte {
+	isBatch := len(message) > 0 && message[0] == '['
+	batch := []json.RawMessage{message}
+	if isBatch {
+		if json.Unmarshal(message, &batch) != nil {
+			return nil
+		}
+	}
+
+	var responses []*JSONRPCResponse
+	for _, item := range batch {
+		var raw rawJSONRPCMessage
+		if json.Unmarshal(item, &raw) != nil || raw.Method == "" || len(raw.ID) == 0 {
+			continue
+		}
+		var id interface{}
+		if json.Unmarshal(raw.ID, &id) != nil {
+			continue
+		}
+		responses = append(responses, newJSONRPCErrorResponse(InternalError, err.Error(), id))
+	}
+
+	if len(responses) == 0 {
+		return nil
+	}
+	var response []byte
+	if isBatch {
+		response, _ = json.Marshal(responses)
+	} else {
+		response, _ = json.Marshal(responses[0])
+	}
+	return response
+}
+
+// stdioWriter serializes responses and notifications so lines never interleave
+type stdioWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *stdioWriter) writeLine(data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	line := make([]byte, 0, len(data)+1)
+	line = append(line, data...)
+	line = append(line, '\n')
+
+	_, err := w.out.Write(line)
+	return err
+}
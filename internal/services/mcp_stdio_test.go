@@ -0,0 +1,144 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeStdioResponses(t *testing.T, output string) []JSONRPCResponse {
+	var responses []JSONRPCResponse
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp JSONRPCResponse
+		require.NoError(t, json.Unmarshal([]byte(line), &resp), "each response must be a single JSON line")
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// responsesByID indexes stdio responses, which are written in completion order
+func responsesByID(responses []JSONRPCResponse) map[interface{}]JSONRPCResponse {
+	byID := make(map[interface{}]JSONRPCResponse, len(responses))
+	for _, resp := range responses {
+		byID[resp.ID] = resp
+	}
+	return byID
+}
+
+func TestServeMCPStdio_DispatchesNewlineDelimitedRequests(t *testing.T) {
+	server := NewMCPServer(nil, nil, nil, nil, &SimpleLogger{})
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"does/not/exist"}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	err := ServeMCPStdio(context.Background(), server, strings.NewReader(input), &out, &SimpleLogger{})
+	require.NoError(t, err)
+
+	responses := responsesByID(decodeStdioResponses(t, out.String()))
+	require.Len(t, responses, 3)
+
+	assert.Nil(t, responses[float64(1)].Error)
+
+	assert.Nil(t, responses[float64(2)].Error)
+	tools := responses[float64(2)].Result.(map[string]interface{})["tools"].([]interface{})
+	assert.NotEmpty(t, tools)
+
+	require.NotNil(t, responses[float64(3)].Error)
+	assert.Equal(t, MethodNotFound, responses[float64(3)].Error.Code)
+}
+
+func TestServeMCPStdio_ReportsParseAndVersionErrors(t *testing.T) {
+	server := NewMCPServer(nil, nil, nil, nil, &SimpleLogger{})
+
+	input := "not json\n" + `{"jsonrpc":"1.0","id":7,"method":"tools/list"}` + "\n"
+
+	var out bytes.Buffer
+	err := ServeMCPStdio(context.Background(), server, strings.NewReader(input), &out, &SimpleLogger{})
+	require.NoError(t, err)
+
+	responses := responsesByID(decodeStdioResponses(t, out.String()))
+	require.Len(t, responses, 2)
+	assert.Equal(t, ParseError, responses[nil].Error.Code)
+	assert.Equal(t, InvalidRequest, responses[float64(7)].Error.Code)
+}
+
+// failingMessageHandler fails every message, as a remote client does when the server is unreachable
+type failingMessageHandler struct{}
+
+func (failingMessageHandler) HandleMessage(ctx context.Context, message []byte) ([]byte, error) {
+	return nil, errors.New("remote server returned 502 Bad Gateway")
+}
+
+func TestServeMCPStdio_AnswersRequestsWhenTheHandlerFails(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`[{"jsonrpc":"2.0","id":"b1","method":"tools/list"},{"jsonrpc":"2.0","method":"notifications/cancelled"}]`,
+	}, "\n")
+
+	var out bytes.Buffer
+	err := ServeMCPStdio(context.Background(), failingMessageHandler{}, strings.NewReader(input), &out, &SimpleLogger{})
+	require.NoError(t, err)
+
+	var responses []JSONRPCResponse
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if strings.HasPrefix(line, "[") {
+			var batch []JSONRPCResponse
+			require.NoError(t, json.Unmarshal([]byte(line), &batch))
+			responses = append(responses, batch...)
+			continue
+		}
+		responses = append(responses, decodeStdioResponses(t, line)...)
+	}
+
+	byID := responsesByID(responses)
+	require.Len(t, byID, 2, "notifications are not answered")
+	for _, id := range []interface{}{float64(1), "b1"} {
+		require.NotNil(t, byID[id].Error, "request %v", id)
+		assert.Equal(t, InternalError, byID[id].Error.Code)
+		assert.Contains(t, byID[id].Error.Message, "502 Bad Gateway")
+	}
+}
+
+func TestMCPRemoteClient_ForwardsMessagesWithBearerToken(t *testing.T) {
+	var gotAuth, gotPath string
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		body, _ := io.ReadAll(r.Body)
+		NewMCPServer(nil, nil, nil, nil, &SimpleLogger{}).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body)))
+	}))
+	defer remote.Close()
+
+	client := NewMCPRemoteClient(remote.URL+"/", "test-token", "")
+
+	var out bytes.Buffer
+	input := `{"jsonrpc":"2.0","id":"a","method":"initialize"}` + "\n"
+	err := ServeMCPStdio(context.Background(), client, strings.NewReader(input), &out, &SimpleLogger{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer test-token", gotAuth)
+	assert.Equal(t, "/mcp", gotPath)
+
+	responses := decodeStdioResponses(t, out.String())
+	require.Len(t, responses, 1)
+	assert.Equal(t, "a", responses[0].ID)
+	assert.Nil(t, responses[0].Error)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens a language model would see for a piece of text
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// defaultTokenizer backs EstimateTokenCount and new response optimizers
+var defaultTokenizer Tokenizer = NewBPETokenizer()
+
+// BPETokenizer estimates token counts for cl100k-style byte pair encodings
+// without shipping a vocabulary. Text is split the way the encoder's
+// pre-tokenizer splits it, so no estimate merges across a word, number or
+// punctuation boundary, and each piece is then charged for the merges
+// typical of its kind. Plug in an exact encoder through Tokenizer when one
+// is available.
+type BPETokenizer struct{}
+
+// NewBPETokenizer creates the default tokenizer
+func NewBPETokenizer() *BPETokenizer {
+	return &BPETokenizer{}
+}
+
+// preToken kinds, following the alternatives of the cl100k pre-tokenizer
+const (
+	preTokenContraction = iota
+	preTokenWord
+	preTokenNumber
+	preTokenPunctuation
+	preTokenSpace
+)
+
+// CountTokens estimates the number of tokens in text
+func (b *BPETokenizer) CountTokens(text string) int {
+	tokens := 0
+	for len(text) > 0 {
+		size, kind := nextPreToken(text)
+		tokens += preTokenCost(text[:size], kind)
+		text = text[size:]
+	}
+	return tokens
+}
+
+// nextPreToken returns the byte length and kind of the piece text starts with
+func nextPreToken(text string) (int, int) {
+	// Contractions: 's 't 're 've 'm 'll 'd
+	if text[0] == '\'' && len(text) > 1 {
+		lower := strings.ToLower(text[1:min(len(text), 3)])
+		for _, suffix := range []string{"re", "ve", "ll", "s", "t", "m", "d"} {
+			if strings.HasPrefix(lower, suffix) {
+				return 1 + len(suffix), preTokenContraction
+			}
+		}
+	}
+
+	first, firstSize := utf8.DecodeRuneInString(text)
+
+	// Words, with at most one leading character that is not a letter, digit or line break
+	start := 0
+	if !unicode.IsLetter(first) && !unicode.IsNumber(first) && first != '\r' && first != '\n' {
+		start = firstSize
+	}
+	if end := scanRunes(text, start, unicode.IsLetter); end > start {
+		return end, preTokenWord
+	}
+
+	// Numbers, in groups of up to three digits
+	if unicode.IsNumber(first) {
+		end := 0
+		for digits := 0; digits < 3 && end < len(text); digits++ {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !unicode.IsNumber(r) {
+				break
+			}
+			end += size
+		}
+		return end, preTokenNumber
+	}
+
+	// Punctuation runs, with an optional leading space and trailing line breaks
+	start = 0
+	if first == ' ' {
+		start = 1
+	}
+	if end := scanRunes(text, start, isPunctuationRune); end > start {
+		return scanRunes(text, end, isLineBreak), preTokenPunctuation
+	}
+
+	// Whitespace: a run ending in line breaks, or a run that leaves its last
+	// space to the word that follows
+	end := scanRunes(text, 0, unicode.IsSpace)
+	if lastBreak := strings.LastIndexAny(text[:end], "\r\n"); lastBreak >= 0 {
+		return lastBreak + 1, preTokenSpace
+	}
+	if end < len(text) && end > 1 {
+		_, size := utf8.DecodeLastRuneInString(text[:end])
+		end -= size
+	}
+	return end, preTokenSpace
+}
+
+// preTokenCost estimates how many tokens byte pair merges leave of a piece
+func preTokenCost(piece string, kind int) int {
+	switch kind {
+	case preTokenWord:
+		return wordTokenCost(piece)
+	case preTokenPunctuation:
+		// Common runs such as "**", "();" and "```" are single tokens
+		return max(1, (len(strings.TrimSpace(piece))+2)/3)
+	case preTokenSpace:
+		// Indentation and blank lines merge into few tokens
+		return max(1, (len(piece)+7)/8)
+	default:
+		return 1
+	}
+}
+
+// wordTokenCost charges a word per case-delimited segment, so identifiers
+// such as getUserFromContext cost one token per part as they do in BPE
+// vocabularies. Letters outside ASCII are mostly split into their own tokens.
+func wordTokenCost(word string) int {
+	tokens := 0
+	segment := 0
+	var previous rune
+	for i, r := range word {
+		if r >= utf8.RuneSelf {
+			tokens++
+			tokens += segmentTokenCost(segment)
+			segment = 0
+		} else if i > 0 && unicode.IsUpper(r) && unicode.IsLower(previous) {
+			tokens += segmentTokenCost(segment)
+			segment = 1
+		} else {
+			segment++
+		}
+		previous = r
+	}
+	tokens += segmentTokenCost(segment)
+	return max(1, tokens)
+}
+
+// segmentTokenCost charges a run of ASCII letters, counting a leading space;
+// common words of up to seven characters are a single token
+func segmentTokenCost(letters int) int {
+	return (letters + 6) / 7
+}
+
+// scanRunes returns the end of the run of runes matching match that starts at start
+func scanRunes(text string, start int, match func(rune) bool) int {
+	end := start
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !match(r) {
+			break
+		}
+		end += size
+	}
+	return end
+}
+
+func isPunctuationRune(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isLineBreak(r rune) bool {
+	return r == '\r' || r == '\n'
+}
@@ -3,14 +3,30 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/DevAnuragT/context_keeper/internal/models"
 )
 
+// packedResultTools lists the tools whose results can be packed into a max_tokens budget
+var packedResultTools = []string{
+	"search_project_knowledge",
+	"get_context_for_file",
+	"get_decision_history",
+	"list_recent_architecture_discussions",
+	"explain_why_code_exists",
+	"explore_related_knowledge",
+}
+
 // searchProjectKnowledge implements the search_project_knowledge MCP tool
 func (m *MCPServer) searchProjectKnowledge(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
 	query := getStringParam(arguments, "query", "")
 	if query == "" {
 		return &MCPToolResult{
@@ -36,7 +52,7 @@ func (m *MCPServer) searchProjectKnowledge(ctx context.Context, arguments map[st
 	}
 
 	// Execute search
-	results, err := m.knowledgeGraph.SearchKnowledge(ctx, kgQuery)
+	results, err := m.knowledgeGraph.SearchKnowledgeByProject(ctx, projectID, kgQuery)
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -48,11 +64,12 @@ func (m *MCPServer) searchProjectKnowledge(ctx context.Context, arguments map[st
 	}
 
 	// Format results
-	var content strings.Builder
-	content.WriteString(fmt.Sprintf("# Search Results for: %s\n\n", query))
-	content.WriteString(fmt.Sprintf("Found %d results:\n\n", len(results)))
+	header := fmt.Sprintf("# Search Results for: %s\n\n", query)
+	header += fmt.Sprintf("Found %d results:\n\n", len(results))
 
+	items := make([]PackItem, 0, len(results))
 	for i, result := range results {
+		var content strings.Builder
 		content.WriteString(fmt.Sprintf("## %d. %s\n", i+1, result.Entity.Title))
 		content.WriteString(fmt.Sprintf("**Type:** %s\n", result.Entity.EntityType))
 		if result.Entity.PlatformSource != nil {
@@ -72,21 +89,41 @@ func (m *MCPServer) searchProjectKnowledge(ctx context.Context, arguments map[st
 		}
 		
 		content.WriteString("---\n\n")
-	}
 
-	// Optimize response for token limits
-	optimizedContent := m.responseOptimizer.OptimizeResponse(content.String(), "search_results")
+		items = append(items, PackItem{
+			ID:         packItemID("result", i),
+			Title:      result.Entity.Title,
+			Text:       content.String(),
+			Rank:       i,
+			Similarity: result.Similarity,
+			Timestamp:  result.Entity.CreatedAt,
+		})
+	}
 
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: optimizedContent,
-		}},
-	}, nil
+	result, packed := m.packedToolResult(ctx, projectID, arguments, header, items, "")
+	var packedResults []models.SearchResult
+	for i, searchResult := range results {
+		if packed.Includes(packItemID("result", i)) {
+			packedResults = append(packedResults, searchResult)
+		}
+	}
+	structured := map[string]interface{}{
+		"project_id": projectID,
+		"query":      query,
+		"results":    structuredSearchResults(packedResults),
+	}
+	addPackingSummary(structured, packed)
+	result.StructuredContent = structured
+	return result, nil
 }
 
 // getContextForFile implements the get_context_for_file MCP tool
 func (m *MCPServer) getContextForFile(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
 	filePath := getStringParam(arguments, "file_path", "")
 	if filePath == "" {
 		return &MCPToolResult{
@@ -101,7 +138,7 @@ func (m *MCPServer) getContextForFile(ctx context.Context, arguments map[string]
 	includeHistory := getBoolParam(arguments, "include_history", true)
 
 	// Get file context from knowledge graph
-	fileContext, err := m.knowledgeGraph.GetContextForFile(ctx, filePath)
+	fileContext, err := m.knowledgeGraph.GetContextForFileByProject(ctx, projectID, filePath)
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -113,79 +150,125 @@ func (m *MCPServer) getContextForFile(ctx context.Context, arguments map[string]
 	}
 
 	// Format response
-	var content strings.Builder
-	content.WriteString(fmt.Sprintf("# Context for File: %s\n\n", filePath))
+	header := fmt.Sprintf("# Context for File: %s\n\n", filePath)
+	var items []PackItem
 
 	// File context history
-	if len(fileContext.FileContexts) > 0 {
-		content.WriteString("## File Context History\n\n")
-		for i, fc := range fileContext.FileContexts {
-			content.WriteString(fmt.Sprintf("### %d. %s\n", i+1, fc.CreatedAt.Format("2006-01-02 15:04")))
-			if fc.ChangeReason != nil {
-				content.WriteString(fmt.Sprintf("**Change Reason:** %s\n", *fc.ChangeReason))
-			}
-			content.WriteString(fmt.Sprintf("**Discussion Context:** %s\n", fc.DiscussionContext))
-			if len(fc.Contributors) > 0 {
-				content.WriteString(fmt.Sprintf("**Contributors:** %s\n", strings.Join(fc.Contributors, ", ")))
-			}
-			if len(fc.RelatedDecisions) > 0 {
-				content.WriteString(fmt.Sprintf("**Related Decisions:** %s\n", strings.Join(fc.RelatedDecisions, ", ")))
-			}
-			content.WriteString("\n")
+	for i, fc := range fileContext.FileContexts {
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("### %d. %s\n", i+1, fc.CreatedAt.Format("2006-01-02 15:04")))
+		if fc.ChangeReason != nil {
+			content.WriteString(fmt.Sprintf("**Change Reason:** %s\n", *fc.ChangeReason))
+		}
+		content.WriteString(fmt.Sprintf("**Discussion Context:** %s\n", fc.DiscussionContext))
+		if len(fc.Contributors) > 0 {
+			content.WriteString(fmt.Sprintf("**Contributors:** %s\n", strings.Join(fc.Contributors, ", ")))
+		}
+		if len(fc.RelatedDecisions) > 0 {
+			content.WriteString(fmt.Sprintf("**Related Decisions:** %s\n", strings.Join(fc.RelatedDecisions, ", ")))
 		}
 		content.WriteString("\n")
+
+		items = append(items, PackItem{
+			ID:        packItemID("file_context", i),
+			Title:     "change of " + fc.CreatedAt.Format("2006-01-02 15:04"),
+			Section:   "File Context History",
+			Text:      content.String(),
+			Rank:      i,
+			Timestamp: fc.CreatedAt,
+		})
 	}
 
 	// Related entities
-	if len(fileContext.RelatedEntities) > 0 {
-		content.WriteString("## Related Entities\n\n")
-		for i, entity := range fileContext.RelatedEntities {
-			content.WriteString(fmt.Sprintf("### %d. %s (%s)\n", i+1, entity.Entity.Title, entity.Entity.EntityType))
-			content.WriteString(fmt.Sprintf("**Similarity:** %.2f\n", entity.Similarity))
-			if entity.Entity.Content != "" {
-				// Truncate content for readability
-				entityContent := entity.Entity.Content
-				if len(entityContent) > 200 {
-					entityContent = entityContent[:200] + "..."
-				}
-				content.WriteString(fmt.Sprintf("**Content:** %s\n", entityContent))
+	for i, entity := range fileContext.RelatedEntities {
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("### %d. %s (%s)\n", i+1, entity.Entity.Title, entity.Entity.EntityType))
+		content.WriteString(fmt.Sprintf("**Similarity:** %.2f\n", entity.Similarity))
+		if entity.Entity.Content != "" {
+			// Truncate content for readability
+			entityContent := entity.Entity.Content
+			if len(entityContent) > 200 {
+				entityContent = entityContent[:200] + "..."
 			}
-			content.WriteString("\n")
+			content.WriteString(fmt.Sprintf("**Content:** %s\n", entityContent))
 		}
 		content.WriteString("\n")
+
+		items = append(items, PackItem{
+			ID:         packItemID("related_entity", i),
+			Title:      entity.Entity.Title,
+			Section:    "Related Entities",
+			Text:       content.String(),
+			Rank:       i,
+			Similarity: entity.Similarity,
+			Timestamp:  entity.Entity.CreatedAt,
+		})
 	}
 
 	// Related decisions
-	if len(fileContext.RelatedDecisions) > 0 {
-		content.WriteString("## Related Decisions\n\n")
-		for i, decision := range fileContext.RelatedDecisions {
-			content.WriteString(fmt.Sprintf("### %d. %s\n", i+1, decision.Entity.Title))
-			content.WriteString(fmt.Sprintf("**Created:** %s\n", decision.Entity.CreatedAt.Format("2006-01-02 15:04")))
-			if decision.Entity.Content != "" {
-				content.WriteString(fmt.Sprintf("**Decision:** %s\n", decision.Entity.Content))
-			}
-			content.WriteString("\n")
+	for i, decision := range fileContext.RelatedDecisions {
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("### %d. %s\n", i+1, decision.Entity.Title))
+		content.WriteString(fmt.Sprintf("**Created:** %s\n", decision.Entity.CreatedAt.Format("2006-01-02 15:04")))
+		if decision.Entity.Content != "" {
+			content.WriteString(fmt.Sprintf("**Decision:** %s\n", decision.Entity.Content))
 		}
+		content.WriteString("\n")
+
+		items = append(items, PackItem{
+			ID:         packItemID("related_decision", i),
+			Title:      decision.Entity.Title,
+			Section:    "Related Decisions",
+			Text:       content.String(),
+			Rank:       i,
+			Similarity: decision.Similarity,
+			Timestamp:  decision.Entity.CreatedAt,
+		})
 	}
 
+	footer := ""
 	if includeHistory {
 		// Add note about history inclusion
-		content.WriteString("\n---\n*Note: Historical context and related PRs are included in the above information.*\n")
+		footer = "\n---\n*Note: Historical context and related PRs are included in the above information.*\n"
 	}
 
-	// Optimize response for token limits
-	optimizedContent := m.responseOptimizer.OptimizeResponse(content.String(), "file_context")
-
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: optimizedContent,
-		}},
-	}, nil
+	result, packed := m.packedToolResult(ctx, projectID, arguments, header, items, footer)
+	var fileContexts []models.FileContextHistory
+	for i, fc := range fileContext.FileContexts {
+		if packed.Includes(packItemID("file_context", i)) {
+			fileContexts = append(fileContexts, fc)
+		}
+	}
+	var relatedEntities, relatedDecisions []models.SearchResult
+	for i, entity := range fileContext.RelatedEntities {
+		if packed.Includes(packItemID("related_entity", i)) {
+			relatedEntities = append(relatedEntities, entity)
+		}
+	}
+	for i, decision := range fileContext.RelatedDecisions {
+		if packed.Includes(packItemID("related_decision", i)) {
+			relatedDecisions = append(relatedDecisions, decision)
+		}
+	}
+	structured := map[string]interface{}{
+		"project_id":        projectID,
+		"file_path":         filePath,
+		"file_contexts":     nonNilFileContexts(fileContexts),
+		"related_entities":  structuredSearchResults(relatedEntities),
+		"related_decisions": structuredSearchResults(relatedDecisions),
+	}
+	addPackingSummary(structured, packed)
+	result.StructuredContent = structured
+	return result, nil
 }
 
 // getDecisionHistory implements the get_decision_history MCP tool
 func (m *MCPServer) getDecisionHistory(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
 	target := getStringParam(arguments, "target", "")
 	if target == "" {
 		return &MCPToolResult{
@@ -200,7 +283,7 @@ func (m *MCPServer) getDecisionHistory(ctx context.Context, arguments map[string
 	limit := getIntParam(arguments, "limit", 20)
 
 	// Get decision history from knowledge graph
-	decisionHistory, err := m.knowledgeGraph.GetDecisionHistory(ctx, target)
+	decisionHistory, err := m.knowledgeGraph.GetDecisionHistoryByProject(ctx, projectID, target)
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -211,25 +294,33 @@ func (m *MCPServer) getDecisionHistory(ctx context.Context, arguments map[string
 		}, nil
 	}
 
+	// Limit results
+	decisions := decisionHistory.Decisions
+	if len(decisions) > limit {
+		decisions = decisions[:limit]
+	}
+
 	// Format response
-	var content strings.Builder
-	content.WriteString(fmt.Sprintf("# Decision History for: %s\n\n", target))
+	header := fmt.Sprintf("# Decision History for: %s\n\n", target)
+	footer := ""
+	var items []PackItem
 
 	if len(decisionHistory.Decisions) == 0 {
-		content.WriteString("No decisions found for this target.\n")
+		header += "No decisions found for this target.\n"
 	} else {
-		content.WriteString(fmt.Sprintf("Found %d decisions:\n\n", len(decisionHistory.Decisions)))
-
-		// Limit results
-		decisions := decisionHistory.Decisions
-		if len(decisions) > limit {
-			decisions = decisions[:limit]
-		}
+		header += fmt.Sprintf("Found %d decisions:\n\n", len(decisionHistory.Decisions))
 
 		for i, decision := range decisions {
+			var content strings.Builder
 			content.WriteString(fmt.Sprintf("## %d. %s\n", i+1, decision.Title))
 			content.WriteString(fmt.Sprintf("**Created:** %s\n", decision.CreatedAt.Format("2006-01-02 15:04")))
 			content.WriteString(fmt.Sprintf("**Status:** %s\n", decision.Status))
+			if decision.Authoritative {
+				content.WriteString("**Authoritative:** written decision record\n")
+			}
+			if len(decision.Supersedes) > 0 {
+				content.WriteString(fmt.Sprintf("**Supersedes:** %s\n", strings.Join(decision.Supersedes, ", ")))
+			}
 			content.WriteString(fmt.Sprintf("**Platform:** %s\n\n", decision.PlatformSource))
 			
 			content.WriteString("**Decision:**\n")
@@ -263,31 +354,51 @@ func (m *MCPServer) getDecisionHistory(ctx context.Context, arguments map[string
 			}
 			
 			content.WriteString("\n---\n\n")
+
+			items = append(items, PackItem{
+				ID:        packItemID("decision", i),
+				Title:     decision.Title,
+				Text:      content.String(),
+				Rank:      i,
+				Timestamp: decision.CreatedAt,
+			})
 		}
 
 		if len(decisionHistory.Decisions) > limit {
-			content.WriteString(fmt.Sprintf("*Showing %d of %d decisions. Use limit parameter to see more.*\n", limit, len(decisionHistory.Decisions)))
+			footer = fmt.Sprintf("*Showing %d of %d decisions. Use limit parameter to see more.*\n", limit, len(decisionHistory.Decisions))
 		}
 	}
 
-	// Optimize response for token limits
-	optimizedContent := m.responseOptimizer.OptimizeResponse(content.String(), "decision_history")
-
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: optimizedContent,
-		}},
-	}, nil
+	result, packed := m.packedToolResult(ctx, projectID, arguments, header, items, footer)
+	var packedDecisions []models.DecisionRecord
+	for i, decision := range decisions {
+		if packed.Includes(packItemID("decision", i)) {
+			packedDecisions = append(packedDecisions, decision)
+		}
+	}
+	structured := map[string]interface{}{
+		"project_id":      projectID,
+		"target":          target,
+		"total_decisions": len(decisionHistory.Decisions),
+		"decisions":       nonNilDecisions(packedDecisions),
+	}
+	addPackingSummary(structured, packed)
+	result.StructuredContent = structured
+	return result, nil
 }
 
 // listRecentArchitectureDiscussions implements the list_recent_architecture_discussions MCP tool
 func (m *MCPServer) listRecentArchitectureDiscussions(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
 	limit := getIntParam(arguments, "limit", 10)
 	daysBack := getIntParam(arguments, "days_back", 30)
 
 	// Get recent architecture discussions
-	discussions, err := m.knowledgeGraph.GetRecentArchitectureDiscussions(ctx, limit)
+	discussions, err := m.knowledgeGraph.GetRecentArchitectureDiscussionsByProject(ctx, projectID, limit)
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -308,15 +419,16 @@ func (m *MCPServer) listRecentArchitectureDiscussions(ctx context.Context, argum
 	}
 
 	// Format response
-	var content strings.Builder
-	content.WriteString(fmt.Sprintf("# Recent Architecture Discussions (Last %d days)\n\n", daysBack))
+	header := fmt.Sprintf("# Recent Architecture Discussions (Last %d days)\n\n", daysBack)
+	var items []PackItem
 
 	if len(filteredDiscussions) == 0 {
-		content.WriteString("No recent architecture discussions found.\n")
+		header += "No recent architecture discussions found.\n"
 	} else {
-		content.WriteString(fmt.Sprintf("Found %d discussions:\n\n", len(filteredDiscussions)))
+		header += fmt.Sprintf("Found %d discussions:\n\n", len(filteredDiscussions))
 
 		for i, discussion := range filteredDiscussions {
+			var content strings.Builder
 			content.WriteString(fmt.Sprintf("## %d. Discussion from %s\n", i+1, discussion.CreatedAt.Format("2006-01-02 15:04")))
 			content.WriteString(fmt.Sprintf("**Platform:** %s\n", discussion.Platform))
 			if discussion.ThreadID != nil {
@@ -353,22 +465,41 @@ func (m *MCPServer) listRecentArchitectureDiscussions(ctx context.Context, argum
 			}
 			
 			content.WriteString("\n---\n\n")
+
+			items = append(items, PackItem{
+				ID:        packItemID("discussion", i),
+				Title:     fmt.Sprintf("%s discussion from %s", discussion.Platform, discussion.CreatedAt.Format("2006-01-02 15:04")),
+				Text:      content.String(),
+				Rank:      i,
+				Timestamp: discussion.CreatedAt,
+			})
 		}
 	}
 
-	// Optimize response for token limits
-	optimizedContent := m.responseOptimizer.OptimizeResponse(content.String(), "architecture_discussions")
-
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: optimizedContent,
-		}},
-	}, nil
+	result, packed := m.packedToolResult(ctx, projectID, arguments, header, items, "")
+	var packedDiscussions []models.DiscussionSummary
+	for i, discussion := range filteredDiscussions {
+		if packed.Includes(packItemID("discussion", i)) {
+			packedDiscussions = append(packedDiscussions, discussion)
+		}
+	}
+	structured := map[string]interface{}{
+		"project_id":  projectID,
+		"days_back":   daysBack,
+		"discussions": nonNilDiscussions(packedDiscussions),
+	}
+	addPackingSummary(structured, packed)
+	result.StructuredContent = structured
+	return result, nil
 }
 
 // explainWhyCodeExists implements the explain_why_code_exists MCP tool
 func (m *MCPServer) explainWhyCodeExists(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
 	filePath := getStringParam(arguments, "file_path", "")
 	if filePath == "" {
 		return &MCPToolResult{
@@ -396,7 +527,8 @@ func (m *MCPServer) explainWhyCodeExists(ctx context.Context, arguments map[stri
 	}
 
 	// Get comprehensive file context
-	fileContext, err := m.knowledgeGraph.GetContextForFile(ctx, filePath)
+	reportProgress(ctx, 0, 3, "Loading file context")
+	fileContext, err := m.knowledgeGraph.GetContextForFileByProject(ctx, projectID, filePath)
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -408,7 +540,8 @@ func (m *MCPServer) explainWhyCodeExists(ctx context.Context, arguments map[stri
 	}
 
 	// Search for decisions related to this file
-	decisionHistory, err := m.knowledgeGraph.GetDecisionHistory(ctx, filePath)
+	reportProgress(ctx, 1, 3, "Loading related decisions")
+	decisionHistory, err := m.knowledgeGraph.GetDecisionHistoryByProject(ctx, projectID, filePath)
 	if err != nil {
 		return &MCPToolResult{
 			Content: []MCPContent{{
@@ -420,92 +553,308 @@ func (m *MCPServer) explainWhyCodeExists(ctx context.Context, arguments map[stri
 	}
 
 	// Format comprehensive explanation
-	var content strings.Builder
-	content.WriteString(fmt.Sprintf("# Why %s Exists\n\n", filePath))
+	reportProgress(ctx, 2, 3, "Building explanation")
+	header := fmt.Sprintf("# Why %s Exists\n\n", filePath)
 
 	if lineRange != nil {
-		content.WriteString(fmt.Sprintf("*Focusing on lines %d-%d*\n\n", lineRange.Start, lineRange.End))
+		header += fmt.Sprintf("*Focusing on lines %d-%d*\n\n", lineRange.Start, lineRange.End)
 	}
 
 	// Historical context
-	if len(fileContext.FileContexts) > 0 {
-		content.WriteString("## Historical Context\n\n")
-		
-		// Sort by creation date (most recent first)
-		contexts := fileContext.FileContexts
-		
-		for i, fc := range contexts {
-			content.WriteString(fmt.Sprintf("### %s\n", fc.CreatedAt.Format("2006-01-02 15:04")))
-			if fc.ChangeReason != nil && *fc.ChangeReason != "" {
-				content.WriteString(fmt.Sprintf("**Reason for Change:** %s\n\n", *fc.ChangeReason))
-			}
-			content.WriteString(fmt.Sprintf("**Context:** %s\n", fc.DiscussionContext))
-			if len(fc.Contributors) > 0 {
-				content.WriteString(fmt.Sprintf("**Contributors:** %s\n", strings.Join(fc.Contributors, ", ")))
-			}
-			content.WriteString("\n")
-			
-			if i < len(contexts)-1 {
-				content.WriteString("---\n\n")
-			}
+	var items []PackItem
+	for i, fc := range fileContext.FileContexts {
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("### %s\n", fc.CreatedAt.Format("2006-01-02 15:04")))
+		if fc.ChangeReason != nil && *fc.ChangeReason != "" {
+			content.WriteString(fmt.Sprintf("**Reason for Change:** %s\n\n", *fc.ChangeReason))
+		}
+		content.WriteString(fmt.Sprintf("**Context:** %s\n", fc.DiscussionContext))
+		if len(fc.Contributors) > 0 {
+			content.WriteString(fmt.Sprintf("**Contributors:** %s\n", strings.Join(fc.Contributors, ", ")))
 		}
 		content.WriteString("\n")
+
+		items = append(items, PackItem{
+			ID:        packItemID("file_context", i),
+			Title:     "change of " + fc.CreatedAt.Format("2006-01-02 15:04"),
+			Section:   "Historical Context",
+			Text:      content.String(),
+			Rank:      i,
+			Timestamp: fc.CreatedAt,
+		})
 	}
 
 	// Related decisions
-	if len(decisionHistory.Decisions) > 0 {
-		content.WriteString("## Related Decisions\n\n")
-		content.WriteString("The following architectural decisions influenced this code:\n\n")
+	for i, decision := range decisionHistory.Decisions {
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("### %d. %s\n", i+1, decision.Title))
+		content.WriteString(fmt.Sprintf("**Date:** %s\n", decision.CreatedAt.Format("2006-01-02")))
+		content.WriteString(fmt.Sprintf("**Decision:** %s\n", decision.Decision))
 		
-		for i, decision := range decisionHistory.Decisions {
-			content.WriteString(fmt.Sprintf("### %d. %s\n", i+1, decision.Title))
-			content.WriteString(fmt.Sprintf("**Date:** %s\n", decision.CreatedAt.Format("2006-01-02")))
-			content.WriteString(fmt.Sprintf("**Decision:** %s\n", decision.Decision))
-			
-			if decision.Rationale != nil && *decision.Rationale != "" {
-				content.WriteString(fmt.Sprintf("**Rationale:** %s\n", *decision.Rationale))
-			}
-			content.WriteString("\n")
+		if decision.Rationale != nil && *decision.Rationale != "" {
+			content.WriteString(fmt.Sprintf("**Rationale:** %s\n", *decision.Rationale))
 		}
 		content.WriteString("\n")
+
+		items = append(items, PackItem{
+			ID:        packItemID("decision", i),
+			Title:     decision.Title,
+			Section:   "Related Decisions",
+			Text:      content.String(),
+			Rank:      i,
+			Timestamp: decision.CreatedAt,
+		})
 	}
 
 	// Related discussions and features
-	if len(fileContext.RelatedEntities) > 0 {
-		content.WriteString("## Related Context\n\n")
-		
-		for _, entity := range fileContext.RelatedEntities {
-			if entity.Entity.EntityType == "discussion" || entity.Entity.EntityType == "feature" {
-				content.WriteString(fmt.Sprintf("### %s (%s)\n", entity.Entity.Title, entity.Entity.EntityType))
-				if entity.Entity.Content != "" {
-					// Truncate for readability
-					entityContent := entity.Entity.Content
-					if len(entityContent) > 300 {
-						entityContent = entityContent[:300] + "..."
-					}
-					content.WriteString(fmt.Sprintf("%s\n\n", entityContent))
-				}
+	for i, entity := range fileContext.RelatedEntities {
+		if entity.Entity.EntityType != "discussion" && entity.Entity.EntityType != "feature" {
+			continue
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("### %s (%s)\n", entity.Entity.Title, entity.Entity.EntityType))
+		if entity.Entity.Content != "" {
+			// Truncate for readability
+			entityContent := entity.Entity.Content
+			if len(entityContent) > 300 {
+				entityContent = entityContent[:300] + "..."
 			}
+			content.WriteString(fmt.Sprintf("%s\n\n", entityContent))
 		}
+
+		items = append(items, PackItem{
+			ID:         packItemID("related_entity", i),
+			Title:      entity.Entity.Title,
+			Section:    "Related Context",
+			Text:       content.String(),
+			Rank:       i,
+			Similarity: entity.Similarity,
+			Timestamp:  entity.Entity.CreatedAt,
+		})
 	}
 
 	// Summary
-	content.WriteString("## Summary\n\n")
+	footer := "## Summary\n\n"
 	if len(fileContext.FileContexts) > 0 || len(decisionHistory.Decisions) > 0 {
-		content.WriteString("This code exists as a result of the historical context, decisions, and discussions outlined above. ")
-		content.WriteString("The evolution of this file reflects the team's architectural choices and problem-solving approach over time.")
+		footer += "This code exists as a result of the historical context, decisions, and discussions outlined above. "
+		footer += "The evolution of this file reflects the team's architectural choices and problem-solving approach over time."
+	} else {
+		footer += "Limited historical context is available for this file. "
+		footer += "This could indicate it's a newer file or that relevant discussions happened outside the tracked platforms."
+	}
+
+	result, packed := m.packedToolResult(ctx, projectID, arguments, header, items, footer+"\n")
+	var fileContexts []models.FileContextHistory
+	for i, fc := range fileContext.FileContexts {
+		if packed.Includes(packItemID("file_context", i)) {
+			fileContexts = append(fileContexts, fc)
+		}
+	}
+	var decisions []models.DecisionRecord
+	for i, decision := range decisionHistory.Decisions {
+		if packed.Includes(packItemID("decision", i)) {
+			decisions = append(decisions, decision)
+		}
+	}
+	// Without a budget every related entity is returned, including those the
+	// markdown does not list
+	var relatedEntities []models.SearchResult
+	for i, entity := range fileContext.RelatedEntities {
+		if packed.MaxTokens <= 0 || packed.Includes(packItemID("related_entity", i)) {
+			relatedEntities = append(relatedEntities, entity)
+		}
+	}
+	structured := map[string]interface{}{
+		"project_id":       projectID,
+		"file_path":        filePath,
+		"file_contexts":    nonNilFileContexts(fileContexts),
+		"decisions":        nonNilDecisions(decisions),
+		"related_entities": structuredSearchResults(relatedEntities),
+	}
+	if lineRange != nil {
+		structured["line_range"] = lineRange
+	}
+	addPackingSummary(structured, packed)
+	result.StructuredContent = structured
+	return result, nil
+}
+
+// knowledgeRelationshipTypes lists the relationship types explore_related_knowledge can follow
+var knowledgeRelationshipTypes = []string{"relates_to", "introduced_by", "modified_by", "discussed_in"}
+
+// maxExploreDepth caps how far explore_related_knowledge walks from the start entity
+const maxExploreDepth = 5
+
+// exploreRelatedKnowledge implements the explore_related_knowledge MCP tool
+func (m *MCPServer) exploreRelatedKnowledge(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, err := m.resolveProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
+	entityRef := getStringParam(arguments, "entity", "")
+	if entityRef == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: "Error: entity parameter is required",
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	maxDepth := getIntParam(arguments, "max_depth", 2)
+	if maxDepth < 1 {
+		maxDepth = 1
+	}
+	if maxDepth > maxExploreDepth {
+		maxDepth = maxExploreDepth
+	}
+
+	relationshipTypes := getStringArrayParam(arguments, "relationship_types")
+	for _, relType := range relationshipTypes {
+		if !containsString(knowledgeRelationshipTypes, relType) {
+			return &MCPToolResult{
+				Content: []MCPContent{{
+					Type: "text",
+					Text: fmt.Sprintf("Error: unknown relationship type %q (expected one of %s)", relType, strings.Join(knowledgeRelationshipTypes, ", ")),
+				}},
+				IsError: true,
+			}, nil
+		}
+	}
+
+	reportProgress(ctx, 0, 3, "Finding start entity")
+	start, err := m.knowledgeGraph.ResolveKnowledgeEntityByProject(ctx, projectID, entityRef)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error finding entity: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	reportProgress(ctx, 1, 3, "Traversing knowledge graph")
+	graph, err := m.knowledgeGraph.TraverseRelationshipsByProject(ctx, projectID, start.ID, maxDepth, relationshipTypes)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error exploring knowledge graph: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	reportProgress(ctx, 2, 3, "Formatting subgraph")
+	depths := traversalDepths(start.ID, graph.Relationships, maxDepth)
+	titles := make(map[string]string, len(graph.Path))
+	for _, entity := range graph.Path {
+		titles[entity.ID] = entity.Title
+	}
+
+	// The strongest relationship touching an entity ranks it when packing
+	strengths := make(map[string]float64, len(graph.Path))
+	for _, rel := range graph.Relationships {
+		strengths[rel.SourceEntityID] = math.Max(strengths[rel.SourceEntityID], rel.Strength)
+		strengths[rel.TargetEntityID] = math.Max(strengths[rel.TargetEntityID], rel.Strength)
+	}
+
+	// Format response
+	var header strings.Builder
+	header.WriteString(fmt.Sprintf("# Knowledge Related to: %s\n\n", start.Title))
+	header.WriteString(fmt.Sprintf("**Start Entity:** %s (%s, id %s)\n", start.Title, start.EntityType, start.ID))
+	header.WriteString(fmt.Sprintf("**Max Depth:** %d\n", maxDepth))
+	if len(relationshipTypes) > 0 {
+		header.WriteString(fmt.Sprintf("**Relationship Types:** %s\n", strings.Join(relationshipTypes, ", ")))
+	}
+	header.WriteString("\n")
+
+	if len(graph.Path) <= 1 {
+		header.WriteString("No related knowledge found for this entity.\n")
 	} else {
-		content.WriteString("Limited historical context is available for this file. ")
-		content.WriteString("This could indicate it's a newer file or that relevant discussions happened outside the tracked platforms.")
+		header.WriteString(fmt.Sprintf("Found %d related entities:\n\n", len(graph.Path)-1))
 	}
 
-	// Optimize response for token limits
-	optimizedContent := m.responseOptimizer.OptimizeResponse(content.String(), "code_explanation")
+	var items []PackItem
+	for i, entity := range graph.Path {
+		if entity.ID == start.ID {
+			continue
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("## %s\n", entity.Title))
+		content.WriteString(fmt.Sprintf("**Type:** %s\n", entity.EntityType))
+		content.WriteString(fmt.Sprintf("**ID:** %s\n", entity.ID))
+		content.WriteString(fmt.Sprintf("**Depth:** %d\n", depths[entity.ID]))
+		if entity.PlatformSource != nil {
+			content.WriteString(fmt.Sprintf("**Platform:** %s\n", *entity.PlatformSource))
+		}
+		if len(entity.SourceEventIDs) > 0 {
+			content.WriteString(fmt.Sprintf("**Source Events:** %s\n", strings.Join(entity.SourceEventIDs, ", ")))
+		}
+		if entity.Content != "" {
+			content.WriteString(fmt.Sprintf("%s\n", truncatePromptText(entity.Content, 300)))
+		}
+		content.WriteString("\n")
+
+		items = append(items, PackItem{
+			ID:        packItemID("entity", i),
+			Title:     entity.Title,
+			Text:      content.String(),
+			Rank:      depths[entity.ID] - 1,
+			Timestamp: entity.CreatedAt,
+			Strength:  strengths[entity.ID],
+		})
+	}
 
-	return &MCPToolResult{
-		Content: []MCPContent{{
-			Type: "text",
-			Text: optimizedContent,
-		}},
-	}, nil
-}
\ No newline at end of file
+	relationships := graph.Relationships
+	if relationships == nil {
+		relationships = []models.KnowledgeRelationship{}
+	}
+	for i, rel := range relationships {
+		items = append(items, PackItem{
+			ID:       packItemID("relationship", i),
+			Title:    fmt.Sprintf("%s --%s--> %s", titles[rel.SourceEntityID], rel.RelationshipType, titles[rel.TargetEntityID]),
+			Section:  "Relationships",
+			Text:     fmt.Sprintf("- %s --%s (%.2f)--> %s\n", titles[rel.SourceEntityID], rel.RelationshipType, rel.Strength, titles[rel.TargetEntityID]),
+			Rank:     i,
+			Strength: rel.Strength,
+		})
+	}
+
+	result, packed := m.packedToolResult(ctx, projectID, arguments, header.String(), items, "")
+
+	structuredEntities := make([]map[string]interface{}, 0, len(graph.Path))
+	for i, entity := range graph.Path {
+		if entity.ID != start.ID && !packed.Includes(packItemID("entity", i)) {
+			continue
+		}
+		entity.Embedding = nil
+		structuredEntities = append(structuredEntities, map[string]interface{}{
+			"depth":  depths[entity.ID],
+			"entity": entity,
+		})
+	}
+	packedRelationships := make([]models.KnowledgeRelationship, 0, len(relationships))
+	for i, rel := range relationships {
+		if packed.Includes(packItemID("relationship", i)) {
+			packedRelationships = append(packedRelationships, rel)
+		}
+	}
+
+	structured := map[string]interface{}{
+		"project_id":      projectID,
+		"start_entity_id": start.ID,
+		"max_depth":       maxDepth,
+		"entities":        structuredEntities,
+		"relationships":   packedRelationships,
+		"total_strength":  graph.TotalStrength,
+	}
+	addPackingSummary(structured, packed)
+	result.StructuredContent = structured
+	return result, nil
+}
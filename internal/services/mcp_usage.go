@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+)
+
+// mcpQuotasSettingsKey is the project settings key holding MCP usage quotas
+const mcpQuotasSettingsKey = "mcp_quotas"
+
+// MCPQuotaExceededError is returned when a tool call would exceed a usage quota
+type MCPQuotaExceededError struct {
+	Scope  string // project or user
+	Limit  int
+	Unit   string // tool calls or result tokens
+	Window string // hour or day
+}
+
+func (e *MCPQuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d %s per %s exceeded", e.Scope, e.Limit, e.Unit, e.Window)
+}
+
+// MCPUsageServiceImpl implements MCPUsageService on top of the repository
+type MCPUsageServiceImpl struct {
+	store RepositoryStore
+	now   func() time.Time
+
+	mu       sync.Mutex
+	projects map[string]*mcpProjectCalls
+}
+
+// mcpProjectCalls serializes the quota checks of a project and counts the
+// calls it admitted that are not recorded yet, by user
+type mcpProjectCalls struct {
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewMCPUsageService creates a new MCP usage service
+func NewMCPUsageService(store RepositoryStore) *MCPUsageServiceImpl {
+	return &MCPUsageServiceImpl{
+		store:    store,
+		now:      time.Now,
+		projects: make(map[string]*mcpProjectCalls),
+	}
+}
+
+// CheckQuota returns an *MCPQuotaExceededError when the project or the user
+// has used up a quota configured in the project settings. Checks of a project
+// run one at a time and count the calls admitted before that are still
+// running, so concurrent calls cannot together overshoot a call quota.
+func (u *MCPUsageServiceImpl) CheckQuota(ctx context.Context, projectID, userID string) (func(), error) {
+	quotas, err := u.quotas(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if quotas == (models.MCPQuotas{}) {
+		return func() {}, nil
+	}
+
+	calls := u.projectCalls(projectID)
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+
+	projectInFlight := 0
+	for _, count := range calls.inFlight {
+		projectInFlight += count
+	}
+
+	now := u.now()
+	checks := []struct {
+		scope, userID, window string
+		since                 time.Time
+		calls, tokens         int
+		inFlight              int
+	}{
+		{"project", "", "hour", now.Add(-time.Hour), quotas.ProjectCallsPerHour, 0, projectInFlight},
+		{"project", "", "day", now.Add(-24 * time.Hour), quotas.ProjectCallsPerDay, quotas.ProjectTokensPerDay, projectInFlight},
+		{"user", userID, "hour", now.Add(-time.Hour), quotas.UserCallsPerHour, 0, calls.inFlight[userID]},
+		{"user", userID, "day", now.Add(-24 * time.Hour), quotas.UserCallsPerDay, quotas.UserTokensPerDay, calls.inFlight[userID]},
+	}
+
+	for _, check := range checks {
+		if check.calls <= 0 && check.tokens <= 0 {
+			continue
+		}
+
+		totals, err := u.store.GetMCPToolCallTotals(ctx, projectID, check.userID, check.since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count tool calls: %w", err)
+		}
+
+		if check.calls > 0 && totals.Calls+check.inFlight >= check.calls {
+			return nil, &MCPQuotaExceededError{Scope: check.scope, Limit: check.calls, Unit: "tool calls", Window: check.window}
+		}
+		if check.tokens > 0 && totals.ResultTokens >= check.tokens {
+			return nil, &MCPQuotaExceededError{Scope: check.scope, Limit: check.tokens, Unit: "result tokens", Window: check.window}
+		}
+	}
+
+	calls.inFlight[userID]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			calls.mu.Lock()
+			defer calls.mu.Unlock()
+			if calls.inFlight[userID]--; calls.inFlight[userID] == 0 {
+				delete(calls.inFlight, userID)
+			}
+		})
+	}, nil
+}
+
+// projectCalls returns the quota check state of a project, creating it on first use
+func (u *MCPUsageServiceImpl) projectCalls(projectID string) *mcpProjectCalls {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	calls, ok := u.projects[projectID]
+	if !ok {
+		calls = &mcpProjectCalls{inFlight: make(map[string]int)}
+		u.projects[projectID] = calls
+	}
+	return calls
+}
+
+// RecordToolCall stores a metered tool call
+func (u *MCPUsageServiceImpl) RecordToolCall(ctx context.Context, call *models.MCPToolCall) error {
+	if call.CreatedAt.IsZero() {
+		call.CreatedAt = u.now()
+	}
+	if err := u.store.CreateMCPToolCall(ctx, call); err != nil {
+		return fmt.Errorf("failed to record tool call: %w", err)
+	}
+	return nil
+}
+
+// GetUsageSummary aggregates a project's tool calls since the given time by tool and by user
+func (u *MCPUsageServiceImpl) GetUsageSummary(ctx context.Context, projectID string, since time.Time) (*models.MCPUsageSummary, error) {
+	quotas, err := u.quotas(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := u.store.GetMCPToolUsageByProject(ctx, projectID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tool usage: %w", err)
+	}
+
+	summary := &models.MCPUsageSummary{
+		ProjectID: projectID,
+		Since:     since,
+		ByTool:    []models.MCPToolUsage{},
+		ByUser:    []models.MCPToolUsage{},
+		Quotas:    quotas,
+	}
+
+	byTool := make(map[string]*models.MCPToolUsage)
+	byUser := make(map[string]*models.MCPToolUsage)
+	for _, row := range rows {
+		addToolUsage(&summary.Totals, row)
+
+		if byTool[row.ToolName] == nil {
+			byTool[row.ToolName] = &models.MCPToolUsage{ToolName: row.ToolName}
+		}
+		addToolUsage(byTool[row.ToolName], row)
+
+		if byUser[row.UserID] == nil {
+			byUser[row.UserID] = &models.MCPToolUsage{UserID: row.UserID}
+		}
+		addToolUsage(byUser[row.UserID], row)
+	}
+
+	finishToolUsage(&summary.Totals)
+	for _, usage := range byTool {
+		finishToolUsage(usage)
+		summary.ByTool = append(summary.ByTool, *usage)
+	}
+	for _, usage := range byUser {
+		finishToolUsage(usage)
+		summary.ByUser = append(summary.ByUser, *usage)
+	}
+
+	// Busiest first, so the tools agents rely on most lead the report
+	for _, usage := range [][]models.MCPToolUsage{summary.ByTool, summary.ByUser} {
+		sort.Slice(usage, func(i, j int) bool {
+			if usage[i].Calls != usage[j].Calls {
+				return usage[i].Calls > usage[j].Calls
+			}
+			return usage[i].ToolName+usage[i].UserID < usage[j].ToolName+usage[j].UserID
+		})
+	}
+
+	return summary, nil
+}
+
+// quotas reads the MCP quotas from the project settings
+func (u *MCPUsageServiceImpl) quotas(ctx context.Context, projectID string) (models.MCPQuotas, error) {
+	workspace, err := u.store.GetProjectWorkspace(ctx, projectID)
+	if err != nil {
+		return models.MCPQuotas{}, fmt.Errorf("failed to get project workspace: %w", err)
+	}
+	return mcpQuotasFromSettings(workspace.Settings), nil
+}
+
+// mcpQuotasFromSettings parses the "mcp_quotas" project setting, ignoring
+// values that are not positive numbers
+func mcpQuotasFromSettings(settings map[string]interface{}) models.MCPQuotas {
+	raw, _ := settings[mcpQuotasSettingsKey].(map[string]interface{})
+
+	limit := func(key string) int {
+		if value, ok := raw[key].(float64); ok && value > 0 {
+			return int(value)
+		}
+		return 0
+	}
+
+	return models.MCPQuotas{
+		ProjectCallsPerHour: limit("project_calls_per_hour"),
+		ProjectCallsPerDay:  limit("project_calls_per_day"),
+		ProjectTokensPerDay: limit("project_tokens_per_day"),
+		UserCallsPerHour:    limit("user_calls_per_hour"),
+		UserCallsPerDay:     limit("user_calls_per_day"),
+		UserTokensPerDay:    limit("user_tokens_per_day"),
+	}
+}
+
+// addToolUsage adds a per-user, per-tool row into an aggregate
+func addToolUsage(total *models.MCPToolUsage, row models.MCPToolUsage) {
+	total.Calls += row.Calls
+	total.Errors += row.Errors
+	total.Rejected += row.Rejected
+	total.TotalLatencyMs += row.TotalLatencyMs
+	total.ResultBytes += row.ResultBytes
+	total.ResultTokens += row.ResultTokens
+	if row.MaxLatencyMs > total.MaxLatencyMs {
+		total.MaxLatencyMs = row.MaxLatencyMs
+	}
+	if row.LastCalledAt != nil && (total.LastCalledAt == nil || row.LastCalledAt.After(*total.LastCalledAt)) {
+		lastCalledAt := *row.LastCalledAt
+		total.LastCalledAt = &lastCalledAt
+	}
+}
+
+// finishToolUsage computes the average latency of the calls that ran
+func finishToolUsage(usage *models.MCPToolUsage) {
+	if usage.Calls > 0 {
+		usage.AvgLatencyMs = float64(usage.TotalLatencyMs) / float64(usage.Calls)
+	}
+}
+
+// maxToolCallErrorLength bounds the error message stored with a failed tool call
+const maxToolCallErrorLength = 500
+
+// mcpToolCallMeter times one tool call and records it against the caller's quotas
+type mcpToolCallMeter struct {
+	server  *MCPServer
+	call    *models.MCPToolCall
+	started time.Time
+	release func() // frees the call's place in the quotas once it is recorded
+}
+
+type mcpToolCallMeterContextKey struct{}
+
+// startToolCall starts metering a tool call and returns the context to run it
+// with. The meter is nil when there is no usage service or no authenticated
+// user to attribute the call to.
+func (m *MCPServer) startToolCall(ctx context.Context, name string) (context.Context, *mcpToolCallMeter) {
+	user, ok := MCPUserFromContext(ctx)
+	if m.usage == nil || !ok {
+		return ctx, nil
+	}
+
+	meter := &mcpToolCallMeter{
+		server:  m,
+		call:    &models.MCPToolCall{UserID: user.ID, ToolName: name},
+		started: time.Now(),
+	}
+	return context.WithValue(ctx, mcpToolCallMeterContextKey{}, meter), meter
+}
+
+// chargeToolCall attributes the tool call metered in ctx to the project its
+// tool was allowed to read, and returns an error when the call would exceed
+// one of the project's quotas. Calls that never reach a project are recorded
+// for the user only. Quota lookups that fail let the call through rather than
+// lock agents out.
+func (m *MCPServer) chargeToolCall(ctx context.Context, projectID string) error {
+	t, _ := ctx.Value(mcpToolCallMeterContextKey{}).(*mcpToolCallMeter)
+	if t == nil || t.call.ProjectID != nil {
+		return nil
+	}
+	t.call.ProjectID = &projectID
+
+	release, err := m.usage.CheckQuota(ctx, projectID, t.call.UserID)
+	if err == nil {
+		t.release = release
+		return nil
+	}
+
+	var quotaErr *MCPQuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		m.loggerFor(ctx).Error("Failed to check MCP usage quota", err, map[string]interface{}{
+			"project_id": projectID,
+			"tool":       t.call.ToolName,
+		})
+		return nil
+	}
+
+	t.call.Status = models.MCPToolCallQuotaExceeded
+	return fmt.Errorf("%w, try again later", quotaErr)
+}
+
+// finish records the outcome of the call. Recording outlives the request so a
+// client disconnect does not lose the usage.
+func (t *mcpToolCallMeter) finish(ctx context.Context, result *MCPToolResult, err error) {
+	if t == nil {
+		return
+	}
+
+	call := t.call
+	call.LatencyMs = time.Since(t.started).Milliseconds()
+
+	var message string
+	if result != nil {
+		var text strings.Builder
+		for _, content := range result.Content {
+			text.WriteString(content.Text)
+		}
+		call.ResultBytes = text.Len()
+		call.ResultTokens = EstimateTokenCount(text.String())
+		if result.IsError && len(result.Content) > 0 {
+			message = result.Content[0].Text
+		}
+	}
+	if err != nil {
+		message = err.Error()
+	}
+
+	if call.Status == "" {
+		call.Status = models.MCPToolCallSuccess
+		if err != nil || (result != nil && result.IsError) {
+			call.Status = models.MCPToolCallError
+		}
+	}
+	if call.Status != models.MCPToolCallSuccess && message != "" {
+		message = truncatePromptText(message, maxToolCallErrorLength)
+		call.ErrorMessage = &message
+	}
+
+	if recordErr := t.server.usage.RecordToolCall(context.WithoutCancel(ctx), call); recordErr != nil {
+		t.server.loggerFor(ctx).Error("Failed to record MCP tool call", recordErr, map[string]interface{}{
+			"tool": call.ToolName,
+		})
+	}
+	if t.release != nil {
+		t.release()
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageRepositoryStub keeps metered tool calls in memory
+type usageRepositoryStub struct {
+	workspaceRepositoryStub
+	mu    sync.Mutex
+	calls []models.MCPToolCall
+	usage []models.MCPToolUsage
+}
+
+func (r *usageRepositoryStub) CreateMCPToolCall(ctx context.Context, call *models.MCPToolCall) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, *call)
+	return nil
+}
+
+func (r *usageRepositoryStub) GetMCPToolCallTotals(ctx context.Context, projectID, userID string, since time.Time) (*models.MCPToolCallTotals, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	totals := &models.MCPToolCallTotals{}
+	for _, call := range r.calls {
+		if call.ProjectID == nil || *call.ProjectID != projectID || call.Status == models.MCPToolCallQuotaExceeded {
+			continue
+		}
+		if (userID != "" && call.UserID != userID) || call.CreatedAt.Before(since) {
+			continue
+		}
+		totals.Calls++
+		totals.ResultTokens += call.ResultTokens
+	}
+	return totals, nil
+}
+
+func (r *usageRepositoryStub) GetMCPToolUsageByProject(ctx context.Context, projectID string, since time.Time) ([]models.MCPToolUsage, error) {
+	return r.usage, nil
+}
+
+func (r *usageRepositoryStub) countStatus(status models.MCPToolCallStatus) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, call := range r.calls {
+		if call.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// heldKnowledgeGraphStub holds file context calls until released
+type heldKnowledgeGraphStub struct {
+	KnowledgeGraphService
+	entered atomic.Int32
+	release chan struct{}
+}
+
+func (s *heldKnowledgeGraphStub) GetContextForFileByProject(ctx context.Context, projectID, filePath string) (*FileContextResponse, error) {
+	s.entered.Add(1)
+	<-s.release
+	return &FileContextResponse{FilePath: filePath}, nil
+}
+
+func TestMCPQuotasFromSettings(t *testing.T) {
+	quotas := mcpQuotasFromSettings(map[string]interface{}{
+		"mcp_quotas": map[string]interface{}{
+			"project_calls_per_day":  float64(500),
+			"user_calls_per_hour":    float64(20),
+			"user_tokens_per_day":    float64(-1),
+			"project_tokens_per_day": "lots",
+		},
+	})
+
+	assert.Equal(t, models.MCPQuotas{ProjectCallsPerDay: 500, UserCallsPerHour: 20}, quotas)
+	assert.Equal(t, models.MCPQuotas{}, mcpQuotasFromSettings(nil))
+}
+
+func TestMCPUsage_CheckQuota(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	project := "p1"
+	repo := &usageRepositoryStub{
+		workspaceRepositoryStub: workspaceRepositoryStub{settings: map[string]interface{}{"mcp_quotas": map[string]interface{}{
+			"user_calls_per_hour":    float64(2),
+			"project_tokens_per_day": float64(1000),
+		}}},
+		calls: []models.MCPToolCall{
+			{ProjectID: &project, UserID: "u1", Status: models.MCPToolCallSuccess, ResultTokens: 100, CreatedAt: now.Add(-10 * time.Minute)},
+			{ProjectID: &project, UserID: "u1", Status: models.MCPToolCallQuotaExceeded, CreatedAt: now.Add(-5 * time.Minute)},
+			{ProjectID: &project, UserID: "u1", Status: models.MCPToolCallError, ResultTokens: 10, CreatedAt: now.Add(-2 * time.Hour)},
+		},
+	}
+	usage := NewMCPUsageService(repo)
+	usage.now = func() time.Time { return now }
+
+	// Rejected calls and calls outside the hour do not count
+	release, err := usage.CheckQuota(context.Background(), "p1", "u1")
+	require.NoError(t, err)
+
+	// An admitted call counts until it is recorded
+	_, err = usage.CheckQuota(context.Background(), "p1", "u1")
+	var quotaErr *MCPQuotaExceededError
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, MCPQuotaExceededError{Scope: "user", Limit: 2, Unit: "tool calls", Window: "hour"}, *quotaErr)
+
+	repo.calls = append(repo.calls, models.MCPToolCall{ProjectID: &project, UserID: "u1", Status: models.MCPToolCallSuccess, ResultTokens: 100, CreatedAt: now.Add(-time.Minute)})
+	release()
+	release()
+	_, err = usage.CheckQuota(context.Background(), "p1", "u1")
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, MCPQuotaExceededError{Scope: "user", Limit: 2, Unit: "tool calls", Window: "hour"}, *quotaErr)
+
+	// Other users share only the project's token budget
+	release, err = usage.CheckQuota(context.Background(), "p1", "u2")
+	require.NoError(t, err)
+	release()
+	repo.calls = append(repo.calls, models.MCPToolCall{ProjectID: &project, UserID: "u3", Status: models.MCPToolCallSuccess, ResultTokens: 800, CreatedAt: now})
+	_, err = usage.CheckQuota(context.Background(), "p1", "u2")
+	require.ErrorAs(t, err, &quotaErr)
+	assert.Equal(t, "project quota of 1000 result tokens per day exceeded", quotaErr.Error())
+}
+
+func TestMCPUsage_GetUsageSummary(t *testing.T) {
+	earlier := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	repo := &usageRepositoryStub{usage: []models.MCPToolUsage{
+		{ToolName: "search_project_knowledge", UserID: "u1", Calls: 3, Errors: 1, TotalLatencyMs: 300, MaxLatencyMs: 200, ResultTokens: 90, LastCalledAt: &earlier},
+		{ToolName: "get_context_for_file", UserID: "u1", Calls: 1, TotalLatencyMs: 50, MaxLatencyMs: 50, ResultTokens: 40, LastCalledAt: &later},
+		{ToolName: "search_project_knowledge", UserID: "u2", Calls: 1, Rejected: 2, TotalLatencyMs: 100, MaxLatencyMs: 100, ResultTokens: 30, LastCalledAt: &later},
+	}}
+
+	summary, err := NewMCPUsageService(repo).GetUsageSummary(context.Background(), "p1", earlier)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, summary.Totals.Calls)
+	assert.Equal(t, 2, summary.Totals.Rejected)
+	assert.Equal(t, int64(160), summary.Totals.ResultTokens)
+	assert.Equal(t, 90.0, summary.Totals.AvgLatencyMs)
+	assert.Equal(t, later, *summary.Totals.LastCalledAt)
+
+	require.Len(t, summary.ByTool, 2)
+	assert.Equal(t, "search_project_knowledge", summary.ByTool[0].ToolName)
+	assert.Equal(t, 4, summary.ByTool[0].Calls)
+	assert.Equal(t, int64(200), summary.ByTool[0].MaxLatencyMs)
+
+	require.Len(t, summary.ByUser, 2)
+	assert.Equal(t, "u1", summary.ByUser[0].UserID)
+	assert.Equal(t, 4, summary.ByUser[0].Calls)
+	assert.Equal(t, 87.5, summary.ByUser[0].AvgLatencyMs)
+}
+
+func TestMCPUsage_ToolCallsAreMeteredAndLimited(t *testing.T) {
+	repo := &usageRepositoryStub{workspaceRepositoryStub: workspaceRepositoryStub{settings: map[string]interface{}{"mcp_quotas": map[string]interface{}{"user_calls_per_day": float64(1)}}}}
+	kg := &scopedKnowledgeGraphStub{}
+	server, ctx := newMCPTestServer(kg, repo, nil)
+
+	result, err := server.callTool(ctx, "get_context_for_file", map[string]interface{}{"file_path": "main.go"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	require.Len(t, repo.calls, 1)
+	call := repo.calls[0]
+	assert.Equal(t, "p1", *call.ProjectID)
+	assert.Equal(t, "u1", call.UserID)
+	assert.Equal(t, "get_context_for_file", call.ToolName)
+	assert.Equal(t, models.MCPToolCallSuccess, call.Status)
+	assert.Positive(t, call.ResultBytes)
+	assert.Positive(t, call.ResultTokens)
+	assert.Nil(t, call.ErrorMessage)
+
+	// The second call today is over the user's quota and never reaches the tool
+	result, err = server.callTool(ctx, "get_context_for_file", map[string]interface{}{"file_path": "main.go"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "user quota of 1 tool calls per day exceeded")
+	assert.Equal(t, []string{"p1"}, kg.projects)
+
+	require.Len(t, repo.calls, 2)
+	assert.Equal(t, models.MCPToolCallQuotaExceeded, repo.calls[1].Status)
+	require.NotNil(t, repo.calls[1].ErrorMessage)
+
+	// Tool errors are recorded with their message
+	repo.settings = nil
+	result, err = server.callTool(ctx, "get_context_for_file", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	require.Len(t, repo.calls, 3)
+	assert.Equal(t, models.MCPToolCallError, repo.calls[2].Status)
+	require.NotNil(t, repo.calls[2].ErrorMessage)
+}
+
+func TestMCPUsage_ToolCallsAreChargedToTheProjectTheyResolve(t *testing.T) {
+	repo := &usageRepositoryStub{}
+	perms := &readPermissionStub{readable: map[string]bool{"p1": true}}
+	server, ctx := newMCPTestServer(&scopedKnowledgeGraphStub{}, repo, perms)
+
+	result, err := server.callTool(ctx, "get_context_for_file", map[string]interface{}{"file_path": "main.go"})
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	// Metering reuses the tool's permission check rather than repeating it
+	assert.Equal(t, int32(1), perms.reads.Load())
+	require.Len(t, repo.calls, 1)
+	assert.Equal(t, "p1", *repo.calls[0].ProjectID)
+}
+
+func TestMCPUsage_LongErrorMessagesAreCutOnARuneBoundary(t *testing.T) {
+	repo := &usageRepositoryStub{}
+	server, ctx := newMCPTestServer(&scopedKnowledgeGraphStub{}, repo, nil)
+
+	_, meter := server.startToolCall(ctx, "get_context_for_file")
+	meter.finish(ctx, nil, errors.New("x"+strings.Repeat("é", maxToolCallErrorLength)))
+
+	require.Len(t, repo.calls, 1)
+	message := *repo.calls[0].ErrorMessage
+	assert.True(t, utf8.ValidString(message))
+	assert.LessOrEqual(t, len(message), maxToolCallErrorLength+len("..."))
+}
+
+func TestMCPUsage_BatchesCannotOvershootTheQuota(t *testing.T) {
+	repo := &usageRepositoryStub{workspaceRepositoryStub: workspaceRepositoryStub{settings: map[string]interface{}{"mcp_quotas": map[string]interface{}{"user_calls_per_day": float64(2)}}}}
+	kg := &heldKnowledgeGraphStub{release: make(chan struct{})}
+	server, ctx := newMCPTestServer(kg, repo, nil)
+
+	batch := `[
+		{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_context_for_file","arguments":{"file_path":"a.go"}}},
+		{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"get_context_for_file","arguments":{"file_path":"b.go"}}},
+		{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_context_for_file","arguments":{"file_path":"c.go"}}},
+		{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_context_for_file","arguments":{"file_path":"d.go"}}}
+	]`
+	done := make(chan error, 1)
+	go func() {
+		_, err := server.HandleMessage(ctx, []byte(batch))
+		done <- err
+	}()
+
+	// Every call of the batch is either running the tool or rejected before
+	// any of them is recorded
+	require.Eventually(t, func() bool {
+		return int(kg.entered.Load())+repo.countStatus(models.MCPToolCallQuotaExceeded) == 4
+	}, 5*time.Second, time.Millisecond)
+	close(kg.release)
+	require.NoError(t, <-done)
+
+	assert.Equal(t, int32(2), kg.entered.Load())
+	assert.Equal(t, 2, repo.countStatus(models.MCPToolCallSuccess))
+	assert.Equal(t, 2, repo.countStatus(models.MCPToolCallQuotaExceeded))
+}
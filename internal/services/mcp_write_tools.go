@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// decisionStatuses lists the statuses record_decision accepts
+var decisionStatuses = []string{"active", "superseded", "deprecated"}
+
+// recordDecision implements the record_decision MCP tool
+func (m *MCPServer) recordDecision(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, user, err := m.resolveWritableProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
+	title := strings.TrimSpace(getStringParam(arguments, "title", ""))
+	decisionText := strings.TrimSpace(getStringParam(arguments, "decision", ""))
+	if title == "" || decisionText == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: "Error: title and decision parameters are required",
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	status := getStringParam(arguments, "status", "active")
+	if !containsString(decisionStatuses, status) {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error: unknown status %q (expected one of %s)", status, strings.Join(decisionStatuses, ", ")),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	decision, err := m.knowledgeGraph.RecordDecisionByProject(ctx, projectID, user.ID, &DecisionRecord{
+		Title:        title,
+		Decision:     decisionText,
+		Rationale:    getStringParam(arguments, "rationale", ""),
+		Alternatives: getStringArrayParam(arguments, "alternatives"),
+		Consequences: getStringArrayParam(arguments, "consequences"),
+		Status:       status,
+		Participants: []string{user.Email},
+	})
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error recording decision: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# Decision Recorded: %s\n\n", decision.Title))
+	content.WriteString(fmt.Sprintf("**Decision ID:** %s\n", decision.DecisionID))
+	content.WriteString(fmt.Sprintf("**Entity ID:** %s\n", decision.EntityID))
+	content.WriteString(fmt.Sprintf("**Status:** %s\n", decision.Status))
+	content.WriteString(fmt.Sprintf("**Recorded By:** %s\n\n", user.Email))
+	content.WriteString("Use the entity ID with link_entities to connect this decision to files, features or discussions.\n")
+
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: content.String(),
+		}},
+		StructuredContent: map[string]interface{}{
+			"project_id": projectID,
+			"decision":   decision,
+		},
+	}, nil
+}
+
+// annotateFileContext implements the annotate_file_context MCP tool
+func (m *MCPServer) annotateFileContext(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, user, err := m.resolveWritableProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
+	filePath := strings.TrimSpace(getStringParam(arguments, "file_path", ""))
+	note := strings.TrimSpace(getStringParam(arguments, "note", ""))
+	if filePath == "" || note == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: "Error: file_path and note parameters are required",
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	fileContext, err := m.knowledgeGraph.AnnotateFileContextByProject(ctx, projectID, user.ID, &FileContextHistory{
+		FilePath:          filePath,
+		ChangeReason:      getStringParam(arguments, "change_reason", ""),
+		DiscussionContext: note,
+		RelatedDecisions:  getStringArrayParam(arguments, "related_decisions"),
+		Contributors:      []string{user.Email},
+	})
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error annotating file context: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("# File Context Recorded: %s\n\n", fileContext.FilePath))
+	content.WriteString(fmt.Sprintf("**Context ID:** %s\n", fileContext.ContextID))
+	content.WriteString(fmt.Sprintf("**Entity ID:** %s\n", fileContext.EntityID))
+	content.WriteString(fmt.Sprintf("**Recorded By:** %s\n", user.Email))
+
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: content.String(),
+		}},
+		StructuredContent: map[string]interface{}{
+			"project_id":   projectID,
+			"file_context": fileContext,
+		},
+	}, nil
+}
+
+// linkEntities implements the link_entities MCP tool
+func (m *MCPServer) linkEntities(ctx context.Context, arguments map[string]interface{}) (*MCPToolResult, error) {
+	projectID, user, err := m.resolveWritableProject(ctx, arguments)
+	if err != nil {
+		return projectAccessError(err), nil
+	}
+
+	sourceRef := getStringParam(arguments, "source", "")
+	targetRef := getStringParam(arguments, "target", "")
+	relationshipType := getStringParam(arguments, "relationship_type", "")
+	if sourceRef == "" || targetRef == "" || relationshipType == "" {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: "Error: source, target and relationship_type parameters are required",
+			}},
+			IsError: true,
+		}, nil
+	}
+	if !containsString(knowledgeRelationshipTypes, relationshipType) {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error: unknown relationship type %q (expected one of %s)", relationshipType, strings.Join(knowledgeRelationshipTypes, ", ")),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	var strength float64 = 1.0
+	if value, ok := arguments["strength"].(float64); ok {
+		strength = value
+	}
+
+	source, err := m.knowledgeGraph.ResolveKnowledgeEntityByProject(ctx, projectID, sourceRef)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error finding source entity: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+	target, err := m.knowledgeGraph.ResolveKnowledgeEntityByProject(ctx, projectID, targetRef)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error finding target entity: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	relationship, err := m.knowledgeGraph.LinkEntitiesByProject(ctx, projectID, user.ID, source.ID, target.ID, relationshipType, strength)
+	if err != nil {
+		return &MCPToolResult{
+			Content: []MCPContent{{
+				Type: "text",
+				Text: fmt.Sprintf("Error linking entities: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	return &MCPToolResult{
+		Content: []MCPContent{{
+			Type: "text",
+			Text: fmt.Sprintf("Linked %s --%s (%.2f)--> %s\n", source.Title, relationship.RelationshipType, relationship.Strength, target.Title),
+		}},
+		StructuredContent: map[string]interface{}{
+			"project_id":   projectID,
+			"relationship": relationship,
+		},
+	}, nil
+}
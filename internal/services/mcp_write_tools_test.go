@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DevAnuragT/context_keeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRepositoryStub keeps written rows in memory, with every entity stored in project p1
+type writeRepositoryStub struct {
+	RepositoryStore
+	entities      map[string]models.KnowledgeEntity
+	decisions     map[string]models.DecisionRecord
+	fileContexts  []models.FileContextHistory
+	relationships []models.KnowledgeRelationship
+}
+
+func newWriteRepositoryStub() *writeRepositoryStub {
+	return &writeRepositoryStub{
+		entities: map[string]models.KnowledgeEntity{
+			"file-entity": {ID: "file-entity", EntityType: "file_context", Title: "File: billing/client.go", Metadata: map[string]interface{}{"project_id": "p1"}},
+			"other":       {ID: "other", EntityType: "decision", Title: "Other project", Metadata: map[string]interface{}{"project_id": "p2"}},
+		},
+		decisions: map[string]models.DecisionRecord{},
+	}
+}
+
+func (r *writeRepositoryStub) CreateKnowledgeEntity(ctx context.Context, entity *models.KnowledgeEntity) error {
+	entity.ID = "entity-" + entity.EntityID
+	r.entities[entity.ID] = *entity
+	return nil
+}
+
+func (r *writeRepositoryStub) GetKnowledgeEntityByIDAndProject(ctx context.Context, id, projectID string) (*models.KnowledgeEntity, error) {
+	entity, ok := r.entities[id]
+	if !ok || entity.Metadata["project_id"] != projectID {
+		return nil, sql.ErrNoRows
+	}
+	return &entity, nil
+}
+
+func (r *writeRepositoryStub) SearchKnowledgeEntitiesByProject(ctx context.Context, projectID string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) {
+	var results []models.SearchResult
+	for _, entity := range r.entities {
+		if entity.Metadata["project_id"] == projectID && entity.Title == query.Query {
+			results = append(results, models.SearchResult{Entity: entity})
+		}
+	}
+	return results, nil
+}
+
+func (r *writeRepositoryStub) CreateDecisionRecord(ctx context.Context, decision *models.DecisionRecord) error {
+	decision.ID = "row-" + decision.DecisionID
+	r.decisions[decision.DecisionID] = *decision
+	return nil
+}
+
+func (r *writeRepositoryStub) GetDecisionRecord(ctx context.Context, decisionID string) (*models.DecisionRecord, error) {
+	decision, ok := r.decisions[decisionID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &decision, nil
+}
+
+func (r *writeRepositoryStub) CreateFileContextHistory(ctx context.Context, fileContext *models.FileContextHistory) error {
+	r.fileContexts = append(r.fileContexts, *fileContext)
+	return nil
+}
+
+func (r *writeRepositoryStub) GetFileContextHistory(ctx context.Context, filePath string) ([]models.FileContextHistory, error) {
+	var contexts []models.FileContextHistory
+	for _, fc := range r.fileContexts {
+		if fc.FilePath == filePath {
+			contexts = append(contexts, fc)
+		}
+	}
+	return contexts, nil
+}
+
+func (r *writeRepositoryStub) CreateKnowledgeRelationship(ctx context.Context, relationship *models.KnowledgeRelationship) error {
+	r.relationships = append(r.relationships, *relationship)
+	return nil
+}
+
+func newWriteTestServer(writable bool) (*MCPServer, *writeRepositoryStub, context.Context) {
+	repo := newWriteRepositoryStub()
+	perms := &readPermissionStub{readable: map[string]bool{"p1": true}, writable: map[string]bool{"p1": writable}}
+	server, ctx := newMCPTestServer(NewKnowledgeGraphService(repo, nil, nil, &SimpleLogger{}), nil, perms)
+	return server, repo, ctx
+}
+
+func TestMCPWriteTools_RequireWriteAccess(t *testing.T) {
+	server, repo, ctx := newWriteTestServer(false)
+
+	for name, args := range map[string]map[string]interface{}{
+		"record_decision":       {"title": "Use Stripe", "decision": "Bill through Stripe"},
+		"annotate_file_context": {"file_path": "billing/client.go", "note": "Wraps the Stripe SDK"},
+		"link_entities":         {"source": "file-entity", "target": "file-entity", "relationship_type": "relates_to"},
+	} {
+		result, err := server.callTool(ctx, name, args)
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+		assert.Contains(t, result.Content[0].Text, "write access", name)
+	}
+
+	assert.Len(t, repo.entities, 2, "nothing was written")
+	assert.Empty(t, repo.relationships)
+}
+
+func TestMCPWriteTools_RecordDecisionAndLinkToFile(t *testing.T) {
+	server, repo, ctx := newWriteTestServer(true)
+
+	result, err := server.callTool(ctx, "record_decision", map[string]interface{}{
+		"title":        "Use Stripe",
+		"decision":     "Bill through Stripe",
+		"rationale":    "Fewer PCI obligations",
+		"alternatives": []interface{}{"Adyen"},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+
+	decision := result.StructuredContent.(map[string]interface{})["decision"].(*models.DecisionRecord)
+	assert.Equal(t, "mcp", decision.PlatformSource)
+	assert.Equal(t, "active", decision.Status)
+	assert.Equal(t, models.StringList{"dev@example.com"}, decision.Participants)
+
+	entity := repo.entities[decision.EntityID]
+	assert.Equal(t, "p1", entity.Metadata["project_id"])
+	assert.Equal(t, "u1", entity.Metadata["recorded_by"])
+
+	result, err = server.callTool(ctx, "link_entities", map[string]interface{}{
+		"source":            "Use Stripe",
+		"target":            "file-entity",
+		"relationship_type": "relates_to",
+		"strength":          0.7,
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+	require.Len(t, repo.relationships, 1)
+	assert.Equal(t, decision.EntityID, repo.relationships[0].SourceEntityID)
+	assert.Equal(t, "file-entity", repo.relationships[0].TargetEntityID)
+	assert.Equal(t, 0.7, repo.relationships[0].Strength)
+	assert.Equal(t, "u1", repo.relationships[0].Metadata["recorded_by"])
+
+	// Entities of other projects cannot be linked
+	result, err = server.callTool(ctx, "link_entities", map[string]interface{}{
+		"source":            "other",
+		"target":            "file-entity",
+		"relationship_type": "relates_to",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Len(t, repo.relationships, 1)
+}
+
+func TestMCPWriteTools_AnnotateFileContext(t *testing.T) {
+	server, repo, ctx := newWriteTestServer(true)
+
+	result, err := server.callTool(ctx, "annotate_file_context", map[string]interface{}{
+		"file_path":     "billing/client.go",
+		"note":          "Wraps the Stripe SDK so retries live in one place",
+		"change_reason": "Centralise retries",
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+
+	require.Len(t, repo.fileContexts, 1)
+	fc := repo.fileContexts[0]
+	assert.Equal(t, "Centralise retries", *fc.ChangeReason)
+	assert.Equal(t, models.StringList{"dev@example.com"}, fc.Contributors)
+	assert.Equal(t, "u1", repo.entities[fc.EntityID].Metadata["recorded_by"])
+
+	result, err = server.callTool(ctx, "get_context_for_file", map[string]interface{}{"file_path": "billing/client.go"})
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content[0].Text)
+	assert.Contains(t, result.Content[0].Text, "Wraps the Stripe SDK so retries live in one place")
+	assert.Contains(t, result.Content[0].Text, "Centralise retries")
+
+	result, err = server.callTool(ctx, "annotate_file_context", map[string]interface{}{"file_path": "billing/client.go"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
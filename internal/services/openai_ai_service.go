@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+)
+
+// maxLLMEventContent bounds how much of each event's content is sent to the model
+const maxLLMEventContent = 2000
+
+// OpenAIService implements AIService with any OpenAI-compatible chat
+// completions API. Every call asks for a reply that conforms to a JSON schema,
+// so the results map directly onto the processor's records.
+type OpenAIService struct {
+	api   *openAIClient
+	model string
+}
+
+// NewOpenAIService creates an AI service for the chat completions API
+// configured in cfg, using its timeout for each request
+func NewOpenAIService(cfg config.AIServiceConfig, logger Logger) *OpenAIService {
+	return &OpenAIService{
+		api:   newOpenAIClient(cfg.LLMBaseURL, cfg.LLMAPIKey, time.Duration(cfg.Timeout)*time.Second, cfg.MaxRetries, logger),
+		model: cfg.LLMModel,
+	}
+}
+
+// NewAIService returns the OpenAI-compatible service when an LLM endpoint is
+// configured, and a service that finds nothing otherwise, leaving extraction
+// to the processor's patterns
+func NewAIService(cfg config.AIServiceConfig, logger Logger) AIService {
+	if cfg.LLMBaseURL == "" {
+		return &ProductionMockAIService{}
+	}
+	return NewOpenAIService(cfg, logger)
+}
+
+// llmEvent is the view of an event the model is given
+type llmEvent struct {
+	ID        string    `json:"id"`
+	Platform  string    `json:"platform"`
+	Type      string    `json:"type"`
+	Author    string    `json:"author"`
+	Timestamp time.Time `json:"timestamp"`
+	Title     string    `json:"title,omitempty"`
+	Content   string    `json:"content"`
+	Files     []string  `json:"files,omitempty"`
+	Labels    []string  `json:"labels,omitempty"`
+	State     string    `json:"state,omitempty"`
+}
+
+// ExtractDecisions asks the model for the engineering decisions the events record
+func (s *OpenAIService) ExtractDecisions(ctx context.Context, events []NormalizedEvent) ([]DecisionRecord, error) {
+	var reply struct {
+		Decisions []struct {
+			Title          string   `json:"title"`
+			Decision       string   `json:"decision"`
+			Rationale      string   `json:"rationale"`
+			Alternatives   []string `json:"alternatives"`
+			Consequences   []string `json:"consequences"`
+			SourceEventIDs []string `json:"source_event_ids"`
+		} `json:"decisions"`
+	}
+	schema := llmObjectSchema(map[string]interface{}{
+		"decisions": llmArraySchema(llmObjectSchema(map[string]interface{}{
+			"title":            llmStringSchema("Short name of the decision"),
+			"decision":         llmStringSchema("What was decided"),
+			"rationale":        llmStringSchema("Why it was decided, or an empty string"),
+			"alternatives":     llmArraySchema(llmStringSchema("An option that was considered and rejected")),
+			"consequences":     llmArraySchema(llmStringSchema("A stated consequence or trade-off")),
+			"source_event_ids": llmArraySchema(llmStringSchema("ID of an event the decision was made in")),
+		})),
+	})
+	prompt := "Extract the engineering decisions that were actually made in these events. " +
+		"Ignore proposals that were not agreed on. Return no decisions if there are none."
+	if err := s.complete(ctx, "decisions", schema, prompt, events, &reply); err != nil {
+		return nil, err
+	}
+
+	byID := llmEventsByID(events)
+	decisions := make([]DecisionRecord, 0, len(reply.Decisions))
+	for _, extracted := range reply.Decisions {
+		sources := llmSourceEvents(byID, extracted.SourceEventIDs, events)
+		decisions = append(decisions, DecisionRecord{
+			ID:             fmt.Sprintf("decision-%s-%d", sources[0].Platform, sources[0].Timestamp.Unix()),
+			Title:          extracted.Title,
+			Decision:       extracted.Decision,
+			Rationale:      extracted.Rationale,
+			Alternatives:   extracted.Alternatives,
+			Consequences:   extracted.Consequences,
+			Status:         "active",
+			PlatformSource: sources[0].Platform,
+			SourceEventIDs: llmEventIDs(sources),
+			Participants:   llmAuthors(sources),
+			CreatedAt:      sources[0].Timestamp,
+		})
+	}
+	return decisions, nil
+}
+
+// SummarizeDiscussion asks the model to summarise the events as one discussion
+func (s *OpenAIService) SummarizeDiscussion(ctx context.Context, events []NormalizedEvent) (*DiscussionSummary, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	var reply struct {
+		Summary     string   `json:"summary"`
+		KeyPoints   []string `json:"key_points"`
+		ActionItems []string `json:"action_items"`
+	}
+	schema := llmObjectSchema(map[string]interface{}{
+		"summary":      llmStringSchema("Two or three sentences on what was discussed and concluded"),
+		"key_points":   llmArraySchema(llmStringSchema("A point someone made that matters later")),
+		"action_items": llmArraySchema(llmStringSchema("Follow-up work someone took on or asked for")),
+	})
+	prompt := "Summarise this discussion for an engineer who was not part of it."
+	if err := s.complete(ctx, "discussion_summary", schema, prompt, events, &reply); err != nil {
+		return nil, err
+	}
+
+	var fileRefs, featureRefs []string
+	for _, event := range events {
+		fileRefs = append(fileRefs, event.FileRefs...)
+		featureRefs = append(featureRefs, event.FeatureRefs...)
+	}
+	threadID := ""
+	if events[0].ThreadID != nil {
+		threadID = *events[0].ThreadID
+	}
+
+	return &DiscussionSummary{
+		ID:                fmt.Sprintf("summary-%s-%d", events[0].Platform, events[0].Timestamp.Unix()),
+		ThreadID:          threadID,
+		Platform:          events[0].Platform,
+		Participants:      llmAuthors(events),
+		Summary:           reply.Summary,
+		KeyPoints:         reply.KeyPoints,
+		ActionItems:       reply.ActionItems,
+		FileReferences:    llmUnique(fileRefs),
+		FeatureReferences: llmUnique(featureRefs),
+		CreatedAt:         time.Now(),
+	}, nil
+}
+
+// IdentifyFeatures asks the model which product features the events work on
+func (s *OpenAIService) IdentifyFeatures(ctx context.Context, events []NormalizedEvent) ([]FeatureContext, error) {
+	var reply struct {
+		Features []struct {
+			Name           string   `json:"name"`
+			Description    string   `json:"description"`
+			Status         string   `json:"status"`
+			SourceEventIDs []string `json:"source_event_ids"`
+		} `json:"features"`
+	}
+	status := llmStringSchema("Where the feature stands according to the events")
+	status["enum"] = []string{"planned", "in_progress", "completed", "deprecated"}
+	schema := llmObjectSchema(map[string]interface{}{
+		"features": llmArraySchema(llmObjectSchema(map[string]interface{}{
+			"name":             llmStringSchema("Short, stable name of the feature"),
+			"description":      llmStringSchema("What the feature does for users"),
+			"status":           status,
+			"source_event_ids": llmArraySchema(llmStringSchema("ID of an event about the feature")),
+		})),
+	})
+	prompt := "Identify the user-facing features these events build, change or remove. " +
+		"Use the same name for the same feature across events."
+	if err := s.complete(ctx, "features", schema, prompt, events, &reply); err != nil {
+		return nil, err
+	}
+
+	byID := llmEventsByID(events)
+	features := make([]FeatureContext, 0, len(reply.Features))
+	for _, extracted := range reply.Features {
+		sources := llmSourceEvents(byID, extracted.SourceEventIDs, events)
+		var files []string
+		for _, event := range sources {
+			files = append(files, event.FileRefs...)
+		}
+		features = append(features, FeatureContext{
+			ID:           fmt.Sprintf("feature-%s-%d", strings.ReplaceAll(extracted.Name, " ", "-"), sources[0].Timestamp.Unix()),
+			FeatureName:  extracted.Name,
+			Description:  extracted.Description,
+			Status:       extracted.Status,
+			Contributors: llmAuthors(sources),
+			RelatedFiles: llmUnique(files),
+			Discussions:  llmEventIDs(sources),
+			CreatedAt:    sources[0].Timestamp,
+			UpdatedAt:    sources[len(sources)-1].Timestamp,
+		})
+	}
+	return features, nil
+}
+
+// AnalyzeFileContext asks the model why each file the events reference was changed
+func (s *OpenAIService) AnalyzeFileContext(ctx context.Context, events []NormalizedEvent) ([]FileContextHistory, error) {
+	referenced := make(map[string]bool)
+	for _, event := range events {
+		for _, file := range event.FileRefs {
+			referenced[file] = true
+		}
+	}
+	if len(referenced) == 0 {
+		return []FileContextHistory{}, nil
+	}
+
+	var reply struct {
+		Files []struct {
+			FilePath       string   `json:"file_path"`
+			ChangeReason   string   `json:"change_reason"`
+			SourceEventIDs []string `json:"source_event_ids"`
+		} `json:"files"`
+	}
+	schema := llmObjectSchema(map[string]interface{}{
+		"files": llmArraySchema(llmObjectSchema(map[string]interface{}{
+			"file_path":        llmStringSchema("Path of a file listed in the events"),
+			"change_reason":    llmStringSchema("One sentence on why the file was changed"),
+			"source_event_ids": llmArraySchema(llmStringSchema("ID of an event that explains the change")),
+		})),
+	})
+	prompt := "For each file listed in the events, explain in one sentence why it was changed."
+	if err := s.complete(ctx, "file_contexts", schema, prompt, events, &reply); err != nil {
+		return nil, err
+	}
+
+	byID := llmEventsByID(events)
+	fileContexts := make([]FileContextHistory, 0, len(reply.Files))
+	for _, extracted := range reply.Files {
+		if !referenced[extracted.FilePath] {
+			continue // the model may only explain files the events reference
+		}
+		sources := llmSourceEvents(byID, extracted.SourceEventIDs, events)
+		platformSources := make(map[string]interface{})
+		for _, event := range sources {
+			ids, _ := platformSources[event.Platform].([]string)
+			platformSources[event.Platform] = append(ids, event.PlatformID)
+		}
+		var discussion []string
+		for _, event := range sources {
+			discussion = append(discussion, event.Content)
+		}
+		fileContexts = append(fileContexts, FileContextHistory{
+			ID:                fmt.Sprintf("file-%s-%d", strings.ReplaceAll(extracted.FilePath, "/", "-"), sources[0].Timestamp.Unix()),
+			FilePath:          extracted.FilePath,
+			ChangeReason:      extracted.ChangeReason,
+			DiscussionContext: strings.Join(discussion, "\n"),
+			Contributors:      llmAuthors(sources),
+			PlatformSources:   platformSources,
+			CreatedAt:         sources[0].Timestamp,
+		})
+	}
+	return fileContexts, nil
+}
+
+// chatCompletionRequest is the body of a chat completions call
+type chatCompletionRequest struct {
+	Model          string                 `json:"model"`
+	Messages       []chatMessage          `json:"messages"`
+	Temperature    float64                `json:"temperature"`
+	ResponseFormat map[string]interface{} `json:"response_format"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+			Refusal string `json:"refusal"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// complete sends the events with an instruction and decodes the model's reply,
+// which is constrained to schema, into reply
+func (s *OpenAIService) complete(ctx context.Context, name string, schema map[string]interface{}, instruction string, events []NormalizedEvent, reply interface{}) error {
+	view := make([]llmEvent, 0, len(events))
+	for _, event := range events {
+		content := event.Content
+		if len(content) > maxLLMEventContent {
+			content = content[:maxLLMEventContent] + "..."
+		}
+		view = append(view, llmEvent{
+			ID:        event.PlatformID,
+			Platform:  event.Platform,
+			Type:      string(event.EventType),
+			Author:    event.Author,
+			Timestamp: event.Timestamp,
+			Title:     event.Title,
+			Content:   content,
+			Files:     event.FileRefs,
+			Labels:    event.Labels,
+			State:     event.State,
+		})
+	}
+	eventsJSON, err := json.Marshal(view)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	request := chatCompletionRequest{
+		Model: s.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: "You extract engineering knowledge from software team activity. " +
+				"Use only what the events say; never invent names, files or decisions. Refer to events by their id."},
+			{Role: "user", Content: instruction + "\n\nEvents:\n" + string(eventsJSON)},
+		},
+		Temperature: 0,
+		ResponseFormat: map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"name":   name,
+				"strict": true,
+				"schema": schema,
+			},
+		},
+	}
+
+	var completion chatCompletionResponse
+	if err := s.api.post(ctx, "/chat/completions", request, &completion); err != nil {
+		return fmt.Errorf("LLM %s request failed: %w", name, err)
+	}
+	if len(completion.Choices) == 0 {
+		return fmt.Errorf("LLM response has no choices")
+	}
+	choice := completion.Choices[0]
+	if choice.Message.Refusal != "" {
+		return fmt.Errorf("LLM refused the request: %s", choice.Message.Refusal)
+	}
+	if choice.FinishReason == "length" {
+		return fmt.Errorf("LLM reply was cut off at the token limit")
+	}
+
+	if err := json.Unmarshal([]byte(choice.Message.Content), reply); err != nil {
+		return fmt.Errorf("failed to parse LLM %s reply: %w", name, err)
+	}
+	return nil
+}
+
+// llmObjectSchema builds a strict JSON schema object: every property is
+// required and no others are allowed, as structured outputs demand
+func llmObjectSchema(properties map[string]interface{}) map[string]interface{} {
+	required := make([]string, 0, len(properties))
+	for name := range properties {
+		required = append(required, name)
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func llmArraySchema(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+func llmStringSchema(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func llmEventsByID(events []NormalizedEvent) map[string]NormalizedEvent {
+	byID := make(map[string]NormalizedEvent, len(events))
+	for _, event := range events {
+		byID[event.PlatformID] = event
+	}
+	return byID
+}
+
+// llmSourceEvents resolves the event IDs a reply cites, ignoring unknown IDs;
+// replies that cite none are attributed to the first event
+func llmSourceEvents(byID map[string]NormalizedEvent, ids []string, events []NormalizedEvent) []NormalizedEvent {
+	var sources []NormalizedEvent
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if event, ok := byID[id]; ok && !seen[id] {
+			seen[id] = true
+			sources = append(sources, event)
+		}
+	}
+	if len(sources) == 0 {
+		sources = events[:1]
+	}
+	return sources
+}
+
+func llmEventIDs(events []NormalizedEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.PlatformID)
+	}
+	return ids
+}
+
+func llmAuthors(events []NormalizedEvent) []string {
+	var authors []string
+	for _, event := range events {
+		authors = append(authors, event.Author)
+	}
+	return llmUnique(authors)
+}
+
+func llmUnique(values []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, value := range values {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DevAnuragT/context_keeper/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chatCompletionReply wraps content the way a chat completions API does
+func chatCompletionReply(t *testing.T, w http.ResponseWriter, content interface{}) {
+	t.Helper()
+	encoded, err := json.Marshal(content)
+	require.NoError(t, err)
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(t, json.NewEncoder(w).Encode(map[string]interface{}{
+		"choices": []map[string]interface{}{{
+			"message":       map[string]interface{}{"role": "assistant", "content": string(encoded)},
+			"finish_reason": "stop",
+		}},
+	}))
+}
+
+func newTestOpenAIService(baseURL string, maxRetries int) *OpenAIService {
+	service := NewOpenAIService(config.AIServiceConfig{
+		Timeout:    5,
+		MaxRetries: maxRetries,
+		LLMBaseURL: baseURL + "/v1/",
+		LLMAPIKey:  "sk-test",
+		LLMModel:   "test-model",
+	}, &SimpleLogger{})
+	service.api.retryDelay = time.Millisecond
+	return service
+}
+
+func cacheDecisionEvents() []NormalizedEvent {
+	base := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	thread := "thread-1"
+	return []NormalizedEvent{
+		{PlatformID: "msg-1", Platform: "slack", EventType: EventTypeMessage, ThreadID: &thread, Author: "alice", Timestamp: base, Content: "Should the session cache live in Redis or in process?"},
+		{PlatformID: "msg-2", Platform: "slack", EventType: EventTypeMessage, ThreadID: &thread, Author: "bob", Timestamp: base.Add(time.Minute), Content: "We decided to use Redis because sessions must survive deploys.", FileRefs: []string{"auth/session.go"}},
+	}
+}
+
+func TestOpenAIService_ExtractDecisions(t *testing.T) {
+	var request chatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+		chatCompletionReply(t, w, map[string]interface{}{
+			"decisions": []map[string]interface{}{{
+				"title":            "Store sessions in Redis",
+				"decision":         "The session cache moves to Redis",
+				"rationale":        "Sessions must survive deploys",
+				"alternatives":     []string{"In-process cache"},
+				"consequences":     []string{},
+				"source_event_ids": []string{"msg-1", "msg-2", "unknown"},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	decisions, err := newTestOpenAIService(server.URL, 0).ExtractDecisions(context.Background(), cacheDecisionEvents())
+	require.NoError(t, err)
+
+	// The reply is constrained to a strict schema
+	assert.Equal(t, "test-model", request.Model)
+	assert.Equal(t, "json_schema", request.ResponseFormat["type"])
+	jsonSchema := request.ResponseFormat["json_schema"].(map[string]interface{})
+	assert.Equal(t, "decisions", jsonSchema["name"])
+	assert.Equal(t, true, jsonSchema["strict"])
+	item := jsonSchema["schema"].(map[string]interface{})["properties"].(map[string]interface{})["decisions"].(map[string]interface{})["items"].(map[string]interface{})
+	assert.Equal(t, false, item["additionalProperties"])
+	assert.Len(t, item["required"], 6)
+	require.Len(t, request.Messages, 2)
+	assert.Contains(t, request.Messages[1].Content, `"id":"msg-2"`)
+
+	require.Len(t, decisions, 1)
+	decision := decisions[0]
+	assert.Equal(t, "Store sessions in Redis", decision.Title)
+	assert.Equal(t, []string{"In-process cache"}, decision.Alternatives)
+	assert.Equal(t, []string{"msg-1", "msg-2"}, decision.SourceEventIDs)
+	assert.Equal(t, []string{"alice", "bob"}, decision.Participants)
+	assert.Equal(t, "slack", decision.PlatformSource)
+	assert.Equal(t, "active", decision.Status)
+}
+
+func TestOpenAIService_AnalyzeFileContextOnlyExplainsReferencedFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		chatCompletionReply(t, w, map[string]interface{}{
+			"files": []map[string]interface{}{
+				{"file_path": "auth/session.go", "change_reason": "Move sessions to Redis", "source_event_ids": []string{"msg-2"}},
+				{"file_path": "auth/invented.go", "change_reason": "Not in any event", "source_event_ids": []string{}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	fileContexts, err := newTestOpenAIService(server.URL, 0).AnalyzeFileContext(context.Background(), cacheDecisionEvents())
+	require.NoError(t, err)
+	require.Len(t, fileContexts, 1)
+	assert.Equal(t, "auth/session.go", fileContexts[0].FilePath)
+	assert.Equal(t, "Move sessions to Redis", fileContexts[0].ChangeReason)
+	assert.Equal(t, []string{"bob"}, fileContexts[0].Contributors)
+}
+
+func TestOpenAIService_RetriesRateLimitsAndServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			chatCompletionReply(t, w, map[string]interface{}{"summary": "Sessions move to Redis", "key_points": []string{}, "action_items": []string{}})
+		}
+	}))
+	defer server.Close()
+
+	summary, err := newTestOpenAIService(server.URL, 2).SummarizeDiscussion(context.Background(), cacheDecisionEvents())
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, "Sessions move to Redis", summary.Summary)
+	assert.Equal(t, []string{"auth/session.go"}, summary.FileReferences)
+
+	// Retries run out
+	atomic.StoreInt32(&calls, 0)
+	_, err = newTestOpenAIService(server.URL, 1).SummarizeDiscussion(context.Background(), cacheDecisionEvents())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 502")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOpenAIService_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, `{"error":{"message":"invalid schema"}}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := newTestOpenAIService(server.URL, 3).IdentifyFeatures(context.Background(), cacheDecisionEvents())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid schema")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestOpenAIService_TimesOutEachAttempt(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	service := newTestOpenAIService(server.URL, 1)
+	service.api.client.Timeout = 50 * time.Millisecond
+
+	_, err := service.ExtractDecisions(context.Background(), cacheDecisionEvents())
+	require.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOpenAIService_Refusal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{
+				"message":       map[string]interface{}{"role": "assistant", "refusal": "I can't help with that."},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	_, err := newTestOpenAIService(server.URL, 2).ExtractDecisions(context.Background(), cacheDecisionEvents())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refused")
+}
+
+// scriptedAIService returns fixed results, or fails every call
+type scriptedAIService struct {
+	decisions []DecisionRecord
+	summary   *DiscussionSummary
+	features  []FeatureContext
+	files     []FileContextHistory
+	err       error
+}
+
+func (s *scriptedAIService) ExtractDecisions(ctx context.Context, events []NormalizedEvent) ([]DecisionRecord, error) {
+	return s.decisions, s.err
+}
+
+func (s *scriptedAIService) SummarizeDiscussion(ctx context.Context, events []NormalizedEvent) (*DiscussionSummary, error) {
+	return s.summary, s.err
+}
+
+func (s *scriptedAIService) IdentifyFeatures(ctx context.Context, events []NormalizedEvent) ([]FeatureContext, error) {
+	return s.features, s.err
+}
+
+func (s *scriptedAIService) AnalyzeFileContext(ctx context.Context, events []NormalizedEvent) ([]FileContextHistory, error) {
+	return s.files, s.err
+}
+
+func TestContextProcessor_MergesAIResultsWithPatterns(t *testing.T) {
+	ai := &scriptedAIService{
+		decisions: []DecisionRecord{
+			{Title: "Store sessions in Redis", Decision: "The session cache moves to Redis", Alternatives: []string{"In-process cache"}, SourceEventIDs: []string{"msg-2"}, Participants: []string{"bob"}},
+			{Title: "Keep session TTL at one day", Decision: "Sessions expire after a day", SourceEventIDs: []string{"msg-1"}},
+		},
+		summary:  &DiscussionSummary{Summary: "The team moved the session cache to Redis.", ActionItems: []string{"Provision Redis"}},
+		features: []FeatureContext{{FeatureName: "Session storage", Description: "Where login sessions are kept", Status: "in_progress"}},
+		files:    []FileContextHistory{{FilePath: "auth/session.go", ChangeReason: "Sessions move to Redis", Contributors: []string{"bob"}}},
+	}
+	processor := NewContextProcessor(ai, &SimpleLogger{})
+
+	result, err := processor.ProcessEvents(context.Background(), cacheDecisionEvents())
+	require.NoError(t, err)
+
+	// The pattern-matched decision in msg-2 is refined, the other is added
+	require.Len(t, result.DecisionRecords, 2)
+	merged := result.DecisionRecords[0]
+	assert.Equal(t, "Store sessions in Redis", merged.Title)
+	assert.Equal(t, "because sessions must survive deploys", merged.Rationale, "kept from the pattern match")
+	assert.Equal(t, []string{"In-process cache"}, merged.Alternatives)
+	assert.Equal(t, "Keep session TTL at one day", result.DecisionRecords[1].Title)
+
+	require.Len(t, result.DiscussionSummaries, 1)
+	assert.Equal(t, "The team moved the session cache to Redis.", result.DiscussionSummaries[0].Summary)
+	assert.Contains(t, result.DiscussionSummaries[0].ActionItems, "Provision Redis")
+
+	require.Len(t, result.FeatureContexts, 1)
+	assert.Equal(t, "Session storage", result.FeatureContexts[0].FeatureName)
+
+	require.Len(t, result.FileContexts, 1)
+	assert.Equal(t, "Sessions move to Redis", result.FileContexts[0].ChangeReason)
+}
+
+func TestContextProcessor_KeepsPatternResultsWhenAIFails(t *testing.T) {
+	processor := NewContextProcessor(&scriptedAIService{err: errors.New("LLM request failed: connection refused")}, &SimpleLogger{})
+
+	result, err := processor.ProcessEvents(context.Background(), cacheDecisionEvents())
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	require.Len(t, result.DecisionRecords, 1)
+	assert.Equal(t, "We decided to use Redis because sessions must survive deploys", result.DecisionRecords[0].Title)
+	require.Len(t, result.FileContexts, 1)
+	assert.Equal(t, "auth/session.go", result.FileContexts[0].FilePath)
+}
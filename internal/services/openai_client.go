@@ -0,0 +1,133 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultOpenAIRetryDelay is the first backoff after a failed request
+const defaultOpenAIRetryDelay = time.Second
+
+// openAIClient sends JSON requests to an OpenAI-compatible API. Rate limits,
+// server errors and network failures, including timeouts, are retried with
+// exponential backoff.
+type openAIClient struct {
+	baseURL    string
+	apiKey     string
+	maxRetries int
+	retryDelay time.Duration
+	client     *http.Client
+	logger     Logger
+}
+
+func newOpenAIClient(baseURL, apiKey string, timeout time.Duration, maxRetries int, logger Logger) *openAIClient {
+	return &openAIClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		maxRetries: maxRetries,
+		retryDelay: defaultOpenAIRetryDelay,
+		client:     &http.Client{Timeout: timeout},
+		logger:     logger,
+	}
+}
+
+// openAIStatusError is a request that returned a non-200 status
+type openAIStatusError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *openAIStatusError) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable reports whether the request may succeed if it is sent again
+func (e *openAIStatusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// post sends request to path and decodes the reply into response
+func (c *openAIClient) post(ctx context.Context, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = c.send(ctx, path, body, response)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *openAIStatusError
+		var transportErr *url.Error
+		isStatus := errors.As(err, &statusErr)
+		retryable := (isStatus && statusErr.retryable()) || errors.As(err, &transportErr)
+		if !retryable || attempt >= c.maxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		delay := c.retryDelay * time.Duration(1<<attempt)
+		if isStatus && statusErr.RetryAfter > delay {
+			delay = statusErr.RetryAfter
+		}
+		c.logger.Info("Retrying API request", map[string]interface{}{
+			"path":    path,
+			"attempt": attempt + 1,
+			"delay":   delay.String(),
+			"error":   err.Error(),
+		})
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send makes one attempt at a request
+func (c *openAIClient) send(ctx context.Context, path string, body []byte, response interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &openAIStatusError{StatusCode: resp.StatusCode, Body: string(responseBody)}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			statusErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return statusErr
+	}
+
+	if err := json.Unmarshal(responseBody, response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
@@ -484,6 +484,8 @@ func (m *MockTenantIsolationStore) SearchKnowledgeEntitiesByProject(ctx context.
 // Stub implementations for other required methods
 func (m *MockTenantIsolationStore) UpdateKnowledgeEntity(ctx context.Context, entity *models.KnowledgeEntity) error { return nil }
 func (m *MockTenantIsolationStore) DeleteKnowledgeEntity(ctx context.Context, id string) error { return nil }
+func (m *MockTenantIsolationStore) ListKnowledgeEntitiesNeedingEmbedding(ctx context.Context, model string, limit int) ([]models.KnowledgeEntity, error) { return nil, nil }
+func (m *MockTenantIsolationStore) UpdateKnowledgeEntityEmbedding(ctx context.Context, id string, embedding []float32, model string, contentUpdatedAt time.Time) error { return nil }
 func (m *MockTenantIsolationStore) GetKnowledgeEntitiesByProject(ctx context.Context, projectID string, entityTypes []string, limit int) ([]models.KnowledgeEntity, error) { return nil, nil }
 func (m *MockTenantIsolationStore) GetKnowledgeEntityByIDAndProject(ctx context.Context, id, projectID string) (*models.KnowledgeEntity, error) { return nil, nil }
 func (m *MockTenantIsolationStore) CreateKnowledgeRelationship(ctx context.Context, relationship *models.KnowledgeRelationship) error { return nil }
@@ -502,10 +504,15 @@ func (m *MockTenantIsolationStore) GetFileContextHistory(ctx context.Context, fi
 func (m *MockTenantIsolationStore) SearchKnowledgeEntities(ctx context.Context, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) { return nil, nil }
 func (m *MockTenantIsolationStore) SearchSimilarEntities(ctx context.Context, embedding []float32, entityTypes []string, limit int) ([]models.SearchResult, error) { return nil, nil }
 func (m *MockTenantIsolationStore) SearchSimilarEntitiesByProject(ctx context.Context, projectID string, embedding []float32, entityTypes []string, limit int) ([]models.SearchResult, error) { return nil, nil }
+func (m *MockTenantIsolationStore) SearchKnowledgeEntitiesByEmbedding(ctx context.Context, projectID string, embedding []float32, model string, query *models.KnowledgeGraphQuery) ([]models.SearchResult, error) { return nil, nil }
+func (m *MockTenantIsolationStore) CountKnowledgeRelationships(ctx context.Context, entityIDs []string) (map[string]int, error) { return nil, nil }
 func (m *MockTenantIsolationStore) TraverseKnowledgeGraph(ctx context.Context, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error) { return nil, nil }
 func (m *MockTenantIsolationStore) GetRelatedEntities(ctx context.Context, entityID string, relationshipTypes []string, limit int) ([]models.KnowledgeEntity, error) { return nil, nil }
 func (m *MockTenantIsolationStore) TraverseKnowledgeGraphByProject(ctx context.Context, projectID, startEntityID string, maxDepth int, relationshipTypes []string) (*models.GraphTraversalResult, error) { return nil, nil }
 func (m *MockTenantIsolationStore) GetRelatedEntitiesByProject(ctx context.Context, projectID, entityID string, relationshipTypes []string, limit int) ([]models.KnowledgeEntity, error) { return nil, nil }
+func (m *MockTenantIsolationStore) ListFilePathsByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) { return nil, nil }
+func (m *MockTenantIsolationStore) ListFeatureNamesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) { return nil, nil }
+func (m *MockTenantIsolationStore) ListDecisionTitlesByProject(ctx context.Context, projectID, contains string, limit int) ([]string, error) { return nil, nil }
 func (m *MockTenantIsolationStore) CreatePullRequest(ctx context.Context, pr *models.PullRequest) error { return nil }
 func (m *MockTenantIsolationStore) GetRecentPRs(ctx context.Context, repoID int64, limit int) ([]models.PullRequest, error) { return nil, nil }
 func (m *MockTenantIsolationStore) GetRecentPRsByProject(ctx context.Context, projectID string, limit int) ([]models.PullRequest, error) { return nil, nil }
@@ -546,4 +553,7 @@ func (m *MockTenantIsolationStore) GetProjectDataSource(ctx context.Context, dat
 func (m *MockTenantIsolationStore) GetProjectDataSources(ctx context.Context, projectID string) ([]models.ProjectDataSource, error) { return nil, nil }
 func (m *MockTenantIsolationStore) GetProjectDataSourcesByIntegration(ctx context.Context, integrationID string) ([]models.ProjectDataSource, error) { return nil, nil }
 func (m *MockTenantIsolationStore) UpdateProjectDataSource(ctx context.Context, dataSourceID string, updates map[string]interface{}) error { return nil }
-func (m *MockTenantIsolationStore) DeleteProjectDataSource(ctx context.Context, dataSourceID string) error { return nil }
\ No newline at end of file
+func (m *MockTenantIsolationStore) DeleteProjectDataSource(ctx context.Context, dataSourceID string) error { return nil }
+func (m *MockTenantIsolationStore) CreateMCPToolCall(ctx context.Context, call *models.MCPToolCall) error { return nil }
+func (m *MockTenantIsolationStore) GetMCPToolCallTotals(ctx context.Context, projectID, userID string, since time.Time) (*models.MCPToolCallTotals, error) { return &models.MCPToolCallTotals{}, nil }
+func (m *MockTenantIsolationStore) GetMCPToolUsageByProject(ctx context.Context, projectID string, since time.Time) ([]models.MCPToolUsage, error) { return nil, nil }
\ No newline at end of file